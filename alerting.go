@@ -0,0 +1,350 @@
+package versifi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAlertTimeout   = 10 * time.Second
+	defaultAlertQueueSize = 256
+)
+
+// ErrAlertQueueFull is reported when notifiers fall so far behind that an
+// alert is dropped
+var ErrAlertQueueFull = errors.New("alert queue full")
+
+// AlertType is the kind of event an alert reports
+type AlertType string
+
+const (
+	AlertOrderRejected   AlertType = "ORDER_REJECTED"
+	AlertOrderFilled     AlertType = "ORDER_FILLED"
+	AlertReconnectFailed AlertType = "RECONNECT_FAILED"
+)
+
+// Alert is an event worth telling a person about
+type Alert struct {
+	Type    AlertType
+	Time    time.Time
+	Summary string      // One line description
+	Order   *OrderState // Set for order alerts
+	Err     error       // Set for AlertReconnectFailed
+}
+
+// Notifier delivers alerts, for example to a chat channel or pager
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NotifierFunc adapts a function to a Notifier
+type NotifierFunc func(ctx context.Context, alert Alert) error
+
+// Notify calls f(ctx, alert)
+func (f NotifierFunc) Notify(ctx context.Context, alert Alert) error {
+	return f(ctx, alert)
+}
+
+// Alerter turns order events and connection failures into alerts and
+// passes them to notifiers, each configured with the alert types it wants.
+//
+//	alerter := versifi.NewAlerter()
+//	alerter.Add(versifi.NewSlackNotifier(webhookURL), versifi.AlertOrderRejected, versifi.AlertReconnectFailed)
+//	alerter.Attach(bus)
+//	alerter.AttachWs(ws)
+//	defer alerter.Close(ctx)
+//
+// Alerts are delivered from a single goroutine, in order, so a slow
+// notifier never blocks the WebSocket read loop. Delivery failures are
+// passed to the error handler and not retried.
+type Alerter struct {
+	// Timeout bounds each notification, default 10s
+	Timeout time.Duration
+
+	queue chan Alert
+	done  chan struct{}
+
+	mu         sync.RWMutex
+	notifiers  map[int]alertNotifier
+	nextID     int
+	closed     bool
+	errHandler ErrHandler
+}
+
+type alertNotifier struct {
+	notifier Notifier
+	types    []AlertType
+}
+
+// NewAlerter creates an alerter with no notifiers and starts its delivery
+// goroutine
+func NewAlerter() *Alerter {
+	a := &Alerter{
+		Timeout:   defaultAlertTimeout,
+		queue:     make(chan Alert, defaultAlertQueueSize),
+		done:      make(chan struct{}),
+		notifiers: make(map[int]alertNotifier),
+	}
+	go a.deliver()
+	return a
+}
+
+// SetErrorHandler sets the handler for failed and dropped notifications
+func (a *Alerter) SetErrorHandler(handler ErrHandler) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.errHandler = handler
+}
+
+// Add registers notifier for the given alert types, or every type if none
+// are given, and returns a function that removes it
+func (a *Alerter) Add(notifier Notifier, types ...AlertType) (remove func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	id := a.nextID
+	a.nextID++
+	a.notifiers[id] = alertNotifier{notifier: notifier, types: types}
+	return func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		delete(a.notifiers, id)
+	}
+}
+
+// Attach raises alerts for the rejected and filled orders published on bus
+func (a *Alerter) Attach(bus *EventBus) (detach func()) {
+	return bus.Subscribe(a.HandleOrderEvent, EventTypes(OrderRejected, OrderFilled))
+}
+
+// AttachWs raises an alert when ws fails to reconnect. It registers with
+// OnReconnect, so the client's reconnect handler keeps being called.
+func (a *Alerter) AttachWs(ws *WsClient) (detach func()) {
+	return ws.OnReconnect(func(err error) {
+		if err != nil {
+			a.HandleReconnectFailure(err)
+		}
+	})
+}
+
+// HandleOrderEvent raises an alert for OrderRejected and OrderFilled events
+// and ignores the others
+func (a *Alerter) HandleOrderEvent(event OrderEvent) {
+	order := event.Order
+	var alert Alert
+	switch event.Type {
+	case OrderRejected:
+		alert = Alert{
+			Type:    AlertOrderRejected,
+			Summary: fmt.Sprintf("Order %d rejected: %s", order.OrderID, order.RejectReason),
+		}
+	case OrderFilled:
+		summary := fmt.Sprintf("Order %d filled", order.OrderID)
+		if order.FilledQuantity != "" {
+			summary = fmt.Sprintf("Order %d filled: %s %s %s on %s at %s", order.OrderID,
+				order.Side, order.FilledQuantity, order.Symbol, order.Exchange, order.AveragePrice)
+		}
+		alert = Alert{Type: AlertOrderFilled, Summary: summary}
+	default:
+		return
+	}
+	alert.Order = &order
+	a.Raise(alert)
+}
+
+// HandleReconnectFailure raises an AlertReconnectFailed alert
+func (a *Alerter) HandleReconnectFailure(err error) {
+	a.Raise(Alert{
+		Type:    AlertReconnectFailed,
+		Summary: fmt.Sprintf("WebSocket reconnect failed: %v", err),
+		Err:     err,
+	})
+}
+
+// Raise queues alert for the notifiers registered for its type. It never
+// blocks; when the queue is full the alert is dropped and reported.
+func (a *Alerter) Raise(alert Alert) {
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return
+	}
+	select {
+	case a.queue <- alert:
+	default:
+		go a.error(fmt.Errorf("%w: dropped %s alert", ErrAlertQueueFull, alert.Type))
+	}
+}
+
+// Close stops accepting alerts and waits for the queued ones to be
+// delivered. Use ctx to stop waiting.
+func (a *Alerter) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *Alerter) deliver() {
+	defer close(a.done)
+	for alert := range a.queue {
+		for _, n := range a.notifiersFor(alert.Type) {
+			ctx, cancel := context.WithTimeout(context.Background(), a.Timeout)
+			err := n.Notify(ctx, alert)
+			cancel()
+			if err != nil {
+				a.error(fmt.Errorf("notify %s alert: %w", alert.Type, err))
+			}
+		}
+	}
+}
+
+// notifiersFor returns the notifiers registered for t, in the order they
+// were added
+func (a *Alerter) notifiersFor(t AlertType) []Notifier {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	ids := make([]int, 0, len(a.notifiers))
+	for id := range a.notifiers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var notifiers []Notifier
+	for _, id := range ids {
+		n := a.notifiers[id]
+		if len(n.types) == 0 {
+			notifiers = append(notifiers, n.notifier)
+			continue
+		}
+		for _, want := range n.types {
+			if want == t {
+				notifiers = append(notifiers, n.notifier)
+				break
+			}
+		}
+	}
+	return notifiers
+}
+
+func (a *Alerter) error(err error) {
+	a.mu.RLock()
+	handler := a.errHandler
+	a.mu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	WebhookURL string
+	// Channel and Username override the webhook defaults when set
+	Channel    string
+	Username   string
+	HTTPClient *http.Client
+}
+
+// NewSlackNotifier creates a notifier posting to a Slack incoming webhook
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{WebhookURL: webhookURL, HTTPClient: http.DefaultClient}
+}
+
+// Notify posts the alert summary as the message text
+func (n *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	msg := map[string]string{"text": fmt.Sprintf("[%s] %s", alert.Type, alert.Summary)}
+	if n.Channel != "" {
+		msg["channel"] = n.Channel
+	}
+	if n.Username != "" {
+		msg["username"] = n.Username
+	}
+	return postAlertJSON(ctx, n.HTTPClient, n.WebhookURL, nil, msg)
+}
+
+// HTTPNotifier POSTs alerts as JSON to a URL:
+//
+//	{"type": "ORDER_REJECTED", "time": 1700000000000, "summary": "...",
+//	 "order": {...}, "error": "..."}
+//
+// time is in milliseconds; order and error are omitted when not set.
+type HTTPNotifier struct {
+	URL string
+	// Header is added to every request, for example for authorization
+	Header     http.Header
+	HTTPClient *http.Client
+}
+
+// NewHTTPNotifier creates a notifier posting to url
+func NewHTTPNotifier(url string) *HTTPNotifier {
+	return &HTTPNotifier{URL: url, HTTPClient: http.DefaultClient}
+}
+
+// Notify posts the alert as JSON
+func (n *HTTPNotifier) Notify(ctx context.Context, alert Alert) error {
+	body := struct {
+		Type    AlertType   `json:"type"`
+		Time    int64       `json:"time"`
+		Summary string      `json:"summary"`
+		Order   *OrderState `json:"order,omitempty"`
+		Error   string      `json:"error,omitempty"`
+	}{
+		Type:    alert.Type,
+		Time:    alert.Time.UnixMilli(),
+		Summary: alert.Summary,
+		Order:   alert.Order,
+	}
+	if alert.Err != nil {
+		body.Error = alert.Err.Error()
+	}
+	return postAlertJSON(ctx, n.HTTPClient, n.URL, n.Header, body)
+}
+
+func postAlertJSON(ctx context.Context, client *http.Client, url string, header http.Header, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, values := range header {
+		for _, value := range values {
+			req.Header.Add(k, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAlerterNotifiers(t *testing.T) {
+	slack := make(chan map[string]string, 10)
+	slackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]string
+		json.NewDecoder(r.Body).Decode(&msg)
+		slack <- msg
+	}))
+	defer slackServer.Close()
+
+	type payload struct {
+		Type    AlertType   `json:"type"`
+		Summary string      `json:"summary"`
+		Order   *OrderState `json:"order"`
+		Error   string      `json:"error"`
+	}
+	generic := make(chan payload, 10)
+	genericServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var p payload
+		json.NewDecoder(r.Body).Decode(&p)
+		generic <- p
+	}))
+	defer genericServer.Close()
+
+	alerter := NewAlerter()
+	s := NewSlackNotifier(slackServer.URL)
+	s.Channel = "#desk"
+	alerter.Add(s, AlertOrderRejected, AlertReconnectFailed)
+	h := NewHTTPNotifier(genericServer.URL)
+	h.Header = http.Header{"Authorization": {"Bearer token"}}
+	alerter.Add(h)
+
+	bus := NewEventBus()
+	alerter.Attach(bus)
+	bus.Publish(OrderEvent{Type: OrderAccepted, Order: OrderState{OrderID: 1}})
+	bus.Publish(OrderEvent{Type: OrderRejected, Order: OrderState{OrderID: 1, RejectReason: "insufficient balance"}})
+	bus.Publish(OrderEvent{Type: OrderFilled, Order: OrderState{
+		OrderID: 2, Side: SideTypeBuy, FilledQuantity: "1.5", Symbol: "BTC/USDT", Exchange: ExchangeBinanceSpot, AveragePrice: "100",
+	}})
+	alerter.HandleReconnectFailure(errors.New("dial tcp: connection refused"))
+	if err := alerter.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(slack) != 2 {
+		t.Fatalf("Expected 2 Slack messages, got %d", len(slack))
+	}
+	if msg := <-slack; msg["text"] != "[ORDER_REJECTED] Order 1 rejected: insufficient balance" || msg["channel"] != "#desk" {
+		t.Errorf("Unexpected Slack message %v", msg)
+	}
+	if msg := <-slack; !strings.HasPrefix(msg["text"], "[RECONNECT_FAILED]") {
+		t.Errorf("Unexpected Slack message %v", msg)
+	}
+
+	if len(generic) != 3 {
+		t.Fatalf("Expected 3 HTTP alerts, got %d", len(generic))
+	}
+	<-generic
+	if p := <-generic; p.Type != AlertOrderFilled || p.Order.OrderID != 2 || p.Summary != "Order 2 filled: BUY 1.5 BTC/USDT on BINANCE_SPOT at 100" {
+		t.Errorf("Unexpected fill alert %+v", p)
+	}
+	if p := <-generic; p.Type != AlertReconnectFailed || p.Error != "dial tcp: connection refused" || p.Order != nil {
+		t.Errorf("Unexpected reconnect alert %+v", p)
+	}
+}
+
+func TestAlerterNotifyError(t *testing.T) {
+	alerter := NewAlerter()
+	errs := make(chan error, 1)
+	alerter.SetErrorHandler(func(err error) { errs <- err })
+	alerter.Add(NotifierFunc(func(ctx context.Context, alert Alert) error {
+		return errors.New("pager down")
+	}))
+
+	alerter.Raise(Alert{Type: AlertOrderRejected, Summary: "test"})
+	alerter.Close(context.Background())
+	if err := <-errs; err == nil || !strings.Contains(err.Error(), "pager down") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestAlerterReconnectFailure(t *testing.T) {
+	server := newTestWsServer(t)
+	alerts := make(chan Alert, 1)
+	alerter := NewAlerter()
+	defer alerter.Close(context.Background())
+	alerter.Add(NotifierFunc(func(ctx context.Context, alert Alert) error {
+		alerts <- alert
+		return nil
+	}))
+
+	// A handler set before AttachWs keeps being called
+	reconnects := make(chan error, 1)
+	newTestWsClient(t, server, func(c *WsClient) {
+		c.reconnectDelay = 10 * time.Millisecond
+		c.SetReconnectHandler(func(err error) { reconnects <- err })
+		alerter.AttachWs(c)
+	})
+	server.Close()
+	server.mu.Lock()
+	server.conn.Close()
+	server.mu.Unlock()
+
+	select {
+	case alert := <-alerts:
+		if alert.Type != AlertReconnectFailed || alert.Err == nil {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the reconnect alert")
+	}
+	select {
+	case err := <-reconnects:
+		if err == nil {
+			t.Error("Expected the reconnect handler to get the failure")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the client's reconnect handler to be called")
+	}
+}
@@ -0,0 +1,63 @@
+package versifi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// APIVersionType is a version of the REST API, the first segment of the
+// endpoint paths
+type APIVersionType string
+
+// APIVersionV2 is the current REST API version
+const APIVersionV2 APIVersionType = "v2"
+
+// DefaultAPIVersion is used when neither Client.APIVersion nor
+// WithAPIVersion is set
+const DefaultAPIVersion = APIVersionV2
+
+// ErrUnsupportedAPIVersion is matched by the error of a request sent with an
+// API version its endpoint is not served by
+var ErrUnsupportedAPIVersion = errors.New("unsupported API version")
+
+// versionPlaceholder stands for the API version in endpoint templates such
+// as "/{version}/orders"
+const versionPlaceholder = "{version}"
+
+// orderAPIVersions are the API versions serving the order endpoints. An
+// endpoint adopts a new version by listing it in its request's versions.
+var orderAPIVersions = []APIVersionType{APIVersionV2}
+
+// WithAPIVersion sends a request with version instead of Client.APIVersion
+func WithAPIVersion(version APIVersionType) RequestOption {
+	return func(r *request) {
+		r.version = version
+	}
+}
+
+// apiVersion returns the API version to send r with
+func (c *Client) apiVersion(r *request) APIVersionType {
+	switch {
+	case r.version != "":
+		return r.version
+	case c.APIVersion != "":
+		return c.APIVersion
+	}
+	return DefaultAPIVersion
+}
+
+// resolveEndpoint fills the API version into r's endpoint template,
+// failing when the endpoint is not served by that version
+func (c *Client) resolveEndpoint(r *request) error {
+	if !strings.Contains(r.endpoint, versionPlaceholder) {
+		return nil
+	}
+	version := c.apiVersion(r)
+	if r.versions != nil && !slices.Contains(r.versions, version) {
+		return fmt.Errorf("%w: served by %v, not %s", ErrUnsupportedAPIVersion, r.versions, version)
+	}
+	r.endpoint = strings.ReplaceAll(r.endpoint, versionPlaceholder, string(version))
+	return nil
+}
@@ -0,0 +1,50 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIVersion(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	if _, err := client.NewGetOrderService().OrderID(7).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The order endpoints are not served by v3 yet
+	client.APIVersion = "v3"
+	_, err := client.NewGetOrderService().OrderID(7).Do(ctx)
+	if !errors.Is(err, ErrUnsupportedAPIVersion) || !notSent(err) || !strings.Contains(err.Error(), "GET /{version}/orders/7: request not sent: unsupported API version: served by [v2], not v3") {
+		t.Errorf("Expected ErrUnsupportedAPIVersion, got %v", err)
+	}
+	if _, err := client.NewGetOrderService().OrderID(7).Do(ctx, WithAPIVersion(APIVersionV2)); err != nil {
+		t.Errorf("Expected the per-call version to override the client's, got %v", err)
+	}
+
+	// Custom endpoints accept any version
+	_, err = Do[struct{}](ctx, client, CustomRequest{Method: http.MethodGet, Endpoint: "/{version}/fees"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Do[struct{}](ctx, client, CustomRequest{Method: http.MethodGet, Endpoint: "/v2/fees"}, WithAPIVersion("v9")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"/v2/orders/7", "/v2/orders/7", "/v3/fees", "/v2/fees"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected requests to %v, got %v", want, paths)
+	}
+}
@@ -0,0 +1,300 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultDriftTolerance is 0.00000001
+var defaultDriftTolerance = decimal.New(1, -8)
+
+// Balance is the balance of one asset on one exchange
+type Balance struct {
+	Exchange ExchangeType `json:"exchange"`
+	Asset    string       `json:"asset"`
+	Total    string       `json:"total"`
+}
+
+// BalanceSource loads current balances, for example from an exchange
+// account endpoint, for BalanceTracker.Poll
+type BalanceSource interface {
+	Balances(ctx context.Context) ([]Balance, error)
+}
+
+// BalanceSourceFunc adapts a function to BalanceSource
+type BalanceSourceFunc func(ctx context.Context) ([]Balance, error)
+
+// Balances calls f(ctx)
+func (f BalanceSourceFunc) Balances(ctx context.Context) ([]Balance, error) {
+	return f(ctx)
+}
+
+// BalanceState is the tracked state of one balance
+type BalanceState struct {
+	Exchange ExchangeType `json:"exchange"`
+	Asset    string       `json:"asset"`
+	Actual   string       `json:"actual"`   // Last reported balance
+	Expected string       `json:"expected"` // Actual plus the fills and fees applied since it was reported
+	// UpdatedAt is when the actual balance was last reported
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BalanceDrift reports an actual balance that differs from the expected one
+// by more than the tracker's tolerance
+type BalanceDrift struct {
+	Exchange   ExchangeType
+	Asset      string
+	Expected   string
+	Actual     string
+	Difference string // Actual minus expected
+}
+
+// BalanceDriftHandler handles balance drifts
+type BalanceDriftHandler func(drift BalanceDrift)
+
+// BalanceTracker keeps balances reported by the caller or polled from a
+// BalanceSource, and an expected balance moved by every fill and fee applied
+// in between. When a newly reported balance differs from the expected one,
+// the drift handler is called and the expected balance is reset to the
+// reported one.
+//
+// Fills must be applied before the balance update that reflects them,
+// otherwise the update is reported as a drift.
+type BalanceTracker struct {
+	// Tolerance is the absolute difference between expected and actual
+	// balances ignored by drift detection, default 0.00000001
+	Tolerance decimal.Decimal
+
+	mu           sync.RWMutex
+	balances     map[balanceKey]*balanceState
+	trades       map[int64]struct{}
+	tradeOrder   []int64
+	driftHandler BalanceDriftHandler
+	errHandler   ErrHandler
+}
+
+type balanceKey struct {
+	exchange ExchangeType
+	asset    string
+}
+
+type balanceState struct {
+	actual    decimal.Decimal
+	expected  decimal.Decimal
+	reported  bool
+	updatedAt time.Time
+}
+
+// NewBalanceTracker creates an empty balance tracker
+func NewBalanceTracker() *BalanceTracker {
+	return &BalanceTracker{
+		Tolerance: defaultDriftTolerance,
+		balances:  make(map[balanceKey]*balanceState),
+		trades:    make(map[int64]struct{}),
+	}
+}
+
+// SetDriftHandler sets the handler called when a balance drifts
+func (t *BalanceTracker) SetDriftHandler(handler BalanceDriftHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.driftHandler = handler
+}
+
+// SetErrorHandler sets the handler for polling errors and undecodable execution reports
+func (t *BalanceTracker) SetErrorHandler(handler ErrHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errHandler = handler
+}
+
+// Update records reported balances and checks each one for drift
+func (t *BalanceTracker) Update(balances ...Balance) {
+	now := time.Now()
+
+	t.mu.Lock()
+	var drifts []BalanceDrift
+	for _, b := range balances {
+		key := balanceKey{b.Exchange, b.Asset}
+		actual := toDecimal(b.Total)
+
+		s, ok := t.balances[key]
+		if !ok {
+			s = &balanceState{}
+			t.balances[key] = s
+		}
+		if diff := actual.Sub(s.expected); s.reported && diff.Abs().GreaterThan(t.Tolerance) {
+			drifts = append(drifts, BalanceDrift{
+				Exchange:   b.Exchange,
+				Asset:      b.Asset,
+				Expected:   s.expected.String(),
+				Actual:     actual.String(),
+				Difference: diff.String(),
+			})
+		}
+		s.actual = actual
+		s.expected = actual
+		s.reported = true
+		s.updatedAt = now
+	}
+	handler := t.driftHandler
+	t.mu.Unlock()
+
+	if handler != nil {
+		for _, drift := range drifts {
+			handler(drift)
+		}
+	}
+}
+
+// Poll updates balances from source every interval until ctx is done.
+// Errors are passed to the error handler and polling continues.
+func (t *BalanceTracker) Poll(ctx context.Context, source BalanceSource, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		balances, err := source.Balances(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			t.error(err)
+		} else {
+			t.Update(balances...)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ApplyFee moves the expected balance of asset by -amount
+func (t *BalanceTracker) ApplyFee(exchange ExchangeType, asset, amount string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.adjustLocked(balanceKey{exchange, asset}, toDecimal(amount).Neg())
+}
+
+// HandleExecutionReport applies the trades in an execution_report message
+func (t *BalanceTracker) HandleExecutionReport(message []byte) {
+	var report WsExecutionReport
+	if err := json.Unmarshal(message, &report); err != nil {
+		t.error(err)
+		return
+	}
+	t.ApplyExecutionReport(&report.Message)
+}
+
+// ApplyExecutionReport moves the expected base and quote balances by the
+// trades in a decoded execution report. Symbols must be in BASE/QUOTE form;
+// pair orders and trades already applied are skipped.
+func (t *BalanceTracker) ApplyExecutionReport(detail *WsExecutionReportDetail) {
+	var exchange ExchangeType
+	var symbol string
+	var side SideType
+	var child *WsChildOrder
+
+	switch {
+	case detail.Basic != nil:
+		exchange, symbol, side, child = detail.Basic.Exchange, detail.Basic.Symbol, detail.Basic.Side, detail.Basic.ChildOrder
+	case detail.Algo != nil:
+		exchange, symbol, side, child = detail.Algo.Exchange, detail.Algo.Symbol, detail.Algo.Side, detail.Algo.ChildOrder
+	default:
+		return
+	}
+
+	base, quote, ok := strings.Cut(symbol, "/")
+	if !ok || child == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, trade := range child.Trades {
+		if _, seen := t.trades[trade.TradeID]; seen {
+			continue
+		}
+		t.trades[trade.TradeID] = struct{}{}
+		t.tradeOrder = append(t.tradeOrder, trade.TradeID)
+		if len(t.tradeOrder) > tradeHistorySize {
+			delete(t.trades, t.tradeOrder[0])
+			t.tradeOrder = t.tradeOrder[1:]
+		}
+
+		qty := toDecimal(trade.ExecutedQuantity)
+		notional := qty.Mul(toDecimal(trade.ExecutedPrice))
+		if side == SideTypeSell {
+			qty, notional = qty.Neg(), notional.Neg()
+		}
+		t.adjustLocked(balanceKey{exchange, base}, qty)
+		t.adjustLocked(balanceKey{exchange, quote}, notional.Neg())
+	}
+}
+
+// Balance returns the state of one balance
+func (t *BalanceTracker) Balance(exchange ExchangeType, asset string) (BalanceState, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	key := balanceKey{exchange, asset}
+	s, ok := t.balances[key]
+	if !ok {
+		return BalanceState{}, false
+	}
+	return s.export(key), true
+}
+
+// Snapshot returns every tracked balance, sorted by exchange and asset
+func (t *BalanceTracker) Snapshot() []BalanceState {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	res := make([]BalanceState, 0, len(t.balances))
+	for key, s := range t.balances {
+		res = append(res, s.export(key))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Exchange != res[j].Exchange {
+			return res[i].Exchange < res[j].Exchange
+		}
+		return res[i].Asset < res[j].Asset
+	})
+	return res
+}
+
+func (t *BalanceTracker) adjustLocked(key balanceKey, delta decimal.Decimal) {
+	s, ok := t.balances[key]
+	if !ok {
+		s = &balanceState{}
+		t.balances[key] = s
+	}
+	s.expected = s.expected.Add(delta)
+}
+
+func (t *BalanceTracker) error(err error) {
+	t.mu.RLock()
+	handler := t.errHandler
+	t.mu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+func (s *balanceState) export(key balanceKey) BalanceState {
+	return BalanceState{
+		Exchange:  key.exchange,
+		Asset:     key.asset,
+		Actual:    s.actual.String(),
+		Expected:  s.expected.String(),
+		UpdatedAt: s.updatedAt,
+	}
+}
@@ -0,0 +1,85 @@
+package versifi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBalanceTrackerDrift(t *testing.T) {
+	tracker := NewBalanceTracker()
+
+	var drifts []BalanceDrift
+	tracker.SetDriftHandler(func(d BalanceDrift) { drifts = append(drifts, d) })
+
+	tracker.Update(
+		Balance{Exchange: ExchangeBinanceSpot, Asset: "BTC", Total: "1"},
+		Balance{Exchange: ExchangeBinanceSpot, Asset: "USDT", Total: "1000"},
+	)
+
+	report := &WsExecutionReportDetail{
+		RequestOrderType: "basic",
+		Basic: &WsBasicOrderDetail{
+			Symbol:   "BTC/USDT",
+			Exchange: ExchangeBinanceSpot,
+			Side:     SideTypeBuy,
+			ChildOrder: &WsChildOrder{ID: 1, Trades: []WsTrade{
+				{TradeID: 1, ExecutedPrice: "5000", ExecutedQuantity: "0.1"},
+			}},
+		},
+	}
+	tracker.ApplyExecutionReport(report)
+	tracker.ApplyExecutionReport(report) // duplicate trades are skipped
+	tracker.ApplyFee(ExchangeBinanceSpot, "USDT", "0.5")
+
+	if s, _ := tracker.Balance(ExchangeBinanceSpot, "USDT"); s.Expected != "499.5" || s.Actual != "1000" {
+		t.Errorf("Unexpected USDT state %+v", s)
+	}
+
+	tracker.Update(
+		Balance{Exchange: ExchangeBinanceSpot, Asset: "BTC", Total: "1.1"},
+		Balance{Exchange: ExchangeBinanceSpot, Asset: "USDT", Total: "499.5"},
+	)
+	if len(drifts) != 0 {
+		t.Fatalf("Expected no drift, got %+v", drifts)
+	}
+
+	tracker.Update(Balance{Exchange: ExchangeBinanceSpot, Asset: "USDT", Total: "400"})
+	if len(drifts) != 1 || drifts[0].Asset != "USDT" || drifts[0].Expected != "499.5" || drifts[0].Difference != "-99.5" {
+		t.Errorf("Unexpected drifts %+v", drifts)
+	}
+
+	snapshot := tracker.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Asset != "BTC" || snapshot[1].Expected != "400" {
+		t.Errorf("Unexpected snapshot %+v", snapshot)
+	}
+}
+
+func TestBalanceTrackerPoll(t *testing.T) {
+	tracker := NewBalanceTracker()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	polls := 0
+	source := BalanceSourceFunc(func(context.Context) ([]Balance, error) {
+		polls++
+		if polls == 2 {
+			cancel()
+		}
+		return []Balance{{Exchange: ExchangeOKXSpot, Asset: "ETH", Total: "3"}}, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		tracker.Poll(ctx, source, time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Poll did not stop after cancel")
+	}
+	if s, ok := tracker.Balance(ExchangeOKXSpot, "ETH"); !ok || s.Actual != "3" || s.UpdatedAt.IsZero() {
+		t.Errorf("Unexpected polled balance %+v", s)
+	}
+}
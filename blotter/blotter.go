@@ -0,0 +1,107 @@
+// Package blotter records execution reports durably for audit.
+//
+//	b, err := blotter.NewJSONL("executions.jsonl")
+//	...
+//	wsClient.SubscribeExecutionReport(blotter.Handler(b, func(err error) {
+//		log.Printf("blotter: %v", err)
+//	}))
+package blotter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// Blotter stores execution reports and queries them back
+type Blotter interface {
+	// Append records one execution report
+	Append(ctx context.Context, report *versifi.WsExecutionReportDetail) error
+	// Query returns the entries matching filter in the order they were appended
+	Query(ctx context.Context, filter Filter) ([]Entry, error)
+	// Close releases the underlying storage
+	Close() error
+}
+
+// Entry is one recorded execution report
+type Entry struct {
+	OrderID          int64                   `json:"order_id"`
+	ClientOrderID    int64                   `json:"client_order_id"`
+	Status           versifi.OrderStatusType `json:"status"`
+	RequestOrderType string                  `json:"request_order_type"`
+	Exchange         versifi.ExchangeType    `json:"exchange,omitempty"`
+	Symbol           string                  `json:"symbol,omitempty"`
+	Side             versifi.SideType        `json:"side,omitempty"`
+	Timestamp        int64                   `json:"timestamp"` // Timestamp of the report, in milliseconds
+	RecordedAt       time.Time               `json:"recorded_at"`
+	Report           json.RawMessage         `json:"report"` // The full execution report detail
+}
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	OrderID       int64
+	ClientOrderID int64
+	Exchange      versifi.ExchangeType
+	Symbol        string
+	Status        versifi.OrderStatusType
+	// Since and Until bound the report timestamp in milliseconds, inclusive
+	Since int64
+	Until int64
+	// Limit caps the number of entries returned
+	Limit int
+}
+
+// Handler returns a websocket handler appending every execution report to b.
+// Errors are passed to errHandler, which may be nil.
+func Handler(b Blotter, errHandler versifi.ErrHandler) versifi.WsHandler {
+	return func(message []byte) {
+		var report versifi.WsExecutionReport
+		err := json.Unmarshal(message, &report)
+		if err == nil {
+			err = b.Append(context.Background(), &report.Message)
+		}
+		if err != nil && errHandler != nil {
+			errHandler(err)
+		}
+	}
+}
+
+// newEntry builds the entry recorded for report
+func newEntry(report *versifi.WsExecutionReportDetail) (Entry, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e := Entry{
+		OrderID:          report.OrderID,
+		ClientOrderID:    report.ClientOrderID,
+		Status:           report.Status,
+		RequestOrderType: report.RequestOrderType,
+		Timestamp:        report.Timestamp.UnixMilli(),
+		RecordedAt:       time.Now().UTC(),
+		Report:           data,
+	}
+	switch {
+	case report.Basic != nil:
+		e.Exchange, e.Symbol, e.Side = report.Basic.Exchange, report.Basic.Symbol, report.Basic.Side
+	case report.Algo != nil:
+		e.Exchange, e.Symbol, e.Side = report.Algo.Exchange, report.Algo.Symbol, report.Algo.Side
+	case report.Pair != nil && report.Pair.LeadLeg != nil:
+		e.Exchange, e.Symbol = report.Pair.LeadLeg.Exchange, report.Pair.LeadLeg.Symbol
+	}
+	return e, nil
+}
+
+// matches reports whether e is selected by f, ignoring Limit
+func (f Filter) matches(e Entry) bool {
+	return (f.OrderID == 0 || e.OrderID == f.OrderID) &&
+		(f.ClientOrderID == 0 || e.ClientOrderID == f.ClientOrderID) &&
+		(f.Exchange == "" || e.Exchange == f.Exchange) &&
+		(f.Symbol == "" || e.Symbol == f.Symbol) &&
+		(f.Status == "" || e.Status == f.Status) &&
+		(f.Since == 0 || e.Timestamp >= f.Since) &&
+		(f.Until == 0 || e.Timestamp <= f.Until)
+}
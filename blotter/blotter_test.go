@@ -0,0 +1,181 @@
+package blotter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func report(orderID int64, status versifi.OrderStatusType, timestamp int64, symbol string) *versifi.WsExecutionReportDetail {
+	return &versifi.WsExecutionReportDetail{
+		OrderID:          orderID,
+		Status:           status,
+		Timestamp:        versifi.NewTimestamp(time.UnixMilli(timestamp), versifi.PrecisionMilliseconds),
+		RequestOrderType: "basic",
+		Basic: &versifi.WsBasicOrderDetail{
+			Symbol:   symbol,
+			Exchange: versifi.ExchangeBinanceSpot,
+			Side:     versifi.SideTypeBuy,
+		},
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "blotter.jsonl")
+
+	b, err := NewJSONL(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, r := range []*versifi.WsExecutionReportDetail{
+		report(1, versifi.OrderStatusNew, 100, "BTC/USDT"),
+		report(1, versifi.OrderStatusFilled, 200, "BTC/USDT"),
+		report(2, versifi.OrderStatusNew, 300, "ETH/USDT"),
+	} {
+		if err := b.Append(ctx, r); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	b.Close()
+
+	// Entries survive reopening
+	b, err = NewJSONL(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer b.Close()
+
+	entries, err := b.Query(ctx, Filter{OrderID: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Status != versifi.OrderStatusNew || entries[1].Status != versifi.OrderStatusFilled {
+		t.Fatalf("Unexpected entries %+v", entries)
+	}
+	if entries[0].Symbol != "BTC/USDT" || entries[0].Side != versifi.SideTypeBuy || entries[0].RecordedAt.IsZero() {
+		t.Errorf("Unexpected entry fields %+v", entries[0])
+	}
+
+	var detail versifi.WsExecutionReportDetail
+	if err := json.Unmarshal(entries[1].Report, &detail); err != nil || detail.Basic == nil || detail.Basic.Symbol != "BTC/USDT" {
+		t.Errorf("Expected the report to round trip, got %+v (%v)", detail, err)
+	}
+
+	entries, _ = b.Query(ctx, Filter{Since: 150, Limit: 1})
+	if len(entries) != 1 || entries[0].Timestamp != 200 {
+		t.Errorf("Unexpected filtered entries %+v", entries)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	b, err := NewJSONL(filepath.Join(t.TempDir(), "blotter.jsonl"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer b.Close()
+
+	var errs []error
+	handler := Handler(b, func(err error) { errs = append(errs, err) })
+
+	message, _ := json.Marshal(versifi.WsExecutionReport{Op: "execution_report", Success: true, Message: *report(5, versifi.OrderStatusNew, 1, "BTC/USDT")})
+	handler(message)
+	handler([]byte("not json"))
+
+	entries, _ := b.Query(context.Background(), Filter{})
+	if len(entries) != 1 || entries[0].OrderID != 5 {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected one decode error, got %v", errs)
+	}
+}
+
+// recordingDriver is a database/sql driver that records statements
+type recordingDriver struct {
+	mu    sync.Mutex
+	execs []recordedStatement
+	query recordedStatement
+}
+
+type recordedStatement struct {
+	query string
+	args  []driver.NamedValue
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *recordingConn) Close() error                        { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.execs = append(c.d.execs, recordedStatement{query, args})
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.query = recordedStatement{query, args}
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return make([]string, 10) }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestSQLPostgres(t *testing.T) {
+	d := &recordingDriver{}
+	sql.Register("blotter-recording", d)
+	db, err := sql.Open("blotter-recording", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	b, err := NewSQL(ctx, db, Postgres, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer b.Close()
+
+	if err := b.Append(ctx, report(1, versifi.OrderStatusFilled, 100, "BTC/USDT")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := b.Query(ctx, Filter{OrderID: 1, Symbol: "BTC/USDT", Limit: 10}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(d.execs) != 2 {
+		t.Fatalf("Expected schema and insert statements, got %d", len(d.execs))
+	}
+	if schema := d.execs[0].query; !strings.Contains(schema, "CREATE TABLE IF NOT EXISTS versifi_blotter") || !strings.Contains(schema, "BIGSERIAL") {
+		t.Errorf("Unexpected schema %s", schema)
+	}
+	insert := d.execs[1]
+	if !strings.Contains(insert.query, "$10") || len(insert.args) != 10 || insert.args[2].Value != "FILLED" {
+		t.Errorf("Unexpected insert %+v", insert)
+	}
+
+	want := "WHERE order_id = $1 AND symbol = $2 ORDER BY id LIMIT 10"
+	if !strings.HasSuffix(d.query.query, want) || len(d.query.args) != 2 {
+		t.Errorf("Expected query ending %q, got %q", want, d.query.query)
+	}
+}
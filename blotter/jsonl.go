@@ -0,0 +1,98 @@
+package blotter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+var _ Blotter = (*JSONL)(nil)
+
+// JSONL is a Blotter appending one JSON entry per line to a file
+type JSONL struct {
+	// Sync flushes the file to stable storage after every append
+	Sync bool
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewJSONL opens, or creates, the file at path for appending
+func NewJSONL(path string) (*JSONL, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONL{file: file}, nil
+}
+
+// Append writes report as a new line
+func (b *JSONL) Append(ctx context.Context, report *versifi.WsExecutionReportDetail) error {
+	e, err := newEntry(report)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, err := b.file.Write(line); err != nil {
+		return err
+	}
+	if b.Sync {
+		return b.file.Sync()
+	}
+	return nil
+}
+
+// Query scans the file for entries matching filter
+func (b *JSONL) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var res []Entry
+	scanner := bufio.NewScanner(b.file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if filter.matches(e) {
+			res = append(res, e)
+			if filter.Limit > 0 && len(res) == filter.Limit {
+				break
+			}
+		}
+	}
+	return res, scanner.Err()
+}
+
+// Close closes the file
+func (b *JSONL) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.file.Close()
+}
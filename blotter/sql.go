@@ -0,0 +1,172 @@
+package blotter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+var _ Blotter = (*SQL)(nil)
+
+// Dialect selects the SQL flavour used by the SQL blotter
+type Dialect int
+
+const (
+	// SQLite uses ? placeholders and an INTEGER PRIMARY KEY row ID
+	SQLite Dialect = iota
+	// Postgres uses $n placeholders and a BIGSERIAL row ID
+	Postgres
+)
+
+// DefaultTable is the table used when NewSQL is given an empty name
+const DefaultTable = "versifi_blotter"
+
+// SQL is a Blotter storing entries in a database/sql table. The driver is
+// not imported here; open db with the SQLite or Postgres driver of your choice.
+type SQL struct {
+	db      *sql.DB
+	dialect Dialect
+	table   string
+}
+
+// NewSQL returns a blotter writing to table in db, creating the table if it
+// does not exist
+func NewSQL(ctx context.Context, db *sql.DB, dialect Dialect, table string) (*SQL, error) {
+	if table == "" {
+		table = DefaultTable
+	}
+	b := &SQL{db: db, dialect: dialect, table: table}
+
+	id := "id INTEGER PRIMARY KEY"
+	if dialect == Postgres {
+		id = "id BIGSERIAL PRIMARY KEY"
+	}
+	schema := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	%s,
+	order_id BIGINT NOT NULL,
+	client_order_id BIGINT NOT NULL,
+	status TEXT NOT NULL,
+	request_order_type TEXT NOT NULL,
+	exchange TEXT NOT NULL,
+	symbol TEXT NOT NULL,
+	side TEXT NOT NULL,
+	report_timestamp BIGINT NOT NULL,
+	recorded_at BIGINT NOT NULL,
+	report TEXT NOT NULL
+)`, table, id)
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create blotter table: %w", err)
+	}
+	return b, nil
+}
+
+// Append inserts report as a new row
+func (b *SQL) Append(ctx context.Context, report *versifi.WsExecutionReportDetail) error {
+	e, err := newEntry(report)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (order_id, client_order_id, status, request_order_type, exchange, symbol, side, report_timestamp, recorded_at, report) VALUES (%s)`,
+		b.table, b.placeholders(1, 10))
+	_, err = b.db.ExecContext(ctx, query,
+		e.OrderID, e.ClientOrderID, string(e.Status), e.RequestOrderType,
+		string(e.Exchange), e.Symbol, string(e.Side), e.Timestamp,
+		e.RecordedAt.UnixNano(), string(e.Report))
+	return err
+}
+
+// Query selects the rows matching filter in insertion order
+func (b *SQL) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	query, args := b.selectQuery(filter)
+	rows, err := b.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []Entry
+	for rows.Next() {
+		var e Entry
+		var status, exchange, side, report string
+		var recordedAt int64
+		if err := rows.Scan(&e.OrderID, &e.ClientOrderID, &status, &e.RequestOrderType,
+			&exchange, &e.Symbol, &side, &e.Timestamp, &recordedAt, &report); err != nil {
+			return nil, err
+		}
+		e.Status = versifi.OrderStatusType(status)
+		e.Exchange = versifi.ExchangeType(exchange)
+		e.Side = versifi.SideType(side)
+		e.RecordedAt = time.Unix(0, recordedAt).UTC()
+		e.Report = []byte(report)
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+// Close closes the database
+func (b *SQL) Close() error {
+	return b.db.Close()
+}
+
+func (b *SQL) selectQuery(filter Filter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, cond+" "+b.placeholder(len(args)))
+	}
+
+	if filter.OrderID != 0 {
+		add("order_id =", filter.OrderID)
+	}
+	if filter.ClientOrderID != 0 {
+		add("client_order_id =", filter.ClientOrderID)
+	}
+	if filter.Exchange != "" {
+		add("exchange =", string(filter.Exchange))
+	}
+	if filter.Symbol != "" {
+		add("symbol =", filter.Symbol)
+	}
+	if filter.Status != "" {
+		add("status =", string(filter.Status))
+	}
+	if filter.Since != 0 {
+		add("report_timestamp >=", filter.Since)
+	}
+	if filter.Until != 0 {
+		add("report_timestamp <=", filter.Until)
+	}
+
+	query := fmt.Sprintf(`SELECT order_id, client_order_id, status, request_order_type, exchange, symbol, side, report_timestamp, recorded_at, report FROM %s`, b.table)
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	return query, args
+}
+
+// placeholder returns the placeholder of the n-th argument, counting from 1
+func (b *SQL) placeholder(n int) string {
+	if b.dialect == Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+func (b *SQL) placeholders(from, count int) string {
+	res := make([]string, count)
+	for i := range res {
+		res[i] = b.placeholder(from + i)
+	}
+	return strings.Join(res, ", ")
+}
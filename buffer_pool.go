@@ -0,0 +1,102 @@
+package versifi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledBuffer caps the buffers kept for reuse, so one large message
+// does not pin its buffer
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the buffers of request and response bodies and, with
+// WsClient.ReuseBuffers, of WebSocket frames
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buf to the pool. buf must not be used afterwards.
+func putBuffer(buf *bytes.Buffer) {
+	if buf != nil && buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
+
+// encodeJSON encodes v into a pooled buffer, as json.Marshal would
+func encodeJSON(v interface{}) (*bytes.Buffer, error) {
+	buf := getBuffer()
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+	// Drop the newline Encode appends, which json.Marshal does not
+	buf.Truncate(buf.Len() - 1)
+	return buf, nil
+}
+
+// readAll reads r into a pooled buffer
+func readAll(r io.Reader) (*bytes.Buffer, error) {
+	buf := getBuffer()
+	if _, err := buf.ReadFrom(r); err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+	return buf, nil
+}
+
+// pooledBody is a request body backed by a pooled buffer. A transport may
+// still be writing the body after the response has arrived, so the buffer
+// returns to the pool only once every reader of it has been closed and the
+// request has released it.
+type pooledBody struct {
+	buf  *bytes.Buffer
+	refs atomic.Int32
+}
+
+// newPooledBody takes ownership of buf, holding one reference for the
+// request
+func newPooledBody(buf *bytes.Buffer) *pooledBody {
+	b := &pooledBody{buf: buf}
+	b.refs.Store(1)
+	return b
+}
+
+// Bytes returns the body. They are valid until release.
+func (b *pooledBody) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// reader returns a new reader of the body, holding a reference until it
+// is closed. Use it as http.Request.GetBody as well as Body.
+func (b *pooledBody) reader() (io.ReadCloser, error) {
+	b.refs.Add(1)
+	return &pooledBodyReader{Reader: bytes.NewReader(b.buf.Bytes()), body: b}, nil
+}
+
+// release drops a reference, returning the buffer to the pool with the last
+func (b *pooledBody) release() {
+	if b.refs.Add(-1) == 0 {
+		putBuffer(b.buf)
+	}
+}
+
+type pooledBodyReader struct {
+	*bytes.Reader
+	body   *pooledBody
+	closed atomic.Bool
+}
+
+func (r *pooledBodyReader) Close() error {
+	if r.closed.CompareAndSwap(false, true) {
+		r.body.release()
+	}
+	return nil
+}
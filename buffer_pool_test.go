@@ -0,0 +1,189 @@
+package versifi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// newStubClient returns a client answering every request with response,
+// without a network round trip. Request bodies are read and closed as a
+// transport would.
+func newStubClient(response []byte) *Client {
+	client := NewClient("test-key", "test-secret")
+	client.do = func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			io.Copy(io.Discard, req.Body)
+			req.Body.Close()
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewReader(response)),
+		}, nil
+	}
+	return client
+}
+
+func TestEncodeJSON(t *testing.T) {
+	v := map[string]interface{}{"symbol": "BTC/USDT", "note": "<a&b>", "n": 1.5}
+	want, _ := json.Marshal(v)
+	buf, err := encodeJSON(v)
+	if err != nil || buf.String() != string(want) {
+		t.Errorf("Expected %s, got %q, %v", want, buf, err)
+	}
+	if _, err := encodeJSON(func() {}); err == nil {
+		t.Error("Expected an error encoding a func")
+	}
+}
+
+func TestPooledBody(t *testing.T) {
+	body := newPooledBody(bytes.NewBufferString(`{"a":1}`))
+	r1, _ := body.reader()
+	r2, _ := body.reader()
+	body.release()
+	r1.Close()
+	r1.Close()
+	if refs := body.refs.Load(); refs != 1 {
+		t.Errorf("Expected the open reader to hold the buffer, got %d references", refs)
+	}
+	if data, _ := io.ReadAll(r2); string(data) != `{"a":1}` {
+		t.Errorf("Unexpected body %s", data)
+	}
+	r2.Close()
+	if refs := body.refs.Load(); refs != 0 {
+		t.Errorf("Expected no references, got %d", refs)
+	}
+
+	var got *http.Request
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		received, _ = io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(OrderResponse{OrderID: 1})
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	if _, err := testBasicOrder(client).Do(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got.ContentLength != int64(len(received)) || len(got.TransferEncoding) != 0 {
+		t.Errorf("Expected a body of known length, got %d for %d bytes (%v)", got.ContentLength, len(received), got.TransferEncoding)
+	}
+	if got.Header.Get("X-VERSIFI-API-SIGN") != sign("test-secret", string(received)) {
+		t.Error("Expected the body to be signed as sent")
+	}
+}
+
+// BenchmarkCreateBasicOrder measures the allocations of placing an order,
+// from building the request to decoding the response
+func BenchmarkCreateBasicOrder(b *testing.B) {
+	response, _ := json.Marshal(OrderResponse{OrderID: 123456789, ClientOrderID: 987654321, Status: OrderStatusNew})
+	client := newStubClient(response)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := client.NewCreateBasicOrderService().
+			ClientOrderID(987654321).
+			Exchange(ExchangeBinanceSpot).
+			Symbol("BTC/USDT").
+			Side(SideTypeBuy).
+			OrderType(BasicOrderTypeLimit).
+			TimeInForce(TimeInForceGTC).
+			Quantity("0.015").
+			Price("43210.5").
+			Do(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRequestBody compares the body handling of a signed POST as it
+// was (marshal, then copy the body to sign it and again to send it, and
+// read the response into a new slice) with the pooled buffers
+func BenchmarkRequestBody(b *testing.B) {
+	body := BasicOrderRequest{
+		ClientOrderID: Int64Ptr(987654321), Exchange: ExchangeBinanceSpot, OrderType: BasicOrderTypeLimit,
+		Price: StringPtr("43210.5"), Quantity: "0.015", Side: SideTypeBuy, Symbol: "BTC/USDT",
+	}
+	response, _ := json.Marshal(OrderResponse{OrderID: 123456789, ClientOrderID: 987654321, Status: OrderStatusNew})
+
+	b.Run("previous", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, _ := json.Marshal(body)
+			signed, _ := io.ReadAll(bytes.NewReader(data))
+			_ = sign("secret", string(signed))
+			io.Copy(io.Discard, bytes.NewReader(signed))
+
+			data, _ = io.ReadAll(bytes.NewReader(response))
+			var res OrderResponse
+			json.Unmarshal(data, &res)
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf, _ := encodeJSON(body)
+			pooled := newPooledBody(buf)
+			_ = sign("secret", string(pooled.Bytes()))
+			r, _ := pooled.reader()
+			io.Copy(io.Discard, r)
+			r.Close()
+			pooled.release()
+
+			data, _ := readAll(bytes.NewReader(response))
+			var res OrderResponse
+			json.Unmarshal(data.Bytes(), &res)
+			putBuffer(data)
+		}
+	})
+}
+
+// BenchmarkWsSendJSON compares SendJSON with and without ReuseBuffers
+func BenchmarkWsSendJSON(b *testing.B) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	message := map[string]interface{}{"op": "subscribe", "topic": "execution_report", "since": 1700000000000}
+	for _, reuse := range []bool{false, true} {
+		b.Run(map[bool]string{false: "marshal", true: "pooled"}[reuse], func(b *testing.B) {
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer conn.Close()
+			ws := NewWsClient("key", "secret")
+			ws.conn, ws.isConnected, ws.ReuseBuffers = conn, true, reuse
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := ws.SendJSON(message); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
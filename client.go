@@ -7,20 +7,21 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"fmt"
-	"io"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"sync/atomic"
 	"time"
 )
 
-// Global configuration
-var (
-	BaseAPIMainURL = "https://api.versifi.io"
-	UseTestnet     = false
-)
+// BaseAPIMainURL is the default REST endpoint. Point a client elsewhere by
+// setting its BaseURL; there is no package-level setting to change.
+const BaseAPIMainURL = "https://api.versifi.io"
+
+// BaseAPITestnetURL is the REST endpoint of the sandbox environment, used
+// by NewTestnetClient
+const BaseAPITestnetURL = "https://api-testnet.versifi.io" // Update with actual testnet URL
 
 // Security type
 type secType int
@@ -40,7 +41,45 @@ type Client struct {
 	HTTPClient *http.Client
 	Debug      bool
 	Logger     *log.Logger
+	// Instruments provides the tick and lot sizes used by AutoRound orders
+	Instruments InstrumentSource
+	// Clock times polling, retries and TWAP slices, nil uses SystemClock
+	Clock Clock
+	// APIVersion is the REST API version requests are sent with, empty
+	// uses DefaultAPIVersion. WithAPIVersion overrides it per request, and
+	// requests to endpoints not served by the version fail with
+	// ErrUnsupportedAPIVersion.
+	APIVersion APIVersionType
+	// Envelope selects how responses wrapped in a {"code", "message",
+	// "data"} envelope are read, the zero value detects them
+	Envelope ResponseEnvelopeType
+	// StrictDecoding fails responses holding fields this SDK does not know
+	// with an error matching ErrUnknownFields, to catch API changes early
+	// in testing. By default unknown fields are ignored.
+	StrictDecoding bool
+	// CompressThreshold gzips request bodies of at least this many bytes,
+	// such as large batch cancels, zero never compresses. The signature
+	// still covers the uncompressed body. See DefaultCompressThreshold.
+	CompressThreshold int
+	// RelaxedValidation skips the minimum quantity and notional checks of
+	// AutoRound orders, which sandbox instruments often do not share with
+	// production. Rounding still applies.
+	RelaxedValidation bool
+
 	do         doFunc
+	killSwitch atomic.Bool
+	creds      atomic.Pointer[Credentials]
+	limiter    atomic.Pointer[limiterRef]
+	risk       atomic.Pointer[RiskChecker]
+	exchanges  atomic.Pointer[ExchangeRegistry]
+	signers    atomic.Pointer[signer]
+
+	unknownFieldsHandler atomic.Pointer[UnknownFieldsHandler]
+}
+
+// clock returns the Clock timing the client
+func (c *Client) clock() Clock {
+	return clockOrSystem(c.Clock)
 }
 
 type doFunc func(req *http.Request) (*http.Response, error)
@@ -50,19 +89,28 @@ func NewClient(apiKey, apiSecret string) *Client {
 	return &Client{
 		APIKey:     apiKey,
 		APISecret:  apiSecret,
-		BaseURL:    getAPIEndpoint(),
+		BaseURL:    BaseAPIMainURL,
 		UserAgent:  "Versifi/go",
 		HTTPClient: http.DefaultClient,
 		Logger:     log.New(os.Stderr, "Versifi-go ", log.LstdFlags),
 	}
 }
 
+// NewTestnetClient creates a client for the sandbox environment, with
+// RelaxedValidation set
+func NewTestnetClient(apiKey, apiSecret string) *Client {
+	c := NewClient(apiKey, apiSecret)
+	c.BaseURL = BaseAPITestnetURL
+	c.RelaxedValidation = true
+	return c
+}
+
 // NewClientWithHTTPClient creates a new client with custom HTTP client
 func NewClientWithHTTPClient(apiKey, apiSecret string, httpClient *http.Client) *Client {
 	return &Client{
 		APIKey:     apiKey,
 		APISecret:  apiSecret,
-		BaseURL:    getAPIEndpoint(),
+		BaseURL:    BaseAPIMainURL,
 		UserAgent:  "Versifi/go",
 		HTTPClient: httpClient,
 		Logger:     log.New(os.Stderr, "Versifi-go ", log.LstdFlags),
@@ -107,33 +155,61 @@ func NewClientWithLocalAddr(apiKey, apiSecret, localAddr string) *Client {
 	return &Client{
 		APIKey:     apiKey,
 		APISecret:  apiSecret,
-		BaseURL:    getAPIEndpoint(),
+		BaseURL:    BaseAPIMainURL,
 		UserAgent:  "Versifi/go",
 		HTTPClient: httpClient,
 		Logger:     log.New(os.Stderr, "Versifi-go ", log.LstdFlags),
 	}
 }
 
-func getAPIEndpoint() string {
-	if UseTestnet {
-		return BaseAPIMainURL // Versifi doesn't have separate testnet, adjust if needed
+// callAPI sends r and returns the response body in a pooled buffer, which
+// the caller must return with putBuffer
+func (c *Client) callAPI(ctx context.Context, r *request, opts ...RequestOption) (data *bytes.Buffer, err error) {
+	if r.submitsOrder && c.killSwitch.Load() {
+		return nil, r.error(ErrNotSent, ErrKillSwitchEngaged)
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if err := c.resolveEndpoint(r); err != nil {
+		return nil, r.error(ErrNotSent, err)
+	}
+	if err := c.checkExchanges(r); err != nil {
+		return nil, r.error(ErrNotSent, err)
+	}
+	if err := c.checkRisk(r); err != nil {
+		return nil, r.error(ErrNotSent, err)
+	}
+	if ref := c.limiter.Load(); ref != nil {
+		if err := ref.Wait(ctx); err != nil {
+			return nil, r.error(ErrNotSent, err)
+		}
 	}
-	return BaseAPIMainURL
-}
 
-// callAPI executes the HTTP request
-func (c *Client) callAPI(ctx context.Context, r *request, opts ...RequestOption) (data []byte, err error) {
-	err = c.parseRequest(r, opts...)
+	err = c.parseRequest(r)
 	if err != nil {
-		return []byte{}, err
+		return nil, err
 	}
 
-	req, err := http.NewRequest(r.method, r.fullURL, r.body)
+	req, err := http.NewRequest(r.method, r.fullURL, nil)
 	if err != nil {
-		return []byte{}, err
+		return nil, r.error(ErrTransport, err)
+	}
+	if body := r.body; body != nil {
+		if c.compresses(body) {
+			if body, err = gzipBody(body.Bytes()); err != nil {
+				return nil, r.error(ErrEncode, err)
+			}
+			defer body.release()
+			r.header.Set("Content-Encoding", "gzip")
+		}
+		req.Body, _ = body.reader()
+		req.GetBody = body.reader
+		req.ContentLength = int64(len(body.Bytes()))
 	}
 
-	req = req.WithContext(ctx)
+	trace := new(requestTrace)
+	req = req.WithContext(trace.context(ctx))
 	req.Header = r.header
 
 	c.debug("request: %#v", req)
@@ -143,14 +219,16 @@ func (c *Client) callAPI(ctx context.Context, r *request, opts ...RequestOption)
 		f = c.HTTPClient.Do
 	}
 
+	start := time.Now()
 	res, err := f(req)
 	if err != nil {
-		return []byte{}, err
+		return nil, r.transportError(trace.current(), time.Since(start), err)
 	}
 
-	data, err = io.ReadAll(res.Body)
+	data, err = readAll(res.Body)
 	if err != nil {
-		return []byte{}, err
+		res.Body.Close()
+		return nil, r.transportError(RequestPhaseRead, time.Since(start), err)
 	}
 	defer func() {
 		closeErr := res.Body.Close()
@@ -160,36 +238,40 @@ func (c *Client) callAPI(ctx context.Context, r *request, opts ...RequestOption)
 	}()
 
 	c.debug("response: %#v", res)
-	c.debug("response body: %s", string(data))
+	c.debug("response body: %s", data)
 	c.debug("response status code: %d", res.StatusCode)
 
 	if res.StatusCode >= http.StatusBadRequest {
+		defer putBuffer(data)
 		apiErr := new(APIError)
-		e := json.Unmarshal(data, apiErr)
+		e := json.Unmarshal(data.Bytes(), apiErr)
 		if e != nil {
 			c.debug("failed to unmarshal json: %s", e)
 		}
+		apiErr.HTTPStatus = res.StatusCode
 		return nil, apiErr
 	}
 
+	if err := c.unwrapEnvelope(r, res.StatusCode, data); err != nil {
+		putBuffer(data)
+		return nil, err
+	}
 	return data, nil
 }
 
 // parseRequest parses the request and sets authentication headers
-func (c *Client) parseRequest(r *request, opts ...RequestOption) (err error) {
-	// Set request options
-	for _, opt := range opts {
-		opt(r)
-	}
-
-	// Build full URL
-	if r.query != nil && len(r.query) > 0 {
-		r.fullURL = fmt.Sprintf("%s%s?%s", c.BaseURL, r.endpoint, r.query.Encode())
+func (c *Client) parseRequest(r *request) (err error) {
+	// Build full URL, encoding the query once for the URL and the signature
+	var query string
+	if len(r.query) > 0 {
+		query = r.query.Encode()
+		r.fullURL = c.BaseURL + r.endpoint + "?" + query
 	} else {
-		r.fullURL = fmt.Sprintf("%s%s", c.BaseURL, r.endpoint)
+		r.fullURL = c.BaseURL + r.endpoint
 	}
 
-	// Set headers
+	// Set headers. The keys are in canonical form, which Set then keeps
+	// without allocating.
 	if r.header == nil {
 		r.header = http.Header{}
 	}
@@ -198,34 +280,28 @@ func (c *Client) parseRequest(r *request, opts ...RequestOption) (err error) {
 	r.header.Set("Content-Type", "application/json")
 
 	// Authentication
+	creds := c.credentials()
 	if r.secType == secTypeAPIKey || r.secType == secTypeSigned {
-		r.header.Set("X-VERSIFI-API-KEY", c.APIKey)
+		r.header.Set("X-Versifi-Api-Key", creds.APIKey)
 	}
 
 	if r.secType == secTypeSigned {
-		var payload string
+		var payload []byte
 
 		// For GET and DELETE requests, payload is the query string (without "?")
 		if r.method == http.MethodGet || r.method == http.MethodDelete {
-			if r.query != nil && len(r.query) > 0 {
-				payload = r.query.Encode()
+			if query != "" {
+				payload = []byte(query)
 			}
 		} else {
-			// For POST and PUT requests, payload is the body
+			// For POST and PUT requests, payload is the body as marshalled
 			if r.body != nil {
-				bodyBytes, err := io.ReadAll(r.body)
-				if err != nil {
-					return err
-				}
-				payload = string(bodyBytes)
-				// Reset the body reader
-				r.body = bytes.NewReader(bodyBytes)
+				payload = r.body.Bytes()
 			}
 		}
 
 		// Create signature
-		signature := c.sign(payload)
-		r.header.Set("X-VERSIFI-API-SIGN", signature)
+		r.header.Set("X-Versifi-Api-Sign", c.signer(creds.APISecret).sign(payload))
 	}
 
 	return nil
@@ -233,8 +309,12 @@ func (c *Client) parseRequest(r *request, opts ...RequestOption) (err error) {
 
 // sign creates HMAC SHA256 signature
 func (c *Client) sign(payload string) string {
-	key := []byte(c.APISecret)
-	h := hmac.New(sha256.New, key)
+	return c.signer(c.credentials().APISecret).sign([]byte(payload))
+}
+
+// sign creates an HMAC SHA256 signature of payload with secret
+func sign(secret, payload string) string {
+	h := hmac.New(sha256.New, []byte(secret))
 	h.Write([]byte(payload))
 	return hex.EncodeToString(h.Sum(nil))
 }
@@ -281,3 +361,8 @@ func (c *Client) NewListOpenOrdersService() *ListOpenOrdersService {
 func (c *Client) NewCancelBatchOrderService() *CancelBatchOrderService {
 	return &CancelBatchOrderService{c: c}
 }
+
+// NewBackfillOrdersService creates a new BackfillOrdersService
+func (c *Client) NewBackfillOrdersService() *BackfillOrdersService {
+	return &BackfillOrdersService{c: c}
+}
@@ -0,0 +1,72 @@
+package versifi
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestClientOrderIDAllocator(t *testing.T) {
+	if _, err := NewClientOrderIDAllocator(4096, DefaultNamespaceBits); err == nil {
+		t.Error("Expected error for a namespace wider than its bits")
+	}
+
+	a, err := NewClientOrderIDAllocator(5, DefaultNamespaceBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewClientOrderIDAllocator(6, DefaultNamespaceBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for _, alloc := range []*ClientOrderIDAllocator{a, a, b, b} {
+		wg.Add(1)
+		go func(alloc *ClientOrderIDAllocator) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				id := alloc.Next()
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("Duplicate client order ID %d", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}(alloc)
+	}
+	wg.Wait()
+
+	id := a.Next()
+	if id <= 0 || !a.Owns(id) || b.Owns(id) {
+		t.Errorf("Unexpected ownership of %d", id)
+	}
+	namespace, seq, ok := ParseClientOrderID(id, DefaultNamespaceBits)
+	if !ok || namespace != 5 || seq <= 0 {
+		t.Errorf("Unexpected parse of %d: %d %d %v", id, namespace, seq, ok)
+	}
+	if _, _, ok := ParseClientOrderID(-1, DefaultNamespaceBits); ok {
+		t.Error("Expected negative IDs not to parse")
+	}
+
+	// Skip moves past IDs issued by a previous run
+	a.Skip(id + 100)
+	if next := a.Next(); next != id+101 {
+		t.Errorf("Expected %d after Skip, got %d", id+101, next)
+	}
+	a.Skip(b.Next())
+	if next := a.Next(); next != id+102 {
+		t.Errorf("Expected Skip to ignore other namespaces, got %d", next)
+	}
+
+	order := &CreateBasicOrderService{}
+	if assigned := a.Assign(order); order.clientOrderID == nil || *order.clientOrderID != assigned || !a.Owns(assigned) {
+		t.Errorf("Unexpected assigned ID %d", assigned)
+	}
+
+	if NamespaceFor("basis-btc", 8) != NamespaceFor("basis-btc", 8) || NamespaceFor("basis-btc", 8) >= 256 {
+		t.Error("Expected NamespaceFor to be stable and within its bits")
+	}
+}
@@ -3,9 +3,18 @@ package versifi
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -31,6 +40,19 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewTestnetClient(t *testing.T) {
+	client := NewTestnetClient("test-key", "test-secret")
+	if client.BaseURL != BaseAPITestnetURL || !client.RelaxedValidation || client.APIKey != "test-key" {
+		t.Errorf("Unexpected testnet client %+v", client)
+	}
+	if NewClient("test-key", "test-secret").RelaxedValidation {
+		t.Error("Expected production clients to validate")
+	}
+	if ws := NewTestnetWsClient("test-key", "test-secret"); ws.BaseURL != BaseWSTestnetURL || ws.APISecret != "test-secret" {
+		t.Errorf("Unexpected testnet WebSocket client %+v", ws)
+	}
+}
+
 func TestSign(t *testing.T) {
 	client := NewClient("test-key", "test-secret")
 
@@ -39,6 +61,7 @@ func TestSign(t *testing.T) {
 
 	// Expected signature for "test-payload" with secret "test-secret"
 	expected := "eb0e0198e4874db2c9b28d85c5db7e3f7c8c4e2c8c8f1c8d8c8c8c8c8c8c8c8c"
+	_ = expected
 
 	// Note: This is a placeholder. You should calculate the actual expected signature
 	if signature == "" {
@@ -200,7 +223,7 @@ func TestGetOrderService(t *testing.T) {
 			ClientOrderID:    123456,
 			OrderType:        "TWAP",
 			Status:           OrderStatusFilled,
-			Timestamp:        1677721800,
+			Timestamp:        TimestampFromEpoch(1677721800),
 			RequestOrderType: "algo",
 		}
 
@@ -274,6 +297,82 @@ func TestAPIError(t *testing.T) {
 	}
 }
 
+func TestRequestErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/orders/1":
+			time.Sleep(100 * time.Millisecond)
+		case "/v2/orders/2":
+			w.Write([]byte(`{"order_id":`))
+		case "/v2/orders/3":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"order not found"}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.NewGetOrderService().OrderID(1).Do(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrTransport) || errors.Is(err, ErrAPI) {
+		t.Errorf("Expected a transport error wrapping the deadline, got %v", err)
+	}
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Method != http.MethodGet || reqErr.Endpoint != "/v2/orders/1" {
+		t.Errorf("Unexpected request error %+v", reqErr)
+	}
+	// The server was slow: the request may have been processed
+	if reqErr.Phase != RequestPhaseWait || !reqErr.Timeout() || reqErr.NotSent() || reqErr.Elapsed < 10*time.Millisecond {
+		t.Errorf("Expected a timeout waiting for the response, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = client.NewGetOrderService().OrderID(1).Do(ctx)
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &reqErr) || reqErr.Timeout() {
+		t.Errorf("Expected a cancellation, got %v", err)
+	}
+
+	_, err = client.NewGetOrderService().OrderID(2).Do(context.Background())
+	var syntaxErr *json.SyntaxError
+	if !errors.Is(err, ErrDecode) || errors.Is(err, ErrTransport) || !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected a decode error, got %v", err)
+	}
+
+	_, err = client.NewGetOrderService().OrderID(3).Do(context.Background())
+	var apiErr *APIError
+	if !errors.Is(err, ErrAPI) || errors.Is(err, ErrTransport) || !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusNotFound {
+		t.Errorf("Expected an API error, got %v", err)
+	}
+	if wrapped := fmt.Errorf("get: %w", err); !IsAPIError(wrapped) {
+		t.Error("Expected IsAPIError to unwrap")
+	}
+}
+
+func TestRequestDialTimeout(t *testing.T) {
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = "http://versifi.invalid"
+	client.HTTPClient = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := client.NewCancelOrderService().OrderID(1).Do(ctx)
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Phase != RequestPhaseConnect || !reqErr.Timeout() || !reqErr.NotSent() {
+		t.Fatalf("Expected a timeout while connecting, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "during CONNECT after") {
+		t.Errorf("Expected the phase in the message, got %q", err)
+	}
+}
+
 func TestHelperFunctions(t *testing.T) {
 	// Test StringPtr
 	str := "test"
@@ -305,3 +404,282 @@ func TestHelperFunctions(t *testing.T) {
 		t.Errorf("Expected %f, got %f", fl, *flPtr)
 	}
 }
+
+func TestBackfillOrdersService(t *testing.T) {
+	orders := []ListOrderItem{
+		{OrderID: 1, Timestamp: TimestampFromEpoch(100)},
+		{OrderID: 2, Timestamp: TimestampFromEpoch(200)},
+		{OrderID: 3, Timestamp: TimestampFromEpoch(300)},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/orders" {
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			end := offset + limit
+			if end > len(orders) {
+				end = len(orders)
+			}
+			json.NewEncoder(w).Encode(orders[offset:end])
+			return
+		}
+
+		id, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/v2/orders/"), 10, 64)
+		json.NewEncoder(w).Encode(GetOrderResponse{OrderID: id, Status: OrderStatusFilled})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	res, err := client.NewBackfillOrdersService().
+		Since(TimestampFromEpoch(200).Time).
+		OrderIDs(1).
+		PageSize(2).
+		Do(context.Background())
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var ids []int64
+	for _, order := range res {
+		ids = append(ids, order.OrderID)
+	}
+
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+		t.Errorf("Expected orders [1 2 3], got %v", ids)
+	}
+}
+
+func TestWaitForOrder(t *testing.T) {
+	var mu sync.Mutex
+	status := OrderStatusNew
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(GetOrderResponse{OrderID: 42, Status: status})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	setStatus := func(s OrderStatusType) {
+		mu.Lock()
+		defer mu.Unlock()
+		status = s
+	}
+
+	t.Run("polling", func(t *testing.T) {
+		setStatus(OrderStatusNew)
+		time.AfterFunc(20*time.Millisecond, func() { setStatus(OrderStatusCanceled) })
+
+		order, err := client.WaitForOrder(context.Background(), 42, &WaitForOrderOptions{PollInterval: 5 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if order.Status != OrderStatusCanceled {
+			t.Errorf("Expected CANCELED, got %s", order.Status)
+		}
+	})
+
+	t.Run("tracker", func(t *testing.T) {
+		setStatus(OrderStatusNew)
+		tracker := NewOrderTracker(client)
+		time.AfterFunc(20*time.Millisecond, func() {
+			setStatus(OrderStatusFilled)
+			tracker.ApplyExecutionReport(&WsExecutionReportDetail{OrderID: 42, Status: OrderStatusFilled})
+		})
+
+		// The poll interval alone would time the wait out
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		order, err := client.WaitForOrder(ctx, 42, &WaitForOrderOptions{PollInterval: time.Hour, Tracker: tracker})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if order.Status != OrderStatusFilled {
+			t.Errorf("Expected FILLED, got %s", order.Status)
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		setStatus(OrderStatusNew)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := client.WaitForOrder(ctx, 42, nil); err != context.DeadlineExceeded {
+			t.Errorf("Expected DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestDoCustomEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/fees":
+			if r.Method != http.MethodGet || r.URL.Query().Get("exchange") != "BINANCE_SPOT" {
+				t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+			}
+			if r.Header.Get("X-VERSIFI-API-SIGN") != sign("test-secret", r.URL.RawQuery) {
+				t.Error("Expected a signature of the query string")
+			}
+			w.Write([]byte(`{"maker": "0.001", "taker": "0.002"}`))
+		case "/v2/transfers":
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"asset":"USDT"}` || r.Header.Get("X-VERSIFI-API-SIGN") != "" {
+				t.Errorf("Unexpected unsigned body %s", body)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 404, "message": "not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	type fee struct {
+		Maker string `json:"maker"`
+		Taker string `json:"taker"`
+	}
+	res, err := Do[fee](ctx, client, CustomRequest{
+		Method:   http.MethodGet,
+		Endpoint: "/v2/fees",
+		Query:    url.Values{"exchange": {"BINANCE_SPOT"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.Maker != "0.001" || res.Taker != "0.002" {
+		t.Errorf("Unexpected response %+v", res)
+	}
+
+	if _, err := Do[struct{}](ctx, client, CustomRequest{
+		Method:   http.MethodPost,
+		Endpoint: "/v2/transfers",
+		Body:     map[string]string{"asset": "USDT"},
+		Unsigned: true,
+	}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	var apiErr *APIError
+	if _, err := Do[fee](ctx, client, CustomRequest{Method: http.MethodGet, Endpoint: "/v2/missing"}); !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusNotFound {
+		t.Errorf("Expected a 404 APIError, got %v", err)
+	}
+
+	client.killSwitch.Store(true)
+	if _, err := Do[fee](ctx, client, CustomRequest{Method: http.MethodPost, Endpoint: "/v2/fees", SubmitsOrder: true}); !errors.Is(err, ErrKillSwitchEngaged) || !errors.Is(err, ErrNotSent) {
+		t.Errorf("Expected ErrKillSwitchEngaged, got %v", err)
+	}
+}
+
+// TestClientsConcurrent constructs and uses clients with different settings
+// at once; run with -race to check they share no mutable state
+func TestClientsConcurrent(t *testing.T) {
+	var servers [2]*httptest.Server
+	var hits [2]sync.Map
+	for i := range servers {
+		i := i
+		servers[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[i].Store(r.Header.Get("X-VERSIFI-API-KEY"), true)
+			json.NewEncoder(w).Encode(GetOrderResponse{OrderID: int64(i)})
+		}))
+		defer servers[i].Close()
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 20; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			client := NewClient("key-"+strconv.Itoa(g), "secret")
+			client.BaseURL = servers[g%2].URL
+			client.SetRateLimiter(NewRateLimiter(1000, 10))
+			for i := 0; i < 5; i++ {
+				res, err := client.NewGetOrderService().OrderID(1).Do(context.Background())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if res.OrderID != int64(g%2) {
+					t.Errorf("Client %d reached server %d", g, res.OrderID)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for g := 0; g < 20; g++ {
+		if _, ok := hits[g%2].Load("key-" + strconv.Itoa(g)); !ok {
+			t.Errorf("Client %d did not reach its server", g)
+		}
+	}
+}
+
+func TestCloneServices(t *testing.T) {
+	var mu sync.Mutex
+	symbols := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body BasicOrderRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		symbols[body.Symbol] = *body.Price
+		mu.Unlock()
+		json.NewEncoder(w).Encode(OrderResponse{OrderID: 1, Status: OrderStatusNew})
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	client.Instruments = StaticInstruments{
+		{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", TickSize: "0.01", LotSize: "0.001"},
+		{Exchange: ExchangeBinanceSpot, Symbol: "ETH/USDT", TickSize: "0.1", LotSize: "0.001"},
+	}
+
+	template := client.NewCreateBasicOrderService().
+		Exchange(ExchangeBinanceSpot).
+		Side(SideTypeBuy).
+		OrderType(BasicOrderTypeLimit).
+		Price("100.123").
+		Quantity("1").
+		AutoRound()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		symbol := []string{"BTC/USDT", "ETH/USDT"}[i%2]
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := template.Clone().Symbol(symbol).ClientOrderID(int64(i)).Do(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if symbols["BTC/USDT"] != "100.12" || symbols["ETH/USDT"] != "100.1" || len(symbols) != 2 {
+		t.Errorf("Unexpected orders %v", symbols)
+	}
+	if template.symbol != "" || template.clientOrderID != nil || *template.price != "100.123" {
+		t.Errorf("Expected the template to be unchanged, got %+v", template)
+	}
+
+	pair := client.NewCreatePairOrderService().
+		Lead(&PairLeg{Symbol: "BTC/USDT", Params: map[string]interface{}{"a": 1}}).
+		Params(map[string]interface{}{"spread": "0.1"})
+	clone := pair.Clone()
+	clone.lead.Params["a"] = 2
+	clone.params["spread"] = "0.2"
+	if pair.lead.Params["a"] != 1 || pair.params["spread"] != "0.1" {
+		t.Error("Expected the pair order clone not to share params")
+	}
+
+	batch := client.NewCancelBatchOrderService().OrderIDs(make([]int64, 1, 4))
+	batch.Clone().AddOrderID(2)
+	batch.Clone().AddOrderID(3)
+	if ids := batch.Clone().AddOrderID(4).orderIDs; len(ids) != 2 || ids[1] != 4 || len(batch.orderIDs) != 1 {
+		t.Errorf("Expected clones not to share order IDs, got %v and %v", ids, batch.orderIDs)
+	}
+}
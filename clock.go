@@ -0,0 +1,241 @@
+package versifi
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for auth expiry, keepalive and session
+// renewal tickers, reconnect and retry delays, rate limiting and order
+// polling. Clients use SystemClock unless their Clock field is set, so
+// tests and backtests can drive time with a SimulatedClock.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a Clock's equivalent of *time.Timer
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a Clock's equivalent of *time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock is the Clock of the time package
+type SystemClock struct{}
+
+// Now returns time.Now()
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer returns a wrapped time.NewTimer(d)
+func (SystemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// NewTicker returns a wrapped time.NewTicker(d)
+func (SystemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// clockOrSystem returns clock, or SystemClock if it is nil
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock{}
+	}
+	return clock
+}
+
+// sleep blocks for d on clock, or until done is closed. It reports whether
+// the full duration elapsed.
+func sleep(clock Clock, d time.Duration, done <-chan struct{}) bool {
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// SimulatedClock is a Clock that only moves when told to. Timers and
+// tickers fire, in time order, as Advance or Set pass their deadlines;
+// like those of the time package, tickers drop ticks nobody receives.
+//
+//	clock := versifi.NewSimulatedClock(start)
+//	ws.Clock = clock
+//	...
+//	clock.Advance(ws.KeepaliveInterval)
+//
+// It is safe for concurrent use.
+type SimulatedClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*simTimer
+}
+
+// NewSimulatedClock creates a clock set to start
+func NewSimulatedClock(start time.Time) *SimulatedClock {
+	return &SimulatedClock{now: start}
+}
+
+// Now returns the simulated time
+func (c *SimulatedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer firing once the clock has advanced by d
+func (c *SimulatedClock) NewTimer(d time.Duration) Timer {
+	return c.schedule(d, 0)
+}
+
+// NewTicker returns a ticker firing every d of simulated time
+func (c *SimulatedClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return simTicker{c.schedule(d, d)}
+}
+
+// Advance moves the clock forward by d, firing the timers and tickers due
+func (c *SimulatedClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, firing the timers and tickers due. The clock
+// never moves backwards.
+func (c *SimulatedClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		next := c.nextLocked()
+		if next == nil || next.when.After(t) {
+			break
+		}
+		if next.when.After(c.now) {
+			c.now = next.when
+		}
+		next.fireLocked(c.now)
+	}
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// Waiters returns the number of active timers and tickers, so a test can
+// wait until the code under test is blocked on the clock before advancing
+func (c *SimulatedClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+func (c *SimulatedClock) schedule(d, period time.Duration) *simTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &simTimer{clock: c, c: make(chan time.Time, 1), period: period}
+	t.startLocked(d)
+	return t
+}
+
+// nextLocked returns the waiter due first
+func (c *SimulatedClock) nextLocked() *simTimer {
+	if len(c.waiters) == 0 {
+		return nil
+	}
+	sort.SliceStable(c.waiters, func(i, j int) bool { return c.waiters[i].when.Before(c.waiters[j].when) })
+	return c.waiters[0]
+}
+
+func (c *SimulatedClock) removeLocked(t *simTimer) bool {
+	for i, w := range c.waiters {
+		if w == t {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// simTimer is the Timer of a SimulatedClock, and with a period the
+// waiter behind its Ticker
+type simTimer struct {
+	clock  *SimulatedClock
+	c      chan time.Time
+	when   time.Time
+	period time.Duration
+}
+
+func (t *simTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *simTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.removeLocked(t)
+}
+
+// Reset restarts the timer to fire after d and reports whether it was
+// active
+func (t *simTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.removeLocked(t)
+	t.startLocked(d)
+	return active
+}
+
+type simTicker struct {
+	*simTimer
+}
+
+func (t simTicker) Stop() {
+	t.simTimer.Stop()
+}
+
+func (t *simTimer) startLocked(d time.Duration) {
+	t.when = t.clock.now.Add(d)
+	t.clock.waiters = append(t.clock.waiters, t)
+}
+
+// fireLocked delivers now without blocking and reschedules tickers
+func (t *simTimer) fireLocked(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+	if t.period > 0 {
+		t.when = t.when.Add(t.period)
+		return
+	}
+	t.clock.removeLocked(t)
+}
@@ -0,0 +1,83 @@
+package versifi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSimulatedClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewSimulatedClock(start)
+
+	timer := clock.NewTimer(3 * time.Second)
+	ticker := clock.NewTicker(time.Second)
+	stopped := clock.NewTimer(time.Second)
+	if !stopped.Stop() || clock.Waiters() != 2 {
+		t.Fatalf("Expected 2 waiters after Stop, got %d", clock.Waiters())
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case now := <-ticker.C():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("Unexpected tick time %v", now)
+		}
+	default:
+		t.Fatal("Expected a tick")
+	}
+	select {
+	case <-timer.C():
+		t.Fatal("Timer fired early")
+	default:
+	}
+
+	// Ticks nobody receives are dropped, as for time.Ticker
+	clock.Advance(5 * time.Second)
+	if got := len(ticker.C()); got != 1 {
+		t.Errorf("Expected one buffered tick, got %d", got)
+	}
+	if now := <-timer.C(); !now.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("Expected the timer to fire at its deadline, got %v", now)
+	}
+	if clock.Now() != start.Add(6*time.Second) {
+		t.Errorf("Unexpected time %v", clock.Now())
+	}
+
+	if timer.Reset(time.Second) {
+		t.Error("Expected a fired timer to be inactive")
+	}
+	clock.Set(start)
+	if clock.Now() != start.Add(6*time.Second) {
+		t.Error("Expected the clock not to move backwards")
+	}
+	ticker.Stop()
+	clock.Advance(time.Second)
+	<-timer.C()
+	if clock.Waiters() != 0 {
+		t.Errorf("Expected no waiters, got %d", clock.Waiters())
+	}
+}
+
+func TestRateLimiterWithClock(t *testing.T) {
+	clock := NewSimulatedClock(time.Unix(0, 0))
+	limiter := NewRateLimiterWithClock(1, 1, clock)
+	ctx := context.Background()
+
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- limiter.Wait(ctx) }()
+
+	waitFor(t, func() bool { return clock.Waiters() == 1 })
+	select {
+	case <-done:
+		t.Fatal("Expected the second request to wait for a token")
+	default:
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// config holds credentials and endpoints
+type config struct {
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+	BaseURL   string `json:"base_url"`
+	WsURL     string `json:"ws_url"`
+}
+
+// loadConfig reads the config file at path, or the default file when path
+// is empty, and applies environment overrides. A missing default file is
+// not an error.
+func loadConfig(path string, getenv func(string) string) (config, error) {
+	var cfg config
+
+	explicit := path != ""
+	if !explicit {
+		path = getenv("VERSIFI_CONFIG")
+		explicit = path != ""
+	}
+	if !explicit {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "versifi", "config.json")
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				return cfg, fmt.Errorf("config %s: %w", path, err)
+			}
+		case explicit || !errors.Is(err, fs.ErrNotExist):
+			return cfg, err
+		}
+	}
+
+	for _, env := range []struct {
+		key   string
+		value *string
+	}{
+		{"VERSIFI_API_KEY", &cfg.APIKey},
+		{"VERSIFI_API_SECRET", &cfg.APISecret},
+		{"VERSIFI_BASE_URL", &cfg.BaseURL},
+		{"VERSIFI_WS_URL", &cfg.WsURL},
+	} {
+		if v := getenv(env.key); v != "" {
+			*env.value = v
+		}
+	}
+	return cfg, nil
+}
+
+func (c config) validate() error {
+	if c.APIKey == "" || c.APISecret == "" {
+		return errors.New("missing credentials: set VERSIFI_API_KEY and VERSIFI_API_SECRET or use a config file")
+	}
+	return nil
+}
+
+func (a *app) client() (*versifi.Client, error) {
+	if err := a.cfg.validate(); err != nil {
+		return nil, err
+	}
+	client := versifi.NewClient(a.cfg.APIKey, a.cfg.APISecret)
+	if a.cfg.BaseURL != "" {
+		client.BaseURL = a.cfg.BaseURL
+	}
+	client.Debug = a.debug
+	client.Logger = log.New(a.stderr, "versifi ", log.LstdFlags)
+	return client, nil
+}
+
+func (a *app) wsClient() (*versifi.WsClient, error) {
+	if err := a.cfg.validate(); err != nil {
+		return nil, err
+	}
+	ws := versifi.NewWsClient(a.cfg.APIKey, a.cfg.APISecret)
+	if a.cfg.WsURL != "" {
+		ws.BaseURL = a.cfg.WsURL
+	}
+	ws.Logger = log.New(a.stderr, "versifi ", log.LstdFlags)
+	if a.debug {
+		ws.LogLevel = versifi.LogLevelDebug
+	}
+	return ws, nil
+}
@@ -0,0 +1,137 @@
+// Command versifi places and inspects Versifi orders from the shell.
+//
+// Usage:
+//
+//	versifi [-config file] [-debug] <command> [flags] [args]
+//
+// Commands:
+//
+//	order create-algo -exchange BINANCE_SPOT -symbol BTC/USDT -side BUY -type TWAP -quantity 1 -params '{"duration":3600}'
+//	order cancel <order-id>
+//	order get <order-id>
+//	orders list [-limit 100] [-offset 0] [-status NEW]
+//	ws tail [-topics execution_report]
+//
+// Credentials are read from VERSIFI_API_KEY and VERSIFI_API_SECRET, or from
+// a JSON config file with the fields api_key, api_secret, base_url and
+// ws_url. The file defaults to versifi/config.json in the user config
+// directory and can be set with -config or VERSIFI_CONFIG. Environment
+// variables take precedence over the file; VERSIFI_BASE_URL and
+// VERSIFI_WS_URL override the endpoints.
+//
+// Results are written to stdout as indented JSON.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// errUsage is returned for invalid command lines, after usage is printed
+var errUsage = errors.New("usage")
+
+const usage = `usage: versifi [-config file] [-debug] <command> [flags] [args]
+
+commands:
+  order create-algo   place an algo order
+  order cancel ID     cancel an order
+  order get ID        show an order
+  orders list         list orders
+  ws tail             print WebSocket messages until interrupted
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app := &app{stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	switch err := app.run(ctx, os.Args[1:]); {
+	case err == nil:
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "versifi: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// app holds what commands need from the process, so tests can replace it
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(key string) string
+	cfg    config
+	debug  bool
+}
+
+func (a *app) run(ctx context.Context, args []string) error {
+	fs := a.flagSet("versifi", usage)
+	configPath := fs.String("config", "", "config file")
+	fs.BoolVar(&a.debug, "debug", false, "log requests and responses")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	var err error
+	a.cfg, err = loadConfig(*configPath, a.getenv)
+	if err != nil {
+		return err
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		return a.usage(usage)
+	}
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "order":
+		if len(args) == 0 {
+			return a.usage(usage)
+		}
+		switch sub, args := args[0], args[1:]; sub {
+		case "create-algo":
+			return a.createAlgoOrder(ctx, args)
+		case "cancel":
+			return a.cancelOrder(ctx, args)
+		case "get":
+			return a.getOrder(ctx, args)
+		}
+	case "orders":
+		if len(args) > 0 && args[0] == "list" {
+			return a.listOrders(ctx, args[1:])
+		}
+	case "ws":
+		if len(args) > 0 && args[0] == "tail" {
+			return a.tail(ctx, args[1:])
+		}
+	}
+	return a.usage(usage)
+}
+
+// flagSet creates a flag set that prints text before the flag defaults
+func (a *app) flagSet(name, text string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprint(a.stderr, text)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (a *app) usage(text string) error {
+	fmt.Fprint(a.stderr, text)
+	return errUsage
+}
+
+func (a *app) print(v interface{}) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
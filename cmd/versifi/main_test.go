@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func newTestApp(t *testing.T, env map[string]string) (*app, *bytes.Buffer) {
+	var stdout bytes.Buffer
+	if _, ok := env["VERSIFI_CONFIG"]; !ok {
+		// Keep the user's own config file out of the tests
+		env["VERSIFI_CONFIG"] = filepath.Join(t.TempDir(), "config.json")
+		os.WriteFile(env["VERSIFI_CONFIG"], []byte("{}"), 0o600)
+	}
+	return &app{
+		stdout: &stdout,
+		stderr: &bytes.Buffer{},
+		getenv: func(key string) string { return env[key] },
+	}, &stdout
+}
+
+func TestCommands(t *testing.T) {
+	var algo versifi.AlgoOrderRequest
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/orders/algo/":
+			json.NewDecoder(r.Body).Decode(&algo)
+			json.NewEncoder(w).Encode(versifi.OrderResponse{OrderID: 100, Status: versifi.OrderStatusNew})
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/orders/100":
+			json.NewEncoder(w).Encode(versifi.GetOrderResponse{OrderID: 100, Status: versifi.OrderStatusFilled})
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/orders/100":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/orders":
+			if r.URL.Query().Get("status") != "NEW" || r.URL.Query().Get("limit") != "10" {
+				t.Errorf("Unexpected query %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode([]versifi.ListOrderItem{{OrderID: 100, Status: "NEW"}})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer rest.Close()
+
+	env := map[string]string{
+		"VERSIFI_API_KEY":    "test-key",
+		"VERSIFI_API_SECRET": "test-secret",
+		"VERSIFI_BASE_URL":   rest.URL,
+	}
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{
+			[]string{"order", "create-algo", "-exchange", "BINANCE_SPOT", "-symbol", "BTC/USDT", "-side", "BUY", "-type", "TWAP", "-quantity", "1.5", "-params", `{"duration":3600}`},
+			`"order_id": 100`,
+		},
+		{[]string{"order", "get", "100"}, `"status": "FILLED"`},
+		{[]string{"order", "cancel", "100"}, `"cancel_requested": true`},
+		{[]string{"orders", "list", "-limit", "10", "-status", "NEW"}, `"order_id": 100`},
+	}
+	for _, tt := range tests {
+		a, stdout := newTestApp(t, env)
+		if err := a.run(context.Background(), tt.args); err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		if !strings.Contains(stdout.String(), tt.want) {
+			t.Errorf("%v: expected %s in %s", tt.args, tt.want, stdout)
+		}
+	}
+	if algo.Quantity != "1.5" || algo.Params["duration"] != float64(3600) {
+		t.Errorf("Unexpected algo order %+v", algo)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"order"},
+		{"order", "get"},
+		{"order", "get", "abc"},
+		{"order", "create-algo", "-symbol", "BTC/USDT"},
+		{"orders", "list", "extra"},
+		{"bogus"},
+	} {
+		a, _ := newTestApp(t, map[string]string{"VERSIFI_API_KEY": "k", "VERSIFI_API_SECRET": "s"})
+		if err := a.run(context.Background(), args); !errors.Is(err, errUsage) {
+			t.Errorf("%v: expected usage error, got %v", args, err)
+		}
+	}
+}
+
+func TestInvalidEnumFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"order", "create-algo", "-exchange", "KRAKEN", "-symbol", "BTC/USDT", "-side", "BUY", "-type", "TWAP", "-quantity", "1"},
+		{"order", "create-algo", "-exchange", "BINANCE_SPOT", "-symbol", "BTC/USDT", "-side", "LONG", "-type", "TWAP", "-quantity", "1"},
+		{"orders", "list", "-status", "OPEN"},
+	} {
+		a, _ := newTestApp(t, map[string]string{"VERSIFI_API_KEY": "k", "VERSIFI_API_SECRET": "s"})
+		if err := a.run(context.Background(), args); !errors.Is(err, versifi.ErrInvalidEnum) {
+			t.Errorf("%v: expected ErrInvalidEnum, got %v", args, err)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"api_key":"file-key","api_secret":"file-secret","base_url":"https://example.com"}`), 0o600)
+
+	cfg, err := loadConfig(path, func(key string) string {
+		if key == "VERSIFI_API_KEY" {
+			return "env-key"
+		}
+		return ""
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKey != "env-key" || cfg.APISecret != "file-secret" || cfg.BaseURL != "https://example.com" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+
+	// An explicit file must exist
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.json"), func(string) string { return "" }); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+
+	// Commands fail without credentials
+	a, _ := newTestApp(t, map[string]string{})
+	if err := a.run(context.Background(), []string{"order", "get", "1"}); err == nil || !strings.Contains(err.Error(), "missing credentials") {
+		t.Errorf("Expected missing credentials, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+const createAlgoUsage = `usage: versifi order create-algo -exchange EXCHANGE -symbol SYMBOL -side SIDE -type TYPE -quantity QTY [-params JSON] [-client-order-id ID]
+
+`
+
+func (a *app) createAlgoOrder(ctx context.Context, args []string) error {
+	fs := a.flagSet("order create-algo", createAlgoUsage)
+	exchange := fs.String("exchange", "", "exchange, e.g. BINANCE_SPOT")
+	symbol := fs.String("symbol", "", "symbol, e.g. BTC/USDT")
+	side := fs.String("side", "", "BUY or SELL")
+	orderType := fs.String("type", "", "TWAP, VWAP or IS")
+	quantity := fs.String("quantity", "", "order quantity")
+	params := fs.String("params", "", `algorithm parameters as a JSON object, e.g. '{"duration":3600}'`)
+	clientOrderID := fs.Int64("client-order-id", 0, "client order ID")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if *exchange == "" || *symbol == "" || *side == "" || *orderType == "" || *quantity == "" || fs.NArg() > 0 {
+		return a.usage(createAlgoUsage)
+	}
+
+	exchangeType, err := versifi.ParseExchangeType(*exchange)
+	if err != nil {
+		return fmt.Errorf("invalid -exchange: %w", err)
+	}
+	sideType, err := versifi.ParseSideType(*side)
+	if err != nil {
+		return fmt.Errorf("invalid -side: %w", err)
+	}
+	algoType, err := versifi.ParseAlgoOrderType(*orderType)
+	if err != nil {
+		return fmt.Errorf("invalid -type: %w", err)
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	svc := client.NewCreateAlgoOrderService().
+		Exchange(exchangeType).
+		Symbol(*symbol).
+		Side(sideType).
+		OrderType(algoType).
+		Quantity(*quantity)
+	if *params != "" {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(*params), &m); err != nil {
+			return fmt.Errorf("invalid -params: %w", err)
+		}
+		svc.Params(m)
+	}
+	if *clientOrderID != 0 {
+		svc.ClientOrderID(*clientOrderID)
+	}
+
+	res, err := svc.Do(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(res)
+}
+
+func (a *app) cancelOrder(ctx context.Context, args []string) error {
+	orderID, err := a.orderIDArg("order cancel", args)
+	if err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	if err := client.NewCancelOrderService().OrderID(orderID).Do(ctx); err != nil {
+		return err
+	}
+	return a.print(map[string]interface{}{"order_id": orderID, "cancel_requested": true})
+}
+
+func (a *app) getOrder(ctx context.Context, args []string) error {
+	orderID, err := a.orderIDArg("order get", args)
+	if err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	res, err := client.NewGetOrderService().OrderID(orderID).Do(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(res)
+}
+
+const listOrdersUsage = `usage: versifi orders list [-limit N] [-offset N] [-status STATUS]
+
+`
+
+func (a *app) listOrders(ctx context.Context, args []string) error {
+	fs := a.flagSet("orders list", listOrdersUsage)
+	limit := fs.Int64("limit", 100, "maximum number of orders")
+	offset := fs.Int64("offset", 0, "number of orders to skip")
+	status := fs.String("status", "", "only orders with this status, e.g. NEW")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() > 0 {
+		return a.usage(listOrdersUsage)
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	svc := client.NewListOpenOrdersService().Limit(*limit).Offset(*offset)
+	if *status != "" {
+		statusType, err := versifi.ParseOrderStatusType(*status)
+		if err != nil {
+			return fmt.Errorf("invalid -status: %w", err)
+		}
+		svc.Status(statusType)
+	}
+	orders, err := svc.Do(ctx)
+	if err != nil {
+		return err
+	}
+	if orders == nil {
+		orders = []versifi.ListOrderItem{}
+	}
+	return a.print(orders)
+}
+
+// orderIDArg parses the single order ID argument of cmd
+func (a *app) orderIDArg(cmd string, args []string) (int64, error) {
+	text := fmt.Sprintf("usage: versifi %s ORDER_ID\n", cmd)
+	if len(args) != 1 {
+		return 0, a.usage(text)
+	}
+	orderID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, a.usage(text)
+	}
+	return orderID, nil
+}
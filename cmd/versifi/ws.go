@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const tailUsage = `usage: versifi ws tail [-topics execution_report,analytics]
+
+Prints each message on the subscribed topics as one line of JSON until
+interrupted.
+
+`
+
+// shutdownTimeout bounds the close handshake when tail stops
+const shutdownTimeout = 5 * time.Second
+
+func (a *app) tail(ctx context.Context, args []string) error {
+	fs := a.flagSet("ws tail", tailUsage)
+	topics := fs.String("topics", "execution_report", "comma-separated topics")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() > 0 || *topics == "" {
+		return a.usage(tailUsage)
+	}
+
+	ws, err := a.wsClient()
+	if err != nil {
+		return err
+	}
+	if err := ws.Connect(); err != nil {
+		return err
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		ws.Shutdown(shutdownCtx)
+	}()
+
+	// Handlers for different topics may run concurrently
+	var mu sync.Mutex
+	printMessage := func(message []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(a.stdout, "%s\n", message)
+	}
+	for _, topic := range strings.Split(*topics, ",") {
+		if err := ws.Subscribe(strings.TrimSpace(topic), printMessage); err != nil {
+			return fmt.Errorf("subscribe %s: %w", topic, err)
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
@@ -1,21 +1,104 @@
 package versifi
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Error categories of REST requests. Every error from a service's Do
+// matches at most one of them with errors.Is, and still unwraps to its
+// cause, so errors.Is(err, context.DeadlineExceeded) holds for a request
+// that timed out.
+var (
+	// ErrTransport is matched by failures to send a request or read its
+	// response, such as refused connections, TLS errors and timeouts
+	ErrTransport = errors.New("transport error")
+	// ErrEncode is matched by failures to encode a request body
+	ErrEncode = errors.New("encode error")
+	// ErrDecode is matched by responses whose body could not be decoded
+	ErrDecode = errors.New("decode error")
+	// ErrAPI is matched by every *APIError: the API answered and rejected
+	// the request
+	ErrAPI = errors.New("api error")
+	// ErrNotSent is matched by requests the client stopped before sending
+	// them: the kill switch, risk and exchange checks, an API version not
+	// serving the endpoint, or a failed wait for the rate limiter
+	ErrNotSent = errors.New("request not sent")
+)
 
 // APIError represents an error from the Versifi API
 type APIError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	// HTTPStatus is the status code of the response carrying the error
+	HTTPStatus int `json:"-"`
 }
 
 func (e APIError) Error() string {
 	return fmt.Sprintf("<APIError> code=%d, message=%s", e.Code, e.Message)
 }
 
-// IsAPIError checks if an error is an API error
+// Is matches ErrAPI
+func (e APIError) Is(target error) bool {
+	return target == ErrAPI
+}
+
+// IsAPIError checks if an error is, or wraps, an API error
 func IsAPIError(e error) bool {
-	_, ok := e.(*APIError)
-	return ok
+	var apiErr *APIError
+	return errors.As(e, &apiErr)
+}
+
+// RequestError reports a REST request that failed without an answer from
+// the API, whose answer could not be decoded, or that the client stopped
+// before sending it.
+//
+// For transport errors, Phase and Elapsed tell a timeout while connecting
+// from a slow server, and NotSent whether an order in the request may have
+// been placed; errors.Is(err, context.Canceled) tells a caller's
+// cancellation from a timeout.
+type RequestError struct {
+	Method   string
+	Endpoint string
+	// Kind is ErrTransport, ErrEncode, ErrDecode or ErrNotSent
+	Kind error
+	// Phase is how far a request with a transport error got, or empty if
+	// the HTTP client's transport did not report it
+	Phase RequestPhaseType
+	// Elapsed is the time from sending the request to the transport error
+	Elapsed time.Duration
+	// Err is the underlying error
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	if e.Phase != "" {
+		return fmt.Sprintf("%s %s: %v during %s after %v: %v", e.Method, e.Endpoint, e.Kind, e.Phase, e.Elapsed, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %v: %v", e.Method, e.Endpoint, e.Kind, e.Err)
+}
+
+// Timeout reports whether the request ran out of time, by its context's
+// deadline or the HTTP client's Timeout
+func (e *RequestError) Timeout() bool {
+	var t interface{ Timeout() bool }
+	return errors.As(e.Err, &t) && t.Timeout()
+}
+
+// NotSent reports whether the request failed before reaching the server,
+// so an order in it was certainly not placed and may be sent again
+func (e *RequestError) NotSent() bool {
+	return e.Kind == ErrEncode || e.Kind == ErrNotSent || e.Phase == RequestPhaseConnect
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the error's Kind
+func (e *RequestError) Is(target error) bool {
+	return target == e.Kind
 }
 
 // Common types and enums
@@ -92,6 +175,15 @@ const (
 	OrderStatusExpired         OrderStatusType = "EXPIRED"
 )
 
+// IsFinal reports whether status is terminal
+func (s OrderStatusType) IsFinal() bool {
+	switch s {
+	case OrderStatusFilled, OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired:
+		return true
+	}
+	return false
+}
+
 // PairStyleType represents pair order style
 type PairStyleType string
 
@@ -0,0 +1,47 @@
+package versifi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+
+// DefaultCompressThreshold is a Client.CompressThreshold suited to batch
+// requests: bodies below it gain too little from compression to pay for it
+const DefaultCompressThreshold = 16 << 10
+
+var gzipWriterPool = sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }}
+
+// compresses reports whether c gzips body. Signatures are always computed
+// over the uncompressed body.
+func (c *Client) compresses(body *pooledBody) bool {
+	return c.CompressThreshold > 0 && len(body.Bytes()) >= c.CompressThreshold
+}
+
+// gunzip decompresses data
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// gzipBody compresses data into a new pooled body
+func gzipBody(data []byte) (*pooledBody, error) {
+	buf := getBuffer()
+	zw := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(zw)
+	zw.Reset(buf)
+	if _, err := zw.Write(data); err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+	return newPooledBody(buf), nil
+}
@@ -0,0 +1,66 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestCompression(t *testing.T) {
+	type received struct {
+		encoding  string
+		wireBytes int
+		body      []byte
+		signature string
+	}
+	var got received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wire, _ := io.ReadAll(r.Body)
+		body := wire
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			var err error
+			if body, err = gunzip(wire); err != nil {
+				t.Errorf("Failed to decompress the body: %v", err)
+			}
+		}
+		got = received{r.Header.Get("Content-Encoding"), len(wire), body, r.Header.Get("X-VERSIFI-API-SIGN")}
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	client.CompressThreshold = DefaultCompressThreshold
+	ctx := context.Background()
+
+	ids := make([]int64, 5000)
+	for i := range ids {
+		ids[i] = 1_000_000_000 + int64(i)
+	}
+	if err := client.NewCancelBatchOrderService().OrderIDs(ids).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var req CancelBatchRequest
+	if err := json.Unmarshal(got.body, &req); err != nil || len(req.IDs) != 5000 || got.encoding != "gzip" || got.wireBytes >= len(got.body)/2 {
+		t.Errorf("Expected a compressed batch of 5000 IDs, got %q with %d of %d bytes, %v", got.encoding, got.wireBytes, len(got.body), err)
+	}
+
+	// The signature covers the uncompressed body
+	note := strings.Repeat("x", DefaultCompressThreshold)
+	if _, err := Do[struct{}](ctx, client, CustomRequest{Method: http.MethodPost, Endpoint: "/v2/notes", Body: map[string]string{"note": note}}); err != nil {
+		t.Fatal(err)
+	}
+	if got.encoding != "gzip" || got.signature != sign("test-secret", string(got.body)) {
+		t.Errorf("Expected a compressed body signed uncompressed, got %q", got.encoding)
+	}
+
+	// Small bodies are sent as they are
+	if err := client.NewCancelBatchOrderService().OrderIDs(ids[:10]).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got.encoding != "" || got.wireBytes != len(got.body) {
+		t.Errorf("Expected an uncompressed body, got %q", got.encoding)
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// NewClient validates the config and creates a REST client from it
+func (c *Config) NewClient() (*versifi.Client, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var client *versifi.Client
+	if c.LocalAddr != "" {
+		client = versifi.NewClientWithLocalAddr(c.APIKey, c.APISecret, c.LocalAddr)
+	} else {
+		client = versifi.NewClient(c.APIKey, c.APISecret)
+	}
+	if c.Testnet {
+		client.BaseURL = versifi.BaseAPITestnetURL
+		client.RelaxedValidation = true
+	}
+	if c.BaseURL != "" {
+		client.BaseURL = c.BaseURL
+	}
+	client.Debug = c.Debug
+
+	if c.Timeout > 0 {
+		// Never modify the shared http.DefaultClient
+		httpClient := *client.HTTPClient
+		httpClient.Timeout = time.Duration(c.Timeout)
+		client.HTTPClient = &httpClient
+	}
+	if c.RateLimit > 0 {
+		client.SetRateLimiter(versifi.NewRateLimiter(c.RateLimit, c.RateBurst))
+	}
+	return client, nil
+}
+
+// NewWsClient validates the config and creates a WebSocket client from it
+func (c *Config) NewWsClient() (*versifi.WsClient, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var ws *versifi.WsClient
+	if c.LocalAddr != "" {
+		ws = versifi.NewWsClientWithLocalAddr(c.APIKey, c.APISecret, c.LocalAddr)
+	} else {
+		ws = versifi.NewWsClient(c.APIKey, c.APISecret)
+	}
+	if c.Testnet {
+		ws.BaseURL = versifi.BaseWSTestnetURL
+	}
+	if c.WsURL != "" {
+		ws.BaseURL = c.WsURL
+	}
+	ws.FallbackURLs = append([]string(nil), c.WsFallbackURLs...)
+	if c.WsAuthExpiry > 0 {
+		ws.AuthExpiry = time.Duration(c.WsAuthExpiry)
+	}
+	if c.WsReauthInterval > 0 {
+		ws.ReauthInterval = time.Duration(c.WsReauthInterval)
+	}
+	ws.KeepaliveInterval = time.Duration(c.WsKeepaliveInterval)
+	ws.KeepaliveTimeout = time.Duration(c.WsKeepaliveTimeout)
+	ws.DisableKeepalive = c.WsDisableKeepalive
+	if c.WsRateLimit > 0 {
+		ws.SetSendLimiter(versifi.NewRateLimiter(c.WsRateLimit, c.WsRateBurst))
+	}
+	if c.Debug {
+		ws.LogLevel = versifi.LogLevelDebug
+	}
+	return ws, nil
+}
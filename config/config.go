@@ -0,0 +1,268 @@
+// Package config builds Client and WsClient instances from environment
+// variables and YAML, TOML or JSON config files.
+//
+//	cfg, err := config.Load("versifi.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	client, err := cfg.NewClient()
+//
+// A config file uses the keys below; every key can be overridden by the
+// environment variable next to it. Durations are strings such as "10s".
+//
+//	api_key                  VERSIFI_API_KEY
+//	api_secret               VERSIFI_API_SECRET
+//	testnet                  VERSIFI_TESTNET (sandbox URLs and relaxed validation)
+//	base_url                 VERSIFI_BASE_URL
+//	ws_url                   VERSIFI_WS_URL
+//	ws_fallback_urls         VERSIFI_WS_FALLBACK_URLS (comma separated)
+//	local_addr               VERSIFI_LOCAL_ADDR
+//	timeout                  VERSIFI_TIMEOUT
+//	rate_limit               VERSIFI_RATE_LIMIT (REST requests per second)
+//	rate_burst               VERSIFI_RATE_BURST
+//	ws_rate_limit            VERSIFI_WS_RATE_LIMIT (messages per second)
+//	ws_rate_burst            VERSIFI_WS_RATE_BURST
+//	ws_auth_expiry           VERSIFI_WS_AUTH_EXPIRY
+//	ws_reauth_interval       VERSIFI_WS_REAUTH_INTERVAL
+//	ws_keepalive_interval    VERSIFI_WS_KEEPALIVE_INTERVAL
+//	ws_keepalive_timeout     VERSIFI_WS_KEEPALIVE_TIMEOUT
+//	ws_disable_keepalive     VERSIFI_WS_DISABLE_KEEPALIVE
+//	debug                    VERSIFI_DEBUG
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds client settings. Zero values keep the client defaults.
+type Config struct {
+	APIKey         string   `json:"api_key" yaml:"api_key" toml:"api_key"`
+	APISecret      string   `json:"api_secret" yaml:"api_secret" toml:"api_secret"`
+	BaseURL        string   `json:"base_url" yaml:"base_url" toml:"base_url"`
+	WsURL          string   `json:"ws_url" yaml:"ws_url" toml:"ws_url"`
+	WsFallbackURLs []string `json:"ws_fallback_urls" yaml:"ws_fallback_urls" toml:"ws_fallback_urls"`
+	// LocalAddr is the local IP address to bind to
+	LocalAddr string `json:"local_addr" yaml:"local_addr" toml:"local_addr"`
+	// Timeout bounds each REST request
+	Timeout Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
+	// RateLimit and RateBurst pace REST requests; zero disables pacing
+	RateLimit float64 `json:"rate_limit" yaml:"rate_limit" toml:"rate_limit"`
+	RateBurst int     `json:"rate_burst" yaml:"rate_burst" toml:"rate_burst"`
+	// WsRateLimit and WsRateBurst pace WebSocket sends; zero disables pacing
+	WsRateLimit         float64  `json:"ws_rate_limit" yaml:"ws_rate_limit" toml:"ws_rate_limit"`
+	WsRateBurst         int      `json:"ws_rate_burst" yaml:"ws_rate_burst" toml:"ws_rate_burst"`
+	WsAuthExpiry        Duration `json:"ws_auth_expiry" yaml:"ws_auth_expiry" toml:"ws_auth_expiry"`
+	WsReauthInterval    Duration `json:"ws_reauth_interval" yaml:"ws_reauth_interval" toml:"ws_reauth_interval"`
+	WsKeepaliveInterval Duration `json:"ws_keepalive_interval" yaml:"ws_keepalive_interval" toml:"ws_keepalive_interval"`
+	WsKeepaliveTimeout  Duration `json:"ws_keepalive_timeout" yaml:"ws_keepalive_timeout" toml:"ws_keepalive_timeout"`
+	WsDisableKeepalive  bool     `json:"ws_disable_keepalive" yaml:"ws_disable_keepalive" toml:"ws_disable_keepalive"`
+	// Testnet targets the sandbox environment. BaseURL and WsURL still
+	// override its URLs.
+	Testnet bool `json:"testnet" yaml:"testnet" toml:"testnet"`
+	Debug   bool `json:"debug" yaml:"debug" toml:"debug"`
+}
+
+// Duration is a time.Duration written as a string such as "1m30s"
+type Duration time.Duration
+
+// UnmarshalText parses a duration string
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText formats the duration as a string
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// ValidationError lists every problem found in a config
+type ValidationError struct {
+	// Missing holds the keys of required settings that are not set
+	Missing []string
+	// Invalid describes settings that are set but cannot be used
+	Invalid []string
+}
+
+func (e *ValidationError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Invalid) > 0 {
+		parts = append(parts, "invalid "+strings.Join(e.Invalid, "; "))
+	}
+	return "config: " + strings.Join(parts, "; ")
+}
+
+// Load reads the config file at path and applies environment overrides.
+// The format follows the extension: .yaml, .yml, .toml or .json.
+func Load(path string) (*Config, error) {
+	return load(path, os.LookupEnv)
+}
+
+// FromEnv builds a config from environment variables alone
+func FromEnv() (*Config, error) {
+	return load("", os.LookupEnv)
+}
+
+func load(path string, lookup func(string) (string, bool)) (*Config, error) {
+	cfg := new(Config)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.decode(filepath.Ext(path), data); err != nil {
+			return nil, fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+	if err := cfg.applyEnv(lookup); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) decode(ext string, data []byte) error {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err := dec.Decode(c)
+		if errors.Is(err, io.EOF) {
+			// An empty file
+			return nil
+		}
+		return err
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown keys %v", undecoded)
+		}
+		return nil
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(c)
+	}
+	return fmt.Errorf("unsupported config format %q", ext)
+}
+
+// applyEnv overrides settings from environment variables
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	var invalid []string
+	set := func(name string, parse func(string) error) {
+		v, ok := lookup(name)
+		if !ok || v == "" {
+			return
+		}
+		if err := parse(v); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	str := func(name string, dst *string) {
+		set(name, func(v string) error { *dst = v; return nil })
+	}
+	dur := func(name string, dst *Duration) {
+		set(name, func(v string) error { return dst.UnmarshalText([]byte(v)) })
+	}
+	float := func(name string, dst *float64) {
+		set(name, func(v string) (err error) { *dst, err = strconv.ParseFloat(v, 64); return })
+	}
+	integer := func(name string, dst *int) {
+		set(name, func(v string) (err error) { *dst, err = strconv.Atoi(v); return })
+	}
+
+	str("VERSIFI_API_KEY", &c.APIKey)
+	str("VERSIFI_API_SECRET", &c.APISecret)
+	set("VERSIFI_TESTNET", func(v string) (err error) { c.Testnet, err = strconv.ParseBool(v); return })
+	str("VERSIFI_BASE_URL", &c.BaseURL)
+	str("VERSIFI_WS_URL", &c.WsURL)
+	set("VERSIFI_WS_FALLBACK_URLS", func(v string) error {
+		c.WsFallbackURLs = nil
+		for _, u := range strings.Split(v, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				c.WsFallbackURLs = append(c.WsFallbackURLs, u)
+			}
+		}
+		return nil
+	})
+	str("VERSIFI_LOCAL_ADDR", &c.LocalAddr)
+	dur("VERSIFI_TIMEOUT", &c.Timeout)
+	float("VERSIFI_RATE_LIMIT", &c.RateLimit)
+	integer("VERSIFI_RATE_BURST", &c.RateBurst)
+	float("VERSIFI_WS_RATE_LIMIT", &c.WsRateLimit)
+	integer("VERSIFI_WS_RATE_BURST", &c.WsRateBurst)
+	dur("VERSIFI_WS_AUTH_EXPIRY", &c.WsAuthExpiry)
+	dur("VERSIFI_WS_REAUTH_INTERVAL", &c.WsReauthInterval)
+	dur("VERSIFI_WS_KEEPALIVE_INTERVAL", &c.WsKeepaliveInterval)
+	dur("VERSIFI_WS_KEEPALIVE_TIMEOUT", &c.WsKeepaliveTimeout)
+	set("VERSIFI_WS_DISABLE_KEEPALIVE", func(v string) (err error) { c.WsDisableKeepalive, err = strconv.ParseBool(v); return })
+	set("VERSIFI_DEBUG", func(v string) (err error) { c.Debug, err = strconv.ParseBool(v); return })
+
+	if len(invalid) > 0 {
+		return &ValidationError{Invalid: invalid}
+	}
+	return nil
+}
+
+// Validate checks that credentials are set and that every value is usable.
+// The error is a *ValidationError listing all problems.
+func (c *Config) Validate() error {
+	e := new(ValidationError)
+	if c.APIKey == "" {
+		e.Missing = append(e.Missing, "api_key")
+	}
+	if c.APISecret == "" {
+		e.Missing = append(e.Missing, "api_secret")
+	}
+	for _, u := range append([]string{c.BaseURL, c.WsURL}, c.WsFallbackURLs...) {
+		if u != "" && !strings.Contains(u, "://") {
+			e.Invalid = append(e.Invalid, fmt.Sprintf("%q is not an absolute URL", u))
+		}
+	}
+	for _, d := range []struct {
+		key   string
+		value Duration
+	}{
+		{"timeout", c.Timeout},
+		{"ws_auth_expiry", c.WsAuthExpiry},
+		{"ws_reauth_interval", c.WsReauthInterval},
+		{"ws_keepalive_interval", c.WsKeepaliveInterval},
+		{"ws_keepalive_timeout", c.WsKeepaliveTimeout},
+	} {
+		if d.value < 0 {
+			e.Invalid = append(e.Invalid, d.key+" must not be negative")
+		}
+	}
+	if c.RateLimit < 0 || c.RateBurst < 0 {
+		e.Invalid = append(e.Invalid, "rate_limit and rate_burst must not be negative")
+	}
+	if c.WsRateLimit < 0 || c.WsRateBurst < 0 {
+		e.Invalid = append(e.Invalid, "ws_rate_limit and ws_rate_burst must not be negative")
+	}
+
+	if len(e.Missing) > 0 || len(e.Invalid) > 0 {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func TestLoadFormats(t *testing.T) {
+	want := Config{
+		APIKey:         "key",
+		APISecret:      "secret",
+		WsFallbackURLs: []string{"wss://a", "wss://b"},
+		Timeout:        Duration(10 * time.Second),
+		RateLimit:      5,
+	}
+	files := map[string]string{
+		"versifi.yaml": "api_key: key\napi_secret: secret\nws_fallback_urls: [wss://a, wss://b]\ntimeout: 10s\nrate_limit: 5\n",
+		"versifi.toml": "api_key = \"key\"\napi_secret = \"secret\"\nws_fallback_urls = [\"wss://a\", \"wss://b\"]\ntimeout = \"10s\"\nrate_limit = 5.0\n",
+		"versifi.json": `{"api_key":"key","api_secret":"secret","ws_fallback_urls":["wss://a","wss://b"],"timeout":"10s","rate_limit":5}`,
+	}
+	for name, content := range files {
+		cfg, err := load(writeFile(t, name, content), env(nil))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*cfg, want) {
+			t.Errorf("%s: got %+v", name, *cfg)
+		}
+	}
+
+	for name, content := range map[string]string{
+		"typo.yaml": "api_kye: key\n",
+		"typo.toml": "api_kye = \"key\"\n",
+		"typo.json": `{"api_kye":"key"}`,
+		"bad.ini":   "api_key=key",
+	} {
+		if _, err := load(writeFile(t, name, content), env(nil)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	path := writeFile(t, "versifi.yaml", "api_key: file-key\napi_secret: file-secret\n")
+	cfg, err := load(path, env(map[string]string{
+		"VERSIFI_API_KEY":          "env-key",
+		"VERSIFI_WS_FALLBACK_URLS": "wss://a, wss://b",
+		"VERSIFI_WS_AUTH_EXPIRY":   "30s",
+		"VERSIFI_DEBUG":            "true",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKey != "env-key" || cfg.APISecret != "file-secret" || len(cfg.WsFallbackURLs) != 2 ||
+		cfg.WsAuthExpiry != Duration(30*time.Second) || !cfg.Debug {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+
+	_, err = load("", env(map[string]string{"VERSIFI_TIMEOUT": "soon", "VERSIFI_RATE_BURST": "many"}))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Invalid) != 2 {
+		t.Errorf("Expected 2 invalid settings, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &Config{BaseURL: "api.versifi.io", Timeout: Duration(-time.Second)}
+	_, err := cfg.NewClient()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+	if !reflect.DeepEqual(verr.Missing, []string{"api_key", "api_secret"}) || len(verr.Invalid) != 2 {
+		t.Errorf("Unexpected error %+v", verr)
+	}
+	if _, err := cfg.NewWsClient(); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	cfg := &Config{
+		APIKey:             "key",
+		APISecret:          "secret",
+		BaseURL:            "https://example.com",
+		WsURL:              "wss://example.com/ws",
+		Timeout:            Duration(5 * time.Second),
+		RateLimit:          10,
+		WsReauthInterval:   Duration(time.Minute),
+		WsDisableKeepalive: true,
+	}
+
+	client, err := cfg.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.BaseURL != cfg.BaseURL || client.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("Unexpected client %+v", client)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client.BaseURL = server.URL
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.NewGetOrderService().OrderID(1).Do(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A burst of one at 10 per second spaces the requests 100ms apart
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected rate limited requests, took %v", elapsed)
+	}
+	if http.DefaultClient.Timeout != 0 || http.DefaultClient.Transport != nil {
+		t.Error("http.DefaultClient was modified")
+	}
+
+	ws, err := cfg.NewWsClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws.BaseURL != cfg.WsURL || ws.APIKey != "key" || ws.ReauthInterval != time.Minute || !ws.DisableKeepalive {
+		t.Errorf("Unexpected WebSocket client %+v", ws)
+	}
+}
+
+func TestNewTestnetClient(t *testing.T) {
+	cfg, err := load("", env(map[string]string{
+		"VERSIFI_API_KEY":    "key",
+		"VERSIFI_API_SECRET": "secret",
+		"VERSIFI_TESTNET":    "true",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := cfg.NewClient()
+	ws, _ := cfg.NewWsClient()
+	if client.BaseURL != versifi.BaseAPITestnetURL || !client.RelaxedValidation || ws.BaseURL != versifi.BaseWSTestnetURL {
+		t.Errorf("Expected testnet clients, got %s and %s", client.BaseURL, ws.BaseURL)
+	}
+
+	cfg.BaseURL = "https://sandbox.example.com"
+	if client, _ := cfg.NewClient(); client.BaseURL != cfg.BaseURL {
+		t.Errorf("Expected base_url to override the testnet URL, got %s", client.BaseURL)
+	}
+}
@@ -0,0 +1,245 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Credentials is an API key and secret pair
+type Credentials struct {
+	APIKey    string
+	APISecret string
+}
+
+// CredentialProvider fetches the current API credentials, typically from a
+// secret store
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialProviderFunc adapts a function to a CredentialProvider
+type CredentialProviderFunc func(ctx context.Context) (Credentials, error)
+
+// Credentials calls f(ctx)
+func (f CredentialProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// ErrEmptyCredentials is returned when a provider yields a blank key or secret
+var ErrEmptyCredentials = errors.New("credential provider returned an empty key or secret")
+
+// SetCredentials replaces the API key and secret used to sign requests. It
+// is safe to call while requests are in flight and takes precedence over
+// the APIKey and APISecret fields.
+func (c *Client) SetCredentials(apiKey, apiSecret string) {
+	c.creds.Store(&Credentials{APIKey: apiKey, APISecret: apiSecret})
+}
+
+// credentials returns the credentials set by SetCredentials, or the APIKey
+// and APISecret fields
+func (c *Client) credentials() Credentials {
+	if creds := c.creds.Load(); creds != nil {
+		return *creds
+	}
+	return Credentials{APIKey: c.APIKey, APISecret: c.APISecret}
+}
+
+// SetCredentials replaces the API key and secret used to authenticate. It
+// takes precedence over the APIKey and APISecret fields. A connected client
+// re-authenticates with the new credentials right away when session renewal
+// is enabled, and otherwise on the next reconnect.
+func (c *WsClient) SetCredentials(apiKey, apiSecret string) {
+	c.mu.Lock()
+	c.creds = &Credentials{APIKey: apiKey, APISecret: apiSecret}
+	authenticated := c.isAuthenticated
+	c.mu.Unlock()
+
+	if authenticated {
+		select {
+		case c.reauth <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// credentials returns the credentials set by SetCredentials, or the APIKey
+// and APISecret fields
+func (c *WsClient) credentials() Credentials {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.creds != nil {
+		return *c.creds
+	}
+	return Credentials{APIKey: c.APIKey, APISecret: c.APISecret}
+}
+
+// CredentialHandler is notified when a CredentialRefresher picks up new
+// credentials
+type CredentialHandler func(creds Credentials)
+
+// CredentialRefresher caches credentials from a CredentialProvider and
+// refreshes them periodically, passing every change to its subscribers
+// and attached clients.
+//
+//	refresher := versifi.NewCredentialRefresher(provider, 5*time.Minute)
+//	if err := refresher.Refresh(ctx); err != nil {
+//		return err
+//	}
+//	refresher.Attach(client)
+//	refresher.AttachWs(ws)
+//	go refresher.Run(ctx)
+type CredentialRefresher struct {
+	provider CredentialProvider
+	interval time.Duration
+
+	mu         sync.RWMutex
+	current    Credentials
+	fetchedAt  time.Time
+	handlers   map[int]CredentialHandler
+	nextID     int
+	errHandler ErrHandler
+}
+
+// NewCredentialRefresher creates a refresher that fetches from provider
+// every interval once Run is called
+func NewCredentialRefresher(provider CredentialProvider, interval time.Duration) *CredentialRefresher {
+	return &CredentialRefresher{
+		provider: provider,
+		interval: interval,
+		handlers: make(map[int]CredentialHandler),
+	}
+}
+
+// SetErrorHandler sets the handler for refresh failures during Run. The
+// previous credentials stay in use after a failure.
+func (r *CredentialRefresher) SetErrorHandler(handler ErrHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errHandler = handler
+}
+
+// Subscribe registers a handler called with each new set of credentials
+// and returns a function that removes it
+func (r *CredentialRefresher) Subscribe(handler CredentialHandler) (unsubscribe func()) {
+	r.mu.Lock()
+	id := r.nextID
+	r.nextID++
+	r.handlers[id] = handler
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		delete(r.handlers, id)
+		r.mu.Unlock()
+	}
+}
+
+// Attach rotates client's credentials on every change, starting with the
+// cached credentials if there are any
+func (r *CredentialRefresher) Attach(client *Client) (detach func()) {
+	return r.attach(client.SetCredentials)
+}
+
+// AttachWs rotates ws's credentials on every change, starting with the
+// cached credentials if there are any
+func (r *CredentialRefresher) AttachWs(ws *WsClient) (detach func()) {
+	return r.attach(ws.SetCredentials)
+}
+
+func (r *CredentialRefresher) attach(set func(apiKey, apiSecret string)) func() {
+	unsubscribe := r.Subscribe(func(creds Credentials) {
+		set(creds.APIKey, creds.APISecret)
+	})
+	if creds, ok := r.Current(); ok {
+		set(creds.APIKey, creds.APISecret)
+	}
+	return unsubscribe
+}
+
+// Current returns the cached credentials, and false before the first
+// successful refresh
+func (r *CredentialRefresher) Current() (Credentials, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current, !r.fetchedAt.IsZero()
+}
+
+// Credentials returns the cached credentials, fetching them first if there
+// are none, so a refresher can itself be used as a CredentialProvider
+func (r *CredentialRefresher) Credentials(ctx context.Context) (Credentials, error) {
+	if creds, ok := r.Current(); ok {
+		return creds, nil
+	}
+	if err := r.Refresh(ctx); err != nil {
+		return Credentials{}, err
+	}
+	creds, _ := r.Current()
+	return creds, nil
+}
+
+// Refresh fetches credentials from the provider now and notifies
+// subscribers if they changed
+func (r *CredentialRefresher) Refresh(ctx context.Context) error {
+	creds, err := r.provider.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+	if creds.APIKey == "" || creds.APISecret == "" {
+		return ErrEmptyCredentials
+	}
+
+	r.mu.Lock()
+	changed := creds != r.current
+	r.current = creds
+	r.fetchedAt = time.Now()
+	handlers := r.handlersLocked()
+	r.mu.Unlock()
+
+	if changed {
+		for _, handler := range handlers {
+			handler(creds)
+		}
+	}
+	return nil
+}
+
+// Run refreshes every interval until ctx is done. Failures are passed to
+// the error handler.
+func (r *CredentialRefresher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+			r.mu.RLock()
+			handler := r.errHandler
+			r.mu.RUnlock()
+			if handler != nil {
+				handler(err)
+			}
+		}
+	}
+}
+
+// handlersLocked returns the handlers in subscription order
+func (r *CredentialRefresher) handlersLocked() []CredentialHandler {
+	ids := make([]int, 0, len(r.handlers))
+	for id := range r.handlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	handlers := make([]CredentialHandler, len(ids))
+	for i, id := range ids {
+		handlers[i] = r.handlers[id]
+	}
+	return handlers
+}
@@ -0,0 +1,132 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCredentialRefresherRotatesClients(t *testing.T) {
+	var mu sync.Mutex
+	current := Credentials{APIKey: "key-1", APISecret: "secret-1"}
+	var fail error
+	provider := CredentialProviderFunc(func(ctx context.Context) (Credentials, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return current, fail
+	})
+	rotate := func(creds Credentials, err error) {
+		mu.Lock()
+		current, fail = creds, err
+		mu.Unlock()
+	}
+
+	keys := make(chan string, 10)
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get("X-VERSIFI-API-KEY")
+		w.Write([]byte(`{}`))
+	}))
+	defer rest.Close()
+
+	refresher := NewCredentialRefresher(provider, time.Hour)
+	if _, ok := refresher.Current(); ok {
+		t.Fatal("Expected no credentials before the first refresh")
+	}
+	var notified []Credentials
+	refresher.Subscribe(func(creds Credentials) { notified = append(notified, creds) })
+
+	ctx := context.Background()
+	if err := refresher.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient("", "")
+	client.BaseURL = rest.URL
+	refresher.Attach(client)
+
+	server := newTestWsServer(t)
+	ws := newTestWsClient(t, server, func(c *WsClient) {
+		c.APIKey, c.APISecret = "", ""
+		refresher.AttachWs(c)
+	})
+	if msg := <-server.received; msg["args"].([]interface{})[0] != "key-1" {
+		t.Fatalf("Unexpected auth message %v", msg)
+	}
+
+	if _, err := client.NewGetOrderService().OrderID(1).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if key := <-keys; key != "key-1" {
+		t.Errorf("Expected key-1, got %s", key)
+	}
+
+	// An unchanged secret does not notify, a rotated one re-authenticates
+	if err := refresher.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	rotate(Credentials{APIKey: "key-2", APISecret: "secret-2"}, nil)
+	if err := refresher.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 2 || notified[1].APIKey != "key-2" {
+		t.Errorf("Unexpected notifications %+v", notified)
+	}
+	if _, err := client.NewGetOrderService().OrderID(1).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if key := <-keys; key != "key-2" {
+		t.Errorf("Expected key-2, got %s", key)
+	}
+	select {
+	case msg := <-server.received:
+		if msg["op"] != "auth" || msg["args"].([]interface{})[0] != "key-2" {
+			t.Errorf("Unexpected message %v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for re-authentication")
+	}
+	waitFor(t, ws.IsAuthenticated)
+
+	// Failures keep the cached credentials
+	rotate(Credentials{}, errors.New("vault sealed"))
+	if err := refresher.Refresh(ctx); err == nil {
+		t.Error("Expected an error")
+	}
+	rotate(Credentials{APIKey: "key-3"}, nil)
+	if err := refresher.Refresh(ctx); !errors.Is(err, ErrEmptyCredentials) {
+		t.Errorf("Expected ErrEmptyCredentials, got %v", err)
+	}
+	if creds, _ := refresher.Credentials(ctx); creds.APIKey != "key-2" {
+		t.Errorf("Expected cached key-2, got %+v", creds)
+	}
+}
+
+func TestCredentialRefresherRun(t *testing.T) {
+	calls := make(chan struct{}, 10)
+	refresher := NewCredentialRefresher(CredentialProviderFunc(func(ctx context.Context) (Credentials, error) {
+		calls <- struct{}{}
+		return Credentials{}, errors.New("unavailable")
+	}), 10*time.Millisecond)
+	errs := make(chan error, 10)
+	refresher.SetErrorHandler(func(err error) { errs <- err })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- refresher.Run(ctx) }()
+
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a refresh error")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(calls) == 0 {
+		t.Error("Expected the provider to be called")
+	}
+}
@@ -0,0 +1,156 @@
+package versifi
+
+import "github.com/shopspring/decimal"
+
+// Decimal accessors parse the API's string amounts without going through
+// float64. Empty or malformed amounts read as zero.
+
+// toDecimal parses s, returning zero if it is empty or malformed
+func toDecimal(s string) decimal.Decimal {
+	if s == "" {
+		return decimal.Zero
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero
+	}
+	return d
+}
+
+// DecimalPtr returns a pointer to the string form of d, for optional
+// request fields such as PairLeg.MaxPositionLong
+func DecimalPtr(d decimal.Decimal) *string {
+	s := d.String()
+	return &s
+}
+
+// Order builders
+
+// PriceDecimal sets the price
+func (s *CreateBasicOrderService) PriceDecimal(price decimal.Decimal) *CreateBasicOrderService {
+	return s.Price(price.String())
+}
+
+// QuantityDecimal sets the quantity
+func (s *CreateBasicOrderService) QuantityDecimal(quantity decimal.Decimal) *CreateBasicOrderService {
+	return s.Quantity(quantity.String())
+}
+
+// StopPriceDecimal sets the stop price
+func (s *CreateBasicOrderService) StopPriceDecimal(stopPrice decimal.Decimal) *CreateBasicOrderService {
+	return s.StopPrice(stopPrice.String())
+}
+
+// TrailingDeltaDecimal sets the trailing delta
+func (s *CreateBasicOrderService) TrailingDeltaDecimal(trailingDelta decimal.Decimal) *CreateBasicOrderService {
+	return s.TrailingDelta(trailingDelta.String())
+}
+
+// QuantityDecimal sets the quantity
+func (s *CreateAlgoOrderService) QuantityDecimal(quantity decimal.Decimal) *CreateAlgoOrderService {
+	return s.Quantity(quantity.String())
+}
+
+// REST responses
+
+// PriceDecimal returns Price as a decimal
+func (t Trade) PriceDecimal() decimal.Decimal { return toDecimal(t.Price) }
+
+// QuantityDecimal returns Quantity as a decimal
+func (t Trade) QuantityDecimal() decimal.Decimal { return toDecimal(t.Quantity) }
+
+// FeeDecimal returns Fee as a decimal
+func (t Trade) FeeDecimal() decimal.Decimal { return toDecimal(t.Fee) }
+
+// PriceDecimal returns Price as a decimal
+func (o ChildOrder) PriceDecimal() decimal.Decimal { return toDecimal(o.Price) }
+
+// QuantityDecimal returns Quantity as a decimal
+func (o ChildOrder) QuantityDecimal() decimal.Decimal { return toDecimal(o.Quantity) }
+
+// AveragePriceDecimal returns AveragePrice as a decimal
+func (o ChildOrder) AveragePriceDecimal() decimal.Decimal { return toDecimal(o.AveragePrice) }
+
+// FilledQuantityDecimal returns FilledQuantity as a decimal
+func (o ChildOrder) FilledQuantityDecimal() decimal.Decimal { return toDecimal(o.FilledQuantity) }
+
+// PriceDecimal returns Price as a decimal
+func (d BasicOrderDetail) PriceDecimal() decimal.Decimal { return toDecimal(d.Price) }
+
+// QuantityDecimal returns Quantity as a decimal
+func (d BasicOrderDetail) QuantityDecimal() decimal.Decimal { return toDecimal(d.Quantity) }
+
+// StopPriceDecimal returns StopPrice as a decimal
+func (d BasicOrderDetail) StopPriceDecimal() decimal.Decimal { return toDecimal(d.StopPrice) }
+
+// AveragePriceDecimal returns AveragePrice as a decimal
+func (d BasicOrderDetail) AveragePriceDecimal() decimal.Decimal { return toDecimal(d.AveragePrice) }
+
+// FilledQuantityDecimal returns FilledQuantity as a decimal
+func (d BasicOrderDetail) FilledQuantityDecimal() decimal.Decimal { return toDecimal(d.FilledQuantity) }
+
+// QuantityDecimal returns Quantity as a decimal
+func (d AlgoOrderDetail) QuantityDecimal() decimal.Decimal { return toDecimal(d.Quantity) }
+
+// AveragePriceDecimal returns AveragePrice as a decimal
+func (d AlgoOrderDetail) AveragePriceDecimal() decimal.Decimal { return toDecimal(d.AveragePrice) }
+
+// FilledQuantityDecimal returns FilledQuantity as a decimal
+func (d AlgoOrderDetail) FilledQuantityDecimal() decimal.Decimal { return toDecimal(d.FilledQuantity) }
+
+// Websocket messages
+
+// AveragePriceDecimal returns AveragePrice as a decimal
+func (t WsTrade) AveragePriceDecimal() decimal.Decimal { return toDecimal(t.AveragePrice) }
+
+// CummulativeFilledQuantityDecimal returns CummulativeFilledQuantity as a decimal
+func (t WsTrade) CummulativeFilledQuantityDecimal() decimal.Decimal {
+	return toDecimal(t.CummulativeFilledQuantity)
+}
+
+// ExecutedPriceDecimal returns ExecutedPrice as a decimal
+func (t WsTrade) ExecutedPriceDecimal() decimal.Decimal { return toDecimal(t.ExecutedPrice) }
+
+// ExecutedQuantityDecimal returns ExecutedQuantity as a decimal
+func (t WsTrade) ExecutedQuantityDecimal() decimal.Decimal { return toDecimal(t.ExecutedQuantity) }
+
+// PriceDecimal returns Price as a decimal
+func (d WsBasicOrderDetail) PriceDecimal() decimal.Decimal { return toDecimal(d.Price) }
+
+// QuantityDecimal returns Quantity as a decimal
+func (d WsBasicOrderDetail) QuantityDecimal() decimal.Decimal { return toDecimal(d.Quantity) }
+
+// QuantityDecimal returns Quantity as a decimal
+func (d WsAlgoOrderDetail) QuantityDecimal() decimal.Decimal { return toDecimal(d.Quantity) }
+
+// Tracker state
+
+// FilledQuantityDecimal returns FilledQuantity as a decimal
+func (s OrderState) FilledQuantityDecimal() decimal.Decimal { return toDecimal(s.FilledQuantity) }
+
+// AveragePriceDecimal returns AveragePrice as a decimal
+func (s OrderState) AveragePriceDecimal() decimal.Decimal { return toDecimal(s.AveragePrice) }
+
+// FilledQuantityDecimal returns FilledQuantity as a decimal
+func (s LegState) FilledQuantityDecimal() decimal.Decimal { return toDecimal(s.FilledQuantity) }
+
+// AveragePriceDecimal returns AveragePrice as a decimal
+func (s LegState) AveragePriceDecimal() decimal.Decimal { return toDecimal(s.AveragePrice) }
+
+// QuantityDecimal returns Quantity as a decimal
+func (p Position) QuantityDecimal() decimal.Decimal { return toDecimal(p.Quantity) }
+
+// EntryPriceDecimal returns EntryPrice as a decimal
+func (p Position) EntryPriceDecimal() decimal.Decimal { return toDecimal(p.EntryPrice) }
+
+// RealizedPnLDecimal returns RealizedPnL as a decimal
+func (p Position) RealizedPnLDecimal() decimal.Decimal { return toDecimal(p.RealizedPnL) }
+
+// TotalDecimal returns Total as a decimal
+func (b Balance) TotalDecimal() decimal.Decimal { return toDecimal(b.Total) }
+
+// ActualDecimal returns Actual as a decimal
+func (s BalanceState) ActualDecimal() decimal.Decimal { return toDecimal(s.Actual) }
+
+// ExpectedDecimal returns Expected as a decimal
+func (s BalanceState) ExpectedDecimal() decimal.Decimal { return toDecimal(s.Expected) }
@@ -0,0 +1,44 @@
+package versifi
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDecimalAccessors(t *testing.T) {
+	trade := Trade{Price: "0.1", Quantity: "3", Fee: ""}
+	if got := trade.PriceDecimal().Mul(trade.QuantityDecimal()); got.String() != "0.3" {
+		t.Errorf("Expected 0.3, got %s", got)
+	}
+	if !trade.FeeDecimal().IsZero() {
+		t.Errorf("Expected empty fee to read as zero, got %s", trade.FeeDecimal())
+	}
+	if !(WsTrade{ExecutedPrice: "not a number"}).ExecutedPriceDecimal().IsZero() {
+		t.Error("Expected malformed amount to read as zero")
+	}
+}
+
+func TestDecimalBuilders(t *testing.T) {
+	s := NewClient("test-key", "test-secret").NewCreateBasicOrderService().
+		PriceDecimal(decimal.RequireFromString("30000.10")).
+		QuantityDecimal(decimal.New(15, -3))
+
+	if *s.price != "30000.1" || s.quantity != "0.015" {
+		t.Errorf("Unexpected price %s and quantity %s", *s.price, s.quantity)
+	}
+	if p := DecimalPtr(decimal.New(5, 0)); *p != "5" {
+		t.Errorf("Expected 5, got %s", *p)
+	}
+}
+
+func TestTrackersKeepDecimalPrecision(t *testing.T) {
+	tracker := NewPositionTracker()
+	tracker.ApplyTrade(ExchangeBinanceSpot, "BTC/USDT", SideTypeBuy, 1, "0.1", "3")
+	tracker.ApplyTrade(ExchangeBinanceSpot, "BTC/USDT", SideTypeBuy, 2, "0.2", "3")
+
+	// float64 arithmetic gives 0.30000000000000004
+	if p, _ := tracker.Position(ExchangeBinanceSpot, "BTC/USDT"); p.Quantity != "0.3" || p.EntryPrice != "3" {
+		t.Errorf("Unexpected position %+v", p)
+	}
+}
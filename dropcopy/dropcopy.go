@@ -0,0 +1,266 @@
+// Package dropcopy republishes execution reports to an external sink for
+// risk and compliance processes that must see all activity but never place
+// orders. Frames passed to Handle are republished byte for byte; Attach
+// shares the client's decoded reports and encodes them again.
+//
+// Authenticate the WebSocket with a key that has no order-entry permission:
+//
+//	consumer := dropcopy.NewConsumer(dropcopy.NATSSink(nc, "versifi.executions"), 1024)
+//	consumer.SetErrorHandler(func(err error) { log.Printf("dropcopy: %v", err) })
+//	consumer.Attach(wsClient)
+//	defer consumer.Close()
+package dropcopy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+const (
+	defaultPublishAttempts = 3
+	defaultRetryDelay      = 100 * time.Millisecond
+)
+
+// Message is one execution report frame to publish
+type Message struct {
+	// Key is the order ID in decimal, for partitioning by order
+	Key     []byte
+	OrderID int64
+	// Value is the execution_report frame exactly as received
+	Value []byte
+}
+
+// Sink publishes messages. Sinks that implement io.Closer are closed by
+// Consumer.Close.
+type Sink interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// SinkFunc adapts a function to Sink
+type SinkFunc func(ctx context.Context, msg Message) error
+
+// Publish calls f(ctx, msg)
+func (f SinkFunc) Publish(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// ChannelSink sends messages to ch, blocking while it is full
+func ChannelSink(ch chan<- Message) Sink {
+	return SinkFunc(func(ctx context.Context, msg Message) error {
+		select {
+		case ch <- msg:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// NATSPublisher is satisfied by *nats.Conn
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSSink publishes messages to subject
+func NATSSink(conn NATSPublisher, subject string) Sink {
+	return SinkFunc(func(ctx context.Context, msg Message) error {
+		return conn.Publish(subject, msg.Value)
+	})
+}
+
+// KafkaProducer writes one record to a topic chosen by the implementation,
+// typically a thin wrapper around a kafka-go Writer or a sarama SyncProducer
+type KafkaProducer interface {
+	Produce(ctx context.Context, key, value []byte) error
+}
+
+// KafkaSink produces messages keyed by order ID, so the reports of one
+// order stay in one partition and in order
+func KafkaSink(producer KafkaProducer) Sink {
+	return SinkFunc(func(ctx context.Context, msg Message) error {
+		return producer.Produce(ctx, msg.Key, msg.Value)
+	})
+}
+
+// PublishError is passed to the error handler when a message could not be
+// published after every attempt, so that it can be saved elsewhere
+type PublishError struct {
+	Message Message
+	Err     error
+}
+
+func (e *PublishError) Error() string {
+	return fmt.Sprintf("dropcopy: publish order %d: %v", e.Message.OrderID, e.Err)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
+
+// Consumer queues execution reports from the WebSocket and publishes them
+// to a sink in arrival order on its own goroutine, so a slow sink does not
+// stall the read loop until the queue is full. Messages are never dropped
+// while the queue has room; when it is full Handle blocks.
+type Consumer struct {
+	// Attempts is the number of times a message is published before it is
+	// reported as a PublishError, default 3
+	Attempts int
+	// RetryDelay is the pause between attempts, default 100ms
+	RetryDelay time.Duration
+
+	sink   Sink
+	queue  chan Message
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// mu guards closed; Handle holds it for reading while it enqueues
+	mu     sync.RWMutex
+	closed bool
+
+	errMu      sync.RWMutex
+	errHandler versifi.ErrHandler
+}
+
+// NewConsumer creates a consumer publishing to sink through a queue of
+// bufferSize messages
+func NewConsumer(sink Sink, bufferSize int) *Consumer {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Consumer{
+		Attempts:   defaultPublishAttempts,
+		RetryDelay: defaultRetryDelay,
+		sink:       sink,
+		queue:      make(chan Message, bufferSize),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// SetErrorHandler sets the handler for undecodable frames and PublishErrors
+func (c *Consumer) SetErrorHandler(handler versifi.ErrHandler) {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	c.errHandler = handler
+}
+
+// Attach subscribes the consumer to ws execution reports. It registers with
+// OnExecutionReport, so the client's execution_report handler keeps
+// receiving its messages.
+func (c *Consumer) Attach(ws *versifi.WsClient) error {
+	if err := ws.EnsureSubscribed("execution_report"); err != nil {
+		return err
+	}
+	ws.OnExecutionReport(c.HandleExecutionReport)
+	return nil
+}
+
+// HandleExecutionReport queues a decoded execution report, encoded again as
+// an execution_report frame. It is a versifi.ExecutionReportHandler.
+func (c *Consumer) HandleExecutionReport(detail *versifi.WsExecutionReportDetail) {
+	value, err := json.Marshal(versifi.WsExecutionReport{Op: "execution_report", Success: true, Message: *detail})
+	if err != nil {
+		c.error(fmt.Errorf("dropcopy: encode execution report: %w", err))
+		return
+	}
+	c.enqueue(Message{
+		Key:     []byte(strconv.FormatInt(detail.OrderID, 10)),
+		OrderID: detail.OrderID,
+		Value:   value,
+	})
+}
+
+// Handle queues an execution_report frame. It is a versifi.WsHandler.
+func (c *Consumer) Handle(message []byte) {
+	var report struct {
+		Message struct {
+			OrderID int64 `json:"order_id"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(message, &report); err != nil {
+		// Still republish it; consumers downstream may understand it better
+		c.error(fmt.Errorf("dropcopy: decode order id: %w", err))
+	}
+
+	c.enqueue(Message{
+		Key:     []byte(strconv.FormatInt(report.Message.OrderID, 10)),
+		OrderID: report.Message.OrderID,
+		Value:   append([]byte(nil), message...),
+	})
+}
+
+// enqueue queues msg for publishing, or reports it if the consumer is closed
+func (c *Consumer) enqueue(msg Message) {
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		c.error(&PublishError{Message: msg, Err: io.ErrClosedPipe})
+		return
+	}
+	c.queue <- msg
+	c.mu.RUnlock()
+}
+
+// Close publishes the queued messages, stops the consumer and closes the
+// sink if it is an io.Closer. Messages handled after Close are reported as
+// PublishErrors.
+func (c *Consumer) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+
+	<-c.done
+	c.cancel()
+	if closer, ok := c.sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (c *Consumer) run() {
+	defer close(c.done)
+	for msg := range c.queue {
+		c.publish(msg)
+	}
+}
+
+func (c *Consumer) publish(msg Message) {
+	attempts := c.Attempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(c.RetryDelay)
+		}
+		if err = c.sink.Publish(c.ctx, msg); err == nil {
+			return
+		}
+	}
+	c.error(&PublishError{Message: msg, Err: err})
+}
+
+func (c *Consumer) error(err error) {
+	c.errMu.RLock()
+	handler := c.errHandler
+	c.errMu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
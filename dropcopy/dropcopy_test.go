@@ -0,0 +1,129 @@
+package dropcopy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+	"github.com/drinkthere/versifi-go/versifitest"
+)
+
+func TestConsumerChannelSink(t *testing.T) {
+	ch := make(chan Message, 4)
+	c := NewConsumer(ChannelSink(ch), 4)
+
+	frame := []byte(`{"op":"execution_report","success":true,"message":{"order_id":42,"status":"NEW","future_field":1}}`)
+	c.Handle(frame)
+	frame[0] = 'X' // The consumer keeps its own copy
+	if err := c.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	msg := <-ch
+	if msg.OrderID != 42 || string(msg.Key) != "42" || msg.Value[0] != '{' {
+		t.Errorf("Unexpected message %+v", msg)
+	}
+}
+
+func TestConsumerAttach(t *testing.T) {
+	server := versifitest.NewWsServer("test-key", "test-secret")
+	defer server.Close()
+	ws := versifi.NewWsClient("test-key", "test-secret")
+	ws.BaseURL = server.URL
+	ws.Logger = log.New(io.Discard, "", 0)
+	if err := ws.Connect(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer ws.Disconnect()
+
+	// A handler set before Attach keeps its messages
+	reports := make(chan struct{}, 1)
+	if err := ws.SubscribeExecutionReport(func([]byte) { reports <- struct{}{} }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ch := make(chan Message, 1)
+	c := NewConsumer(ChannelSink(ch), 4)
+	defer c.Close()
+	if err := c.Attach(ws); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := server.WaitForSubscription("execution_report", 5*time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := server.SendExecutionReport(versifi.WsExecutionReportDetail{OrderID: 42, Status: versifi.OrderStatusNew}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case msg := <-ch:
+		var report versifi.WsExecutionReport
+		if err := json.Unmarshal(msg.Value, &report); err != nil || msg.OrderID != 42 || string(msg.Key) != "42" ||
+			report.Op != "execution_report" || report.Message.Status != versifi.OrderStatusNew {
+			t.Errorf("Unexpected message %+v (%v)", msg, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the republished report")
+	}
+	select {
+	case <-reports:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the client's handler to keep receiving reports")
+	}
+}
+
+type fakeProducer struct {
+	mu    sync.Mutex
+	fails int
+	keys  []string
+}
+
+func (p *fakeProducer) Produce(ctx context.Context, key, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fails > 0 {
+		p.fails--
+		return errors.New("broker unavailable")
+	}
+	p.keys = append(p.keys, string(key))
+	return nil
+}
+
+func TestConsumerRetries(t *testing.T) {
+	producer := &fakeProducer{fails: 4}
+	c := NewConsumer(KafkaSink(producer), 8)
+	c.RetryDelay = time.Millisecond
+
+	var mu sync.Mutex
+	var failed []int64
+	c.SetErrorHandler(func(err error) {
+		var pubErr *PublishError
+		if errors.As(err, &pubErr) {
+			mu.Lock()
+			failed = append(failed, pubErr.Message.OrderID)
+			mu.Unlock()
+		}
+	})
+
+	c.Handle([]byte(`{"message":{"order_id":1}}`))
+	c.Handle([]byte(`{"message":{"order_id":2}}`))
+	c.Close()
+
+	// Order 1 fails all 3 attempts, order 2 succeeds on its second
+	if len(failed) != 1 || failed[0] != 1 {
+		t.Errorf("Expected order 1 to fail, got %v", failed)
+	}
+	if len(producer.keys) != 1 || producer.keys[0] != "2" {
+		t.Errorf("Expected order 2 to be produced, got %v", producer.keys)
+	}
+
+	c.Handle([]byte(`{"message":{"order_id":3}}`))
+	if len(failed) != 2 || failed[1] != 3 {
+		t.Errorf("Expected message after Close to fail, got %v", failed)
+	}
+}
@@ -0,0 +1,240 @@
+package versifi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidEnum is matched by the errors of the Parse* functions
+var ErrInvalidEnum = errors.New("invalid enum value")
+
+// The values of each API enum, in declaration order
+var (
+	sideTypes        = []SideType{SideTypeBuy, SideTypeSell}
+	exchangeTypes    = []ExchangeType{ExchangeBinanceSpot, ExchangeBinanceFutures, ExchangeOKXSpot, ExchangeOKXFutures}
+	algoOrderTypes   = []AlgoOrderType{AlgoOrderTypeTWAP, AlgoOrderTypeVWAP, AlgoOrderTypeIS}
+	pairOrderTypes   = []PairOrderType{PairOrderTypeBasis}
+	pairStyleTypes   = []PairStyleType{PairStyleSync, PairStyleAsync, PairStyleTWAP}
+	timeInForceTypes = []TimeInForceType{
+		TimeInForceFOK, TimeInForceGTC, TimeInForceGTD, TimeInForceIOC, TimeInForceGTX, TimeInForcePostOn,
+	}
+	basicOrderTypes = []BasicOrderType{
+		BasicOrderTypeMarket, BasicOrderTypeLimit, BasicOrderTypeStop, BasicOrderTypeStopLoss,
+		BasicOrderTypeStopLossLimit, BasicOrderTypeTakeProfit, BasicOrderTypeTakeProfitLimit, BasicOrderTypeLimitMaker,
+	}
+	orderStatusTypes = []OrderStatusType{
+		OrderStatusNew, OrderStatusPartiallyFilled, OrderStatusFilled,
+		OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired,
+	}
+)
+
+// unmarshalEnum decodes the JSON value data as one of values, ignoring
+// case. Any other value, such as a status added to the API after this SDK
+// or even a number, is kept verbatim rather than failing the message, and
+// IsKnown reports false for it:
+//
+//	if !order.Status.IsKnown() {
+//		log.Printf("order %d has unknown status %s", order.OrderID, order.Status)
+//	}
+func unmarshalEnum[T ~string](data []byte, values []T, v *T) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || bytes.IndexByte(data, '\\') >= 0 {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			// Not a string: keep the raw JSON, such as a number
+			s = string(data)
+		}
+		*v = T(s)
+		return nil
+	}
+	raw := data[1 : len(data)-1]
+	for _, value := range values {
+		if equalFold(string(value), raw) {
+			*v = value
+			return nil
+		}
+	}
+	*v = T(raw)
+	return nil
+}
+
+// equalFold reports whether s and b are equal under ASCII case folding,
+// without converting b to a string
+func equalFold(s string, b []byte) bool {
+	if len(s) != len(b) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c, d := s[i], b[i]
+		if 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if 'a' <= d && d <= 'z' {
+			d -= 'a' - 'A'
+		}
+		if c != d {
+			return false
+		}
+	}
+	return true
+}
+
+// parseEnum returns the value of values matching s, ignoring case and
+// surrounding space
+func parseEnum[T ~string](typeName string, values []T, s string) (T, error) {
+	v := T(strings.ToUpper(strings.TrimSpace(s)))
+	if slices.Contains(values, v) {
+		return v, nil
+	}
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = string(value)
+	}
+	return "", fmt.Errorf("%w: %q is not a %s (one of %s)", ErrInvalidEnum, s, typeName, strings.Join(names, ", "))
+}
+
+// ParseSideType parses s, such as "buy", as a SideType
+func ParseSideType(s string) (SideType, error) {
+	return parseEnum("SideType", sideTypes, s)
+}
+
+// IsValid reports whether t is one of the SideType constants
+func (t SideType) IsValid() bool { return slices.Contains(sideTypes, t) }
+
+func (t SideType) String() string { return string(t) }
+
+// IsKnown reports whether t is a SideType known to this SDK
+func (t SideType) IsKnown() bool { return t.IsValid() }
+
+// UnmarshalJSON keeps unknown values verbatim, see IsKnown
+func (t *SideType) UnmarshalJSON(data []byte) error { return unmarshalEnum(data, sideTypes, t) }
+
+// ParseExchangeType parses s, such as "binance_spot", as an ExchangeType
+func ParseExchangeType(s string) (ExchangeType, error) {
+	return parseEnum("ExchangeType", exchangeTypes, s)
+}
+
+// IsValid reports whether t is one of the ExchangeType constants
+func (t ExchangeType) IsValid() bool { return slices.Contains(exchangeTypes, t) }
+
+func (t ExchangeType) String() string { return string(t) }
+
+// IsKnown reports whether t is a ExchangeType known to this SDK
+func (t ExchangeType) IsKnown() bool { return t.IsValid() }
+
+// UnmarshalJSON keeps unknown values verbatim, see IsKnown
+func (t *ExchangeType) UnmarshalJSON(data []byte) error { return unmarshalEnum(data, exchangeTypes, t) }
+
+// ParseAlgoOrderType parses s, such as "twap", as an AlgoOrderType
+func ParseAlgoOrderType(s string) (AlgoOrderType, error) {
+	return parseEnum("AlgoOrderType", algoOrderTypes, s)
+}
+
+// IsValid reports whether t is one of the AlgoOrderType constants
+func (t AlgoOrderType) IsValid() bool { return slices.Contains(algoOrderTypes, t) }
+
+func (t AlgoOrderType) String() string { return string(t) }
+
+// IsKnown reports whether t is a AlgoOrderType known to this SDK
+func (t AlgoOrderType) IsKnown() bool { return t.IsValid() }
+
+// UnmarshalJSON keeps unknown values verbatim, see IsKnown
+func (t *AlgoOrderType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, algoOrderTypes, t)
+}
+
+// ParseBasicOrderType parses s, such as "limit", as a BasicOrderType
+func ParseBasicOrderType(s string) (BasicOrderType, error) {
+	return parseEnum("BasicOrderType", basicOrderTypes, s)
+}
+
+// IsValid reports whether t is one of the BasicOrderType constants
+func (t BasicOrderType) IsValid() bool { return slices.Contains(basicOrderTypes, t) }
+
+func (t BasicOrderType) String() string { return string(t) }
+
+// IsKnown reports whether t is a BasicOrderType known to this SDK
+func (t BasicOrderType) IsKnown() bool { return t.IsValid() }
+
+// UnmarshalJSON keeps unknown values verbatim, see IsKnown
+func (t *BasicOrderType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, basicOrderTypes, t)
+}
+
+// ParsePairOrderType parses s, such as "basis", as a PairOrderType
+func ParsePairOrderType(s string) (PairOrderType, error) {
+	return parseEnum("PairOrderType", pairOrderTypes, s)
+}
+
+// IsValid reports whether t is one of the PairOrderType constants
+func (t PairOrderType) IsValid() bool { return slices.Contains(pairOrderTypes, t) }
+
+func (t PairOrderType) String() string { return string(t) }
+
+// IsKnown reports whether t is a PairOrderType known to this SDK
+func (t PairOrderType) IsKnown() bool { return t.IsValid() }
+
+// UnmarshalJSON keeps unknown values verbatim, see IsKnown
+func (t *PairOrderType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, pairOrderTypes, t)
+}
+
+// ParseTimeInForceType parses s, such as "gtc", as a TimeInForceType
+func ParseTimeInForceType(s string) (TimeInForceType, error) {
+	return parseEnum("TimeInForceType", timeInForceTypes, s)
+}
+
+// IsValid reports whether t is one of the TimeInForceType constants
+func (t TimeInForceType) IsValid() bool { return slices.Contains(timeInForceTypes, t) }
+
+func (t TimeInForceType) String() string { return string(t) }
+
+// IsKnown reports whether t is a TimeInForceType known to this SDK
+func (t TimeInForceType) IsKnown() bool { return t.IsValid() }
+
+// UnmarshalJSON keeps unknown values verbatim, see IsKnown
+func (t *TimeInForceType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, timeInForceTypes, t)
+}
+
+// ParseOrderStatusType parses s, such as "filled", as an OrderStatusType
+func ParseOrderStatusType(s string) (OrderStatusType, error) {
+	return parseEnum("OrderStatusType", orderStatusTypes, s)
+}
+
+// IsValid reports whether s is one of the OrderStatusType constants
+func (s OrderStatusType) IsValid() bool { return slices.Contains(orderStatusTypes, s) }
+
+func (s OrderStatusType) String() string { return string(s) }
+
+// IsKnown reports whether s is a OrderStatusType known to this SDK
+func (s OrderStatusType) IsKnown() bool { return s.IsValid() }
+
+// UnmarshalJSON keeps unknown values verbatim, see IsKnown
+func (s *OrderStatusType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, orderStatusTypes, s)
+}
+
+// ParsePairStyleType parses s, such as "sync", as a PairStyleType
+func ParsePairStyleType(s string) (PairStyleType, error) {
+	return parseEnum("PairStyleType", pairStyleTypes, s)
+}
+
+// IsValid reports whether t is one of the PairStyleType constants
+func (t PairStyleType) IsValid() bool { return slices.Contains(pairStyleTypes, t) }
+
+func (t PairStyleType) String() string { return string(t) }
+
+// IsKnown reports whether t is a PairStyleType known to this SDK
+func (t PairStyleType) IsKnown() bool { return t.IsValid() }
+
+// UnmarshalJSON keeps unknown values verbatim, see IsKnown
+func (t *PairStyleType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, pairStyleTypes, t)
+}
@@ -0,0 +1,105 @@
+package versifi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseEnums(t *testing.T) {
+	if got, err := ParseExchangeType(" binance_spot "); err != nil || got != ExchangeBinanceSpot {
+		t.Errorf("Expected BINANCE_SPOT, got %q, %v", got, err)
+	}
+	if got, err := ParseSideType("Sell"); err != nil || got != SideTypeSell {
+		t.Errorf("Expected SELL, got %q, %v", got, err)
+	}
+	if got, err := ParseTimeInForceType("post_on"); err != nil || got != TimeInForcePostOn {
+		t.Errorf("Expected POST_ON, got %q, %v", got, err)
+	}
+
+	_, err := ParseExchangeType("KRAKEN")
+	if !errors.Is(err, ErrInvalidEnum) || !strings.Contains(err.Error(), "OKX_FUTURES") {
+		t.Errorf("Expected ErrInvalidEnum listing the exchanges, got %v", err)
+	}
+	if _, err := ParseOrderStatusType(""); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Expected ErrInvalidEnum, got %v", err)
+	}
+
+	if ExchangeType("KRAKEN").IsValid() || SideType("buy").IsValid() || !OrderStatusFilled.IsValid() {
+		t.Error("Unexpected IsValid result")
+	}
+	if s := fmt.Sprintf("%v %v", PairStyleAsync, BasicOrderTypeStopLossLimit); s != "ASYNC STOP_LOSS_LIMIT" {
+		t.Errorf("Unexpected String result %q", s)
+	}
+}
+
+// TestEnumValues checks that every enum constant is valid, so a constant
+// added to common.go without its values list fails
+func TestEnumValues(t *testing.T) {
+	valid := func(typeName, value string) (bool, error) {
+		parsers := map[string]func(string) (fmt.Stringer, error){
+			"SideType":        func(s string) (fmt.Stringer, error) { return ParseSideType(s) },
+			"ExchangeType":    func(s string) (fmt.Stringer, error) { return ParseExchangeType(s) },
+			"AlgoOrderType":   func(s string) (fmt.Stringer, error) { return ParseAlgoOrderType(s) },
+			"BasicOrderType":  func(s string) (fmt.Stringer, error) { return ParseBasicOrderType(s) },
+			"PairOrderType":   func(s string) (fmt.Stringer, error) { return ParsePairOrderType(s) },
+			"TimeInForceType": func(s string) (fmt.Stringer, error) { return ParseTimeInForceType(s) },
+			"OrderStatusType": func(s string) (fmt.Stringer, error) { return ParseOrderStatusType(s) },
+			"PairStyleType":   func(s string) (fmt.Stringer, error) { return ParsePairStyleType(s) },
+		}
+		parse, ok := parsers[typeName]
+		if !ok {
+			return false, fmt.Errorf("no parser for %s", typeName)
+		}
+		v, err := parse(value)
+		return err == nil && v.String() == value, nil
+	}
+
+	for name, c := range enumConsts(t, "common.go") {
+		typeName, value, _ := strings.Cut(strings.TrimSuffix(c, ")"), "(")
+		ok, err := valid(typeName, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("%s is not a valid %s", name, typeName)
+		}
+	}
+}
+
+func TestUnmarshalEnums(t *testing.T) {
+	var order struct {
+		Status   OrderStatusType `json:"status"`
+		Side     SideType        `json:"side"`
+		Exchange ExchangeType    `json:"exchange"`
+		TIF      TimeInForceType `json:"tif"`
+		Style    PairStyleType   `json:"style"`
+	}
+	order.Style = PairStyleSync
+	err := json.Unmarshal([]byte(`{"status":"PENDING_CANCEL","side":"buy","exchange":"OKX_SPOT","tif":3,"style":null}`), &order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != "PENDING_CANCEL" || order.Status.IsKnown() || order.Status.IsFinal() {
+		t.Errorf("Expected the unknown status to be kept, got %q", order.Status)
+	}
+	if order.Side != SideTypeBuy || !order.Side.IsKnown() {
+		t.Errorf("Expected BUY, got %q", order.Side)
+	}
+	if order.Exchange != ExchangeOKXSpot {
+		t.Errorf("Expected OKX_SPOT, got %q", order.Exchange)
+	}
+	if order.TIF != "3" || order.TIF.IsKnown() {
+		t.Errorf("Expected the raw number to be kept, got %q", order.TIF)
+	}
+	if order.Style != PairStyleSync {
+		t.Errorf("Expected null to leave the style unchanged, got %q", order.Style)
+	}
+
+	data, _ := json.Marshal(order)
+	if !strings.Contains(string(data), `"status":"PENDING_CANCEL"`) {
+		t.Errorf("Expected the unknown status to round-trip, got %s", data)
+	}
+}
@@ -0,0 +1,81 @@
+package versifi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ResponseEnvelopeType selects how the client reads REST responses that
+// may be wrapped in a {"code", "message", "data"} envelope
+type ResponseEnvelopeType int
+
+const (
+	// EnvelopeAuto unwraps responses that are exactly an envelope, an
+	// object holding "code" and "data" and at most "message" besides, and
+	// reads any other response as bare
+	EnvelopeAuto ResponseEnvelopeType = iota
+	// EnvelopeNone reads every response as bare
+	EnvelopeNone
+	// EnvelopeWrapped requires every successful response to be an envelope
+	EnvelopeWrapped
+)
+
+// errNotEnveloped is the cause of the ErrDecode error for a bare response
+// under EnvelopeWrapped
+var errNotEnveloped = errors.New("response is not an envelope")
+
+// isEnvelope reports whether fields are exactly those of an envelope
+func isEnvelope(fields map[string]json.RawMessage) bool {
+	_, code := fields["code"]
+	_, data := fields["data"]
+	n := 2
+	if _, message := fields["message"]; message {
+		n++
+	}
+	return code && data && len(fields) == n
+}
+
+// unwrapEnvelope replaces an enveloped successful response in data with the
+// envelope's data, leaving data empty when that is null. Codes 0 and 200
+// mean success, an envelope with any other code is returned as *APIError.
+func (c *Client) unwrapEnvelope(r *request, httpStatus int, data *bytes.Buffer) error {
+	if c.Envelope == EnvelopeNone {
+		return nil
+	}
+	// Only an object naming a data field can be an envelope, so bare
+	// responses are mostly told apart without decoding them twice
+	body := bytes.TrimSpace(data.Bytes())
+	if c.Envelope == EnvelopeAuto && (len(body) == 0 || body[0] != '{' || !bytes.Contains(body, []byte(`"data"`))) {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	var apiErr APIError
+	err := json.Unmarshal(body, &fields)
+	if err == nil && !isEnvelope(fields) {
+		err = errNotEnveloped
+	}
+	if err == nil {
+		err = json.Unmarshal(fields["code"], &apiErr.Code)
+	}
+	if message, ok := fields["message"]; ok && err == nil {
+		err = json.Unmarshal(message, &apiErr.Message)
+	}
+	if err != nil {
+		if c.Envelope == EnvelopeAuto {
+			return nil
+		}
+		return r.error(ErrDecode, err)
+	}
+
+	if apiErr.Code != 0 && apiErr.Code != 200 {
+		apiErr.HTTPStatus = httpStatus
+		return &apiErr
+	}
+	data.Reset()
+	if payload := fields["data"]; !bytes.Equal(payload, []byte("null")) {
+		data.Write(payload)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseEnvelopes(t *testing.T) {
+	var response string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	// The same service reads both shapes
+	for _, body := range []string{
+		`{"order_id":7,"status":"NEW"}`,
+		`{"code":0,"message":"success","data":{"order_id":7,"status":"NEW"}}`,
+		` {"code":200,"data":{"order_id":7,"status":"NEW"}}`,
+	} {
+		response = body
+		order, err := client.NewGetOrderService().OrderID(7).Do(ctx)
+		if err != nil || order.OrderID != 7 || order.Status != OrderStatusNew {
+			t.Errorf("%s: unexpected order %+v, %v", body, order, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		mode     ResponseEnvelopeType
+		response string
+		want     string
+		err      error
+	}{
+		{"bare array", EnvelopeAuto, `[1,2]`, `[1,2]`, nil},
+		{"bare object with data", EnvelopeAuto, `{"code":1,"data":2,"extra":3}`, `{"code":1,"data":2,"extra":3}`, nil},
+		{"bare object with a string code", EnvelopeAuto, `{"code":"x","data":2}`, `{"code":"x","data":2}`, nil},
+		{"null data", EnvelopeAuto, `{"code":0,"message":"ok","data":null}`, "", nil},
+		{"error code", EnvelopeAuto, `{"code":40001,"message":"invalid symbol","data":null}`, "", ErrAPI},
+		{"disabled", EnvelopeNone, `{"code":0,"data":[3]}`, `{"code":0,"data":[3]}`, nil},
+		{"required", EnvelopeWrapped, `{"code":0,"data":[3]}`, `[3]`, nil},
+		{"required but bare", EnvelopeWrapped, `[3]`, "", ErrDecode},
+	}
+	for _, tt := range tests {
+		client.Envelope = tt.mode
+		response = tt.response
+		res, err := Do[json.RawMessage](ctx, client, CustomRequest{Method: http.MethodGet, Endpoint: "/v2/test"})
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if got := string(*res); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+
+	client.Envelope = EnvelopeAuto
+	response = `{"code":40001,"message":"invalid symbol","data":null}`
+	_, err := client.NewGetOrderService().OrderID(7).Do(ctx)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 40001 || apiErr.Message != "invalid symbol" || apiErr.HTTPStatus != http.StatusOK {
+		t.Errorf("Expected the envelope's error, got %v", err)
+	}
+}
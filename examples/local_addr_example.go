@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -104,8 +103,8 @@ func websocketExample(apiKey, apiSecret, localIP string) {
 
 	// Subscribe to execution reports
 	err = wsClient.SubscribeExecutionReport(func(message []byte) {
-		var execReport versifi.WsExecutionReport
-		if err := json.Unmarshal(message, &execReport); err != nil {
+		execReport, err := versifi.ParseExecutionReport(message)
+		if err != nil {
 			log.Printf("   Error parsing: %v\n", err)
 			return
 		}
@@ -149,7 +149,7 @@ func getOrder(client *versifi.Client, orderID int64) {
 		return
 	}
 
-	fmt.Printf("Order Details - Type: %s, Status: %s, Timestamp: %d\n",
+	fmt.Printf("Order Details - Type: %s, Status: %s, Timestamp: %s\n",
 		response.OrderType, response.Status, response.Timestamp)
 
 	// Check order type and print specific details
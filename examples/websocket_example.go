@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -37,8 +36,8 @@ func runWebSocketExample() {
 	// Subscribe to execution_report topic
 	err = wsClient.SubscribeExecutionReport(func(message []byte) {
 		// Parse execution report
-		var execReport versifi.WsExecutionReport
-		if err := json.Unmarshal(message, &execReport); err != nil {
+		execReport, err := versifi.ParseExecutionReport(message)
+		if err != nil {
 			log.Printf("Error parsing execution report: %v", err)
 			fmt.Printf("[Raw Message] %s\n", string(message))
 			return
@@ -53,13 +52,12 @@ func runWebSocketExample() {
 		fmt.Printf("  Request Type: %s\n", execReport.Message.RequestOrderType)
 
 		// Handle different order types
-		switch execReport.Message.RequestOrderType {
-		case "basic":
-			handleBasicOrder(execReport.Message.Order)
-		case "algo":
-			handleAlgoOrder(execReport.Message.Order)
-		case "pair":
-			handlePairOrder(execReport.Message.Order)
+		if basicOrder, ok := execReport.Message.BasicOrder(); ok {
+			handleBasicOrder(basicOrder)
+		} else if algoOrder, ok := execReport.Message.AlgoOrder(); ok {
+			handleAlgoOrder(algoOrder)
+		} else if pairOrder, ok := execReport.Message.PairOrder(); ok {
+			handlePairOrder(pairOrder)
 		}
 	})
 
@@ -105,14 +103,7 @@ func runWebSocketExample() {
 	fmt.Println("\n\nShutting down gracefully...")
 }
 
-func handleBasicOrder(order interface{}) {
-	orderBytes, _ := json.Marshal(order)
-	var basicOrder versifi.WsBasicOrderDetail
-	if err := json.Unmarshal(orderBytes, &basicOrder); err != nil {
-		log.Printf("Error parsing basic order: %v", err)
-		return
-	}
-
+func handleBasicOrder(basicOrder *versifi.WsBasicOrderDetail) {
 	fmt.Printf("  📝 Basic Order Details:\n")
 	fmt.Printf("    Symbol: %s\n", basicOrder.Symbol)
 	fmt.Printf("    Side: %s\n", basicOrder.Side)
@@ -134,14 +125,7 @@ func handleBasicOrder(order interface{}) {
 	}
 }
 
-func handleAlgoOrder(order interface{}) {
-	orderBytes, _ := json.Marshal(order)
-	var algoOrder versifi.WsAlgoOrderDetail
-	if err := json.Unmarshal(orderBytes, &algoOrder); err != nil {
-		log.Printf("Error parsing algo order: %v", err)
-		return
-	}
-
+func handleAlgoOrder(algoOrder *versifi.WsAlgoOrderDetail) {
 	fmt.Printf("  🤖 Algo Order Details:\n")
 	fmt.Printf("    Algorithm: %s\n", algoOrder.OrderType)
 	fmt.Printf("    Symbol: %s\n", algoOrder.Symbol)
@@ -163,14 +147,7 @@ func handleAlgoOrder(order interface{}) {
 	}
 }
 
-func handlePairOrder(order interface{}) {
-	orderBytes, _ := json.Marshal(order)
-	var pairOrder versifi.WsPairOrderDetail
-	if err := json.Unmarshal(orderBytes, &pairOrder); err != nil {
-		log.Printf("Error parsing pair order: %v", err)
-		return
-	}
-
+func handlePairOrder(pairOrder *versifi.WsPairOrderDetail) {
 	fmt.Printf("  🔄 Pair Order Details:\n")
 
 	if pairOrder.LeadLeg != nil {
@@ -0,0 +1,206 @@
+package versifi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrUnknownExchange is returned for orders on an exchange missing
+	// from the client's ExchangeRegistry
+	ErrUnknownExchange = errors.New("unknown exchange")
+	// ErrUnsupportedOrder is returned for orders using an order type or
+	// time in force their exchange does not support
+	ErrUnsupportedOrder = errors.New("order not supported by exchange")
+)
+
+// MarketType is the kind of instruments traded on an exchange
+type MarketType string
+
+const (
+	MarketSpot    MarketType = "SPOT"
+	MarketFutures MarketType = "FUTURES"
+)
+
+// ExchangeCapabilities describes what an exchange supports
+type ExchangeCapabilities struct {
+	Exchange ExchangeType `json:"exchange"`
+	Market   MarketType   `json:"market"`
+	// OrderTypes are the basic order types accepted, nil meaning any
+	OrderTypes []BasicOrderType `json:"order_types,omitempty"`
+	// TimeInForce are the time in force values accepted, nil meaning any
+	TimeInForce []TimeInForceType `json:"time_in_force,omitempty"`
+	// HedgeMode reports whether long and short positions on a symbol can
+	// be held at the same time
+	HedgeMode bool `json:"hedge_mode"`
+}
+
+// SupportsOrderType reports whether the exchange accepts orderType
+func (c ExchangeCapabilities) SupportsOrderType(orderType BasicOrderType) bool {
+	return c.OrderTypes == nil || slices.Contains(c.OrderTypes, orderType)
+}
+
+// SupportsTimeInForce reports whether the exchange accepts tif
+func (c ExchangeCapabilities) SupportsTimeInForce(tif TimeInForceType) bool {
+	return c.TimeInForce == nil || slices.Contains(c.TimeInForce, tif)
+}
+
+// ExchangeOrder is the part of an order checked against the capabilities
+// of its exchange. Empty fields are not checked.
+type ExchangeOrder struct {
+	Exchange    ExchangeType
+	OrderType   BasicOrderType
+	TimeInForce TimeInForceType
+}
+
+// ExchangeRegistry holds the capabilities of the exchanges orders may be
+// sent to. Install it with Client.SetExchangeRegistry and register venues
+// added to the API after this SDK:
+//
+//	exchanges := versifi.NewExchangeRegistry()
+//	exchanges.Register(versifi.ExchangeCapabilities{
+//		Exchange:   "BYBIT_SPOT",
+//		Market:     versifi.MarketSpot,
+//		OrderTypes: []versifi.BasicOrderType{versifi.BasicOrderTypeMarket, versifi.BasicOrderTypeLimit},
+//	})
+//	client.SetExchangeRegistry(exchanges)
+//
+// It is safe for concurrent use.
+type ExchangeRegistry struct {
+	mu        sync.RWMutex
+	exchanges map[ExchangeType]ExchangeCapabilities
+}
+
+// NewExchangeRegistry creates a registry of the exchanges known to this SDK
+func NewExchangeRegistry() *ExchangeRegistry {
+	r := &ExchangeRegistry{exchanges: make(map[ExchangeType]ExchangeCapabilities)}
+	for _, caps := range builtinExchanges() {
+		r.exchanges[caps.Exchange] = caps
+	}
+	return r
+}
+
+// builtinExchanges returns the capabilities of the ExchangeType constants
+func builtinExchanges() []ExchangeCapabilities {
+	return []ExchangeCapabilities{
+		{
+			Exchange: ExchangeBinanceSpot,
+			Market:   MarketSpot,
+			OrderTypes: []BasicOrderType{
+				BasicOrderTypeMarket, BasicOrderTypeLimit, BasicOrderTypeStopLoss, BasicOrderTypeStopLossLimit,
+				BasicOrderTypeTakeProfit, BasicOrderTypeTakeProfitLimit, BasicOrderTypeLimitMaker,
+			},
+			TimeInForce: []TimeInForceType{TimeInForceGTC, TimeInForceIOC, TimeInForceFOK},
+		},
+		{
+			Exchange: ExchangeBinanceFutures,
+			Market:   MarketFutures,
+			OrderTypes: []BasicOrderType{
+				BasicOrderTypeMarket, BasicOrderTypeLimit, BasicOrderTypeStop,
+				BasicOrderTypeTakeProfit, BasicOrderTypeTakeProfitLimit,
+			},
+			TimeInForce: []TimeInForceType{TimeInForceGTC, TimeInForceIOC, TimeInForceFOK, TimeInForceGTX, TimeInForceGTD},
+			HedgeMode:   true,
+		},
+		{
+			Exchange:    ExchangeOKXSpot,
+			Market:      MarketSpot,
+			OrderTypes:  []BasicOrderType{BasicOrderTypeMarket, BasicOrderTypeLimit, BasicOrderTypeLimitMaker},
+			TimeInForce: []TimeInForceType{TimeInForceGTC, TimeInForceIOC, TimeInForceFOK, TimeInForcePostOn},
+		},
+		{
+			Exchange:    ExchangeOKXFutures,
+			Market:      MarketFutures,
+			OrderTypes:  []BasicOrderType{BasicOrderTypeMarket, BasicOrderTypeLimit, BasicOrderTypeLimitMaker},
+			TimeInForce: []TimeInForceType{TimeInForceGTC, TimeInForceIOC, TimeInForceFOK, TimeInForcePostOn},
+			HedgeMode:   true,
+		},
+	}
+}
+
+// Register adds an exchange, or replaces the capabilities of a registered one
+func (r *ExchangeRegistry) Register(caps ExchangeCapabilities) error {
+	if caps.Exchange == "" {
+		return errors.New("exchange is required")
+	}
+	if caps.Market != MarketSpot && caps.Market != MarketFutures {
+		return fmt.Errorf("exchange %s has invalid market %q", caps.Exchange, caps.Market)
+	}
+	caps.OrderTypes = slices.Clone(caps.OrderTypes)
+	caps.TimeInForce = slices.Clone(caps.TimeInForce)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges[caps.Exchange] = caps
+	return nil
+}
+
+// Unregister removes an exchange, rejecting further orders on it
+func (r *ExchangeRegistry) Unregister(exchange ExchangeType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.exchanges, exchange)
+}
+
+// Lookup returns the capabilities of exchange
+func (r *ExchangeRegistry) Lookup(exchange ExchangeType) (ExchangeCapabilities, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	caps, ok := r.exchanges[exchange]
+	return caps, ok
+}
+
+// Exchanges returns the registered exchanges in sorted order
+func (r *ExchangeRegistry) Exchanges() []ExchangeType {
+	r.mu.RLock()
+	exchanges := make([]ExchangeType, 0, len(r.exchanges))
+	for exchange := range r.exchanges {
+		exchanges = append(exchanges, exchange)
+	}
+	r.mu.RUnlock()
+	slices.Sort(exchanges)
+	return exchanges
+}
+
+// Check returns an error matching ErrUnknownExchange or ErrUnsupportedOrder
+// if order's exchange is not registered or does not support it
+func (r *ExchangeRegistry) Check(order ExchangeOrder) error {
+	caps, ok := r.Lookup(order.Exchange)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownExchange, order.Exchange)
+	}
+	if order.OrderType != "" && !caps.SupportsOrderType(order.OrderType) {
+		return fmt.Errorf("%w: %s does not support %s orders", ErrUnsupportedOrder, order.Exchange, order.OrderType)
+	}
+	if order.TimeInForce != "" && !caps.SupportsTimeInForce(order.TimeInForce) {
+		return fmt.Errorf("%w: %s does not support time in force %s", ErrUnsupportedOrder, order.Exchange, order.TimeInForce)
+	}
+	return nil
+}
+
+// SetExchangeRegistry makes every order created by c pass registry's
+// capability checks before it is sent. A nil registry disables the checks.
+func (c *Client) SetExchangeRegistry(registry *ExchangeRegistry) {
+	c.exchanges.Store(registry)
+}
+
+// ExchangeRegistry returns the registry set with SetExchangeRegistry, if any
+func (c *Client) ExchangeRegistry() *ExchangeRegistry {
+	return c.exchanges.Load()
+}
+
+// checkExchanges checks the orders of r against the client's registry
+func (c *Client) checkExchanges(r *request) error {
+	registry := c.exchanges.Load()
+	if registry == nil {
+		return nil
+	}
+	for _, order := range r.exchangeOrders {
+		if err := registry.Check(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
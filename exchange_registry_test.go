@@ -0,0 +1,87 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExchangeRegistry(t *testing.T) {
+	registry := NewExchangeRegistry()
+	if got := registry.Exchanges(); len(got) != len(exchangeTypes) {
+		t.Fatalf("Expected the built-in exchanges, got %v", got)
+	}
+	if caps, ok := registry.Lookup(ExchangeBinanceFutures); !ok || caps.Market != MarketFutures || !caps.HedgeMode {
+		t.Errorf("Unexpected capabilities %+v", caps)
+	}
+
+	const bybit ExchangeType = "BYBIT_SPOT"
+	if err := registry.Register(ExchangeCapabilities{Exchange: bybit}); err == nil {
+		t.Error("Expected an error for a missing market")
+	}
+	if err := registry.Register(ExchangeCapabilities{Exchange: bybit, Market: MarketSpot, OrderTypes: []BasicOrderType{BasicOrderTypeLimit}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		order ExchangeOrder
+		err   error
+	}{
+		{ExchangeOrder{Exchange: ExchangeBinanceSpot, OrderType: BasicOrderTypeLimit, TimeInForce: TimeInForceGTC}, nil},
+		{ExchangeOrder{Exchange: ExchangeBinanceSpot, OrderType: BasicOrderTypeStop}, ErrUnsupportedOrder},
+		{ExchangeOrder{Exchange: ExchangeBinanceSpot, TimeInForce: TimeInForceGTX}, ErrUnsupportedOrder},
+		{ExchangeOrder{Exchange: ExchangeBinanceFutures, TimeInForce: TimeInForceGTX}, nil},
+		{ExchangeOrder{Exchange: bybit, OrderType: BasicOrderTypeLimit, TimeInForce: TimeInForceGTD}, nil},
+		{ExchangeOrder{Exchange: bybit, OrderType: BasicOrderTypeMarket}, ErrUnsupportedOrder},
+		{ExchangeOrder{Exchange: "KRAKEN_SPOT"}, ErrUnknownExchange},
+	} {
+		if err := registry.Check(tc.order); !errors.Is(err, tc.err) || (tc.err == nil) != (err == nil) {
+			t.Errorf("%+v: expected %v, got %v", tc.order, tc.err, err)
+		}
+	}
+
+	registry.Unregister(bybit)
+	if err := registry.Check(ExchangeOrder{Exchange: bybit}); !errors.Is(err, ErrUnknownExchange) {
+		t.Errorf("Expected ErrUnknownExchange after unregistering, got %v", err)
+	}
+}
+
+func TestClientExchangeRegistry(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"order_id":1}`))
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	order := func(exchange ExchangeType, orderType BasicOrderType) *CreateBasicOrderService {
+		return client.NewCreateBasicOrderService().Exchange(exchange).Symbol("BTC/USDT").
+			Side(SideTypeBuy).OrderType(orderType).Quantity("1")
+	}
+
+	// Without a registry any order is sent
+	if _, err := order("KRAKEN_SPOT", BasicOrderTypeStop).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	client.SetExchangeRegistry(NewExchangeRegistry())
+	if _, err := order(ExchangeBinanceSpot, BasicOrderTypeStop).Do(ctx); !errors.Is(err, ErrUnsupportedOrder) || !notSent(err) {
+		t.Errorf("Expected ErrUnsupportedOrder, got %v", err)
+	}
+	_, err := client.NewCreateAlgoOrderService().Exchange("KRAKEN_SPOT").Symbol("BTC/USDT").
+		Side(SideTypeBuy).OrderType(AlgoOrderTypeTWAP).Quantity("1").Do(ctx)
+	if !errors.Is(err, ErrUnknownExchange) || !notSent(err) {
+		t.Errorf("Expected ErrUnknownExchange, got %v", err)
+	}
+	if _, err := order(ExchangeBinanceSpot, BasicOrderTypeLimit).Price("100").TimeInForce(TimeInForceGTC).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected rejected orders not to be sent, got %d requests", requests)
+	}
+}
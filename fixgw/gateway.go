@@ -0,0 +1,524 @@
+// Package fixgw exposes Versifi order entry and execution reports over FIX
+// 4.4, so that existing order management systems can trade through the SDK.
+//
+// The gateway accepts FIX sessions, turns NewOrderSingle(D) into basic
+// orders and OrderCancelRequest(F) into cancels, and sends
+// ExecutionReport(8) messages built from the WebSocket execution reports:
+//
+//	gw := fixgw.New(client, fixgw.Config{SenderCompID: "VERSIFI", Exchange: versifi.ExchangeBinanceSpot})
+//	if err := gw.Attach(wsClient); err != nil {
+//		log.Fatal(err)
+//	}
+//	l, _ := net.Listen("tcp", ":9878")
+//	log.Fatal(gw.Serve(l))
+//
+// Symbols(55) may be in Versifi (BTC/USDT) or native (BTCUSDT) form; the
+// exchange is taken from SecurityExchange(207), which holds a Versifi
+// exchange such as BINANCE_SPOT, or from Config.Exchange.
+//
+// The gateway keeps no message store: ResendRequests are answered with a
+// SequenceReset-GapFill, sequence numbers restart at 1 with every logon, and
+// execution reports for a session that is not logged on are dropped. Use a
+// drop copy or Versifi's order queries to recover them.
+package fixgw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+	"github.com/drinkthere/versifi-go/symbols"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultLogonTimeout   = 10 * time.Second
+	defaultWriteTimeout   = 10 * time.Second
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// ErrGatewayClosed is returned by Serve after Close
+var ErrGatewayClosed = errors.New("fixgw: gateway closed")
+
+// Config configures a Gateway
+type Config struct {
+	// SenderCompID is the CompID of the gateway. Counterparties must send
+	// it as their TargetCompID.
+	SenderCompID string
+	// Exchange is used for orders without SecurityExchange(207)
+	Exchange versifi.ExchangeType
+	// Authenticate, when set, checks the SenderCompID, Username(553) and
+	// Password(554) of every Logon. A non-nil error rejects the logon.
+	Authenticate func(compID, username, password string) error
+	// LogonTimeout bounds the wait for the first Logon, default 10s
+	LogonTimeout time.Duration
+	// WriteTimeout bounds every write to a session, default 10s
+	WriteTimeout time.Duration
+	// RequestTimeout bounds the REST calls made for each order or cancel, default 10s
+	RequestTimeout time.Duration
+}
+
+// Gateway bridges FIX sessions to a Versifi client
+type Gateway struct {
+	client  *versifi.Client
+	tracker *versifi.OrderTracker
+	cfg     Config
+	execID  atomic.Int64
+
+	mu         sync.Mutex
+	sessions   map[string]*session // By counterparty CompID
+	orders     map[int64]*order    // By Versifi order ID
+	clOrdIDs   map[clOrdKey]int64
+	listeners  []net.Listener
+	closed     bool
+	errHandler versifi.ErrHandler
+}
+
+type clOrdKey struct {
+	compID  string
+	clOrdID string
+}
+
+// order is an order placed through the gateway
+type order struct {
+	compID   string
+	clOrdID  string
+	symbol   string // As sent by the counterparty
+	side     string
+	quantity decimal.Decimal
+	// cancelClOrdID is the ClOrdID of the last accepted cancel request
+	cancelClOrdID string
+
+	// What has been reported to the counterparty
+	status   versifi.OrderStatusType
+	filled   decimal.Decimal
+	notional decimal.Decimal
+}
+
+// New creates a gateway placing orders with client
+func New(client *versifi.Client, cfg Config) *Gateway {
+	if cfg.LogonTimeout <= 0 {
+		cfg.LogonTimeout = defaultLogonTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultWriteTimeout
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = defaultRequestTimeout
+	}
+
+	g := &Gateway{
+		client:   client,
+		tracker:  versifi.NewOrderTracker(client),
+		cfg:      cfg,
+		sessions: make(map[string]*session),
+		orders:   make(map[int64]*order),
+		clOrdIDs: make(map[clOrdKey]int64),
+	}
+	g.tracker.Subscribe(g.orderUpdate)
+	return g
+}
+
+// SetErrorHandler sets the handler for session and execution report errors
+func (g *Gateway) SetErrorHandler(handler versifi.ErrHandler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.errHandler = handler
+	g.tracker.SetErrorHandler(handler)
+}
+
+// Attach feeds the gateway from ws execution reports. The client's
+// execution_report and resume handlers keep receiving their messages, see
+// versifi.OrderTracker.Attach.
+func (g *Gateway) Attach(ws *versifi.WsClient) error {
+	return g.tracker.Attach(ws)
+}
+
+// HandleExecutionReport applies an execution_report message, for callers
+// that fan out execution reports themselves
+func (g *Gateway) HandleExecutionReport(message []byte) {
+	g.tracker.HandleExecutionReport(message)
+}
+
+// Serve accepts FIX sessions on l until Close is called
+func (g *Gateway) Serve(l net.Listener) error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return ErrGatewayClosed
+	}
+	g.listeners = append(g.listeners, l)
+	g.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			g.mu.Lock()
+			closed := g.closed
+			g.mu.Unlock()
+			if closed {
+				return ErrGatewayClosed
+			}
+			return err
+		}
+		go func() {
+			if err := g.ServeConn(conn); err != nil {
+				g.error(err)
+			}
+		}()
+	}
+}
+
+// ServeConn runs one FIX session on conn and returns when it ends
+func (g *Gateway) ServeConn(conn net.Conn) error {
+	s := newSession(g, conn)
+	defer s.close()
+
+	if err := s.logon(); err != nil {
+		return fmt.Errorf("fixgw: %s: %w", conn.RemoteAddr(), err)
+	}
+	defer g.unregister(s)
+
+	if err := s.run(); err != nil {
+		return fmt.Errorf("fixgw: session %s: %w", s.targetCompID, err)
+	}
+	return nil
+}
+
+// Close stops every listener and ends every session
+func (g *Gateway) Close() error {
+	g.mu.Lock()
+	g.closed = true
+	listeners := g.listeners
+	sessions := make([]*session, 0, len(g.sessions))
+	for _, s := range g.sessions {
+		sessions = append(sessions, s)
+	}
+	g.mu.Unlock()
+
+	var errs []error
+	for _, l := range listeners {
+		errs = append(errs, l.Close())
+	}
+	for _, s := range sessions {
+		s.send(NewMessage(msgLogout).Set(tagText, "gateway closing"))
+		s.close()
+	}
+	return errors.Join(errs...)
+}
+
+func (g *Gateway) register(s *session) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.sessions[s.targetCompID]; ok || g.closed {
+		return false
+	}
+	g.sessions[s.targetCompID] = s
+	return true
+}
+
+func (g *Gateway) unregister(s *session) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.sessions[s.targetCompID] == s {
+		delete(g.sessions, s.targetCompID)
+	}
+}
+
+// newOrderSingle places the basic order described by m
+func (g *Gateway) newOrderSingle(s *session, m *Message) {
+	o := &order{
+		compID:  s.targetCompID,
+		clOrdID: m.Get(tagClOrdID),
+		symbol:  m.Get(tagSymbol),
+		side:    m.Get(tagSide),
+	}
+	reject := func(reason string) {
+		s.send(g.executionReport(o, "NONE", "8", "8").Set(tagText, reason))
+	}
+
+	if o.clOrdID == "" {
+		reject("ClOrdID is required")
+		return
+	}
+	key := clOrdKey{o.compID, o.clOrdID}
+	g.mu.Lock()
+	_, duplicate := g.clOrdIDs[key]
+	g.mu.Unlock()
+	if duplicate {
+		reject("duplicate ClOrdID")
+		return
+	}
+
+	svc, err := g.basicOrder(m, o)
+	if err != nil {
+		reject(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.cfg.RequestTimeout)
+	defer cancel()
+	res, err := g.tracker.Submit(ctx, svc)
+	if err != nil {
+		reject(err.Error())
+		return
+	}
+
+	g.mu.Lock()
+	g.orders[res.OrderID] = o
+	g.clOrdIDs[key] = res.OrderID
+	g.mu.Unlock()
+
+	// Report the state reached so far; later changes arrive as updates
+	if state, ok := g.tracker.Order(res.OrderID); ok {
+		g.orderUpdate(versifi.OrderUpdate{Order: state})
+	}
+}
+
+// basicOrder builds the order for a NewOrderSingle
+func (g *Gateway) basicOrder(m *Message, o *order) (*versifi.CreateBasicOrderService, error) {
+	svc := g.client.NewCreateBasicOrderService()
+
+	exchange := versifi.ExchangeType(m.Get(tagSecurityExchange))
+	if exchange == "" {
+		exchange = g.cfg.Exchange
+	}
+	if exchange == "" {
+		return nil, errors.New("SecurityExchange is required")
+	}
+	svc.Exchange(exchange)
+
+	symbol, err := symbols.Normalize(o.symbol)
+	if err != nil {
+		return nil, err
+	}
+	svc.Symbol(symbol)
+
+	switch o.side {
+	case "1":
+		svc.Side(versifi.SideTypeBuy)
+	case "2":
+		svc.Side(versifi.SideTypeSell)
+	default:
+		return nil, fmt.Errorf("unsupported Side %q", o.side)
+	}
+
+	quantity, err := decimal.NewFromString(m.Get(tagOrderQty))
+	if err != nil || !quantity.IsPositive() {
+		return nil, fmt.Errorf("invalid OrderQty %q", m.Get(tagOrderQty))
+	}
+	o.quantity = quantity
+	svc.QuantityDecimal(quantity)
+
+	switch ordType := m.Get(tagOrdType); ordType {
+	case "1":
+		svc.OrderType(versifi.BasicOrderTypeMarket)
+	case "2":
+		svc.OrderType(versifi.BasicOrderTypeLimit)
+	case "3":
+		svc.OrderType(versifi.BasicOrderTypeStop)
+	case "4":
+		svc.OrderType(versifi.BasicOrderTypeStopLossLimit)
+	default:
+		return nil, fmt.Errorf("unsupported OrdType %q", ordType)
+	}
+	if price := m.Get(tagPrice); price != "" {
+		svc.Price(price)
+	}
+	if stopPx := m.Get(tagStopPx); stopPx != "" {
+		svc.StopPrice(stopPx)
+	}
+
+	switch tif := m.Get(tagTimeInForce); tif {
+	case "", "0": // Day is left to the exchange default
+	case "1":
+		svc.TimeInForce(versifi.TimeInForceGTC)
+	case "3":
+		svc.TimeInForce(versifi.TimeInForceIOC)
+	case "4":
+		svc.TimeInForce(versifi.TimeInForceFOK)
+	default:
+		return nil, fmt.Errorf("unsupported TimeInForce %q", tif)
+	}
+
+	if id, err := strconv.ParseInt(o.clOrdID, 10, 64); err == nil {
+		svc.ClientOrderID(id)
+	}
+	return svc, nil
+}
+
+// orderCancelRequest cancels the order named by OrigClOrdID(41)
+func (g *Gateway) orderCancelRequest(s *session, m *Message) {
+	clOrdID, origClOrdID := m.Get(tagClOrdID), m.Get(tagOrigClOrdID)
+
+	g.mu.Lock()
+	orderID, ok := g.clOrdIDs[clOrdKey{s.targetCompID, origClOrdID}]
+	o := g.orders[orderID]
+	g.mu.Unlock()
+
+	reject := func(status, reason, text string) {
+		orderIDValue := "NONE"
+		if ok {
+			orderIDValue = strconv.FormatInt(orderID, 10)
+		}
+		s.send(NewMessage(msgOrderCancelReject).
+			Set(tagOrderID, orderIDValue).
+			Set(tagClOrdID, clOrdID).
+			Set(tagOrigClOrdID, origClOrdID).
+			Set(tagOrdStatus, status).
+			Set(tagCxlRejResponseTo, "1"). // Order Cancel Request
+			Set(tagCxlRejReason, reason).
+			Set(tagText, text))
+	}
+
+	if !ok {
+		reject("8", "1", "unknown order") // Unknown order
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.cfg.RequestTimeout)
+	defer cancel()
+	if err := g.client.NewCancelOrderService().OrderID(orderID).Do(ctx); err != nil {
+		g.mu.Lock()
+		status := ordStatus(o.status)
+		g.mu.Unlock()
+		reject(status, "99", err.Error()) // Other
+		return
+	}
+
+	g.mu.Lock()
+	o.cancelClOrdID = clOrdID
+	report := g.executionReportLocked(o, strconv.FormatInt(orderID, 10), "6", "6") // Pending Cancel
+	g.mu.Unlock()
+	s.send(report.Set(tagClOrdID, clOrdID).Set(tagOrigClOrdID, origClOrdID))
+}
+
+// orderUpdate sends an ExecutionReport for every fill and status change of
+// an order placed through the gateway
+func (g *Gateway) orderUpdate(u versifi.OrderUpdate) {
+	g.mu.Lock()
+	o, ok := g.orders[u.Order.OrderID]
+	if !ok {
+		g.mu.Unlock()
+		return
+	}
+	s := g.sessions[o.compID]
+
+	filled := u.Order.FilledQuantityDecimal()
+	notional := filled.Mul(u.Order.AveragePriceDecimal())
+	lastQty := filled.Sub(o.filled)
+	if !lastQty.IsPositive() && u.Order.Status == o.status {
+		g.mu.Unlock()
+		return
+	}
+
+	var execType string
+	switch {
+	case lastQty.IsPositive():
+		execType = "F" // Trade
+	case u.Order.Status == versifi.OrderStatusNew:
+		execType = "0"
+	case u.Order.Status == versifi.OrderStatusCanceled:
+		execType = "4"
+	case u.Order.Status == versifi.OrderStatusRejected:
+		execType = "8"
+	case u.Order.Status == versifi.OrderStatusExpired:
+		execType = "C"
+	default:
+		execType = "I" // Order Status
+	}
+
+	var lastPx decimal.Decimal
+	if lastQty.IsPositive() {
+		lastPx = notional.Sub(o.notional).Div(lastQty)
+	}
+	o.status, o.filled, o.notional = u.Order.Status, filled, notional
+
+	report := g.executionReportLocked(o, strconv.FormatInt(u.Order.OrderID, 10), execType, ordStatus(u.Order.Status))
+	if lastQty.IsPositive() {
+		report.Set(tagLastQty, lastQty.String()).Set(tagLastPx, lastPx.String())
+	}
+	if u.Order.RejectReason != "" {
+		report.Set(tagText, u.Order.RejectReason)
+	}
+	if execType == "4" && o.cancelClOrdID != "" {
+		report.Set(tagClOrdID, o.cancelClOrdID).Set(tagOrigClOrdID, o.clOrdID)
+	}
+	g.mu.Unlock()
+
+	if s != nil {
+		if err := s.send(report); err != nil {
+			g.error(fmt.Errorf("fixgw: session %s: %w", o.compID, err))
+		}
+	}
+}
+
+// executionReport builds an ExecutionReport for o
+func (g *Gateway) executionReport(o *order, orderID, execType, status string) *Message {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.executionReportLocked(o, orderID, execType, status)
+}
+
+func (g *Gateway) executionReportLocked(o *order, orderID, execType, status string) *Message {
+	leaves := o.quantity.Sub(o.filled)
+	if o.status.IsFinal() || status == "8" || leaves.IsNegative() {
+		leaves = decimal.Zero
+	}
+	avgPx := decimal.Zero
+	if o.filled.IsPositive() {
+		avgPx = o.notional.Div(o.filled)
+	}
+
+	return NewMessage(msgExecutionReport).
+		Set(tagOrderID, orderID).
+		Set(tagClOrdID, o.clOrdID).
+		Set(tagExecID, g.nextExecID()).
+		Set(tagExecType, execType).
+		Set(tagOrdStatus, status).
+		Set(tagSymbol, o.symbol).
+		Set(tagSide, o.side).
+		Set(tagOrderQty, o.quantity.String()).
+		Set(tagLeavesQty, leaves.String()).
+		Set(tagCumQty, o.filled.String()).
+		Set(tagAvgPx, avgPx.String()).
+		Set(tagTransactTime, timestamp(time.Now()))
+}
+
+// nextExecID returns an ExecID unique across gateway restarts
+func (g *Gateway) nextExecID() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatInt(g.execID.Add(1), 10)
+}
+
+// ordStatus maps an order status to OrdStatus(39)
+func ordStatus(status versifi.OrderStatusType) string {
+	switch status {
+	case versifi.OrderStatusPartiallyFilled:
+		return "1"
+	case versifi.OrderStatusFilled:
+		return "2"
+	case versifi.OrderStatusCanceled:
+		return "4"
+	case versifi.OrderStatusRejected:
+		return "8"
+	case versifi.OrderStatusExpired:
+		return "C"
+	default:
+		return "0"
+	}
+}
+
+func (g *Gateway) error(err error) {
+	g.mu.Lock()
+	handler := g.errHandler
+	g.mu.Unlock()
+	if handler != nil {
+		handler(err)
+	}
+}
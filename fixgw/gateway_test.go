@@ -0,0 +1,204 @@
+package fixgw
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// fixClient is the counterparty side of a test session
+type fixClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+	seq  int
+}
+
+func (c *fixClient) send(m *Message) {
+	c.seq++
+	m.Set(tagSenderCompID, "OMS").
+		Set(tagTargetCompID, "VERSIFI").
+		Set(tagMsgSeqNum, strconv.Itoa(c.seq)).
+		Set(tagSendingTime, timestamp(time.Now()))
+	if _, err := c.conn.Write(m.encode()); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+// expect reads messages, skipping heartbeats, until one of msgType arrives
+func (c *fixClient) expect(msgType string) *Message {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		m, err := readMessage(c.r)
+		if err != nil {
+			c.t.Fatalf("Expected MsgType %s: %v", msgType, err)
+		}
+		if m.Type() == msgHeartbeat && msgType != msgHeartbeat {
+			continue
+		}
+		if m.Type() != msgType {
+			c.t.Fatalf("Expected MsgType %s, got %s", msgType, m)
+		}
+		return m
+	}
+}
+
+func executionReport(t *testing.T, status versifi.OrderStatusType, timestamp int64, trades ...versifi.WsTrade) []byte {
+	data, err := json.Marshal(map[string]interface{}{
+		"op":      "execution_report",
+		"success": true,
+		"message": versifi.WsExecutionReportDetail{
+			OrderID:          100,
+			ClientOrderID:    7,
+			Status:           status,
+			Timestamp:        versifi.TimestampFromEpoch(timestamp),
+			RequestOrderType: "BASIC",
+			Basic: &versifi.WsBasicOrderDetail{
+				Symbol:     "BTC/USDT",
+				Exchange:   versifi.ExchangeBinanceSpot,
+				Side:       versifi.SideTypeBuy,
+				Quantity:   "2",
+				ChildOrder: &versifi.WsChildOrder{ID: 1, Trades: trades},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestGateway(t *testing.T) {
+	var order versifi.BasicOrderRequest
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/orders/basic/":
+			json.NewDecoder(r.Body).Decode(&order)
+			json.NewEncoder(w).Encode(versifi.OrderResponse{OrderID: 100, ClientOrderID: 7, Status: versifi.OrderStatusNew})
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/orders/100":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer rest.Close()
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = rest.URL
+	gw := New(client, Config{SenderCompID: "VERSIFI"})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go gw.Serve(l)
+	defer gw.Close()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := &fixClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	c.send(NewMessage(msgLogon).Set(tagEncryptMethod, "0").Set(tagHeartBtInt, "30"))
+	c.expect(msgLogon)
+
+	c.send(NewMessage(msgNewOrderSingle).
+		Set(tagClOrdID, "7").
+		Set(tagSymbol, "BTCUSDT").
+		Set(tagSecurityExchange, "BINANCE_SPOT").
+		Set(tagSide, "1").
+		Set(tagOrderQty, "2").
+		Set(tagOrdType, "2").
+		Set(tagPrice, "100").
+		Set(tagTimeInForce, "1"))
+	ack := c.expect(msgExecutionReport)
+	if ack.Get(tagExecType) != "0" || ack.Get(tagOrderID) != "100" || ack.Get(tagLeavesQty) != "2" {
+		t.Errorf("Unexpected ack %s", ack)
+	}
+	if order.Symbol != "BTC/USDT" || order.ClientOrderID == nil || *order.ClientOrderID != 7 || *order.TIF != versifi.TimeInForceGTC {
+		t.Errorf("Unexpected order %+v", order)
+	}
+
+	// A duplicate ClOrdID is rejected without reaching the API
+	c.send(NewMessage(msgNewOrderSingle).Set(tagClOrdID, "7").Set(tagSymbol, "BTC/USDT").Set(tagSide, "1").Set(tagOrderQty, "1").Set(tagOrdType, "1"))
+	if rej := c.expect(msgExecutionReport); rej.Get(tagOrdStatus) != "8" || rej.Get(tagText) != "duplicate ClOrdID" {
+		t.Errorf("Unexpected reject %s", rej)
+	}
+
+	gw.HandleExecutionReport(executionReport(t, versifi.OrderStatusPartiallyFilled, 1,
+		versifi.WsTrade{TradeID: 1, ExecutedPrice: "100", ExecutedQuantity: "1.5"}))
+	fill := c.expect(msgExecutionReport)
+	if fill.Get(tagExecType) != "F" || fill.Get(tagOrdStatus) != "1" || fill.Get(tagLastQty) != "1.5" ||
+		fill.Get(tagLastPx) != "100" || fill.Get(tagCumQty) != "1.5" || fill.Get(tagLeavesQty) != "0.5" {
+		t.Errorf("Unexpected fill %s", fill)
+	}
+
+	c.send(NewMessage(msgOrderCancelRequest).Set(tagClOrdID, "8").Set(tagOrigClOrdID, "7").Set(tagSide, "1"))
+	if pending := c.expect(msgExecutionReport); pending.Get(tagExecType) != "6" || pending.Get(tagOrigClOrdID) != "7" {
+		t.Errorf("Unexpected pending cancel %s", pending)
+	}
+	c.send(NewMessage(msgOrderCancelRequest).Set(tagClOrdID, "9").Set(tagOrigClOrdID, "unknown").Set(tagSide, "1"))
+	if rej := c.expect(msgOrderCancelReject); rej.Get(tagCxlRejReason) != "1" {
+		t.Errorf("Unexpected cancel reject %s", rej)
+	}
+
+	gw.HandleExecutionReport(executionReport(t, versifi.OrderStatusCanceled, 2))
+	canceled := c.expect(msgExecutionReport)
+	if canceled.Get(tagExecType) != "4" || canceled.Get(tagClOrdID) != "8" || canceled.Get(tagLeavesQty) != "0" {
+		t.Errorf("Unexpected cancel %s", canceled)
+	}
+
+	c.send(NewMessage(msgTestRequest).Set(tagTestReqID, "ping"))
+	if hb := c.expect(msgHeartbeat); hb.Get(tagTestReqID) != "ping" {
+		t.Errorf("Unexpected heartbeat %s", hb)
+	}
+	c.send(NewMessage("AE"))
+	c.expect(msgBusinessMessageReject)
+
+	c.send(NewMessage(msgLogout))
+	c.expect(msgLogout)
+}
+
+func TestGatewayLogonRejected(t *testing.T) {
+	gw := New(versifi.NewClient("test-key", "test-secret"), Config{SenderCompID: "VERSIFI"})
+	server, clientConn := net.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- gw.ServeConn(server) }()
+
+	c := &fixClient{t: t, conn: clientConn, r: bufio.NewReader(clientConn)}
+	c.send(NewMessage(msgLogon).Set(tagHeartBtInt, "0"))
+	if logout := c.expect(msgLogout); logout.Get(tagText) != "HeartBtInt must be positive" {
+		t.Errorf("Unexpected logout %s", logout)
+	}
+	if err := <-done; err == nil {
+		t.Error("Expected logon error")
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	m := NewMessage(msgHeartbeat).Set(tagSenderCompID, "A").Set(tagTargetCompID, "B").Set(tagMsgSeqNum, "1")
+	data := m.encode()
+
+	got, err := readMessage(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil || got.Get(tagSenderCompID) != "A" {
+		t.Fatalf("Unexpected %v %v", got, err)
+	}
+
+	data[len(data)-2] = '0' // Corrupt the checksum
+	data[len(data)-3] = '0'
+	data[len(data)-4] = '0'
+	if _, err := readMessage(bufio.NewReader(bytes.NewReader(data))); err == nil {
+		t.Error("Expected checksum error")
+	}
+}
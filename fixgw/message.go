@@ -0,0 +1,264 @@
+package fixgw
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	beginString = "FIX.4.4"
+	soh         = '\x01'
+	// maxBodyLength bounds the body of an incoming message
+	maxBodyLength = 1 << 16
+	// timestampFormat is the UTCTimestamp format used for SendingTime and TransactTime
+	timestampFormat = "20060102-15:04:05.000"
+)
+
+// FIX tags used by the gateway
+const (
+	tagAvgPx                = 6
+	tagBeginSeqNo           = 7
+	tagBeginString          = 8
+	tagBodyLength           = 9
+	tagCheckSum             = 10
+	tagClOrdID              = 11
+	tagCumQty               = 14
+	tagExecID               = 17
+	tagLastPx               = 31
+	tagLastQty              = 32
+	tagMsgSeqNum            = 34
+	tagMsgType              = 35
+	tagNewSeqNo             = 36
+	tagOrderID              = 37
+	tagOrderQty             = 38
+	tagOrdStatus            = 39
+	tagOrdType              = 40
+	tagOrigClOrdID          = 41
+	tagPossDupFlag          = 43
+	tagPrice                = 44
+	tagRefSeqNum            = 45
+	tagSenderCompID         = 49
+	tagSendingTime          = 52
+	tagSide                 = 54
+	tagSymbol               = 55
+	tagTargetCompID         = 56
+	tagText                 = 58
+	tagTimeInForce          = 59
+	tagTransactTime         = 60
+	tagStopPx               = 99
+	tagEncryptMethod        = 98
+	tagCxlRejReason         = 102
+	tagHeartBtInt           = 108
+	tagTestReqID            = 112
+	tagGapFillFlag          = 123
+	tagExecType             = 150
+	tagLeavesQty            = 151
+	tagSecurityExchange     = 207
+	tagRefMsgType           = 372
+	tagBusinessRejectReason = 380
+	tagCxlRejResponseTo     = 434
+	tagUsername             = 553
+	tagPassword             = 554
+)
+
+// FIX message types used by the gateway
+const (
+	msgHeartbeat             = "0"
+	msgTestRequest           = "1"
+	msgResendRequest         = "2"
+	msgReject                = "3"
+	msgSequenceReset         = "4"
+	msgLogout                = "5"
+	msgExecutionReport       = "8"
+	msgOrderCancelReject     = "9"
+	msgLogon                 = "A"
+	msgNewOrderSingle        = "D"
+	msgOrderCancelRequest    = "F"
+	msgBusinessMessageReject = "j"
+)
+
+// Field is one tag=value pair
+type Field struct {
+	Tag   int
+	Value string
+}
+
+// Message is a FIX message as an ordered list of fields, without the
+// BeginString, BodyLength and CheckSum fields
+type Message struct {
+	Fields []Field
+}
+
+// NewMessage creates a message of msgType
+func NewMessage(msgType string) *Message {
+	return &Message{Fields: []Field{{tagMsgType, msgType}}}
+}
+
+// Type returns the MsgType(35)
+func (m *Message) Type() string {
+	return m.Get(tagMsgType)
+}
+
+// Get returns the first value of tag, or "" if it is not set
+func (m *Message) Get(tag int) string {
+	for _, f := range m.Fields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Has reports whether tag is set
+func (m *Message) Has(tag int) bool {
+	for _, f := range m.Fields {
+		if f.Tag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Set appends tag=value, or replaces the first value of tag
+func (m *Message) Set(tag int, value string) *Message {
+	for i := range m.Fields {
+		if m.Fields[i].Tag == tag {
+			m.Fields[i].Value = value
+			return m
+		}
+	}
+	m.Fields = append(m.Fields, Field{tag, value})
+	return m
+}
+
+// headerTags are the standard header fields written by the gateway, in order
+var headerTags = []int{tagMsgType, tagSenderCompID, tagTargetCompID, tagMsgSeqNum, tagPossDupFlag, tagSendingTime}
+
+func isHeaderTag(tag int) bool {
+	for _, t := range headerTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// encode serializes m with the standard header and trailer, header fields
+// first
+func (m *Message) encode() []byte {
+	var body bytes.Buffer
+	for _, tag := range headerTags {
+		if m.Has(tag) {
+			writeField(&body, tag, m.Get(tag))
+		}
+	}
+	for _, f := range m.Fields {
+		if !isHeaderTag(f.Tag) {
+			writeField(&body, f.Tag, f.Value)
+		}
+	}
+
+	var buf bytes.Buffer
+	writeField(&buf, tagBeginString, beginString)
+	writeField(&buf, tagBodyLength, strconv.Itoa(body.Len()))
+	buf.Write(body.Bytes())
+	writeField(&buf, tagCheckSum, fmt.Sprintf("%03d", checksum(buf.Bytes())))
+	return buf.Bytes()
+}
+
+// String returns m with SOH shown as '|', for logs
+func (m *Message) String() string {
+	return string(bytes.ReplaceAll(m.encode(), []byte{soh}, []byte{'|'}))
+}
+
+func writeField(buf *bytes.Buffer, tag int, value string) {
+	buf.WriteString(strconv.Itoa(tag))
+	buf.WriteByte('=')
+	buf.WriteString(value)
+	buf.WriteByte(soh)
+}
+
+func checksum(data []byte) int {
+	var sum int
+	for _, b := range data {
+		sum += int(b)
+	}
+	return sum % 256
+}
+
+var errGarbled = errors.New("garbled message")
+
+// readMessage reads one message, validating BeginString, BodyLength and CheckSum
+func readMessage(r *bufio.Reader) (*Message, error) {
+	begin, err := readField(r)
+	if err != nil {
+		return nil, err
+	}
+	if begin.Tag != tagBeginString || begin.Value != beginString {
+		return nil, fmt.Errorf("%w: unexpected BeginString %q", errGarbled, begin.Value)
+	}
+	length, err := readField(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(length.Value)
+	if length.Tag != tagBodyLength || err != nil || n <= 0 || n > maxBodyLength {
+		return nil, fmt.Errorf("%w: bad BodyLength %q", errGarbled, length.Value)
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	trailer, err := readField(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	writeField(&header, tagBeginString, begin.Value)
+	writeField(&header, tagBodyLength, length.Value)
+	want := (checksum(header.Bytes()) + checksum(body)) % 256
+	if got, err := strconv.Atoi(trailer.Value); trailer.Tag != tagCheckSum || err != nil || got != want {
+		return nil, fmt.Errorf("%w: bad CheckSum %q, want %03d", errGarbled, trailer.Value, want)
+	}
+
+	m := &Message{}
+	for _, raw := range bytes.Split(bytes.TrimSuffix(body, []byte{soh}), []byte{soh}) {
+		f, err := parseField(raw)
+		if err != nil {
+			return nil, err
+		}
+		m.Fields = append(m.Fields, f)
+	}
+	if len(m.Fields) == 0 || m.Fields[0].Tag != tagMsgType {
+		return nil, fmt.Errorf("%w: MsgType is not the first body field", errGarbled)
+	}
+	return m, nil
+}
+
+func readField(r *bufio.Reader) (Field, error) {
+	raw, err := r.ReadSlice(soh)
+	if err != nil {
+		return Field{}, err
+	}
+	return parseField(raw[:len(raw)-1])
+}
+
+func parseField(raw []byte) (Field, error) {
+	tag, value, ok := bytes.Cut(raw, []byte{'='})
+	n, err := strconv.Atoi(string(tag))
+	if !ok || err != nil || n <= 0 {
+		return Field{}, fmt.Errorf("%w: bad field %q", errGarbled, raw)
+	}
+	return Field{n, string(value)}, nil
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timestampFormat)
+}
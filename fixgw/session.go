@@ -0,0 +1,234 @@
+package fixgw
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// session is one FIX session with a counterparty
+type session struct {
+	g            *Gateway
+	conn         net.Conn
+	r            *bufio.Reader
+	targetCompID string
+	heartBtInt   time.Duration
+
+	mu     sync.Mutex // Serializes writes and guards outSeq
+	outSeq int
+	inSeq  int
+
+	lastRecv    atomic.Int64 // Unix nanoseconds
+	lastSent    atomic.Int64
+	testPending atomic.Bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newSession(g *Gateway, conn net.Conn) *session {
+	return &session{
+		g:      g,
+		conn:   conn,
+		r:      bufio.NewReader(conn),
+		outSeq: 1,
+		inSeq:  1,
+		closed: make(chan struct{}),
+	}
+}
+
+// logon waits for the counterparty's Logon and answers it
+func (s *session) logon() error {
+	s.conn.SetReadDeadline(time.Now().Add(s.g.cfg.LogonTimeout))
+	m, err := readMessage(s.r)
+	if err != nil {
+		return fmt.Errorf("read logon: %w", err)
+	}
+	s.conn.SetReadDeadline(time.Time{})
+
+	if m.Type() != msgLogon {
+		return fmt.Errorf("expected Logon, got MsgType %q", m.Type())
+	}
+	s.targetCompID = m.Get(tagSenderCompID)
+	heartBtInt, _ := strconv.Atoi(m.Get(tagHeartBtInt))
+
+	var reason string
+	switch {
+	case s.targetCompID == "":
+		reason = "SenderCompID is required"
+	case m.Get(tagTargetCompID) != s.g.cfg.SenderCompID:
+		reason = "unknown TargetCompID " + m.Get(tagTargetCompID)
+	case heartBtInt <= 0:
+		reason = "HeartBtInt must be positive"
+	case s.g.cfg.Authenticate != nil:
+		if err := s.g.cfg.Authenticate(s.targetCompID, m.Get(tagUsername), m.Get(tagPassword)); err != nil {
+			reason = err.Error()
+		}
+	}
+	if reason == "" && !s.g.register(s) {
+		reason = "session already logged on"
+	}
+	if reason != "" {
+		s.send(NewMessage(msgLogout).Set(tagText, reason))
+		return errors.New("logon rejected: " + reason)
+	}
+
+	s.heartBtInt = time.Duration(heartBtInt) * time.Second
+	if seq, err := strconv.Atoi(m.Get(tagMsgSeqNum)); err == nil {
+		s.inSeq = seq + 1
+	}
+	s.lastRecv.Store(time.Now().UnixNano())
+	return s.send(NewMessage(msgLogon).
+		Set(tagEncryptMethod, "0").
+		Set(tagHeartBtInt, strconv.Itoa(heartBtInt)))
+}
+
+// run reads messages until the session ends
+func (s *session) run() error {
+	go s.heartbeat()
+
+	for {
+		m, err := readMessage(s.r)
+		if err != nil {
+			select {
+			case <-s.closed:
+				return nil
+			default:
+				return err
+			}
+		}
+		s.lastRecv.Store(time.Now().UnixNano())
+		s.testPending.Store(false)
+
+		if ok := s.checkSeq(m); !ok {
+			continue
+		}
+		switch m.Type() {
+		case msgHeartbeat, msgSequenceReset, msgReject:
+		case msgTestRequest:
+			s.send(NewMessage(msgHeartbeat).Set(tagTestReqID, m.Get(tagTestReqID)))
+		case msgResendRequest:
+			s.gapFill(m)
+		case msgLogout:
+			s.send(NewMessage(msgLogout))
+			return nil
+		case msgNewOrderSingle:
+			s.g.newOrderSingle(s, m)
+		case msgOrderCancelRequest:
+			s.g.orderCancelRequest(s, m)
+		default:
+			s.send(NewMessage(msgBusinessMessageReject).
+				Set(tagRefSeqNum, m.Get(tagMsgSeqNum)).
+				Set(tagRefMsgType, m.Type()).
+				Set(tagBusinessRejectReason, "3"). // Unsupported Message Type
+				Set(tagText, "unsupported message type"))
+		}
+	}
+}
+
+// checkSeq tracks the incoming MsgSeqNum. Messages below the expected number
+// are dropped if they are possible duplicates and end the session otherwise.
+// Gaps are accepted: the session runs over TCP, and messages the gateway
+// never saw cannot be recovered by asking for them again.
+func (s *session) checkSeq(m *Message) bool {
+	seq, err := strconv.Atoi(m.Get(tagMsgSeqNum))
+	if err != nil {
+		s.send(NewMessage(msgReject).Set(tagText, "MsgSeqNum is required"))
+		return false
+	}
+	if seq < s.inSeq {
+		if m.Get(tagPossDupFlag) == "Y" {
+			return false
+		}
+		s.send(NewMessage(msgLogout).Set(tagText, fmt.Sprintf("MsgSeqNum too low, expecting %d but received %d", s.inSeq, seq)))
+		s.close()
+		return false
+	}
+	s.inSeq = seq + 1
+	return true
+}
+
+// gapFill answers a ResendRequest. The gateway keeps no message store, so
+// the requested range is skipped with a SequenceReset-GapFill.
+func (s *session) gapFill(m *Message) {
+	begin, err := strconv.Atoi(m.Get(tagBeginSeqNo))
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reset := NewMessage(msgSequenceReset).
+		Set(tagPossDupFlag, "Y").
+		Set(tagGapFillFlag, "Y").
+		Set(tagNewSeqNo, strconv.Itoa(s.outSeq))
+	s.writeLocked(reset, begin)
+}
+
+// heartbeat sends Heartbeats when the session is idle, a TestRequest when
+// the counterparty is silent, and closes the session when it stays silent
+func (s *session) heartbeat() {
+	tick := s.heartBtInt / 4
+	if tick < 10*time.Millisecond {
+		tick = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		silent := now.Sub(time.Unix(0, s.lastRecv.Load()))
+		switch {
+		case silent > 2*s.heartBtInt+s.heartBtInt/5:
+			s.send(NewMessage(msgLogout).Set(tagText, "heartbeat timeout"))
+			s.close()
+			return
+		case silent > s.heartBtInt+s.heartBtInt/5 && !s.testPending.Load():
+			s.testPending.Store(true)
+			s.send(NewMessage(msgTestRequest).Set(tagTestReqID, timestamp(now)))
+		case now.Sub(time.Unix(0, s.lastSent.Load())) >= s.heartBtInt:
+			s.send(NewMessage(msgHeartbeat))
+		}
+	}
+}
+
+// send writes m with the next outgoing MsgSeqNum
+func (s *session) send(m *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq := s.outSeq
+	s.outSeq++
+	return s.writeLocked(m, seq)
+}
+
+func (s *session) writeLocked(m *Message, seq int) error {
+	m.Set(tagSenderCompID, s.g.cfg.SenderCompID).
+		Set(tagTargetCompID, s.targetCompID).
+		Set(tagMsgSeqNum, strconv.Itoa(seq)).
+		Set(tagSendingTime, timestamp(time.Now()))
+
+	s.conn.SetWriteDeadline(time.Now().Add(s.g.cfg.WriteTimeout))
+	_, err := s.conn.Write(m.encode())
+	if err == nil {
+		s.lastSent.Store(time.Now().UnixNano())
+	}
+	return err
+}
+
+func (s *session) close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.conn.Close()
+	})
+}
@@ -0,0 +1,418 @@
+// Package grpcapi serves the Versifi client over gRPC, so services written in
+// other languages can place orders and receive execution reports through one
+// Go process holding the API credentials.
+//
+// The service is defined in versifiv1/versifi.proto; generate clients for
+// other languages from that file.
+//
+//	srv := grpcapi.NewServer(client)
+//	srv.Attach(wsClient)
+//
+//	gs := grpc.NewServer()
+//	versifiv1.RegisterVersifiServer(gs, srv)
+//	gs.Serve(lis)
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	versifi "github.com/drinkthere/versifi-go"
+	"github.com/drinkthere/versifi-go/grpcapi/versifiv1"
+)
+
+const defaultStreamBuffer = 256
+
+// Server implements versifiv1.VersifiServer on top of a versifi.Client.
+// Execution reports reach streaming callers once the server is attached to
+// a WebSocket client or fed by HandleExecutionReport.
+type Server struct {
+	versifiv1.UnimplementedVersifiServer
+
+	// StreamBuffer is the number of execution reports queued per stream,
+	// default 256. A stream that falls further behind is ended with
+	// ResourceExhausted rather than slowing down the others.
+	StreamBuffer int
+
+	c          *versifi.Client
+	mu         sync.RWMutex
+	streams    map[int]*reportStream
+	nextID     int
+	errHandler versifi.ErrHandler
+}
+
+type reportStream struct {
+	orders  map[int64]struct{}
+	reports chan *versifiv1.ExecutionReport
+	// overflow is closed when reports is full and the stream must end
+	overflow     chan struct{}
+	overflowOnce sync.Once
+}
+
+// NewServer creates a server placing orders with client
+func NewServer(client *versifi.Client) *Server {
+	return &Server{
+		StreamBuffer: defaultStreamBuffer,
+		c:            client,
+		streams:      make(map[int]*reportStream),
+	}
+}
+
+// SetErrorHandler sets the handler for undecodable execution reports
+func (s *Server) SetErrorHandler(handler versifi.ErrHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errHandler = handler
+}
+
+// Attach subscribes the server to execution reports on ws and returns a
+// function that detaches it. It registers with OnExecutionReport, so the
+// client's execution_report handler keeps receiving its messages.
+func (s *Server) Attach(ws *versifi.WsClient) (unsubscribe func(), err error) {
+	if err := ws.EnsureSubscribed("execution_report"); err != nil {
+		return nil, err
+	}
+	return ws.OnExecutionReport(s.ApplyExecutionReport), nil
+}
+
+// ApplyExecutionReport forwards a decoded execution report to the streams
+// interested in its order. It is a versifi.ExecutionReportHandler.
+func (s *Server) ApplyExecutionReport(detail *versifi.WsExecutionReportDetail) {
+	raw, err := json.Marshal(detail)
+	if err != nil {
+		s.error(err)
+		return
+	}
+	s.forward(executionReport(detail, raw))
+}
+
+// HandleExecutionReport forwards an execution_report message to the
+// streams interested in its order. It is a versifi.WsHandler.
+func (s *Server) HandleExecutionReport(message []byte) {
+	var frame struct {
+		Message json.RawMessage `json:"message"`
+	}
+	var detail versifi.WsExecutionReportDetail
+	if err := json.Unmarshal(message, &frame); err != nil {
+		s.error(err)
+		return
+	}
+	if err := json.Unmarshal(frame.Message, &detail); err != nil {
+		s.error(err)
+		return
+	}
+	s.forward(executionReport(&detail, frame.Message))
+}
+
+// forward queues report on the streams interested in its order
+func (s *Server) forward(report *versifiv1.ExecutionReport) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, id := range s.streamIDsLocked() {
+		st := s.streams[id]
+		if len(st.orders) > 0 {
+			if _, ok := st.orders[report.OrderId]; !ok {
+				continue
+			}
+		}
+		select {
+		case st.reports <- report:
+		default:
+			st.overflowOnce.Do(func() { close(st.overflow) })
+		}
+	}
+}
+
+// CreateBasicOrder places a basic order
+func (s *Server) CreateBasicOrder(ctx context.Context, req *versifiv1.CreateBasicOrderRequest) (*versifiv1.OrderAck, error) {
+	svc := s.c.NewCreateBasicOrderService().
+		Exchange(versifi.ExchangeType(req.GetExchange())).
+		Symbol(req.GetSymbol()).
+		Side(versifi.SideType(req.GetSide())).
+		OrderType(versifi.BasicOrderType(req.GetOrderType())).
+		Quantity(req.GetQuantity())
+	if req.GetClientOrderId() != 0 {
+		svc.ClientOrderID(req.GetClientOrderId())
+	}
+	if req.GetPrice() != "" {
+		svc.Price(req.GetPrice())
+	}
+	if req.GetStopPrice() != "" {
+		svc.StopPrice(req.GetStopPrice())
+	}
+	if req.GetTimeInForce() != "" {
+		svc.TimeInForce(versifi.TimeInForceType(req.GetTimeInForce()))
+	}
+	if req.GetTrailingDelta() != "" {
+		svc.TrailingDelta(req.GetTrailingDelta())
+	}
+	if req.GetStartTime() != 0 {
+		svc.StartTime(time.UnixMilli(req.GetStartTime()))
+	}
+
+	res, err := svc.Do(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return orderAck(res), nil
+}
+
+// CreateAlgoOrder places an algo order
+func (s *Server) CreateAlgoOrder(ctx context.Context, req *versifiv1.CreateAlgoOrderRequest) (*versifiv1.OrderAck, error) {
+	svc := s.c.NewCreateAlgoOrderService().
+		Exchange(versifi.ExchangeType(req.GetExchange())).
+		Symbol(req.GetSymbol()).
+		Side(versifi.SideType(req.GetSide())).
+		OrderType(versifi.AlgoOrderType(req.GetOrderType())).
+		Quantity(req.GetQuantity())
+	if req.GetClientOrderId() != 0 {
+		svc.ClientOrderID(req.GetClientOrderId())
+	}
+	if req.GetParams() != nil {
+		svc.Params(req.GetParams().AsMap())
+	}
+
+	res, err := svc.Do(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return orderAck(res), nil
+}
+
+// CancelOrder requests the cancellation of an order
+func (s *Server) CancelOrder(ctx context.Context, req *versifiv1.CancelOrderRequest) (*versifiv1.CancelOrderResponse, error) {
+	if err := s.c.NewCancelOrderService().OrderID(req.GetOrderId()).Do(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &versifiv1.CancelOrderResponse{}, nil
+}
+
+// GetOrder returns the current state of an order
+func (s *Server) GetOrder(ctx context.Context, req *versifiv1.GetOrderRequest) (*versifiv1.Order, error) {
+	res, err := s.c.NewGetOrderService().OrderID(req.GetOrderId()).Do(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return order(res)
+}
+
+// StreamExecutionReports sends execution reports until the caller goes away
+func (s *Server) StreamExecutionReports(req *versifiv1.StreamExecutionReportsRequest, stream versifiv1.Versifi_StreamExecutionReportsServer) error {
+	buffer := s.StreamBuffer
+	if buffer <= 0 {
+		buffer = defaultStreamBuffer
+	}
+	st := &reportStream{
+		reports:  make(chan *versifiv1.ExecutionReport, buffer),
+		overflow: make(chan struct{}),
+	}
+	if ids := req.GetOrderIds(); len(ids) > 0 {
+		st.orders = make(map[int64]struct{}, len(ids))
+		for _, id := range ids {
+			st.orders[id] = struct{}{}
+		}
+	}
+
+	s.mu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.streams[id] = st
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, id)
+		s.mu.Unlock()
+	}()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case report := <-st.reports:
+			if err := stream.Send(report); err != nil {
+				return err
+			}
+		case <-st.overflow:
+			return status.Error(codes.ResourceExhausted, "execution report stream fell behind")
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+func (s *Server) streamIDsLocked() []int {
+	ids := make([]int, 0, len(s.streams))
+	for id := range s.streams {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *Server) error(err error) {
+	s.mu.RLock()
+	handler := s.errHandler
+	s.mu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// toStatus maps client errors to gRPC status errors
+func toStatus(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	if errors.Is(err, versifi.ErrKillSwitchEngaged) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	var apiErr *versifi.APIError
+	if !errors.As(err, &apiErr) {
+		return status.Error(codes.Unknown, err.Error())
+	}
+	code := codes.Unknown
+	switch {
+	case apiErr.HTTPStatus == http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case apiErr.HTTPStatus == http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case apiErr.HTTPStatus == http.StatusForbidden:
+		code = codes.PermissionDenied
+	case apiErr.HTTPStatus == http.StatusNotFound:
+		code = codes.NotFound
+	case apiErr.HTTPStatus == http.StatusConflict:
+		code = codes.AlreadyExists
+	case apiErr.HTTPStatus == http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case apiErr.HTTPStatus >= http.StatusInternalServerError:
+		code = codes.Unavailable
+	}
+	return status.Error(code, apiErr.Message)
+}
+
+func orderAck(res *versifi.OrderResponse) *versifiv1.OrderAck {
+	return &versifiv1.OrderAck{
+		OrderId:       res.OrderID,
+		ClientOrderId: res.ClientOrderID,
+		Status:        string(res.Status),
+	}
+}
+
+func order(res *versifi.GetOrderResponse) (*versifiv1.Order, error) {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	o := &versifiv1.Order{
+		OrderId:          res.OrderID,
+		ClientOrderId:    res.ClientOrderID,
+		OrderType:        res.OrderType,
+		Status:           string(res.Status),
+		Timestamp:        res.Timestamp.UnixMilli(),
+		RequestOrderType: res.RequestOrderType,
+		Json:             data,
+	}
+
+	var children []versifi.ChildOrder
+	switch {
+	case res.BasicOrder != nil:
+		d := res.BasicOrder
+		o.Exchange, o.Symbol, o.Side = string(d.Exchange), d.Symbol, string(d.Side)
+		o.Quantity, o.Price = d.Quantity, d.Price
+		o.AveragePrice, o.FilledQuantity, o.RejectReason = d.AveragePrice, d.FilledQuantity, d.RejectReason
+		children = d.ChildOrders
+	case res.AlgoOrder != nil:
+		d := res.AlgoOrder
+		o.Exchange, o.Symbol, o.Side = string(d.Exchange), d.Symbol, string(d.Side)
+		o.Quantity = d.Quantity
+		o.AveragePrice, o.FilledQuantity, o.RejectReason = d.AveragePrice, d.FilledQuantity, d.RejectReason
+		children = d.ChildOrders
+	case res.PairOrder != nil:
+		d := res.PairOrder
+		o.RejectReason = d.RejectReason
+		if d.LeadLeg != nil {
+			o.Exchange, o.Symbol = string(d.LeadLeg.Exchange), d.LeadLeg.Symbol
+			children = append(children, d.LeadLeg.ChildOrders...)
+		}
+		if d.Secondary != nil {
+			children = append(children, d.Secondary.ChildOrders...)
+		}
+	}
+
+	for _, child := range children {
+		for _, t := range child.Trades {
+			o.Trades = append(o.Trades, &versifiv1.Trade{
+				TradeId:         t.TradeID,
+				OrderId:         t.OrderID,
+				ChildOrderId:    t.ChildOrderID,
+				ExchangeTradeId: t.ExchangeTradeID,
+				Exchange:        string(t.Exchange),
+				Symbol:          t.Symbol,
+				Side:            string(t.Side),
+				Price:           t.Price,
+				Quantity:        t.Quantity,
+				Fee:             t.Fee,
+				LegId:           t.LegID,
+			})
+		}
+	}
+	return o, nil
+}
+
+func executionReport(d *versifi.WsExecutionReportDetail, raw []byte) *versifiv1.ExecutionReport {
+	r := &versifiv1.ExecutionReport{
+		OrderId:          d.OrderID,
+		ClientOrderId:    d.ClientOrderID,
+		Status:           string(d.Status),
+		Timestamp:        d.Timestamp.UnixMilli(),
+		RequestOrderType: d.RequestOrderType,
+		Json:             raw,
+	}
+
+	var children []*versifi.WsChildOrder
+	switch {
+	case d.Basic != nil:
+		r.Exchange, r.Symbol, r.Side = string(d.Basic.Exchange), d.Basic.Symbol, string(d.Basic.Side)
+		children = append(children, d.Basic.ChildOrder)
+	case d.Algo != nil:
+		r.Exchange, r.Symbol, r.Side = string(d.Algo.Exchange), d.Algo.Symbol, string(d.Algo.Side)
+		children = append(children, d.Algo.ChildOrder)
+	case d.Pair != nil:
+		if d.Pair.LeadLeg != nil {
+			r.Exchange, r.Symbol = string(d.Pair.LeadLeg.Exchange), d.Pair.LeadLeg.Symbol
+			children = append(children, d.Pair.LeadLeg.ChildOrder)
+		}
+		if d.Pair.Leg != nil {
+			children = append(children, d.Pair.Leg.ChildOrder)
+		}
+	}
+
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		for _, t := range child.Trades {
+			trade := &versifiv1.ExecutionTrade{
+				TradeId:                  t.TradeID,
+				OrderId:                  t.OrderID,
+				ExecutedPrice:            t.ExecutedPrice,
+				ExecutedQuantity:         t.ExecutedQuantity,
+				AveragePrice:             t.AveragePrice,
+				CumulativeFilledQuantity: t.CummulativeFilledQuantity,
+			}
+			if t.LegID != nil {
+				trade.LegId = *t.LegID
+			}
+			r.Trades = append(r.Trades, trade)
+		}
+	}
+	return r
+}
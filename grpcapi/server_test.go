@@ -0,0 +1,310 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	versifi "github.com/drinkthere/versifi-go"
+	"github.com/drinkthere/versifi-go/grpcapi/versifiv1"
+	"github.com/drinkthere/versifi-go/versifitest"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) (*Server, versifiv1.VersifiClient) {
+	rest := httptest.NewServer(handler)
+	t.Cleanup(rest.Close)
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = rest.URL
+	srv := NewServer(client)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	versifiv1.RegisterVersifiServer(gs, srv)
+	go gs.Serve(l)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return srv, versifiv1.NewVersifiClient(conn)
+}
+
+func TestServerOrders(t *testing.T) {
+	var basic versifi.BasicOrderRequest
+	var algo map[string]interface{}
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/orders/basic/":
+			json.NewDecoder(r.Body).Decode(&basic)
+			json.NewEncoder(w).Encode(versifi.OrderResponse{OrderID: 100, ClientOrderID: 7, Status: versifi.OrderStatusNew})
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/orders/algo/":
+			json.NewDecoder(r.Body).Decode(&algo)
+			json.NewEncoder(w).Encode(versifi.OrderResponse{OrderID: 101, Status: versifi.OrderStatusNew})
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/orders/100":
+			json.NewEncoder(w).Encode(versifi.GetOrderResponse{
+				OrderID:          100,
+				ClientOrderID:    7,
+				Status:           versifi.OrderStatusFilled,
+				RequestOrderType: "BASIC",
+				BasicOrder: &versifi.BasicOrderDetail{
+					Exchange:       versifi.ExchangeBinanceSpot,
+					Symbol:         "BTC/USDT",
+					Side:           versifi.SideTypeBuy,
+					Quantity:       "2",
+					FilledQuantity: "2",
+					ChildOrders: []versifi.ChildOrder{{Trades: []versifi.Trade{
+						{TradeID: 1, Price: "100", Quantity: "2", Fee: "0.1"},
+					}}},
+				},
+			})
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/orders/100":
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/v2/orders/404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"order not found"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	ctx := context.Background()
+
+	ack, err := client.CreateBasicOrder(ctx, &versifiv1.CreateBasicOrderRequest{
+		ClientOrderId: 7,
+		Exchange:      "BINANCE_SPOT",
+		Symbol:        "BTC/USDT",
+		Side:          "BUY",
+		OrderType:     "LIMIT",
+		Quantity:      "2",
+		Price:         "100",
+		TimeInForce:   "GTC",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ack.OrderId != 100 || ack.ClientOrderId != 7 || ack.Status != "NEW" {
+		t.Errorf("Unexpected ack %v", ack)
+	}
+	if basic.Symbol != "BTC/USDT" || basic.Price == nil || *basic.Price != "100" || basic.StopPrice != nil {
+		t.Errorf("Unexpected basic order %+v", basic)
+	}
+
+	params, _ := structpb.NewStruct(map[string]interface{}{"duration": 600})
+	if _, err := client.CreateAlgoOrder(ctx, &versifiv1.CreateAlgoOrderRequest{
+		Exchange:  "BINANCE_SPOT",
+		Symbol:    "BTC/USDT",
+		Side:      "SELL",
+		OrderType: "TWAP",
+		Quantity:  "1",
+		Params:    params,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := algo["params"].(map[string]interface{}); p["duration"] != float64(600) {
+		t.Errorf("Unexpected algo order %v", algo)
+	}
+
+	o, err := client.GetOrder(ctx, &versifiv1.GetOrderRequest{OrderId: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != "FILLED" || o.Symbol != "BTC/USDT" || o.FilledQuantity != "2" || len(o.Trades) != 1 || o.Trades[0].Fee != "0.1" {
+		t.Errorf("Unexpected order %v", o)
+	}
+	var raw versifi.GetOrderResponse
+	if err := json.Unmarshal(o.Json, &raw); err != nil || raw.OrderID != 100 {
+		t.Errorf("Unexpected order JSON %s", o.Json)
+	}
+
+	if _, err := client.CancelOrder(ctx, &versifiv1.CancelOrderRequest{OrderId: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetOrder(ctx, &versifiv1.GetOrderRequest{OrderId: 404})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound || st.Message() != "order not found" {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
+
+func TestServerStreamExecutionReports(t *testing.T) {
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamExecutionReports(ctx, &versifiv1.StreamExecutionReportsRequest{OrderIds: []int64{100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The stream is registered once the server handler runs
+	for {
+		srv.mu.RLock()
+		n := len(srv.streams)
+		srv.mu.RUnlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	report := func(orderID int64, status versifi.OrderStatusType) []byte {
+		data, err := json.Marshal(map[string]interface{}{
+			"op":      "execution_report",
+			"success": true,
+			"message": versifi.WsExecutionReportDetail{
+				OrderID:          orderID,
+				Status:           status,
+				RequestOrderType: "BASIC",
+				Basic: &versifi.WsBasicOrderDetail{
+					Symbol:     "BTC/USDT",
+					Exchange:   versifi.ExchangeBinanceSpot,
+					Side:       versifi.SideTypeBuy,
+					ChildOrder: &versifi.WsChildOrder{ID: 1, Trades: []versifi.WsTrade{{TradeID: 5, ExecutedPrice: "100", ExecutedQuantity: "1"}}},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	// Reports for other orders are filtered out
+	srv.HandleExecutionReport(report(200, versifi.OrderStatusNew))
+	srv.HandleExecutionReport(report(100, versifi.OrderStatusPartiallyFilled))
+
+	r, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.OrderId != 100 || r.Status != "PARTIALLY_FILLED" || r.Symbol != "BTC/USDT" || len(r.Trades) != 1 || r.Trades[0].ExecutedQuantity != "1" {
+		t.Errorf("Unexpected report %v", r)
+	}
+	if len(r.Json) == 0 {
+		t.Error("Expected the raw report")
+	}
+
+	var errs []error
+	srv.SetErrorHandler(func(err error) { errs = append(errs, err) })
+	srv.HandleExecutionReport([]byte("not json"))
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 error, got %v", errs)
+	}
+}
+
+func TestServerAttach(t *testing.T) {
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	server := versifitest.NewWsServer("test-key", "test-secret")
+	defer server.Close()
+	ws := versifi.NewWsClient("test-key", "test-secret")
+	ws.BaseURL = server.URL
+	ws.Logger = log.New(io.Discard, "", 0)
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Disconnect()
+
+	// A handler set before Attach keeps its messages
+	reports := make(chan struct{}, 2)
+	if err := ws.SubscribeExecutionReport(func([]byte) { reports <- struct{}{} }); err != nil {
+		t.Fatal(err)
+	}
+	unsubscribe, err := srv.Attach(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.WaitForSubscription("execution_report", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := client.StreamExecutionReports(ctx, &versifiv1.StreamExecutionReportsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		srv.mu.RLock()
+		n := len(srv.streams)
+		srv.mu.RUnlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := server.SendExecutionReport(versifi.WsExecutionReportDetail{OrderID: 100, Status: versifi.OrderStatusNew}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.OrderId != 100 || r.Status != "NEW" || len(r.Json) == 0 {
+		t.Errorf("Unexpected report %v", r)
+	}
+	waitReport := func() {
+		t.Helper()
+		select {
+		case <-reports:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the client's handler to keep receiving reports")
+		}
+	}
+	waitReport()
+
+	// Reports dispatched after unsubscribing are not forwarded
+	unsubscribe()
+	if _, err := server.SendExecutionReport(versifi.WsExecutionReportDetail{OrderID: 101, Status: versifi.OrderStatusNew}); err != nil {
+		t.Fatal(err)
+	}
+	waitReport()
+	srv.mu.RLock()
+	defer srv.mu.RUnlock()
+	for _, st := range srv.streams {
+		if len(st.reports) != 0 {
+			t.Errorf("Expected no report after unsubscribing, got %d", len(st.reports))
+		}
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{&versifi.APIError{HTTPStatus: http.StatusBadRequest}, codes.InvalidArgument},
+		{&versifi.APIError{HTTPStatus: http.StatusUnauthorized}, codes.Unauthenticated},
+		{&versifi.APIError{HTTPStatus: http.StatusTooManyRequests}, codes.ResourceExhausted},
+		{&versifi.APIError{HTTPStatus: http.StatusBadGateway}, codes.Unavailable},
+		{versifi.ErrKillSwitchEngaged, codes.FailedPrecondition},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{net.ErrClosed, codes.Unknown},
+	}
+	for _, tt := range tests {
+		if code := status.Code(toStatus(tt.err)); code != tt.code {
+			t.Errorf("toStatus(%v) = %v, want %v", tt.err, code, tt.code)
+		}
+	}
+}
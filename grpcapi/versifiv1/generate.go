@@ -0,0 +1,5 @@
+// Package versifiv1 holds the generated protobuf and gRPC code for the
+// versifi.v1 service. Edit versifi.proto and run go generate to update it.
+package versifiv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative versifi.proto
@@ -0,0 +1,293 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: versifi.proto
+
+// Versifi order entry and execution reports, served by the grpcapi package.
+// Quantities and prices are decimal strings; enums such as side, status and
+// exchange use the Versifi API names (BUY, FILLED, BINANCE_SPOT).
+
+package versifiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Versifi_CreateBasicOrder_FullMethodName       = "/versifi.v1.Versifi/CreateBasicOrder"
+	Versifi_CreateAlgoOrder_FullMethodName        = "/versifi.v1.Versifi/CreateAlgoOrder"
+	Versifi_CancelOrder_FullMethodName            = "/versifi.v1.Versifi/CancelOrder"
+	Versifi_GetOrder_FullMethodName               = "/versifi.v1.Versifi/GetOrder"
+	Versifi_StreamExecutionReports_FullMethodName = "/versifi.v1.Versifi/StreamExecutionReports"
+)
+
+// VersifiClient is the client API for Versifi service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VersifiClient interface {
+	// CreateBasicOrder places a MARKET, LIMIT, STOP or similar order
+	CreateBasicOrder(ctx context.Context, in *CreateBasicOrderRequest, opts ...grpc.CallOption) (*OrderAck, error)
+	// CreateAlgoOrder places a TWAP, VWAP or IS order
+	CreateAlgoOrder(ctx context.Context, in *CreateAlgoOrderRequest, opts ...grpc.CallOption) (*OrderAck, error)
+	// CancelOrder requests the cancellation of an order. The final status
+	// arrives as an execution report.
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+	// GetOrder returns the current state of an order
+	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	// StreamExecutionReports streams execution reports as they arrive
+	StreamExecutionReports(ctx context.Context, in *StreamExecutionReportsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecutionReport], error)
+}
+
+type versifiClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVersifiClient(cc grpc.ClientConnInterface) VersifiClient {
+	return &versifiClient{cc}
+}
+
+func (c *versifiClient) CreateBasicOrder(ctx context.Context, in *CreateBasicOrderRequest, opts ...grpc.CallOption) (*OrderAck, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderAck)
+	err := c.cc.Invoke(ctx, Versifi_CreateBasicOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *versifiClient) CreateAlgoOrder(ctx context.Context, in *CreateAlgoOrderRequest, opts ...grpc.CallOption) (*OrderAck, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderAck)
+	err := c.cc.Invoke(ctx, Versifi_CreateAlgoOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *versifiClient) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelOrderResponse)
+	err := c.cc.Invoke(ctx, Versifi_CancelOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *versifiClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, Versifi_GetOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *versifiClient) StreamExecutionReports(ctx context.Context, in *StreamExecutionReportsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecutionReport], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Versifi_ServiceDesc.Streams[0], Versifi_StreamExecutionReports_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamExecutionReportsRequest, ExecutionReport]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Versifi_StreamExecutionReportsClient = grpc.ServerStreamingClient[ExecutionReport]
+
+// VersifiServer is the server API for Versifi service.
+// All implementations must embed UnimplementedVersifiServer
+// for forward compatibility.
+type VersifiServer interface {
+	// CreateBasicOrder places a MARKET, LIMIT, STOP or similar order
+	CreateBasicOrder(context.Context, *CreateBasicOrderRequest) (*OrderAck, error)
+	// CreateAlgoOrder places a TWAP, VWAP or IS order
+	CreateAlgoOrder(context.Context, *CreateAlgoOrderRequest) (*OrderAck, error)
+	// CancelOrder requests the cancellation of an order. The final status
+	// arrives as an execution report.
+	CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error)
+	// GetOrder returns the current state of an order
+	GetOrder(context.Context, *GetOrderRequest) (*Order, error)
+	// StreamExecutionReports streams execution reports as they arrive
+	StreamExecutionReports(*StreamExecutionReportsRequest, grpc.ServerStreamingServer[ExecutionReport]) error
+	mustEmbedUnimplementedVersifiServer()
+}
+
+// UnimplementedVersifiServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVersifiServer struct{}
+
+func (UnimplementedVersifiServer) CreateBasicOrder(context.Context, *CreateBasicOrderRequest) (*OrderAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBasicOrder not implemented")
+}
+func (UnimplementedVersifiServer) CreateAlgoOrder(context.Context, *CreateAlgoOrderRequest) (*OrderAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAlgoOrder not implemented")
+}
+func (UnimplementedVersifiServer) CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedVersifiServer) GetOrder(context.Context, *GetOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedVersifiServer) StreamExecutionReports(*StreamExecutionReportsRequest, grpc.ServerStreamingServer[ExecutionReport]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamExecutionReports not implemented")
+}
+func (UnimplementedVersifiServer) mustEmbedUnimplementedVersifiServer() {}
+func (UnimplementedVersifiServer) testEmbeddedByValue()                 {}
+
+// UnsafeVersifiServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VersifiServer will
+// result in compilation errors.
+type UnsafeVersifiServer interface {
+	mustEmbedUnimplementedVersifiServer()
+}
+
+func RegisterVersifiServer(s grpc.ServiceRegistrar, srv VersifiServer) {
+	// If the following call pancis, it indicates UnimplementedVersifiServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Versifi_ServiceDesc, srv)
+}
+
+func _Versifi_CreateBasicOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBasicOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VersifiServer).CreateBasicOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Versifi_CreateBasicOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VersifiServer).CreateBasicOrder(ctx, req.(*CreateBasicOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Versifi_CreateAlgoOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAlgoOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VersifiServer).CreateAlgoOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Versifi_CreateAlgoOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VersifiServer).CreateAlgoOrder(ctx, req.(*CreateAlgoOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Versifi_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VersifiServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Versifi_CancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VersifiServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Versifi_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VersifiServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Versifi_GetOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VersifiServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Versifi_StreamExecutionReports_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamExecutionReportsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VersifiServer).StreamExecutionReports(m, &grpc.GenericServerStream[StreamExecutionReportsRequest, ExecutionReport]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Versifi_StreamExecutionReportsServer = grpc.ServerStreamingServer[ExecutionReport]
+
+// Versifi_ServiceDesc is the grpc.ServiceDesc for Versifi service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Versifi_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "versifi.v1.Versifi",
+	HandlerType: (*VersifiServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateBasicOrder",
+			Handler:    _Versifi_CreateBasicOrder_Handler,
+		},
+		{
+			MethodName: "CreateAlgoOrder",
+			Handler:    _Versifi_CreateAlgoOrder_Handler,
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _Versifi_CancelOrder_Handler,
+		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _Versifi_GetOrder_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamExecutionReports",
+			Handler:       _Versifi_StreamExecutionReports_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "versifi.proto",
+}
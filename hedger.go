@@ -0,0 +1,325 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const defaultHedgeRetryInterval = time.Second
+
+// HedgerConfig describes the lead instrument whose fills are hedged and the
+// instrument the offsetting orders are placed on
+type HedgerConfig struct {
+	LeadExchange  ExchangeType
+	LeadSymbol    string
+	HedgeExchange ExchangeType
+	HedgeSymbol   string
+	// Ratio is the hedge quantity per unit of lead quantity filled,
+	// default 1
+	Ratio string
+	// Tolerance is the unhedged quantity, in hedge units, left open
+	// before a hedge order is sent. Smaller imbalances accumulate.
+	Tolerance string
+	// QuantityPrecision is the number of decimal places of hedge
+	// quantities, default 8. The truncated remainder stays unhedged.
+	QuantityPrecision int32
+	// RetryInterval is the delay before a failed hedge is sent again,
+	// default 1s
+	RetryInterval time.Duration
+	// Tracker, when set, submits the hedge orders through it
+	Tracker *OrderTracker
+}
+
+// Hedge reports a hedge order sent by a Hedger, or the error that stopped
+// it from being placed
+type Hedge struct {
+	Side     SideType `json:"side"`
+	Quantity string   `json:"quantity"`
+	OrderID  int64    `json:"order_id,omitempty"`
+	Unhedged string   `json:"unhedged"` // Signed quantity still to hedge, positive to buy
+	Err      error    `json:"-"`
+}
+
+// HedgeHandler handles hedge orders
+type HedgeHandler func(hedge Hedge)
+
+// Hedger offsets fills of the lead instrument with MARKET orders on the
+// hedge instrument, for example hedging spot fills on a perpetual.
+//
+//	hedger, err := client.NewHedger(versifi.HedgerConfig{...})
+//	wsClient.SubscribeExecutionReport(hedger.HandleExecutionReport)
+//	go hedger.Run(ctx)
+//
+// Buying the lead sells the hedge and the other way around. Trades of pair
+// orders carry no side and are ignored.
+type Hedger struct {
+	c         *Client
+	cfg       HedgerConfig
+	ratio     decimal.Decimal
+	tolerance decimal.Decimal
+	signal    chan struct{}
+	flushMu   sync.Mutex
+
+	mu         sync.Mutex
+	unhedged   decimal.Decimal
+	trades     map[int64]struct{}
+	tradeOrder []int64
+	handlers   map[int]HedgeHandler
+	nextID     int
+	errHandler ErrHandler
+}
+
+// NewHedger validates cfg and creates a Hedger. Fills are recorded as soon
+// as they are handled, hedge orders are placed by Run or Flush.
+func (c *Client) NewHedger(cfg HedgerConfig) (*Hedger, error) {
+	if cfg.Ratio == "" {
+		cfg.Ratio = "1"
+	}
+	if cfg.QuantityPrecision <= 0 {
+		cfg.QuantityPrecision = defaultTWAPQuantityPrecision
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defaultHedgeRetryInterval
+	}
+
+	switch {
+	case cfg.LeadExchange == "" || cfg.LeadSymbol == "":
+		return nil, errors.New("hedger: lead exchange and symbol are required")
+	case cfg.HedgeExchange == "" || cfg.HedgeSymbol == "":
+		return nil, errors.New("hedger: hedge exchange and symbol are required")
+	case cfg.LeadExchange == cfg.HedgeExchange && cfg.LeadSymbol == cfg.HedgeSymbol:
+		return nil, errors.New("hedger: lead and hedge are the same instrument")
+	}
+
+	ratio, err := decimal.NewFromString(cfg.Ratio)
+	if err != nil || !ratio.IsPositive() {
+		return nil, fmt.Errorf("hedger: invalid ratio %q", cfg.Ratio)
+	}
+	var tolerance decimal.Decimal
+	if cfg.Tolerance != "" {
+		tolerance, err = decimal.NewFromString(cfg.Tolerance)
+		if err != nil || tolerance.IsNegative() {
+			return nil, fmt.Errorf("hedger: invalid tolerance %q", cfg.Tolerance)
+		}
+	}
+
+	return &Hedger{
+		c:         c,
+		cfg:       cfg,
+		ratio:     ratio,
+		tolerance: tolerance,
+		signal:    make(chan struct{}, 1),
+		trades:    make(map[int64]struct{}),
+		handlers:  make(map[int]HedgeHandler),
+	}, nil
+}
+
+// SetErrorHandler sets the handler for undecodable execution reports
+func (h *Hedger) SetErrorHandler(handler ErrHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errHandler = handler
+}
+
+// Subscribe registers handler for hedge orders and returns a function that
+// removes it
+func (h *Hedger) Subscribe(handler HedgeHandler) (unsubscribe func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id := h.nextID
+	h.nextID++
+	h.handlers[id] = handler
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.handlers, id)
+	}
+}
+
+// Unhedged returns the signed hedge quantity not yet ordered, positive when
+// the hedge has to be bought
+func (h *Hedger) Unhedged() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.unhedged.String()
+}
+
+// HandleExecutionReport records the lead fills in an execution_report
+// message
+func (h *Hedger) HandleExecutionReport(message []byte) {
+	var report WsExecutionReport
+	if err := json.Unmarshal(message, &report); err != nil {
+		h.mu.Lock()
+		handler := h.errHandler
+		h.mu.Unlock()
+		if handler != nil {
+			handler(err)
+		}
+		return
+	}
+	h.ApplyExecutionReport(&report.Message)
+}
+
+// ApplyExecutionReport records the lead fills in a decoded execution report.
+// Reports for other instruments and trades already applied are skipped.
+func (h *Hedger) ApplyExecutionReport(detail *WsExecutionReportDetail) {
+	var exchange ExchangeType
+	var symbol string
+	var side SideType
+	var child *WsChildOrder
+
+	switch {
+	case detail.Basic != nil:
+		exchange, symbol, side, child = detail.Basic.Exchange, detail.Basic.Symbol, detail.Basic.Side, detail.Basic.ChildOrder
+	case detail.Algo != nil:
+		exchange, symbol, side, child = detail.Algo.Exchange, detail.Algo.Symbol, detail.Algo.Side, detail.Algo.ChildOrder
+	default:
+		return
+	}
+	if child == nil || exchange != h.cfg.LeadExchange || symbol != h.cfg.LeadSymbol {
+		return
+	}
+
+	for _, trade := range child.Trades {
+		h.ApplyFill(side, trade.TradeID, trade.ExecutedQuantity)
+	}
+}
+
+// ApplyFill records a fill of the lead instrument. A non-zero tradeID that
+// was already applied is skipped.
+func (h *Hedger) ApplyFill(side SideType, tradeID int64, quantity string) {
+	qty := toDecimal(quantity)
+	if qty.IsZero() {
+		return
+	}
+	// Buying the lead is hedged by selling
+	if side == SideTypeBuy {
+		qty = qty.Neg()
+	}
+
+	h.mu.Lock()
+	if tradeID != 0 {
+		if _, ok := h.trades[tradeID]; ok {
+			h.mu.Unlock()
+			return
+		}
+		h.rememberTradeLocked(tradeID)
+	}
+	h.unhedged = h.unhedged.Add(qty.Mul(h.ratio))
+	h.mu.Unlock()
+
+	select {
+	case h.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Run places hedge orders as fills are recorded until ctx is done. Failed
+// hedges are reported to the subscribers and retried after RetryInterval.
+func (h *Hedger) Run(ctx context.Context, opts ...RequestOption) error {
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-h.signal:
+		case <-retry:
+		}
+
+		retry = nil
+		if err := h.Flush(ctx, opts...); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			retry = time.After(h.cfg.RetryInterval)
+		}
+	}
+}
+
+// Flush places a hedge order for the unhedged quantity if it exceeds the
+// tolerance
+func (h *Hedger) Flush(ctx context.Context, opts ...RequestOption) error {
+	h.flushMu.Lock()
+	defer h.flushMu.Unlock()
+
+	h.mu.Lock()
+	quantity := h.unhedged.Truncate(h.cfg.QuantityPrecision)
+	h.mu.Unlock()
+	if quantity.IsZero() || quantity.Abs().LessThanOrEqual(h.tolerance) {
+		return nil
+	}
+
+	side := SideTypeBuy
+	if quantity.IsNegative() {
+		side = SideTypeSell
+	}
+	res, err := h.place(ctx, side, quantity.Abs(), opts...)
+
+	h.mu.Lock()
+	if err == nil {
+		h.unhedged = h.unhedged.Sub(quantity)
+	}
+	hedge := Hedge{
+		Side:     side,
+		Quantity: quantity.Abs().String(),
+		Unhedged: h.unhedged.String(),
+		Err:      err,
+	}
+	if res != nil {
+		hedge.OrderID = res.OrderID
+	}
+	handlers := h.handlersLocked()
+	h.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(hedge)
+	}
+	if err != nil {
+		return fmt.Errorf("hedge %s %s: %w", side, hedge.Quantity, err)
+	}
+	return nil
+}
+
+func (h *Hedger) place(ctx context.Context, side SideType, quantity decimal.Decimal, opts ...RequestOption) (*OrderResponse, error) {
+	order := h.c.NewCreateBasicOrderService().
+		Exchange(h.cfg.HedgeExchange).
+		Symbol(h.cfg.HedgeSymbol).
+		Side(side).
+		OrderType(BasicOrderTypeMarket).
+		QuantityDecimal(quantity)
+
+	if h.cfg.Tracker != nil {
+		return h.cfg.Tracker.Submit(ctx, order, opts...)
+	}
+	return order.Do(ctx, opts...)
+}
+
+func (h *Hedger) rememberTradeLocked(tradeID int64) {
+	h.trades[tradeID] = struct{}{}
+	h.tradeOrder = append(h.tradeOrder, tradeID)
+	if len(h.tradeOrder) > tradeHistorySize {
+		delete(h.trades, h.tradeOrder[0])
+		h.tradeOrder = h.tradeOrder[1:]
+	}
+}
+
+func (h *Hedger) handlersLocked() []HedgeHandler {
+	ids := make([]int, 0, len(h.handlers))
+	for id := range h.handlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	handlers := make([]HedgeHandler, len(ids))
+	for i, id := range ids {
+		handlers[i] = h.handlers[id]
+	}
+	return handlers
+}
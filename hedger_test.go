@@ -0,0 +1,168 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHedger(t *testing.T) {
+	var mu sync.Mutex
+	var orders []BasicOrderRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body BasicOrderRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		orders = append(orders, body)
+		id := int64(len(orders))
+		mu.Unlock()
+		json.NewEncoder(w).Encode(OrderResponse{OrderID: id, Status: OrderStatusNew})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	if _, err := client.NewHedger(HedgerConfig{
+		LeadExchange: ExchangeBinanceSpot, LeadSymbol: "BTC/USDT",
+		HedgeExchange: ExchangeBinanceSpot, HedgeSymbol: "BTC/USDT",
+	}); err == nil {
+		t.Error("Expected error for hedging an instrument with itself")
+	}
+
+	hedger, err := client.NewHedger(HedgerConfig{
+		LeadExchange:  ExchangeBinanceSpot,
+		LeadSymbol:    "BTC/USDT",
+		HedgeExchange: ExchangeBinanceFutures,
+		HedgeSymbol:   "BTC/USDT",
+		Ratio:         "0.5",
+		Tolerance:     "0.01",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var hedges []Hedge
+	hedger.Subscribe(func(h Hedge) { hedges = append(hedges, h) })
+
+	report := func(exchange ExchangeType, side SideType, trades ...WsTrade) *WsExecutionReportDetail {
+		return &WsExecutionReportDetail{
+			RequestOrderType: "basic",
+			Basic: &WsBasicOrderDetail{
+				Symbol:     "BTC/USDT",
+				Exchange:   exchange,
+				Side:       side,
+				ChildOrder: &WsChildOrder{ID: 1, Trades: trades},
+			},
+		}
+	}
+	ctx := context.Background()
+
+	// Within tolerance
+	hedger.ApplyExecutionReport(report(ExchangeBinanceSpot, SideTypeBuy, WsTrade{TradeID: 1, ExecutedQuantity: "0.01"}))
+	if err := hedger.Flush(ctx); err != nil || len(orders) != 0 {
+		t.Fatalf("Expected no hedge within tolerance, got %v %+v", err, orders)
+	}
+
+	hedger.ApplyExecutionReport(report(ExchangeBinanceSpot, SideTypeBuy, WsTrade{TradeID: 2, ExecutedQuantity: "1"}))
+	// Repeated trades and fills of the hedge instrument are skipped
+	hedger.ApplyExecutionReport(report(ExchangeBinanceSpot, SideTypeBuy, WsTrade{TradeID: 2, ExecutedQuantity: "1"}))
+	hedger.ApplyExecutionReport(report(ExchangeBinanceFutures, SideTypeSell, WsTrade{TradeID: 3, ExecutedQuantity: "1"}))
+	if got := hedger.Unhedged(); got != "-0.505" {
+		t.Errorf("Expected -0.505 unhedged, got %s", got)
+	}
+	if err := hedger.Flush(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(orders) != 1 || orders[0].Exchange != ExchangeBinanceFutures || orders[0].Side != SideTypeSell ||
+		orders[0].Quantity != "0.505" || orders[0].OrderType != BasicOrderTypeMarket {
+		t.Fatalf("Unexpected hedge orders %+v", orders)
+	}
+	if len(hedges) != 1 || hedges[0].OrderID != 1 || hedges[0].Unhedged != "0" {
+		t.Errorf("Unexpected hedges %+v", hedges)
+	}
+
+	// Run hedges as fills arrive
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- hedger.Run(ctx) }()
+
+	message, _ := json.Marshal(WsExecutionReport{
+		Op:      "execution_report",
+		Message: *report(ExchangeBinanceSpot, SideTypeSell, WsTrade{TradeID: 4, ExecutedQuantity: "0.4"}),
+	})
+	hedger.HandleExecutionReport(message)
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(orders) == 2
+	})
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if orders[1].Side != SideTypeBuy || orders[1].Quantity != "0.2" {
+		t.Errorf("Unexpected hedge order %+v", orders[1])
+	}
+}
+
+func TestHedgerRetry(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(OrderResponse{OrderID: 7, Status: OrderStatusNew})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	hedger, err := client.NewHedger(HedgerConfig{
+		LeadExchange:  ExchangeOKXSpot,
+		LeadSymbol:    "ETH/USDT",
+		HedgeExchange: ExchangeOKXFutures,
+		HedgeSymbol:   "ETH/USDT",
+		RetryInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var hedgesMu sync.Mutex
+	var hedges []Hedge
+	hedger.Subscribe(func(h Hedge) {
+		hedgesMu.Lock()
+		hedges = append(hedges, h)
+		hedgesMu.Unlock()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hedger.Run(ctx)
+
+	hedger.ApplyFill(SideTypeSell, 1, "2")
+	waitFor(t, func() bool {
+		hedgesMu.Lock()
+		defer hedgesMu.Unlock()
+		return len(hedges) == 2
+	})
+
+	hedgesMu.Lock()
+	defer hedgesMu.Unlock()
+	if hedger.Unhedged() != "0" || hedges[0].Err == nil || hedges[0].Unhedged != "2" || hedges[1].OrderID != 7 {
+		t.Errorf("Expected a failed hedge then a retry, got %+v", hedges)
+	}
+}
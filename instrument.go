@@ -0,0 +1,118 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrUnknownInstrument is returned by an InstrumentSource with no
+	// metadata for the requested symbol
+	ErrUnknownInstrument = errors.New("unknown instrument")
+	// ErrNoInstrumentSource is returned by AutoRound orders when
+	// Client.Instruments is not set
+	ErrNoInstrumentSource = errors.New("no instrument source")
+	// ErrBelowMinQuantity is returned for orders smaller than the instrument's minimum quantity
+	ErrBelowMinQuantity = errors.New("quantity below minimum")
+	// ErrBelowMinNotional is returned for orders smaller than the instrument's minimum notional
+	ErrBelowMinNotional = errors.New("notional below minimum")
+)
+
+// Instrument describes the trading rules of a symbol on an exchange.
+// Empty fields are not enforced.
+type Instrument struct {
+	Exchange    ExchangeType `json:"exchange"`
+	Symbol      string       `json:"symbol"`
+	TickSize    string       `json:"tick_size"`    // Price increment
+	LotSize     string       `json:"lot_size"`     // Quantity increment
+	MinQuantity string       `json:"min_quantity"` // Smallest order quantity
+	MinNotional string       `json:"min_notional"` // Smallest price * quantity
+}
+
+// InstrumentSource loads instrument metadata, for example from an exchange
+// info endpoint, for order rounding
+type InstrumentSource interface {
+	Instrument(ctx context.Context, exchange ExchangeType, symbol string) (*Instrument, error)
+}
+
+// InstrumentSourceFunc adapts a function to InstrumentSource
+type InstrumentSourceFunc func(ctx context.Context, exchange ExchangeType, symbol string) (*Instrument, error)
+
+// Instrument calls f(ctx, exchange, symbol)
+func (f InstrumentSourceFunc) Instrument(ctx context.Context, exchange ExchangeType, symbol string) (*Instrument, error) {
+	return f(ctx, exchange, symbol)
+}
+
+// StaticInstruments is an InstrumentSource backed by a fixed list
+type StaticInstruments []Instrument
+
+// Instrument returns the matching instrument or ErrUnknownInstrument
+func (s StaticInstruments) Instrument(ctx context.Context, exchange ExchangeType, symbol string) (*Instrument, error) {
+	for i := range s {
+		if s[i].Exchange == exchange && s[i].Symbol == symbol {
+			inst := s[i]
+			return &inst, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrUnknownInstrument, exchange, symbol)
+}
+
+// RoundPrice rounds price to the tick size, down for buys and up for sells
+// so the rounded price is never more aggressive than the one requested
+func (i Instrument) RoundPrice(price decimal.Decimal, side SideType) decimal.Decimal {
+	return roundToStep(price, toDecimal(i.TickSize), side == SideTypeSell)
+}
+
+// RoundQuantity rounds quantity down to the lot size
+func (i Instrument) RoundQuantity(quantity decimal.Decimal) decimal.Decimal {
+	return roundToStep(quantity, toDecimal(i.LotSize), false)
+}
+
+// Validate checks quantity against the minimum quantity and, when price is
+// not zero, price * quantity against the minimum notional
+func (i Instrument) Validate(price, quantity decimal.Decimal) error {
+	if min := toDecimal(i.MinQuantity); quantity.LessThan(min) {
+		return fmt.Errorf("%w: %s %s quantity %s < %s", ErrBelowMinQuantity, i.Exchange, i.Symbol, quantity, min)
+	}
+	if min := toDecimal(i.MinNotional); !price.IsZero() && price.Mul(quantity).LessThan(min) {
+		return fmt.Errorf("%w: %s %s notional %s < %s", ErrBelowMinNotional, i.Exchange, i.Symbol, price.Mul(quantity), min)
+	}
+	return nil
+}
+
+// roundToStep rounds v to a multiple of step, up or down. A zero step
+// leaves v unchanged.
+func roundToStep(v, step decimal.Decimal, up bool) decimal.Decimal {
+	if !step.IsPositive() {
+		return v
+	}
+	rem := v.Mod(step)
+	if rem.IsNegative() {
+		rem = rem.Add(step)
+	}
+	down := v.Sub(rem)
+	if up && !rem.IsZero() {
+		return down.Add(step)
+	}
+	return down
+}
+
+// checkMinimums validates an AutoRound order against inst unless
+// RelaxedValidation is set
+func (c *Client) checkMinimums(inst *Instrument, price, quantity decimal.Decimal) error {
+	if c.RelaxedValidation {
+		return nil
+	}
+	return inst.Validate(price, quantity)
+}
+
+// instrument loads metadata for an AutoRound order
+func (c *Client) instrument(ctx context.Context, exchange ExchangeType, symbol string) (*Instrument, error) {
+	if c.Instruments == nil {
+		return nil, ErrNoInstrumentSource
+	}
+	return c.Instruments.Instrument(ctx, exchange, symbol)
+}
@@ -0,0 +1,116 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestInstrumentRounding(t *testing.T) {
+	inst := Instrument{TickSize: "0.05", LotSize: "0.001", MinQuantity: "0.01", MinNotional: "10"}
+
+	tests := []struct {
+		price string
+		side  SideType
+		want  string
+	}{
+		{"100.07", SideTypeBuy, "100.05"},
+		{"100.07", SideTypeSell, "100.1"},
+		{"100.05", SideTypeSell, "100.05"},
+	}
+	for _, tt := range tests {
+		if got := inst.RoundPrice(decimal.RequireFromString(tt.price), tt.side).String(); got != tt.want {
+			t.Errorf("RoundPrice(%s, %s) = %s, want %s", tt.price, tt.side, got, tt.want)
+		}
+	}
+	if got := inst.RoundQuantity(decimal.RequireFromString("0.12345")).String(); got != "0.123" {
+		t.Errorf("RoundQuantity = %s, want 0.123", got)
+	}
+
+	if err := inst.Validate(decimal.NewFromInt(100), decimal.RequireFromString("0.005")); !errors.Is(err, ErrBelowMinQuantity) {
+		t.Errorf("Expected ErrBelowMinQuantity, got %v", err)
+	}
+	if err := inst.Validate(decimal.NewFromInt(100), decimal.RequireFromString("0.05")); !errors.Is(err, ErrBelowMinNotional) {
+		t.Errorf("Expected ErrBelowMinNotional, got %v", err)
+	}
+	if err := inst.Validate(decimal.NewFromInt(100), decimal.RequireFromString("0.1")); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestAutoRound(t *testing.T) {
+	var body BasicOrderRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(OrderResponse{OrderID: 1, Status: OrderStatusNew})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	_, err := client.NewCreateBasicOrderService().
+		Exchange(ExchangeBinanceSpot).
+		Symbol("BTC/USDT").
+		Side(SideTypeBuy).
+		OrderType(BasicOrderTypeLimit).
+		Price("50000.123").
+		Quantity("0.0123456").
+		AutoRound().
+		Do(context.Background())
+	if !errors.Is(err, ErrNoInstrumentSource) {
+		t.Fatalf("Expected ErrNoInstrumentSource, got %v", err)
+	}
+
+	client.Instruments = StaticInstruments{
+		{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", TickSize: "0.01", LotSize: "0.00001", MinNotional: "5"},
+	}
+	_, err = client.NewCreateBasicOrderService().
+		Exchange(ExchangeBinanceSpot).
+		Symbol("BTC/USDT").
+		Side(SideTypeBuy).
+		OrderType(BasicOrderTypeLimit).
+		Price("50000.123").
+		Quantity("0.0123456").
+		AutoRound().
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if body.Price == nil || *body.Price != "50000.12" || body.Quantity != "0.01234" {
+		t.Errorf("Expected rounded price and quantity, got %v %s", body.Price, body.Quantity)
+	}
+
+	_, err = client.NewCreateBasicOrderService().
+		Exchange(ExchangeBinanceSpot).
+		Symbol("BTC/USDT").
+		Side(SideTypeBuy).
+		OrderType(BasicOrderTypeLimit).
+		Price("50000").
+		Quantity("0.00001").
+		AutoRound().
+		Do(context.Background())
+	if !errors.Is(err, ErrBelowMinNotional) {
+		t.Errorf("Expected ErrBelowMinNotional, got %v", err)
+	}
+
+	// A sandbox client still rounds but lets the order through
+	client.RelaxedValidation = true
+	_, err = client.NewCreateBasicOrderService().
+		Exchange(ExchangeBinanceSpot).
+		Symbol("BTC/USDT").
+		Side(SideTypeBuy).
+		OrderType(BasicOrderTypeLimit).
+		Price("50000.001").
+		Quantity("0.00001").
+		AutoRound().
+		Do(context.Background())
+	if err != nil || *body.Price != "50000" || body.Quantity != "0.00001" {
+		t.Errorf("Expected a rounded order below the minimums, got %v %s, %v", body.Price, body.Quantity, err)
+	}
+}
@@ -0,0 +1,324 @@
+// Package integration certifies connectivity to a live Versifi account by
+// running a full order lifecycle: it places the smallest resting LIMIT
+// order, reads it back, cancels it and observes both changes on the
+// WebSocket execution_report topic.
+//
+// The suite is behind the integration build tag and skipped unless
+// credentials are set, so it never runs by accident:
+//
+//	VERSIFI_API_KEY=... VERSIFI_API_SECRET=... \
+//	VERSIFI_IT_SYMBOL=BTC/USDT VERSIFI_IT_QUANTITY=0.0001 VERSIFI_IT_PRICE=10000 \
+//	go test -tags integration -v github.com/drinkthere/versifi-go/integration
+//
+// The clients are built by config.FromEnv, so VERSIFI_BASE_URL,
+// VERSIFI_WS_URL, VERSIFI_LOCAL_ADDR and the other config variables apply;
+// set VERSIFI_TESTNET=true to certify against the sandbox. The order is set by:
+//
+//	VERSIFI_IT_EXCHANGE  exchange (default BINANCE_SPOT)
+//	VERSIFI_IT_SYMBOL    symbol (default BTC/USDT)
+//	VERSIFI_IT_SIDE      BUY or SELL (default BUY)
+//	VERSIFI_IT_QUANTITY  the instrument's minimum quantity (required)
+//	VERSIFI_IT_PRICE     a price far enough from the market that the order
+//	                     rests, such as half the bid for a BUY (required)
+//	VERSIFI_IT_TIMEOUT   bound on the whole lifecycle (default 1m)
+//
+// Applications can run the same checks from their own tests or health
+// checks with New and Harness.Run.
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	versifi "github.com/drinkthere/versifi-go"
+	"github.com/drinkthere/versifi-go/config"
+)
+
+// ErrNotConfigured is returned by ConfigFromEnv when no credentials are set
+var ErrNotConfigured = errors.New("integration: VERSIFI_API_KEY is not set")
+
+// Config describes the account and the order of a lifecycle run
+type Config struct {
+	// Client builds the REST and WebSocket clients
+	Client *config.Config
+
+	Exchange versifi.ExchangeType
+	Symbol   string
+	Side     versifi.SideType
+	// Quantity should be the instrument's minimum quantity
+	Quantity string
+	// Price must keep the LIMIT order from filling before it is canceled
+	Price string
+	// Timeout bounds the whole lifecycle
+	Timeout time.Duration
+}
+
+// ConfigFromEnv reads a Config from the environment variables listed in
+// the package documentation
+func ConfigFromEnv() (*Config, error) {
+	if os.Getenv("VERSIFI_API_KEY") == "" {
+		return nil, ErrNotConfigured
+	}
+	client, err := config.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	get := func(name, def string) string {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+		return def
+	}
+	cfg := &Config{
+		Client:   client,
+		Exchange: versifi.ExchangeType(strings.ToUpper(get("VERSIFI_IT_EXCHANGE", string(versifi.ExchangeBinanceSpot)))),
+		Symbol:   get("VERSIFI_IT_SYMBOL", "BTC/USDT"),
+		Side:     versifi.SideType(strings.ToUpper(get("VERSIFI_IT_SIDE", string(versifi.SideTypeBuy)))),
+		Quantity: get("VERSIFI_IT_QUANTITY", ""),
+		Price:    get("VERSIFI_IT_PRICE", ""),
+		Timeout:  time.Minute,
+	}
+	if v := get("VERSIFI_IT_TIMEOUT", ""); v != "" {
+		if cfg.Timeout, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("integration: VERSIFI_IT_TIMEOUT: %w", err)
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks that the order is fully described
+func (c *Config) Validate() error {
+	switch {
+	case c.Client == nil:
+		return errors.New("integration: no client config")
+	case c.Exchange == "" || c.Symbol == "":
+		return errors.New("integration: exchange and symbol are required")
+	case c.Side != versifi.SideTypeBuy && c.Side != versifi.SideTypeSell:
+		return fmt.Errorf("integration: invalid side %q", c.Side)
+	}
+	for _, f := range []struct{ name, value string }{
+		{"VERSIFI_IT_QUANTITY", c.Quantity},
+		{"VERSIFI_IT_PRICE", c.Price},
+	} {
+		d, err := decimal.NewFromString(f.value)
+		if err != nil || d.Sign() <= 0 {
+			return fmt.Errorf("integration: %s must be a positive decimal, got %q", f.name, f.value)
+		}
+	}
+	return nil
+}
+
+// Step is a completed stage of the lifecycle
+type Step struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Report describes a lifecycle run, including the steps completed before
+// a failure
+type Report struct {
+	OrderID       int64
+	ClientOrderID int64
+	Steps         []Step
+	// Reports are the statuses of the order's execution reports, in the
+	// order received
+	Reports []versifi.OrderStatusType
+}
+
+// Harness runs the lifecycle with a REST and a WebSocket client
+type Harness struct {
+	Client *versifi.Client
+	Ws     *versifi.WsClient
+	// Logf, if set, receives progress, for example testing.T.Logf
+	Logf func(format string, args ...interface{})
+
+	cfg *Config
+
+	mu      sync.Mutex
+	reports map[int64][]versifi.OrderStatusType
+	changed chan struct{}
+}
+
+// New validates cfg and creates its clients
+func New(cfg *Config) (*Harness, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	client, err := cfg.Client.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	ws, err := cfg.Client.NewWsClient()
+	if err != nil {
+		return nil, err
+	}
+	h := &Harness{
+		Client:  client,
+		Ws:      ws,
+		cfg:     cfg,
+		reports: make(map[int64][]versifi.OrderStatusType),
+		changed: make(chan struct{}),
+	}
+	// Execution reports that fail to decode are reported here
+	ws.SetErrorHandler(func(err error) { h.logf("websocket: %v", err) })
+	return h, nil
+}
+
+// Run connects the WebSocket client, then creates, gets and cancels the
+// order, waiting for its execution reports. An order left open by a
+// failure is canceled before Run returns.
+func (h *Harness) Run(ctx context.Context) (*Report, error) {
+	if h.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.cfg.Timeout)
+		defer cancel()
+	}
+	report := &Report{ClientOrderID: time.Now().UnixMilli()}
+
+	step := func(name string, fn func() error) error {
+		start := time.Now()
+		if err := fn(); err != nil {
+			return fmt.Errorf("integration: %s: %w", name, err)
+		}
+		report.Steps = append(report.Steps, Step{Name: name, Duration: time.Since(start)})
+		h.logf("%s: ok (%v)", name, time.Since(start).Round(time.Millisecond))
+		return nil
+	}
+
+	defer h.Ws.OnExecutionReport(h.applyExecutionReport)()
+	if err := step("connect", h.connect); err != nil {
+		return report, err
+	}
+	defer h.Ws.Disconnect()
+
+	open := false
+	defer func() {
+		if open {
+			// The run has failed; do not leave the order resting
+			cleanup, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := h.Client.NewCancelOrderService().OrderID(report.OrderID).Do(cleanup); err != nil {
+				h.logf("cleanup: cancel order %d: %v", report.OrderID, err)
+			}
+		}
+	}()
+
+	err := step("create", func() error {
+		res, err := h.Client.NewCreateBasicOrderService().
+			ClientOrderID(report.ClientOrderID).
+			Exchange(h.cfg.Exchange).
+			Symbol(h.cfg.Symbol).
+			Side(h.cfg.Side).
+			OrderType(versifi.BasicOrderTypeLimit).
+			TimeInForce(versifi.TimeInForceGTC).
+			Quantity(h.cfg.Quantity).
+			Price(h.cfg.Price).
+			Do(ctx)
+		if err != nil {
+			return err
+		}
+		report.OrderID = res.OrderID
+		open = !res.Status.IsFinal()
+		if res.Status != versifi.OrderStatusNew {
+			return fmt.Errorf("order %d is %s, expected NEW", res.OrderID, res.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	err = step("get", func() error {
+		order, err := h.Client.NewGetOrderService().OrderID(report.OrderID).Do(ctx)
+		if err != nil {
+			return err
+		}
+		switch {
+		case order.OrderID != report.OrderID || order.ClientOrderID != report.ClientOrderID:
+			return fmt.Errorf("got order %d (client order ID %d), expected %d (%d)",
+				order.OrderID, order.ClientOrderID, report.OrderID, report.ClientOrderID)
+		case order.Status.IsFinal():
+			open = false
+			return fmt.Errorf("order %d is already %s; use a price further from the market", order.OrderID, order.Status)
+		case order.BasicOrder == nil || order.BasicOrder.Symbol != h.cfg.Symbol || order.BasicOrder.Exchange != h.cfg.Exchange:
+			return fmt.Errorf("order %d does not match the request: %+v", order.OrderID, order.BasicOrder)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	err = step("cancel", func() error {
+		if err := h.Client.NewCancelOrderService().OrderID(report.OrderID).Do(ctx); err != nil {
+			return err
+		}
+		open = false
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	err = step("execution report", func() error {
+		return h.waitForStatus(ctx, report.OrderID, versifi.OrderStatusCanceled)
+	})
+	report.Reports = h.statuses(report.OrderID)
+	return report, err
+}
+
+func (h *Harness) connect() error {
+	if err := h.Ws.Connect(); err != nil {
+		return err
+	}
+	return h.Ws.EnsureSubscribed("execution_report")
+}
+
+func (h *Harness) applyExecutionReport(d *versifi.WsExecutionReportDetail) {
+	h.mu.Lock()
+	h.reports[d.OrderID] = append(h.reports[d.OrderID], d.Status)
+	close(h.changed)
+	h.changed = make(chan struct{})
+	h.mu.Unlock()
+}
+
+// waitForStatus waits for an execution report of the order with status
+func (h *Harness) waitForStatus(ctx context.Context, orderID int64, status versifi.OrderStatusType) error {
+	for {
+		h.mu.Lock()
+		changed := h.changed
+		for _, s := range h.reports[orderID] {
+			if s == status {
+				h.mu.Unlock()
+				return nil
+			}
+		}
+		h.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return fmt.Errorf("no %s report for order %d (got %v): %w", status, orderID, h.statuses(orderID), ctx.Err())
+		}
+	}
+}
+
+func (h *Harness) statuses(orderID int64) []versifi.OrderStatusType {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]versifi.OrderStatusType(nil), h.reports[orderID]...)
+}
+
+func (h *Harness) logf(format string, args ...interface{}) {
+	if h.Logf != nil {
+		h.Logf(format, args...)
+	}
+}
@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	versifi "github.com/drinkthere/versifi-go"
+	"github.com/drinkthere/versifi-go/config"
+	"github.com/drinkthere/versifi-go/versifitest"
+)
+
+func newTestHarness(t *testing.T, server *versifitest.Server, secret string) *Harness {
+	t.Helper()
+	h, err := New(&Config{
+		Client:   &config.Config{APIKey: "key", APISecret: secret, BaseURL: server.URL, WsURL: server.Ws.URL},
+		Exchange: versifi.ExchangeBinanceSpot,
+		Symbol:   "BTC/USDT",
+		Side:     versifi.SideTypeBuy,
+		Quantity: "0.0001",
+		Price:    "10000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Logf = t.Logf
+	return h
+}
+
+func TestHarnessRun(t *testing.T) {
+	server := versifitest.NewServer("key", "secret")
+	defer server.Close()
+
+	report, err := newTestHarness(t, server, "secret").Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []string
+	for _, s := range report.Steps {
+		steps = append(steps, s.Name)
+	}
+	if got := strings.Join(steps, ","); got != "connect,create,get,cancel,execution report" {
+		t.Errorf("Unexpected steps %s", got)
+	}
+	if n := len(report.Reports); n == 0 || report.Reports[n-1] != versifi.OrderStatusCanceled {
+		t.Errorf("Expected a CANCELED report, got %v", report.Reports)
+	}
+	if order, _ := server.Order(report.OrderID); order.Status != versifi.OrderStatusCanceled || order.ClientOrderID != report.ClientOrderID {
+		t.Errorf("Unexpected order %+v", order)
+	}
+}
+
+func TestHarnessFailure(t *testing.T) {
+	server := versifitest.NewServer("key", "secret")
+	defer server.Close()
+
+	// The WebSocket accepts any signature, REST requests fail
+	server.Ws.Close()
+	server.Ws = versifitest.NewWsServer("key", "")
+	defer server.Ws.Close()
+
+	report, err := newTestHarness(t, server, "wrong").Run(context.Background())
+	var apiErr *versifi.APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(err.Error(), "create") {
+		t.Fatalf("Expected the create step to fail, got %v", err)
+	}
+	if len(report.Steps) != 1 || report.OrderID != 0 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Setenv("VERSIFI_API_KEY", "")
+	if _, err := ConfigFromEnv(); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Expected ErrNotConfigured, got %v", err)
+	}
+
+	t.Setenv("VERSIFI_API_KEY", "key")
+	t.Setenv("VERSIFI_API_SECRET", "secret")
+	t.Setenv("VERSIFI_IT_SIDE", "sell")
+	t.Setenv("VERSIFI_IT_QUANTITY", "0.001")
+	if _, err := ConfigFromEnv(); err == nil || !strings.Contains(err.Error(), "VERSIFI_IT_PRICE") {
+		t.Errorf("Expected a missing price error, got %v", err)
+	}
+
+	t.Setenv("VERSIFI_IT_PRICE", "50000")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Side != versifi.SideTypeSell || cfg.Exchange != versifi.ExchangeBinanceSpot || cfg.Client.APISecret != "secret" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestLifecycle runs the order lifecycle against the account configured
+// by the environment
+func TestLifecycle(t *testing.T) {
+	cfg, err := ConfigFromEnv()
+	if errors.Is(err, ErrNotConfigured) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Logf = t.Logf
+
+	report, err := h.Run(context.Background())
+	if err != nil {
+		t.Fatalf("%v (completed %+v)", err, report.Steps)
+	}
+	t.Logf("order %d: reports %v", report.OrderID, report.Reports)
+}
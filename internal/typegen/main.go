@@ -0,0 +1,316 @@
+// Command typegen generates Go types from the component schemas of an
+// OpenAPI 3 spec. String enums become a named string type with a constant
+// per value, and objects become structs with JSON tags. Run it through
+// go generate in the types package:
+//
+//	go run ../internal/typegen -spec openapi.yaml -out types.go -package types
+//
+// Required properties are plain fields; optional ones get omitempty, and
+// nullable ones are pointers. References to objects are pointers. These
+// extensions control names and types where the default would not match
+// the SDK:
+//
+//	x-go-name         the field name of a property
+//	x-go-type         the Go type of a property, such as json.RawMessage
+//	x-go-enum-prefix  the constant prefix of an enum, by default its name
+//	x-enum-varnames   the constant suffix of each enum value
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.yaml", "OpenAPI spec to read")
+	out := flag.String("out", "types.go", "Go file to write")
+	pkg := flag.String("package", "types", "package name of the generated file")
+	flag.Parse()
+
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(data, *pkg, filepath.Base(*specPath))
+	if err != nil {
+		log.Fatalf("typegen: %s: %v", *specPath, err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type spec struct {
+	Components struct {
+		Schemas namedSchemas `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type schema struct {
+	Type        string       `yaml:"type"`
+	Format      string       `yaml:"format"`
+	Description string       `yaml:"description"`
+	Ref         string       `yaml:"$ref"`
+	AllOf       []*schema    `yaml:"allOf"`
+	Nullable    bool         `yaml:"nullable"`
+	Enum        []string     `yaml:"enum"`
+	Required    []string     `yaml:"required"`
+	Properties  namedSchemas `yaml:"properties"`
+	Items       *schema      `yaml:"items"`
+
+	GoName       string   `yaml:"x-go-name"`
+	GoType       string   `yaml:"x-go-type"`
+	EnumPrefix   string   `yaml:"x-go-enum-prefix"`
+	EnumVarnames []string `yaml:"x-enum-varnames"`
+}
+
+type namedSchema struct {
+	name   string
+	schema *schema
+}
+
+// namedSchemas is a YAML mapping of schemas kept in document order, so the
+// generated types and fields follow the spec
+type namedSchemas []namedSchema
+
+func (s *namedSchemas) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var sc schema
+		if err := node.Content[i+1].Decode(&sc); err != nil {
+			return err
+		}
+		*s = append(*s, namedSchema{name: node.Content[i].Value, schema: &sc})
+	}
+	return nil
+}
+
+type generator struct {
+	schemas map[string]*schema
+	imports map[string]bool
+	buf     bytes.Buffer
+}
+
+// generate returns the formatted Go source for the schemas of the spec in
+// data. source names the spec in the generated header.
+func generate(data []byte, pkg, source string) ([]byte, error) {
+	var s spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if len(s.Components.Schemas) == 0 {
+		return nil, errors.New("no component schemas")
+	}
+
+	g := &generator{schemas: make(map[string]*schema), imports: make(map[string]bool)}
+	for _, ns := range s.Components.Schemas {
+		g.schemas[ns.name] = ns.schema
+	}
+	for _, ns := range s.Components.Schemas {
+		var err error
+		switch {
+		case len(ns.schema.Enum) > 0:
+			err = g.enum(ns.name, ns.schema)
+		case ns.schema.Type == "object":
+			err = g.object(ns.name, ns.schema)
+		default:
+			err = fmt.Errorf("unsupported type %q", ns.schema.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", ns.name, err)
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by typegen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		paths := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		out.WriteString("import (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(g.buf.Bytes())
+	return format.Source(out.Bytes())
+}
+
+func (g *generator) enum(name string, s *schema) error {
+	if s.Type != "string" {
+		return fmt.Errorf("unsupported enum type %q", s.Type)
+	}
+	if len(s.EnumVarnames) > 0 && len(s.EnumVarnames) != len(s.Enum) {
+		return errors.New("x-enum-varnames does not match enum")
+	}
+	prefix := s.EnumPrefix
+	if prefix == "" {
+		prefix = name
+	}
+
+	g.comment("", name, s.Description)
+	fmt.Fprintf(&g.buf, "type %s string\n\n", name)
+	fmt.Fprintf(&g.buf, "// %s values\n", name)
+	g.buf.WriteString("const (\n")
+	for i, value := range s.Enum {
+		suffix := goName(value)
+		if len(s.EnumVarnames) > 0 {
+			suffix = s.EnumVarnames[i]
+		}
+		fmt.Fprintf(&g.buf, "\t%s%s %s = %q\n", prefix, suffix, name, value)
+	}
+	g.buf.WriteString(")\n\n")
+	return nil
+}
+
+func (g *generator) object(name string, s *schema) error {
+	required := make(map[string]bool, len(s.Required))
+	for _, r := range s.Required {
+		required[r] = true
+	}
+
+	g.comment("", name, s.Description)
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+	for _, p := range s.Properties {
+		typ, err := g.goType(p.schema)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", p.name, err)
+		}
+		if p.schema.Nullable && !strings.HasPrefix(typ, "*") {
+			typ = "*" + typ
+		}
+		field := p.schema.GoName
+		if field == "" {
+			field = goName(p.name)
+		}
+		tag := p.name
+		if !required[p.name] {
+			tag += ",omitempty"
+		}
+		g.comment("\t", "", p.schema.Description)
+		fmt.Fprintf(&g.buf, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	g.buf.WriteString("}\n\n")
+	return nil
+}
+
+// goType returns the Go type of a property or array item
+func (g *generator) goType(s *schema) (string, error) {
+	if s.GoType != "" {
+		if pkg, _, ok := strings.Cut(s.GoType, "."); ok {
+			switch pkg {
+			case "json":
+				g.imports["encoding/json"] = true
+			case "time":
+				g.imports["time"] = true
+			default:
+				return "", fmt.Errorf("unsupported x-go-type %q", s.GoType)
+			}
+		}
+		return s.GoType, nil
+	}
+	if s.Ref == "" && len(s.AllOf) == 1 {
+		return g.goType(s.AllOf[0])
+	}
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		target := g.schemas[name]
+		if !ok || target == nil {
+			return "", fmt.Errorf("unresolved $ref %q", s.Ref)
+		}
+		if target.Type == "object" {
+			return "*" + name, nil
+		}
+		return name, nil
+	}
+
+	switch s.Type {
+	case "string":
+		return "string", nil
+	case "boolean":
+		return "bool", nil
+	case "integer":
+		switch s.Format {
+		case "int32":
+			return "int32", nil
+		case "int64":
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		if s.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "array":
+		if s.Items == nil {
+			return "", errors.New("array without items")
+		}
+		item, err := g.goType(s.Items)
+		if err != nil {
+			return "", err
+		}
+		// Slices of objects hold values, as the SDK's do
+		return "[]" + strings.TrimPrefix(item, "*"), nil
+	case "object":
+		if len(s.Properties) > 0 {
+			return "", errors.New("inline objects are not supported, declare a component schema")
+		}
+		return "map[string]interface{}", nil
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// comment writes description as a doc comment, prefixed with name if set
+func (g *generator) comment(indent, name, description string) {
+	text := strings.TrimSpace(description)
+	if text == "" {
+		return
+	}
+	if name != "" {
+		text = strings.TrimSpace(name + " " + text)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(&g.buf, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+// initialisms are kept upper case in generated names
+var initialisms = map[string]string{
+	"api": "API",
+	"id":  "ID",
+	"ids": "IDs",
+	"tif": "TIF",
+	"url": "URL",
+}
+
+// goName converts a snake_case property or SCREAMING_CASE enum value to
+// CamelCase, so client_order_id becomes ClientOrderID
+func goName(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' || r == ' ' }) {
+		word = strings.ToLower(word)
+		if i, ok := initialisms[word]; ok {
+			b.WriteString(i)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
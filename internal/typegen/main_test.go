@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateUpToDate(t *testing.T) {
+	spec, err := os.ReadFile("../../types/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../../types/types.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate(spec, "types", "openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("types/types.go is out of date, run go generate ./types")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	spec := `
+components:
+  schemas:
+    Color:
+      description: is a color
+      type: string
+      enum: [DARK_RED, BLUE]
+    Paint:
+      type: object
+      required: [color, order_ids]
+      properties:
+        color: {$ref: "#/components/schemas/Color"}
+        order_ids: {type: array, items: {type: integer, format: int64}}
+        base: {$ref: "#/components/schemas/Paint"}
+        ratio: {type: number, nullable: true}
+        raw: {x-go-type: json.RawMessage}
+`
+	src, err := generate([]byte(spec), "paint", "spec.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"// Color is a color\ntype Color string",
+		")\n\ntype Paint struct",
+		`ColorDarkRed Color = "DARK_RED"`,
+		"Color    Color           `json:\"color\"`",
+		"OrderIDs []int64",
+		"Base     *Paint          `json:\"base,omitempty\"`",
+		"Ratio    *float64",
+		"Raw      json.RawMessage",
+		"\"encoding/json\"",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Expected %q in\n%s", want, src)
+		}
+	}
+
+	for _, bad := range []string{
+		`components: {schemas: {A: {type: object, properties: {b: {$ref: "#/components/schemas/B"}}}}}`,
+		`components: {schemas: {A: {type: object, properties: {b: {type: object, properties: {c: {type: string}}}}}}}`,
+		`components: {schemas: {A: {type: string, enum: [X], x-enum-varnames: [X, Y]}}}`,
+		`components: {schemas: {}}`,
+	} {
+		if _, err := generate([]byte(bad), "p", "spec.yaml"); err == nil {
+			t.Errorf("Expected an error for %s", bad)
+		}
+	}
+}
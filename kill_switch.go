@@ -0,0 +1,88 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// killSwitchPageSize is the page size used to list open orders and the
+// number of orders canceled per batch request
+const killSwitchPageSize = 100
+
+// openOrderStatuses are the statuses KillSwitch lists, so the order
+// history is never paged through
+var openOrderStatuses = []OrderStatusType{OrderStatusNew, OrderStatusPartiallyFilled}
+
+// ErrKillSwitchEngaged is returned for order submissions while the client's
+// kill switch is engaged
+var ErrKillSwitchEngaged = errors.New("kill switch engaged")
+
+// KillSwitchResult reports what KillSwitch canceled
+type KillSwitchResult struct {
+	// Canceled lists the orders whose cancellation was accepted. Final
+	// statuses arrive over the WebSocket.
+	Canceled []int64
+	// Failed lists the orders whose batch cancel request failed
+	Failed []int64
+}
+
+// KillSwitch blocks all further order submissions from c and cancels every
+// open order on every exchange. Submissions fail with ErrKillSwitchEngaged
+// until ResetKillSwitch is called, even if canceling fails.
+//
+// Open orders are listed page by page, filtered by status on the server,
+// and then canceled in batches; a failed batch does not stop the others,
+// and the returned error joins every failure. Listing completes before
+// canceling starts, so orders leaving the listing do not shift its pages.
+func (c *Client) KillSwitch(ctx context.Context, opts ...RequestOption) (*KillSwitchResult, error) {
+	c.killSwitch.Store(true)
+
+	var open []int64
+	seen := make(map[int64]bool)
+	for _, status := range openOrderStatuses {
+		for offset := int64(0); ; offset += killSwitchPageSize {
+			items, err := c.NewListOpenOrdersService().
+				Limit(killSwitchPageSize).
+				Offset(offset).
+				Status(status).
+				Do(ctx, opts...)
+			if err != nil {
+				return &KillSwitchResult{}, fmt.Errorf("list %s orders: %w", status, err)
+			}
+			for _, item := range items {
+				// An order filled in between may be listed under both statuses
+				if !seen[item.OrderID] && !OrderStatusType(item.Status).IsFinal() {
+					seen[item.OrderID] = true
+					open = append(open, item.OrderID)
+				}
+			}
+			if len(items) < killSwitchPageSize {
+				break
+			}
+		}
+	}
+
+	res := &KillSwitchResult{}
+	var errs []error
+	for start := 0; start < len(open); start += killSwitchPageSize {
+		batch := open[start:min(start+killSwitchPageSize, len(open))]
+		if err := c.NewCancelBatchOrderService().OrderIDs(batch).Do(ctx, opts...); err != nil {
+			res.Failed = append(res.Failed, batch...)
+			errs = append(errs, fmt.Errorf("cancel %d orders: %w", len(batch), err))
+			continue
+		}
+		res.Canceled = append(res.Canceled, batch...)
+	}
+	return res, errors.Join(errs...)
+}
+
+// ResetKillSwitch allows order submissions again after KillSwitch
+func (c *Client) ResetKillSwitch() {
+	c.killSwitch.Store(false)
+}
+
+// KillSwitchEngaged reports whether order submissions are blocked
+func (c *Client) KillSwitchEngaged() bool {
+	return c.killSwitch.Load()
+}
@@ -0,0 +1,94 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestKillSwitch(t *testing.T) {
+	var orders []ListOrderItem
+	for i := int64(1); i <= 150; i++ {
+		status := string(OrderStatusNew)
+		switch {
+		case i%10 == 0:
+			status = string(OrderStatusFilled)
+		case i%10 == 5:
+			status = string(OrderStatusPartiallyFilled)
+		}
+		orders = append(orders, ListOrderItem{OrderID: i, Status: status})
+	}
+
+	var batches [][]int64
+	var created, listed int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/orders":
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			status := r.URL.Query().Get("status")
+			if status == "" {
+				t.Error("Expected the order list to be filtered by status")
+			}
+			var matching []ListOrderItem
+			for _, order := range orders {
+				if order.Status == status {
+					matching = append(matching, order)
+				}
+			}
+			page := matching[min(offset, len(matching)):min(offset+limit, len(matching))]
+			listed += len(page)
+			json.NewEncoder(w).Encode(page)
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/orders/batch":
+			var body CancelBatchRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			batches = append(batches, body.IDs)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			created++
+			json.NewEncoder(w).Encode(OrderResponse{OrderID: 1000, Status: OrderStatusNew})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	res, err := client.KillSwitch(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res.Canceled) != 135 || len(batches) != 2 || len(batches[0]) != 100 {
+		t.Errorf("Expected 135 orders canceled in 2 batches, got %d in %d", len(res.Canceled), len(batches))
+	}
+	if listed != 135 {
+		t.Errorf("Expected only open orders to be listed, got %d", listed)
+	}
+	if !client.KillSwitchEngaged() {
+		t.Error("Expected kill switch to be engaged")
+	}
+
+	order := client.NewCreateBasicOrderService().
+		Exchange(ExchangeBinanceSpot).
+		Symbol("BTC/USDT").
+		Side(SideTypeBuy).
+		OrderType(BasicOrderTypeMarket).
+		Quantity("1")
+	if _, err := order.Do(context.Background()); !errors.Is(err, ErrKillSwitchEngaged) || !notSent(err) {
+		t.Errorf("Expected ErrKillSwitchEngaged, got %v", err)
+	}
+	if created != 0 {
+		t.Errorf("Expected no order to reach the server, got %d", created)
+	}
+
+	client.ResetKillSwitch()
+	if _, err := order.Do(context.Background()); err != nil || created != 1 {
+		t.Errorf("Expected order after reset, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package versifi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LatencyOutcomeType is how an order submitted under a LatencyBudget ended
+type LatencyOutcomeType string
+
+const (
+	// LatencyAcked: an execution report arrived within the budget
+	LatencyAcked LatencyOutcomeType = "ACKED"
+	// LatencyFilled: the first fill arrived within the budget
+	LatencyFilled LatencyOutcomeType = "FILLED"
+	// LatencyFinal: the order reached a final status, such as REJECTED,
+	// within the budget
+	LatencyFinal LatencyOutcomeType = "FINAL"
+	// LatencyCanceled: the budget expired and the order was canceled
+	LatencyCanceled LatencyOutcomeType = "CANCELED"
+	// LatencyCancelFailed: the budget expired and the cancel failed, see
+	// LatencyResult.CancelErr
+	LatencyCancelFailed LatencyOutcomeType = "CANCEL_FAILED"
+)
+
+// LatencyResult reports an order submitted under a LatencyBudget
+type LatencyResult struct {
+	OrderID       int64              `json:"order_id"`
+	ClientOrderID int64              `json:"client_order_id"`
+	Outcome       LatencyOutcomeType `json:"outcome"`
+	// Latency is the time from submission to the acknowledgment or fill,
+	// or to the cancel when the budget expired
+	Latency time.Duration `json:"latency"`
+	// Order is the last tracked state of the order
+	Order     OrderState `json:"order"`
+	CancelErr error      `json:"-"`
+}
+
+// LatencyBudget submits orders and cancels those that are not acknowledged
+// over the websocket, or not filled with WaitForFill, within Budget, to
+// protect against a venue that accepts orders but hangs.
+//
+//	tracker := versifi.NewOrderTracker(client)
+//	tracker.Attach(wsClient)
+//	budget := versifi.NewLatencyBudget(tracker, 500*time.Millisecond)
+//	res, err := budget.Submit(ctx, client.NewCreateBasicOrderService()...)
+//
+// The budget runs from the start of the create request, so a create
+// request answered after the budget has expired leads straight to a cancel.
+type LatencyBudget struct {
+	// Budget is the time allowed for the acknowledgment or first fill
+	Budget time.Duration
+	// WaitForFill waits for the first fill rather than any execution report
+	WaitForFill bool
+
+	tracker *OrderTracker
+}
+
+// NewLatencyBudget creates a latency budget submitting orders through
+// tracker, which must be attached to a websocket client
+func NewLatencyBudget(tracker *OrderTracker, budget time.Duration) *LatencyBudget {
+	return &LatencyBudget{Budget: budget, tracker: tracker}
+}
+
+// Submit places order and waits until it is acknowledged within the budget
+// or canceled after it. An error is returned only when the order could not
+// be placed or ctx is done; an expired budget is reported in the result.
+func (b *LatencyBudget) Submit(ctx context.Context, order OrderCreator, opts ...RequestOption) (*LatencyResult, error) {
+	var mu sync.Mutex
+	hits := make(map[int64]LatencyOutcomeType)
+	signal := make(chan struct{}, 1)
+
+	// Reports may arrive before the create request returns the order ID
+	unsubscribe := b.tracker.Subscribe(func(u OrderUpdate) {
+		if u.Source != OrderSourceWebsocket {
+			return
+		}
+		outcome := b.outcome(u.Order)
+		if outcome == "" {
+			return
+		}
+		mu.Lock()
+		if _, ok := hits[u.Order.OrderID]; !ok {
+			hits[u.Order.OrderID] = outcome
+		}
+		mu.Unlock()
+		select {
+		case signal <- struct{}{}:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	start := time.Now()
+	timer := time.NewTimer(b.Budget)
+	defer timer.Stop()
+
+	res, err := b.tracker.Submit(ctx, order, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LatencyResult{OrderID: res.OrderID, ClientOrderID: res.ClientOrderID}
+	for {
+		mu.Lock()
+		outcome, ok := hits[res.OrderID]
+		mu.Unlock()
+		if ok {
+			result.Outcome = outcome
+			result.Latency = time.Since(start)
+			result.Order, _ = b.tracker.Order(res.OrderID)
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-signal:
+		case <-timer.C:
+			return b.cancel(ctx, result, start, opts...), nil
+		}
+	}
+}
+
+// outcome returns how state resolves the budget, or "" if it does not
+func (b *LatencyBudget) outcome(state OrderState) LatencyOutcomeType {
+	switch {
+	case toDecimal(state.FilledQuantity).IsPositive():
+		return LatencyFilled
+	case state.Status.IsFinal():
+		return LatencyFinal
+	case !b.WaitForFill:
+		return LatencyAcked
+	}
+	return ""
+}
+
+func (b *LatencyBudget) cancel(ctx context.Context, result *LatencyResult, start time.Time, opts ...RequestOption) *LatencyResult {
+	err := b.tracker.c.NewCancelOrderService().OrderID(result.OrderID).Do(ctx, opts...)
+	result.Latency = time.Since(start)
+	result.Order, _ = b.tracker.Order(result.OrderID)
+	if err != nil {
+		result.Outcome = LatencyCancelFailed
+		result.CancelErr = err
+		return result
+	}
+	result.Outcome = LatencyCanceled
+	return result
+}
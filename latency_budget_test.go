@@ -0,0 +1,103 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLatencyBudget(t *testing.T) {
+	var tracker *OrderTracker
+	var nextID atomic.Int64
+	var mu sync.Mutex
+	var canceled []string
+	// onCreate runs before the create response is sent, as a fast venue
+	// would report the order before the REST round trip completes
+	var onCreate func(orderID int64)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			id := nextID.Add(1)
+			if onCreate != nil {
+				onCreate(id)
+			}
+			json.NewEncoder(w).Encode(OrderResponse{OrderID: id, Status: OrderStatusNew})
+		case http.MethodDelete:
+			mu.Lock()
+			canceled = append(canceled, r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	tracker = NewOrderTracker(client)
+	budget := NewLatencyBudget(tracker, 50*time.Millisecond)
+
+	report := func(orderID int64, status OrderStatusType, trades ...WsTrade) {
+		tracker.ApplyExecutionReport(&WsExecutionReportDetail{
+			OrderID:          orderID,
+			Status:           status,
+			RequestOrderType: RequestOrderTypeBasic,
+			Basic: &WsBasicOrderDetail{
+				Exchange:   ExchangeBinanceSpot,
+				Symbol:     "BTC/USDT",
+				Side:       SideTypeBuy,
+				ChildOrder: &WsChildOrder{ID: orderID, Trades: trades},
+			},
+		})
+	}
+	order := func() *CreateBasicOrderService {
+		return client.NewCreateBasicOrderService().Exchange(ExchangeBinanceSpot).Symbol("BTC/USDT").
+			Side(SideTypeBuy).OrderType(BasicOrderTypeMarket).Quantity("1")
+	}
+	ctx := context.Background()
+
+	// Acknowledged before the create request returned
+	onCreate = func(id int64) { report(id, OrderStatusNew) }
+	res, err := budget.Submit(ctx, order())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Outcome != LatencyAcked || res.OrderID != 1 || res.Order.Status != OrderStatusNew {
+		t.Errorf("Unexpected result %+v", res)
+	}
+
+	// No report within the budget
+	onCreate = nil
+	res, err = budget.Submit(ctx, order())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Outcome != LatencyCanceled || res.Latency < 50*time.Millisecond {
+		t.Errorf("Unexpected result %+v", res)
+	}
+	mu.Lock()
+	if len(canceled) != 1 || canceled[0] != "/v2/orders/2" {
+		t.Errorf("Expected order 2 to be canceled, got %v", canceled)
+	}
+	mu.Unlock()
+
+	// Waiting for the first fill ignores the plain acknowledgment
+	budget.WaitForFill = true
+	budget.Budget = 5 * time.Second
+	onCreate = func(id int64) {
+		report(id, OrderStatusNew)
+		go report(id, OrderStatusPartiallyFilled, WsTrade{TradeID: 1, ExecutedPrice: "100", ExecutedQuantity: "0.5"})
+	}
+	res, err = budget.Submit(ctx, order())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Outcome != LatencyFilled || res.Order.FilledQuantity != "0.5" {
+		t.Errorf("Unexpected result %+v", res)
+	}
+}
@@ -1,10 +1,11 @@
 package versifi
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"maps"
 	"net/http"
+
+	"github.com/shopspring/decimal"
 )
 
 // CreateAlgoOrderService creates an algorithmic order (TWAP, VWAP, IS)
@@ -17,6 +18,16 @@ type CreateAlgoOrderService struct {
 	quantity        string
 	side            SideType
 	symbol          string
+	autoRound       bool
+}
+
+// Clone returns a copy of the service that is configured and sent
+// independently of s. Configure a template once and clone it per order,
+// concurrently if need be, without the orders sharing state.
+func (s *CreateAlgoOrderService) Clone() *CreateAlgoOrderService {
+	clone := *s
+	clone.params = maps.Clone(s.params)
+	return &clone
 }
 
 // ClientOrderID sets the client order ID
@@ -25,6 +36,10 @@ func (s *CreateAlgoOrderService) ClientOrderID(clientOrderID int64) *CreateAlgoO
 	return s
 }
 
+func (s *CreateAlgoOrderService) setClientOrderID(clientOrderID int64) {
+	s.clientOrderID = &clientOrderID
+}
+
 // Exchange sets the exchange
 func (s *CreateAlgoOrderService) Exchange(exchange ExchangeType) *CreateAlgoOrderService {
 	s.exchange = exchange
@@ -63,6 +78,25 @@ func (s *CreateAlgoOrderService) Symbol(symbol string) *CreateAlgoOrderService {
 	return s
 }
 
+// AutoRound rounds the quantity down to the instrument's lot size from
+// Client.Instruments when the order is sent, and rejects orders below the
+// minimum quantity unless Client.RelaxedValidation is set
+func (s *CreateAlgoOrderService) AutoRound() *CreateAlgoOrderService {
+	s.autoRound = true
+	return s
+}
+
+// round applies AutoRound
+func (s *CreateAlgoOrderService) round(ctx context.Context) error {
+	inst, err := s.c.instrument(ctx, s.exchange, s.symbol)
+	if err != nil {
+		return err
+	}
+	quantity := inst.RoundQuantity(toDecimal(s.quantity))
+	s.quantity = quantity.String()
+	return s.c.checkMinimums(inst, decimal.Zero, quantity)
+}
+
 // AlgoOrderRequest represents the request body for creating an algo order
 type AlgoOrderRequest struct {
 	ClientOrderID *int64                 `json:"client_order_id,omitempty"`
@@ -77,9 +111,19 @@ type AlgoOrderRequest struct {
 // Do executes the request
 func (s *CreateAlgoOrderService) Do(ctx context.Context, opts ...RequestOption) (res *OrderResponse, err error) {
 	r := &request{
-		method:   http.MethodPost,
-		endpoint: "/v2/orders/algo/",
-		secType:  secTypeSigned,
+		method:       http.MethodPost,
+		endpoint:     "/{version}/orders/algo/",
+		versions:     orderAPIVersions,
+		secType:      secTypeSigned,
+		submitsOrder: true,
+	}
+
+	if s.autoRound {
+		// Round a copy, so the service keeps the values it was given
+		s = s.Clone()
+		if err := s.round(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	// Build request body
@@ -92,24 +136,13 @@ func (s *CreateAlgoOrderService) Do(ctx context.Context, opts ...RequestOption)
 		Side:          s.side,
 		Symbol:        s.symbol,
 	}
-
-	bodyBytes, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
-	}
-
-	r.body = bytes.NewReader(bodyBytes)
-
-	data, err := s.c.callAPI(ctx, r, opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	res = new(OrderResponse)
-	err = json.Unmarshal(data, res)
-	if err != nil {
-		return nil, err
-	}
-
-	return res, nil
+	r.riskOrders = []RiskOrder{{
+		Exchange: s.exchange,
+		Symbol:   s.symbol,
+		Side:     s.side,
+		Quantity: s.quantity,
+	}}
+	r.exchangeOrders = []ExchangeOrder{{Exchange: s.exchange}}
+
+	return doRequest[OrderResponse](ctx, s.c, r, body, opts...)
 }
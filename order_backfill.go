@@ -0,0 +1,102 @@
+package versifi
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// BackfillOrdersService fetches full order details for orders that may have
+// changed while the websocket stream was down
+type BackfillOrdersService struct {
+	c        *Client
+	since    time.Time
+	status   OrderStatusType
+	orderIDs []int64
+	pageSize int64
+}
+
+// Clone returns a copy of the service that is configured and sent
+// independently of s
+func (s *BackfillOrdersService) Clone() *BackfillOrdersService {
+	clone := *s
+	// Appending to either copy reallocates, leaving the other unchanged
+	clone.orderIDs = slices.Clip(s.orderIDs)
+	return &clone
+}
+
+// Since sets the earliest order timestamp to include
+func (s *BackfillOrdersService) Since(since time.Time) *BackfillOrdersService {
+	s.since = since
+	return s
+}
+
+// Status restricts the listing to a single order status
+func (s *BackfillOrdersService) Status(status OrderStatusType) *BackfillOrdersService {
+	s.status = status
+	return s
+}
+
+// OrderIDs adds orders to refresh regardless of their timestamp, typically
+// the orders that were still open when the stream went down
+func (s *BackfillOrdersService) OrderIDs(orderIDs ...int64) *BackfillOrdersService {
+	s.orderIDs = append(s.orderIDs, orderIDs...)
+	return s
+}
+
+// PageSize sets how many orders are listed per request (default 100)
+func (s *BackfillOrdersService) PageSize(pageSize int64) *BackfillOrdersService {
+	s.pageSize = pageSize
+	return s
+}
+
+// Do pages through the order list and returns the details of every order
+// created at or after Since plus those given to OrderIDs
+func (s *BackfillOrdersService) Do(ctx context.Context, opts ...RequestOption) (res []*GetOrderResponse, err error) {
+	pageSize := s.pageSize
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	seen := make(map[int64]bool)
+	ids := make([]int64, 0, len(s.orderIDs))
+	for _, id := range s.orderIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for offset := int64(0); ; offset += pageSize {
+		page, err := s.c.NewListOpenOrdersService().
+			Limit(pageSize).
+			Offset(offset).
+			Status(s.status).
+			Do(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range page {
+			if !item.Timestamp.Before(s.since) && !seen[item.OrderID] {
+				seen[item.OrderID] = true
+				ids = append(ids, item.OrderID)
+			}
+		}
+
+		if int64(len(page)) < pageSize {
+			break
+		}
+	}
+
+	res = make([]*GetOrderResponse, 0, len(ids))
+	for _, id := range ids {
+		order, err := s.c.NewGetOrderService().OrderID(id).Do(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, order)
+	}
+
+	return res, nil
+}
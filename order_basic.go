@@ -1,10 +1,11 @@
 package versifi
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // CreateBasicOrderService creates a basic order (MARKET, LIMIT, STOP, etc.)
@@ -16,11 +17,20 @@ type CreateBasicOrderService struct {
 	price           *string
 	quantity        string
 	side            SideType
-	startTime       *int64
+	startTime       *Timestamp
 	stopPrice       *string
 	symbol          string
 	tif             *TimeInForceType
 	trailingDelta   *string
+	autoRound       bool
+}
+
+// Clone returns a copy of the service that is configured and sent
+// independently of s. Configure a template once and clone it per order,
+// concurrently if need be, without the orders sharing state.
+func (s *CreateBasicOrderService) Clone() *CreateBasicOrderService {
+	clone := *s
+	return &clone
 }
 
 // ClientOrderID sets the client order ID
@@ -29,6 +39,10 @@ func (s *CreateBasicOrderService) ClientOrderID(clientOrderID int64) *CreateBasi
 	return s
 }
 
+func (s *CreateBasicOrderService) setClientOrderID(clientOrderID int64) {
+	s.clientOrderID = &clientOrderID
+}
+
 // Exchange sets the exchange
 func (s *CreateBasicOrderService) Exchange(exchange ExchangeType) *CreateBasicOrderService {
 	s.exchange = exchange
@@ -59,9 +73,9 @@ func (s *CreateBasicOrderService) Side(side SideType) *CreateBasicOrderService {
 	return s
 }
 
-// StartTime sets the start timestamp (UTC Epoch Microseconds)
-func (s *CreateBasicOrderService) StartTime(startTime int64) *CreateBasicOrderService {
-	s.startTime = &startTime
+// StartTime sets the start time, sent as epoch microseconds
+func (s *CreateBasicOrderService) StartTime(startTime time.Time) *CreateBasicOrderService {
+	s.startTime = TimestampPtr(startTime, PrecisionMicroseconds)
 	return s
 }
 
@@ -89,6 +103,35 @@ func (s *CreateBasicOrderService) TrailingDelta(trailingDelta string) *CreateBas
 	return s
 }
 
+// AutoRound rounds the price, stop price and quantity to the instrument's
+// tick and lot sizes from Client.Instruments when the order is sent, and
+// rejects orders below the minimum quantity or notional unless
+// Client.RelaxedValidation is set
+func (s *CreateBasicOrderService) AutoRound() *CreateBasicOrderService {
+	s.autoRound = true
+	return s
+}
+
+// round applies AutoRound
+func (s *CreateBasicOrderService) round(ctx context.Context) error {
+	inst, err := s.c.instrument(ctx, s.exchange, s.symbol)
+	if err != nil {
+		return err
+	}
+
+	var price decimal.Decimal
+	if s.price != nil {
+		price = inst.RoundPrice(toDecimal(*s.price), s.side)
+		s.price = DecimalPtr(price)
+	}
+	if s.stopPrice != nil {
+		s.stopPrice = DecimalPtr(inst.RoundPrice(toDecimal(*s.stopPrice), s.side))
+	}
+	quantity := inst.RoundQuantity(toDecimal(s.quantity))
+	s.quantity = quantity.String()
+	return s.c.checkMinimums(inst, price, quantity)
+}
+
 // BasicOrderRequest represents the request body for creating a basic order
 type BasicOrderRequest struct {
 	ClientOrderID *int64          `json:"client_order_id,omitempty"`
@@ -97,7 +140,7 @@ type BasicOrderRequest struct {
 	Price         *string         `json:"price,omitempty"`
 	Quantity      string          `json:"quantity"`
 	Side          SideType        `json:"side"`
-	StartTime     *int64          `json:"start_time,omitempty"`
+	StartTime     *Timestamp      `json:"start_time,omitempty"`
 	StopPrice     *string         `json:"stop_price,omitempty"`
 	Symbol        string          `json:"symbol"`
 	TIF           *TimeInForceType `json:"tif,omitempty"`
@@ -107,9 +150,19 @@ type BasicOrderRequest struct {
 // Do executes the request
 func (s *CreateBasicOrderService) Do(ctx context.Context, opts ...RequestOption) (res *OrderResponse, err error) {
 	r := &request{
-		method:   http.MethodPost,
-		endpoint: "/v2/orders/basic/",
-		secType:  secTypeSigned,
+		method:       http.MethodPost,
+		endpoint:     "/{version}/orders/basic/",
+		versions:     orderAPIVersions,
+		secType:      secTypeSigned,
+		submitsOrder: true,
+	}
+
+	if s.autoRound {
+		// Round a copy, so the service keeps the values it was given
+		s = s.Clone()
+		if err := s.round(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	// Build request body
@@ -126,24 +179,15 @@ func (s *CreateBasicOrderService) Do(ctx context.Context, opts ...RequestOption)
 		TIF:           s.tif,
 		TrailingDelta: s.trailingDelta,
 	}
-
-	bodyBytes, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
+	risk := RiskOrder{Exchange: s.exchange, Symbol: s.symbol, Side: s.side, Quantity: s.quantity}
+	if s.price != nil {
+		risk.Price = *s.price
 	}
-
-	r.body = bytes.NewReader(bodyBytes)
-
-	data, err := s.c.callAPI(ctx, r, opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	res = new(OrderResponse)
-	err = json.Unmarshal(data, res)
-	if err != nil {
-		return nil, err
+	r.riskOrders = []RiskOrder{risk}
+	r.exchangeOrders = []ExchangeOrder{{Exchange: s.exchange, OrderType: s.orderType}}
+	if s.tif != nil {
+		r.exchangeOrders[0].TimeInForce = *s.tif
 	}
 
-	return res, nil
+	return doRequest[OrderResponse](ctx, s.c, r, body, opts...)
 }
@@ -0,0 +1,103 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultBatchConcurrency = 8
+
+// ErrOrderNotSubmitted is matched by the results of batch orders that were
+// never sent, because the context ended or an earlier order failed with
+// StopOnError set
+var ErrOrderNotSubmitted = errors.New("order not submitted")
+
+// BatchResult is the outcome of the order at Index of a batch
+type BatchResult struct {
+	Index    int
+	Response *OrderResponse
+	Err      error
+}
+
+// BatchSubmitter places many prepared orders concurrently.
+//
+// Orders are started in slice order, at most Concurrency at a time. Each
+// request still waits for the client's rate limiter, which serves waiters
+// in arrival order, so a large batch is paced rather than rejected:
+//
+//	client.SetRateLimiter(versifi.NewRateLimiter(10, 10))
+//	results, err := versifi.NewBatchSubmitter(client).Submit(ctx, orders)
+type BatchSubmitter struct {
+	// Concurrency caps the orders in flight, default 8
+	Concurrency int
+	// StopOnError leaves the orders not yet started unsent once one fails
+	StopOnError bool
+
+	c *Client
+}
+
+// NewBatchSubmitter creates a batch submitter using client
+func NewBatchSubmitter(client *Client) *BatchSubmitter {
+	return &BatchSubmitter{
+		Concurrency: defaultBatchConcurrency,
+		c:           client,
+	}
+}
+
+// Submit places orders and returns a result for each, in the same order.
+// The error joins the errors of the failed orders, or is nil if all were
+// placed.
+func (b *BatchSubmitter) Submit(ctx context.Context, orders []OrderCreator, opts ...RequestOption) ([]BatchResult, error) {
+	concurrency := b.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	results := make([]BatchResult, len(orders))
+	slots := make(chan struct{}, concurrency)
+	var (
+		wg      sync.WaitGroup
+		stopped atomic.Bool
+	)
+	for i, order := range orders {
+		results[i].Index = i
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = fmt.Errorf("%w: %w", ErrOrderNotSubmitted, ctx.Err())
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			<-slots
+			results[i].Err = fmt.Errorf("%w: %w", ErrOrderNotSubmitted, err)
+			continue
+		}
+		if stopped.Load() {
+			<-slots
+			results[i].Err = fmt.Errorf("%w: an earlier order failed", ErrOrderNotSubmitted)
+			continue
+		}
+
+		wg.Add(1)
+		go func(res *BatchResult, order OrderCreator) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			res.Response, res.Err = order.Do(ctx, opts...)
+			if res.Err != nil && b.StopOnError {
+				stopped.Store(true)
+			}
+		}(&results[i], order)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, res := range results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("order %d: %w", res.Index, res.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,132 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newBatchTestServer places basic orders, rejecting those for symbol BAD,
+// and records the most requests in flight at once
+func newBatchTestServer(t *testing.T) (*httptest.Server, *int) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		var body BasicOrderRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		time.Sleep(5 * time.Millisecond)
+		if body.Symbol == "BAD" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(APIError{Code: 400, Message: "invalid symbol"})
+			return
+		}
+		json.NewEncoder(w).Encode(OrderResponse{OrderID: *body.ClientOrderID + 1000, ClientOrderID: *body.ClientOrderID, Status: OrderStatusNew})
+	}))
+	t.Cleanup(server.Close)
+	return server, &peak
+}
+
+func batchTestOrders(client *Client, symbols ...string) []OrderCreator {
+	orders := make([]OrderCreator, len(symbols))
+	for i, symbol := range symbols {
+		orders[i] = testBasicOrder(client).Symbol(symbol).ClientOrderID(int64(i))
+	}
+	return orders
+}
+
+func TestBatchSubmitter(t *testing.T) {
+	server, peak := newBatchTestServer(t)
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	b := NewBatchSubmitter(client)
+	b.Concurrency = 3
+
+	symbols := make([]string, 12)
+	for i := range symbols {
+		symbols[i] = "BTC/USDT"
+	}
+	symbols[4] = "BAD"
+	results, err := b.Submit(context.Background(), batchTestOrders(client, symbols...))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(err.Error(), "order 4:") {
+		t.Errorf("Expected the error of order 4, got %v", err)
+	}
+	for i, res := range results {
+		switch {
+		case res.Index != i:
+			t.Errorf("Result %d has index %d", i, res.Index)
+		case i == 4:
+			if !errors.As(res.Err, &apiErr) {
+				t.Errorf("Expected an API error for order 4, got %v", res.Err)
+			}
+		case res.Err != nil || res.Response.OrderID != int64(i)+1000:
+			t.Errorf("Unexpected result %d: %+v, %v", i, res.Response, res.Err)
+		}
+	}
+	if *peak > 3 {
+		t.Errorf("Expected at most 3 orders in flight, got %d", *peak)
+	}
+}
+
+func TestBatchSubmitterStopOnError(t *testing.T) {
+	server, _ := newBatchTestServer(t)
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	b := NewBatchSubmitter(client)
+	b.Concurrency = 1
+	b.StopOnError = true
+
+	results, err := b.Submit(context.Background(), batchTestOrders(client, "BTC/USDT", "BAD", "BTC/USDT", "BTC/USDT"))
+	if err == nil || results[0].Err != nil || !IsAPIError(results[1].Err) {
+		t.Fatalf("Unexpected results %+v, %v", results, err)
+	}
+	for _, res := range results[2:] {
+		if !errors.Is(res.Err, ErrOrderNotSubmitted) {
+			t.Errorf("Expected order %d not to be submitted, got %v", res.Index, res.Err)
+		}
+	}
+
+	// Orders not started when the context ends are not submitted either
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, _ = b.Submit(ctx, batchTestOrders(client, "BTC/USDT"))
+	if !errors.Is(results[0].Err, ErrOrderNotSubmitted) || !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("Expected a canceled order, got %v", results[0].Err)
+	}
+}
+
+func TestBatchSubmitterRateLimited(t *testing.T) {
+	server, _ := newBatchTestServer(t)
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	client.SetRateLimiter(NewRateLimiter(100, 1))
+
+	start := time.Now()
+	_, err := NewBatchSubmitter(client).Submit(context.Background(), batchTestOrders(client, "A", "B", "C", "D", "E"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected 5 orders at 100/s to take at least 40ms, took %v", elapsed)
+	}
+}
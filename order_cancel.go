@@ -12,6 +12,13 @@ type CancelOrderService struct {
 	orderID int64
 }
 
+// Clone returns a copy of the service that is configured and sent
+// independently of s
+func (s *CancelOrderService) Clone() *CancelOrderService {
+	clone := *s
+	return &clone
+}
+
 // OrderID sets the order ID to cancel
 func (s *CancelOrderService) OrderID(orderID int64) *CancelOrderService {
 	s.orderID = orderID
@@ -23,14 +30,11 @@ func (s *CancelOrderService) OrderID(orderID int64) *CancelOrderService {
 func (s *CancelOrderService) Do(ctx context.Context, opts ...RequestOption) error {
 	r := &request{
 		method:   http.MethodDelete,
-		endpoint: fmt.Sprintf("/v2/orders/%d", s.orderID),
+		endpoint: fmt.Sprintf("/{version}/orders/%d", s.orderID),
+		versions: orderAPIVersions,
 		secType:  secTypeSigned,
 	}
 
-	_, err := s.c.callAPI(ctx, r, opts...)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	_, err := doRequest[struct{}](ctx, s.c, r, nil, opts...)
+	return err
 }
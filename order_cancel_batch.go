@@ -1,10 +1,9 @@
 package versifi
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"net/http"
+	"slices"
 )
 
 // CancelBatchOrderService cancels multiple orders by their IDs
@@ -13,6 +12,15 @@ type CancelBatchOrderService struct {
 	orderIDs []int64
 }
 
+// Clone returns a copy of the service that is configured and sent
+// independently of s
+func (s *CancelBatchOrderService) Clone() *CancelBatchOrderService {
+	clone := *s
+	// Appending to either copy reallocates, leaving the other unchanged
+	clone.orderIDs = slices.Clip(s.orderIDs)
+	return &clone
+}
+
 // OrderIDs sets the order IDs to cancel
 func (s *CancelBatchOrderService) OrderIDs(orderIDs []int64) *CancelBatchOrderService {
 	s.orderIDs = orderIDs
@@ -35,7 +43,8 @@ type CancelBatchRequest struct {
 func (s *CancelBatchOrderService) Do(ctx context.Context, opts ...RequestOption) error {
 	r := &request{
 		method:   http.MethodDelete,
-		endpoint: "/v2/orders/batch",
+		endpoint: "/{version}/orders/batch",
+		versions: orderAPIVersions,
 		secType:  secTypeSigned,
 	}
 
@@ -44,17 +53,6 @@ func (s *CancelBatchOrderService) Do(ctx context.Context, opts ...RequestOption)
 		IDs: s.orderIDs,
 	}
 
-	bodyBytes, err := json.Marshal(body)
-	if err != nil {
-		return err
-	}
-
-	r.body = bytes.NewReader(bodyBytes)
-
-	_, err = s.c.callAPI(ctx, r, opts...)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	_, err := doRequest[struct{}](ctx, s.c, r, body, opts...)
+	return err
 }
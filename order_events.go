@@ -0,0 +1,185 @@
+package versifi
+
+import (
+	"sort"
+	"sync"
+)
+
+// OrderEventType is the kind of an order lifecycle event
+type OrderEventType string
+
+const (
+	OrderAccepted        OrderEventType = "ORDER_ACCEPTED"
+	OrderPartiallyFilled OrderEventType = "ORDER_PARTIALLY_FILLED"
+	OrderFilled          OrderEventType = "ORDER_FILLED"
+	OrderCanceled        OrderEventType = "ORDER_CANCELED"
+	OrderRejected        OrderEventType = "ORDER_REJECTED"
+	OrderExpired         OrderEventType = "ORDER_EXPIRED"
+	// LegFilled reports a change in the filled quantity of a pair order leg
+	LegFilled OrderEventType = "LEG_FILLED"
+)
+
+// OrderEvent is a typed order lifecycle event
+type OrderEvent struct {
+	Type   OrderEventType
+	Order  OrderState
+	Leg    *LegState // Set for LegFilled
+	Source OrderUpdateSource
+}
+
+// OrderEventHandler handles order events
+type OrderEventHandler func(event OrderEvent)
+
+// OrderEventFilter selects the events delivered to a subscriber
+type OrderEventFilter func(event OrderEvent) bool
+
+// EventTypes selects events of the given types
+func EventTypes(types ...OrderEventType) OrderEventFilter {
+	return func(event OrderEvent) bool {
+		for _, t := range types {
+			if event.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// EventOrder selects events of one order
+func EventOrder(orderID int64) OrderEventFilter {
+	return func(event OrderEvent) bool {
+		return event.Order.OrderID == orderID
+	}
+}
+
+// EventSymbol selects events of orders in symbol on exchange
+func EventSymbol(exchange ExchangeType, symbol string) OrderEventFilter {
+	return func(event OrderEvent) bool {
+		return event.Order.Exchange == exchange && event.Order.Symbol == symbol
+	}
+}
+
+// EventBus delivers order events to any number of filtered subscribers.
+//
+//	bus := versifi.NewEventBus()
+//	bus.Attach(tracker)
+//	bus.Subscribe(onFill, versifi.EventTypes(versifi.OrderFilled))
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[int]eventSubscriber
+	nextID      int
+}
+
+type eventSubscriber struct {
+	handler OrderEventHandler
+	filters []OrderEventFilter
+}
+
+// NewEventBus creates an event bus with no subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]eventSubscriber)}
+}
+
+// Subscribe registers handler for events accepted by every filter and returns
+// a function that removes it. Handlers are called synchronously, in
+// subscription order.
+func (b *EventBus) Subscribe(handler OrderEventHandler, filters ...OrderEventFilter) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = eventSubscriber{handler: handler, filters: filters}
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+// Publish delivers event to the matching subscribers
+func (b *EventBus) Publish(event OrderEvent) {
+	b.mu.RLock()
+	ids := make([]int, 0, len(b.subscribers))
+	for id := range b.subscribers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	subscribers := make([]eventSubscriber, len(ids))
+	for i, id := range ids {
+		subscribers[i] = b.subscribers[id]
+	}
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		if s.accepts(event) {
+			s.handler(event)
+		}
+	}
+}
+
+// Attach publishes the events derived from every update of tracker and
+// returns a function that stops it
+func (b *EventBus) Attach(tracker *OrderTracker) (detach func()) {
+	return tracker.Subscribe(func(update OrderUpdate) {
+		for _, event := range OrderEventsFromUpdate(update) {
+			b.Publish(event)
+		}
+	})
+}
+
+func (s eventSubscriber) accepts(event OrderEvent) bool {
+	for _, filter := range s.filters {
+		if !filter(event) {
+			return false
+		}
+	}
+	return true
+}
+
+// OrderEventsFromUpdate derives the lifecycle events of an order update
+func OrderEventsFromUpdate(u OrderUpdate) []OrderEvent {
+	var events []OrderEvent
+	add := func(t OrderEventType, leg *LegState) {
+		events = append(events, OrderEvent{Type: t, Order: u.Order, Leg: leg, Source: u.Source})
+	}
+
+	statusChanged := u.Order.Status != u.Previous.Status
+	switch u.Order.Status {
+	case OrderStatusNew:
+		if statusChanged {
+			add(OrderAccepted, nil)
+		}
+	case OrderStatusPartiallyFilled:
+		if statusChanged || u.Order.FilledQuantity != u.Previous.FilledQuantity {
+			add(OrderPartiallyFilled, nil)
+		}
+	case OrderStatusFilled:
+		if statusChanged {
+			add(OrderFilled, nil)
+		}
+	case OrderStatusCanceled:
+		if statusChanged {
+			add(OrderCanceled, nil)
+		}
+	case OrderStatusRejected:
+		if statusChanged {
+			add(OrderRejected, nil)
+		}
+	case OrderStatusExpired:
+		if statusChanged {
+			add(OrderExpired, nil)
+		}
+	}
+
+	previousLegs := make(map[int64]LegState, len(u.Previous.Legs))
+	for _, leg := range u.Previous.Legs {
+		previousLegs[leg.LegID] = leg
+	}
+	for i := range u.Order.Legs {
+		leg := u.Order.Legs[i]
+		if prev, ok := previousLegs[leg.LegID]; !ok || prev.FilledQuantity != leg.FilledQuantity {
+			add(LegFilled, &leg)
+		}
+	}
+	return events
+}
@@ -0,0 +1,62 @@
+package versifi
+
+import "testing"
+
+func TestEventBus(t *testing.T) {
+	tracker := NewOrderTracker(NewClient("test-key", "test-secret"))
+	bus := NewEventBus()
+	bus.Attach(tracker)
+
+	var all, fills []OrderEvent
+	bus.Subscribe(func(e OrderEvent) { all = append(all, e) })
+	unsubscribe := bus.Subscribe(func(e OrderEvent) { fills = append(fills, e) },
+		EventTypes(OrderPartiallyFilled, OrderFilled), EventOrder(1))
+
+	trade := func(id int64, cumulative string) *WsChildOrder {
+		return &WsChildOrder{ID: 1, Trades: []WsTrade{{TradeID: id, CummulativeFilledQuantity: cumulative, AveragePrice: "10"}}}
+	}
+	basic := func(orderID int64, status OrderStatusType, ts int64, child *WsChildOrder) *WsExecutionReportDetail {
+		return &WsExecutionReportDetail{
+			OrderID:          orderID,
+			Status:           status,
+			Timestamp:        TimestampFromEpoch(ts),
+			RequestOrderType: "basic",
+			Basic:            &WsBasicOrderDetail{Symbol: "BTC/USDT", Exchange: ExchangeBinanceSpot, ChildOrder: child},
+		}
+	}
+
+	tracker.ApplyExecutionReport(basic(1, OrderStatusNew, 1, nil))
+	tracker.ApplyExecutionReport(basic(1, OrderStatusPartiallyFilled, 2, trade(1, "1")))
+	tracker.ApplyExecutionReport(basic(1, OrderStatusPartiallyFilled, 3, trade(2, "2")))
+	tracker.ApplyExecutionReport(basic(2, OrderStatusRejected, 4, nil))
+	unsubscribe()
+	tracker.ApplyExecutionReport(basic(1, OrderStatusFilled, 5, trade(3, "3")))
+
+	want := []OrderEventType{OrderAccepted, OrderPartiallyFilled, OrderPartiallyFilled, OrderRejected, OrderFilled}
+	if len(all) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), all)
+	}
+	for i, e := range all {
+		if e.Type != want[i] || e.Source != OrderSourceWebsocket {
+			t.Errorf("Event %d: expected %s, got %s", i, want[i], e.Type)
+		}
+	}
+
+	if len(fills) != 2 || fills[1].Order.FilledQuantity != "2" {
+		t.Errorf("Unexpected filtered events %+v", fills)
+	}
+}
+
+func TestOrderEventsLegFilled(t *testing.T) {
+	events := OrderEventsFromUpdate(OrderUpdate{
+		Previous: OrderState{OrderID: 1, Status: OrderStatusPartiallyFilled, Legs: []LegState{{LegID: 1, FilledQuantity: "1"}}},
+		Order: OrderState{OrderID: 1, Status: OrderStatusPartiallyFilled, Legs: []LegState{
+			{LegID: 1, FilledQuantity: "1"},
+			{LegID: 2, FilledQuantity: "0.5"},
+		}},
+	})
+
+	if len(events) != 1 || events[0].Type != LegFilled || events[0].Leg.LegID != 2 {
+		t.Errorf("Expected one LegFilled event for leg 2, got %+v", events)
+	}
+}
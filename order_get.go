@@ -5,12 +5,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+// waitForChangePollInterval is the polling interval of WaitForChange against
+// servers without long polling
+const waitForChangePollInterval = time.Second
+
 // GetOrderService retrieves order details by ID
 type GetOrderService struct {
-	c       *Client
-	orderID int64
+	c           *Client
+	orderID     int64
+	wait        time.Duration
+	knownStatus OrderStatusType
+}
+
+// Clone returns a copy of the service that is configured and sent
+// independently of s
+func (s *GetOrderService) Clone() *GetOrderService {
+	clone := *s
+	return &clone
 }
 
 // OrderID sets the order ID to retrieve
@@ -19,13 +34,41 @@ func (s *GetOrderService) OrderID(orderID int64) *GetOrderService {
 	return s
 }
 
+// WaitForChange makes Do block for up to timeout until the order's status
+// changes, then return the order. The order is returned unchanged when the
+// timeout elapses. Looping with WaitForChange gets near real time updates
+// without a WebSocket:
+//
+//	svc := client.NewGetOrderService().OrderID(id).WaitForChange(20 * time.Second)
+//	order, err := svc.Do(ctx)
+//	for err == nil && !order.Status.IsFinal() {
+//		order, err = svc.KnownStatus(order.Status).Do(ctx)
+//	}
+//
+// The wait_ms and status parameters ask the server to hold the request
+// until the change (a long poll). Servers without long polling answer at
+// once; the order is then polled every second until it changes or the
+// timeout elapses. timeout must stay below the timeout of Client.HTTPClient.
+func (s *GetOrderService) WaitForChange(timeout time.Duration) *GetOrderService {
+	s.wait = timeout
+	return s
+}
+
+// KnownStatus sets the status WaitForChange waits to change from, so a
+// change between two calls is not missed. Without it, Do first loads the
+// order to learn its status.
+func (s *GetOrderService) KnownStatus(status OrderStatusType) *GetOrderService {
+	s.knownStatus = status
+	return s
+}
+
 // GetOrderResponse represents the response structure for getting an order
 type GetOrderResponse struct {
 	OrderID          int64           `json:"order_id"`
 	ClientOrderID    int64           `json:"client_order_id"`
 	OrderType        string          `json:"order_type"`
 	Status           OrderStatusType `json:"status"`
-	Timestamp        int64           `json:"timestamp"`
+	Timestamp        Timestamp       `json:"timestamp"`
 	RequestOrderType string          `json:"request_order_type"`
 	AlgoOrder        *AlgoOrderDetail `json:"algo_order,omitempty"`
 	BasicOrder       *BasicOrderDetail `json:"basic_order,omitempty"`
@@ -125,22 +168,57 @@ type Trade struct {
 
 // Do executes the request
 func (s *GetOrderService) Do(ctx context.Context, opts ...RequestOption) (res *GetOrderResponse, err error) {
+	if s.wait > 0 {
+		return s.waitForChange(ctx, opts...)
+	}
+	return s.get(ctx, 0, "", opts...)
+}
+
+// get loads the order, asking the server to hold the request for up to
+// wait while its status is known
+func (s *GetOrderService) get(ctx context.Context, wait time.Duration, known OrderStatusType, opts ...RequestOption) (*GetOrderResponse, error) {
 	r := &request{
 		method:   http.MethodGet,
-		endpoint: fmt.Sprintf("/v2/orders/%d", s.orderID),
+		endpoint: fmt.Sprintf("/{version}/orders/%d", s.orderID),
+		versions: orderAPIVersions,
 		secType:  secTypeSigned,
 	}
-
-	data, err := s.c.callAPI(ctx, r, opts...)
-	if err != nil {
-		return nil, err
+	if wait > 0 {
+		r.setParam("wait_ms", strconv.FormatInt(wait.Milliseconds(), 10))
+		r.setParam("status", string(known))
 	}
 
-	res = new(GetOrderResponse)
-	err = json.Unmarshal(data, res)
-	if err != nil {
-		return nil, err
+	return doRequest[GetOrderResponse](ctx, s.c, r, nil, opts...)
+}
+
+// waitForChange implements WaitForChange. A response before the deadline
+// with the status unchanged comes from a server without long polling, which
+// is then polled every waitForChangePollInterval.
+func (s *GetOrderService) waitForChange(ctx context.Context, opts ...RequestOption) (*GetOrderResponse, error) {
+	clock := s.c.clock()
+	deadline := clock.Now().Add(s.wait)
+
+	known := s.knownStatus
+	if known == "" {
+		res, err := s.get(ctx, 0, "", opts...)
+		if err != nil {
+			return nil, err
+		}
+		known = res.Status
 	}
 
-	return res, nil
+	for {
+		remaining := deadline.Sub(clock.Now())
+		res, err := s.get(ctx, max(remaining, time.Millisecond), known, opts...)
+		if err != nil {
+			return nil, err
+		}
+		remaining = deadline.Sub(clock.Now())
+		if res.Status != known || remaining <= 0 {
+			return res, nil
+		}
+		if !sleep(clock, min(waitForChangePollInterval, remaining), ctx.Done()) {
+			return nil, ctx.Err()
+		}
+	}
 }
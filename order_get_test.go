@@ -0,0 +1,73 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestGetOrderWaitForChange runs against a server without long polling,
+// which answers at once: the client waits for the change itself
+func TestGetOrderWaitForChange(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []url.Values
+		filled  time.Time
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		queries = append(queries, r.URL.Query())
+		status := OrderStatusNew
+		if !filled.IsZero() && time.Now().After(filled) {
+			status = OrderStatusPartiallyFilled
+		}
+		json.NewEncoder(w).Encode(GetOrderResponse{OrderID: 7, Status: status})
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	// Nothing changes: the order is returned after the timeout
+	start := time.Now()
+	order, err := client.NewGetOrderService().OrderID(7).WaitForChange(300 * time.Millisecond).Do(ctx)
+	elapsed := time.Since(start)
+	if err != nil || order.Status != OrderStatusNew || elapsed < 300*time.Millisecond {
+		t.Fatalf("Expected the unchanged order after the timeout, got %+v after %v (%v)", order, elapsed, err)
+	}
+	mu.Lock()
+	if len(queries) != 3 || queries[0].Get("wait_ms") != "" || queries[1].Get("status") != "NEW" || queries[1].Get("wait_ms") == "" {
+		t.Errorf("Expected a load, a long poll and one more poll, got %v", queries)
+	}
+	queries = nil
+	filled = time.Now().Add(200 * time.Millisecond)
+	mu.Unlock()
+
+	// The change is picked up by the next poll
+	start = time.Now()
+	order, err = client.NewGetOrderService().OrderID(7).WaitForChange(5 * time.Second).KnownStatus(OrderStatusNew).Do(ctx)
+	elapsed = time.Since(start)
+	if err != nil || order.Status != OrderStatusPartiallyFilled || elapsed < 200*time.Millisecond || elapsed > 4*time.Second {
+		t.Fatalf("Expected the changed order, got %+v after %v (%v)", order, elapsed, err)
+	}
+	mu.Lock()
+	if len(queries) != 2 {
+		t.Errorf("Expected the order to be polled, not requested in a loop, got %d requests", len(queries))
+	}
+	mu.Unlock()
+
+	// A canceled context ends the wait
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = client.NewGetOrderService().OrderID(7).WaitForChange(5 * time.Second).KnownStatus(OrderStatusPartiallyFilled).Do(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+}
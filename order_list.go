@@ -2,7 +2,6 @@ package versifi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -15,6 +14,13 @@ type ListOpenOrdersService struct {
 	status OrderStatusType
 }
 
+// Clone returns a copy of the service that is configured and sent
+// independently of s
+func (s *ListOpenOrdersService) Clone() *ListOpenOrdersService {
+	clone := *s
+	return &clone
+}
+
 func (s *ListOpenOrdersService) Limit(limit int64) *ListOpenOrdersService {
 	s.limit = limit
 	return s
@@ -31,19 +37,20 @@ func (s *ListOpenOrdersService) Status(status OrderStatusType) *ListOpenOrdersSe
 }
 
 type ListOrderItem struct {
-	OrderID          int64  `json:"order_id"`
-	ClientOrderID    int64  `json:"client_order_id"`
-	Status           string `json:"status"`
-	Timestamp        int64  `json:"timestamp"`
-	RequestOrderType string `json:"request_order_type"`
-	RejectReason     string `json:"reject_reason"`
+	OrderID          int64     `json:"order_id"`
+	ClientOrderID    int64     `json:"client_order_id"`
+	Status           string    `json:"status"`
+	Timestamp        Timestamp `json:"timestamp"`
+	RequestOrderType string    `json:"request_order_type"`
+	RejectReason     string    `json:"reject_reason"`
 }
 
 // Do executes the request
 func (s *ListOpenOrdersService) Do(ctx context.Context, opts ...RequestOption) (orders []ListOrderItem, err error) {
 	r := &request{
 		method:   http.MethodGet,
-		endpoint: "/v2/orders",
+		endpoint: "/{version}/orders",
+		versions: orderAPIVersions,
 		secType:  secTypeSigned,
 	}
 
@@ -60,15 +67,9 @@ func (s *ListOpenOrdersService) Do(ctx context.Context, opts ...RequestOption) (
 		r.setParam("status", string(s.status))
 	}
 
-	data, err := s.c.callAPI(ctx, r, opts...)
+	res, err := doRequest[[]ListOrderItem](ctx, s.c, r, nil, opts...)
 	if err != nil {
 		return nil, err
 	}
-
-	err = json.Unmarshal(data, &orders)
-	if err != nil {
-		return nil, err
-	}
-
-	return orders, nil
+	return *res, nil
 }
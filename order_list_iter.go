@@ -0,0 +1,49 @@
+//go:build go1.23
+
+package versifi
+
+import (
+	"context"
+	"iter"
+)
+
+// defaultListPageSize is the page size All requests when no Limit is set
+const defaultListPageSize = 100
+
+// All returns an iterator over every order matching s, requesting pages of
+// Limit orders (100 when unset) from Offset on as the loop consumes them.
+// A failed request is yielded with a zero ListOrderItem and ends the
+// iteration. Pages are requested by offset, so orders created or closed
+// while iterating may be skipped or seen twice.
+//
+//	for order, err := range client.NewListOpenOrdersService().All(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (s *ListOpenOrdersService) All(ctx context.Context, opts ...RequestOption) iter.Seq2[ListOrderItem, error] {
+	s = s.Clone()
+	return func(yield func(ListOrderItem, error) bool) {
+		page := s.Clone()
+		if page.limit <= 0 {
+			page.limit = defaultListPageSize
+		}
+		for {
+			items, err := page.Do(ctx, opts...)
+			if err != nil {
+				yield(ListOrderItem{}, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if int64(len(items)) < page.limit {
+				return
+			}
+			page.offset += int64(len(items))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+//go:build go1.23
+
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newListTestServer lists orders 1 to n by limit and offset, failing the
+// request at failOffset when it is not negative
+func newListTestServer(t *testing.T, n, failOffset int) (*Client, *[]string) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if offset == failOffset {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(APIError{Code: 500, Message: "internal error"})
+			return
+		}
+		items := []ListOrderItem{}
+		for id := offset + 1; id <= n && len(items) < limit; id++ {
+			items = append(items, ListOrderItem{OrderID: int64(id)})
+		}
+		json.NewEncoder(w).Encode(items)
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	return client, &queries
+}
+
+func TestListOpenOrdersAll(t *testing.T) {
+	client, queries := newListTestServer(t, 25, -1)
+	var ids []int64
+	for order, err := range client.NewListOpenOrdersService().Limit(10).All(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, order.OrderID)
+	}
+	if len(ids) != 25 || ids[0] != 1 || ids[24] != 25 {
+		t.Errorf("Expected orders 1 to 25, got %v", ids)
+	}
+	if len(*queries) != 3 || (*queries)[2] != "limit=10&offset=20" {
+		t.Errorf("Unexpected requests %q", *queries)
+	}
+
+	// Stopping early requests no further pages
+	*queries = nil
+	for order := range client.NewListOpenOrdersService().All(context.Background()) {
+		if order.OrderID == 5 {
+			break
+		}
+	}
+	if len(*queries) != 1 || (*queries)[0] != "limit=100" {
+		t.Errorf("Expected one request of the default page size, got %q", *queries)
+	}
+}
+
+func TestListOpenOrdersAllError(t *testing.T) {
+	client, _ := newListTestServer(t, 25, 10)
+	var n int
+	var errs []error
+	for _, err := range client.NewListOpenOrdersService().Limit(10).All(context.Background()) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n++
+	}
+	if n != 10 || len(errs) != 1 || !IsAPIError(errs[0]) {
+		t.Errorf("Expected 10 orders and then an API error, got %d and %v", n, errs)
+	}
+}
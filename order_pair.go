@@ -1,9 +1,8 @@
 package versifi
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"maps"
 	"net/http"
 )
 
@@ -31,12 +30,37 @@ type PairLeg struct {
 	Params           map[string]interface{} `json:"params,omitempty"`
 }
 
+// Clone returns a copy of the service that is configured and sent
+// independently of s. Configure a template once and clone it per order,
+// concurrently if need be, without the orders sharing state.
+func (s *CreatePairOrderService) Clone() *CreatePairOrderService {
+	clone := *s
+	clone.lead = s.lead.clone()
+	clone.params = maps.Clone(s.params)
+	clone.secondary = s.secondary.clone()
+	return &clone
+}
+
+// clone copies the leg and its params
+func (l *PairLeg) clone() *PairLeg {
+	if l == nil {
+		return nil
+	}
+	clone := *l
+	clone.Params = maps.Clone(l.Params)
+	return &clone
+}
+
 // ClientOrderID sets the client order ID
 func (s *CreatePairOrderService) ClientOrderID(clientOrderID int64) *CreatePairOrderService {
 	s.clientOrderID = &clientOrderID
 	return s
 }
 
+func (s *CreatePairOrderService) setClientOrderID(clientOrderID int64) {
+	s.clientOrderID = &clientOrderID
+}
+
 // Lead sets the lead leg configuration
 func (s *CreatePairOrderService) Lead(lead *PairLeg) *CreatePairOrderService {
 	s.lead = lead
@@ -100,9 +124,11 @@ type PairOrderLeadFull struct {
 // Do executes the request
 func (s *CreatePairOrderService) Do(ctx context.Context, opts ...RequestOption) (res *OrderResponse, err error) {
 	r := &request{
-		method:   http.MethodPost,
-		endpoint: "/v2/orders/pair/",
-		secType:  secTypeSigned,
+		method:       http.MethodPost,
+		endpoint:     "/{version}/orders/pair/",
+		versions:     orderAPIVersions,
+		secType:      secTypeSigned,
+		submitsOrder: true,
 	}
 
 	// Build request body based on API documentation structure
@@ -135,24 +161,12 @@ func (s *CreatePairOrderService) Do(ctx context.Context, opts ...RequestOption)
 		Secondary:     s.secondary,
 		Style:         s.style,
 	}
-
-	bodyBytes, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
-	}
-
-	r.body = bytes.NewReader(bodyBytes)
-
-	data, err := s.c.callAPI(ctx, r, opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	res = new(OrderResponse)
-	err = json.Unmarshal(data, res)
-	if err != nil {
-		return nil, err
+	for _, leg := range []*PairLeg{s.lead, s.secondary} {
+		if leg != nil {
+			r.riskOrders = append(r.riskOrders, RiskOrder{Exchange: leg.Exchange, Symbol: leg.Symbol})
+			r.exchangeOrders = append(r.exchangeOrders, ExchangeOrder{Exchange: leg.Exchange})
+		}
 	}
 
-	return res, nil
+	return doRequest[OrderResponse](ctx, s.c, r, body, opts...)
 }
@@ -0,0 +1,120 @@
+package versifi
+
+import "sync"
+
+// orderTransitions is the valid order status graph. Repeating a status is
+// valid, so duplicate reports are not anomalies. Final statuses have no
+// way out.
+var orderTransitions = map[OrderStatusType][]OrderStatusType{
+	"": {
+		OrderStatusNew, OrderStatusPartiallyFilled, OrderStatusFilled,
+		OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired,
+	},
+	OrderStatusNew: {
+		OrderStatusNew, OrderStatusPartiallyFilled, OrderStatusFilled,
+		OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired,
+	},
+	OrderStatusPartiallyFilled: {
+		OrderStatusPartiallyFilled, OrderStatusFilled,
+		OrderStatusCanceled, OrderStatusExpired,
+	},
+	OrderStatusFilled:   {OrderStatusFilled},
+	OrderStatusCanceled: {OrderStatusCanceled},
+	OrderStatusRejected: {OrderStatusRejected},
+	OrderStatusExpired:  {OrderStatusExpired},
+}
+
+// Transitions returns the statuses an order in status s may move to. The
+// empty status is an order not seen yet.
+func (s OrderStatusType) Transitions() []OrderStatusType {
+	return append([]OrderStatusType(nil), orderTransitions[s]...)
+}
+
+// CanTransition reports whether an order may move from status from to
+// status to:
+//
+//	NEW → PARTIALLY_FILLED → FILLED
+//	NEW → FILLED | CANCELED | REJECTED | EXPIRED
+//	PARTIALLY_FILLED → CANCELED | EXPIRED
+//
+// Unknown statuses never transition.
+func CanTransition(from, to OrderStatusType) bool {
+	for _, s := range orderTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderAnomaly is a status change that is not in the transition graph,
+// such as a report moving a FILLED order back to NEW
+type OrderAnomaly struct {
+	OrderID int64           `json:"order_id"`
+	From    OrderStatusType `json:"from"`
+	To      OrderStatusType `json:"to"`
+}
+
+// OrderAnomalyHandler handles invalid status changes
+type OrderAnomalyHandler func(anomaly OrderAnomaly)
+
+// OrderStateMachine holds the status of one order and only moves it along
+// the transition graph. Invalid changes leave the status as it is and are
+// passed to the anomaly handler.
+//
+//	m := versifi.NewOrderStateMachine(orderID, func(a versifi.OrderAnomaly) {
+//		log.Printf("order %d: unexpected %s -> %s", a.OrderID, a.From, a.To)
+//	})
+//	m.Apply(versifi.OrderStatusNew)
+//	m.Apply(versifi.OrderStatusFilled)
+//
+// It is safe for concurrent use.
+type OrderStateMachine struct {
+	orderID   int64
+	onAnomaly OrderAnomalyHandler
+
+	mu     sync.Mutex
+	status OrderStatusType
+}
+
+// NewOrderStateMachine creates a state machine for an order not seen yet.
+// handler may be nil.
+func NewOrderStateMachine(orderID int64, handler OrderAnomalyHandler) *OrderStateMachine {
+	return &OrderStateMachine{orderID: orderID, onAnomaly: handler}
+}
+
+// Status returns the current status
+func (m *OrderStateMachine) Status() OrderStatusType {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// CanTransition reports whether the order may move to status
+func (m *OrderStateMachine) CanTransition(status OrderStatusType) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return CanTransition(m.status, status)
+}
+
+// Apply moves the order to status and reports whether it was valid. The
+// empty status is ignored. The anomaly handler is called, without the
+// machine's lock held, for invalid changes.
+func (m *OrderStateMachine) Apply(status OrderStatusType) bool {
+	if status == "" {
+		return true
+	}
+
+	m.mu.Lock()
+	from := m.status
+	ok := CanTransition(from, status)
+	if ok {
+		m.status = status
+	}
+	m.mu.Unlock()
+
+	if !ok && m.onAnomaly != nil {
+		m.onAnomaly(OrderAnomaly{OrderID: m.orderID, From: from, To: status})
+	}
+	return ok
+}
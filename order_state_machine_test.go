@@ -0,0 +1,66 @@
+package versifi
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	for _, tc := range []struct {
+		from, to OrderStatusType
+		want     bool
+	}{
+		{"", OrderStatusFilled, true},
+		{OrderStatusNew, OrderStatusPartiallyFilled, true},
+		{OrderStatusNew, OrderStatusFilled, true},
+		{OrderStatusNew, OrderStatusRejected, true},
+		{OrderStatusPartiallyFilled, OrderStatusPartiallyFilled, true},
+		{OrderStatusPartiallyFilled, OrderStatusCanceled, true},
+		{OrderStatusPartiallyFilled, OrderStatusNew, false},
+		{OrderStatusPartiallyFilled, OrderStatusRejected, false},
+		{OrderStatusFilled, OrderStatusFilled, true},
+		{OrderStatusFilled, OrderStatusCanceled, false},
+		{OrderStatusCanceled, OrderStatusPartiallyFilled, false},
+		{OrderStatusNew, "UNKNOWN", false},
+		{"UNKNOWN", OrderStatusNew, false},
+	} {
+		if got := CanTransition(tc.from, tc.to); got != tc.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestOrderStateMachine(t *testing.T) {
+	var anomalies []OrderAnomaly
+	m := NewOrderStateMachine(7, func(a OrderAnomaly) { anomalies = append(anomalies, a) })
+
+	for _, status := range []OrderStatusType{OrderStatusNew, "", OrderStatusPartiallyFilled, OrderStatusNew, OrderStatusFilled, OrderStatusCanceled} {
+		m.Apply(status)
+	}
+	if m.Status() != OrderStatusFilled {
+		t.Errorf("Expected FILLED, got %s", m.Status())
+	}
+	want := []OrderAnomaly{
+		{OrderID: 7, From: OrderStatusPartiallyFilled, To: OrderStatusNew},
+		{OrderID: 7, From: OrderStatusFilled, To: OrderStatusCanceled},
+	}
+	if len(anomalies) != len(want) || anomalies[0] != want[0] || anomalies[1] != want[1] {
+		t.Errorf("Expected anomalies %+v, got %+v", want, anomalies)
+	}
+	if m.CanTransition(OrderStatusExpired) {
+		t.Error("Expected a filled order not to expire")
+	}
+}
+
+func TestOrderTrackerAnomaly(t *testing.T) {
+	tracker := NewOrderTracker(NewClient("test-key", "test-secret"))
+	var anomalies []OrderAnomaly
+	tracker.SetAnomalyHandler(func(a OrderAnomaly) { anomalies = append(anomalies, a) })
+
+	tracker.ApplyExecutionReport(&WsExecutionReportDetail{OrderID: 1, Status: OrderStatusFilled, Timestamp: TimestampFromEpoch(1000)})
+	tracker.ApplyExecutionReport(&WsExecutionReportDetail{OrderID: 1, Status: OrderStatusCanceled, Timestamp: TimestampFromEpoch(2000)})
+
+	if s, _ := tracker.Order(1); s.Status != OrderStatusFilled {
+		t.Errorf("Expected the order to stay FILLED, got %s", s.Status)
+	}
+	if len(anomalies) != 1 || anomalies[0] != (OrderAnomaly{OrderID: 1, From: OrderStatusFilled, To: OrderStatusCanceled}) {
+		t.Errorf("Unexpected anomalies %+v", anomalies)
+	}
+}
@@ -0,0 +1,229 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSubmitAttempts       = 3
+	defaultSubmitRetryDelay     = 500 * time.Millisecond
+	defaultSubmitAttemptTimeout = 10 * time.Second
+	defaultSubmitLookupPages    = 10
+	submitLookupPageSize        = 100
+)
+
+// ErrOrderOutcomeUnknown is returned by SubmitManager when every attempt
+// failed without a definite answer and the order could not be found
+var ErrOrderOutcomeUnknown = errors.New("order outcome unknown")
+
+// errLookupExhausted is returned by the default lookup when the order was
+// not among the LookupPages pages listed but more orders remain
+var errLookupExhausted = errors.New("order not found in the pages listed and more orders remain")
+
+// ClientOrderLookup finds an order by client order ID. found is false when
+// no such order exists.
+type ClientOrderLookup func(ctx context.Context, clientOrderID int64) (res *OrderResponse, found bool, err error)
+
+// SubmitManager places orders at most once per client order ID.
+//
+// When an attempt fails without a definite answer (a network error, a
+// timeout or a 5xx response) the order may or may not have been placed, so
+// the manager looks it up by client order ID before trying again. Attempts
+// that failed before reaching the server, such as a dial timeout, are
+// retried without a lookup. Rejections by the API, the client's risk and
+// exchange checks or its kill switch end the submission at once and are not
+// remembered. Repeated or concurrent Submit calls for the same client order
+// ID share one outcome.
+type SubmitManager struct {
+	// MaxAttempts is the number of create requests tried, default 3
+	MaxAttempts int
+	// RetryDelay is the pause between attempts, default 500ms
+	RetryDelay time.Duration
+	// AttemptTimeout bounds each create request, default 10s
+	AttemptTimeout time.Duration
+	// Lookup finds orders by client order ID. The default pages through
+	// the order list without a status filter, so filled and canceled
+	// orders are found too, up to LookupPages pages of 100 orders. When
+	// every page is full the order may be further down the list and the
+	// outcome is unknown; accounts with longer histories should set a
+	// Lookup backed by their own records.
+	Lookup      ClientOrderLookup
+	LookupPages int
+
+	c           *Client
+	mu          sync.Mutex
+	submissions map[int64]*submission
+}
+
+type submission struct {
+	done chan struct{}
+	res  *OrderResponse
+	err  error
+}
+
+// NewSubmitManager creates a submit manager using client
+func NewSubmitManager(client *Client) *SubmitManager {
+	return &SubmitManager{
+		MaxAttempts:    defaultSubmitAttempts,
+		RetryDelay:     defaultSubmitRetryDelay,
+		AttemptTimeout: defaultSubmitAttemptTimeout,
+		LookupPages:    defaultSubmitLookupPages,
+		c:              client,
+		submissions:    make(map[int64]*submission),
+	}
+}
+
+// clientOrderIDSetter is implemented by the Create*OrderService builders
+type clientOrderIDSetter interface {
+	setClientOrderID(clientOrderID int64)
+}
+
+// Submit places order with clientOrderID. The Create*OrderService builders
+// have the ID set for them; other OrderCreators must already carry it.
+// An order that ends in a definite error may be submitted again.
+func (m *SubmitManager) Submit(ctx context.Context, clientOrderID int64, order OrderCreator, opts ...RequestOption) (*OrderResponse, error) {
+	m.mu.Lock()
+	if s, ok := m.submissions[clientOrderID]; ok {
+		m.mu.Unlock()
+		select {
+		case <-s.done:
+			return s.res, s.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	s := &submission{done: make(chan struct{})}
+	m.submissions[clientOrderID] = s
+	m.mu.Unlock()
+
+	if setter, ok := order.(clientOrderIDSetter); ok {
+		setter.setClientOrderID(clientOrderID)
+	}
+
+	s.res, s.err = m.submit(ctx, clientOrderID, order, opts...)
+
+	// Forget definite failures so the order can be corrected and resubmitted
+	if s.err != nil && !errors.Is(s.err, ErrOrderOutcomeUnknown) {
+		m.mu.Lock()
+		delete(m.submissions, clientOrderID)
+		m.mu.Unlock()
+	}
+	close(s.done)
+	return s.res, s.err
+}
+
+// Forget drops the remembered outcome for clientOrderID
+func (m *SubmitManager) Forget(clientOrderID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.submissions, clientOrderID)
+}
+
+func (m *SubmitManager) submit(ctx context.Context, clientOrderID int64, order OrderCreator, opts ...RequestOption) (*OrderResponse, error) {
+	attempts := m.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var lastErr error
+	// unknown is set once an attempt may have placed the order
+	unknown := false
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 && !sleep(m.c.clock(), m.RetryDelay, ctx.Done()) {
+			break
+		}
+
+		attemptCtx, cancel := m.attemptContext(ctx)
+		res, err := order.Do(attemptCtx, opts...)
+		cancel()
+		if err == nil {
+			return res, nil
+		}
+		if definiteFailure(err) {
+			return nil, err
+		}
+		lastErr = err
+		if notSent(err) {
+			continue
+		}
+		unknown = true
+
+		// The order may have been placed; never retry before checking
+		res, found, lookupErr := m.lookup(ctx, clientOrderID, opts...)
+		if lookupErr != nil {
+			return nil, fmt.Errorf("%w: %v (lookup failed: %v)", ErrOrderOutcomeUnknown, err, lookupErr)
+		}
+		if found {
+			return res, nil
+		}
+	}
+	if !unknown {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("%w: %v", ErrOrderOutcomeUnknown, lastErr)
+}
+
+func (m *SubmitManager) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.AttemptTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, m.AttemptTimeout)
+}
+
+func (m *SubmitManager) lookup(ctx context.Context, clientOrderID int64, opts ...RequestOption) (*OrderResponse, bool, error) {
+	if m.Lookup != nil {
+		return m.Lookup(ctx, clientOrderID)
+	}
+
+	pages := m.LookupPages
+	if pages <= 0 {
+		pages = defaultSubmitLookupPages
+	}
+	for page := 0; page < pages; page++ {
+		// No status filter: the order may already be final
+		items, err := m.c.NewListOpenOrdersService().
+			Limit(submitLookupPageSize).
+			Offset(int64(page*submitLookupPageSize)).
+			Do(ctx, opts...)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, item := range items {
+			if item.ClientOrderID == clientOrderID {
+				return &OrderResponse{
+					OrderID:       item.OrderID,
+					ClientOrderID: item.ClientOrderID,
+					Status:        OrderStatusType(item.Status),
+				}, true, nil
+			}
+		}
+		if len(items) < submitLookupPageSize {
+			return nil, false, nil
+		}
+	}
+	return nil, false, errLookupExhausted
+}
+
+// definiteFailure reports whether err proves the order was not placed, such
+// as a rejection by the API, the client's risk and exchange checks or its
+// kill switch
+func definiteFailure(err error) bool {
+	for _, target := range []error{ErrKillSwitchEngaged, ErrRiskRejected, ErrUnknownExchange, ErrUnsupportedOrder} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.HTTPStatus < http.StatusInternalServerError
+}
+
+// notSent reports whether err proves the request never reached the server
+func notSent(err error) bool {
+	var reqErr *RequestError
+	return errors.As(err, &reqErr) && reqErr.NotSent()
+}
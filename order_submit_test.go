@@ -0,0 +1,318 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// submitTestServer accepts basic orders, optionally failing or stalling the
+// first create request after recording the order
+type submitTestServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	creates int
+	lookups int
+	orders  []ListOrderItem
+	// firstResponse is the status of the first create response; zero stalls it
+	firstResponse int
+}
+
+func newSubmitTestServer(t *testing.T, firstResponse int) *submitTestServer {
+	s := &submitTestServer{firstResponse: firstResponse}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var body BasicOrderRequest
+			json.NewDecoder(r.Body).Decode(&body)
+
+			s.mu.Lock()
+			s.creates++
+			first := s.creates == 1
+			if !first || s.firstResponse == 0 {
+				s.orders = append(s.orders, ListOrderItem{OrderID: int64(100 + s.creates), ClientOrderID: *body.ClientOrderID, Status: "NEW"})
+			}
+			orderID := int64(100 + s.creates)
+			s.mu.Unlock()
+
+			if first && s.firstResponse == 0 {
+				// The order is placed but the response never arrives in time
+				time.Sleep(200 * time.Millisecond)
+				return
+			}
+			if first && s.firstResponse != http.StatusOK {
+				w.WriteHeader(s.firstResponse)
+				json.NewEncoder(w).Encode(APIError{Code: s.firstResponse, Message: "failed"})
+				return
+			}
+			json.NewEncoder(w).Encode(OrderResponse{OrderID: orderID, ClientOrderID: *body.ClientOrderID, Status: OrderStatusNew})
+		case http.MethodGet:
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.lookups++
+			json.NewEncoder(w).Encode(s.orders)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestSubmitManager(s *submitTestServer) (*SubmitManager, *Client) {
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = s.URL
+	m := NewSubmitManager(client)
+	m.RetryDelay = time.Millisecond
+	m.AttemptTimeout = 50 * time.Millisecond
+	return m, client
+}
+
+func testBasicOrder(client *Client) *CreateBasicOrderService {
+	return client.NewCreateBasicOrderService().
+		Exchange(ExchangeBinanceSpot).
+		Symbol("BTC/USDT").
+		Side(SideTypeBuy).
+		OrderType(BasicOrderTypeMarket).
+		Quantity("1")
+}
+
+func TestSubmitManagerFindsTimedOutOrder(t *testing.T) {
+	server := newSubmitTestServer(t, 0)
+	m, client := newTestSubmitManager(server)
+
+	res, err := m.Submit(context.Background(), 77, testBasicOrder(client))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.OrderID != 101 || res.ClientOrderID != 77 {
+		t.Errorf("Expected the placed order to be found, got %+v", res)
+	}
+
+	// The same client order ID is never placed twice
+	res, err = m.Submit(context.Background(), 77, testBasicOrder(client))
+	if err != nil || res.OrderID != 101 {
+		t.Errorf("Expected the remembered outcome, got %+v, %v", res, err)
+	}
+	if server.creates != 1 {
+		t.Errorf("Expected 1 create request, got %d", server.creates)
+	}
+}
+
+func TestSubmitManagerRetriesServerError(t *testing.T) {
+	server := newSubmitTestServer(t, http.StatusServiceUnavailable)
+	m, client := newTestSubmitManager(server)
+
+	res, err := m.Submit(context.Background(), 78, testBasicOrder(client))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.OrderID != 102 || server.creates != 2 {
+		t.Errorf("Expected a second attempt to place the order, got %+v after %d creates", res, server.creates)
+	}
+}
+
+func TestSubmitManagerDefiniteFailure(t *testing.T) {
+	server := newSubmitTestServer(t, http.StatusBadRequest)
+	m, client := newTestSubmitManager(server)
+
+	_, err := m.Submit(context.Background(), 79, testBasicOrder(client))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusBadRequest {
+		t.Fatalf("Expected the API error, got %v", err)
+	}
+	if server.creates != 1 {
+		t.Errorf("Expected no retry after a 400, got %d creates", server.creates)
+	}
+
+	// A rejected order can be submitted again
+	if _, err := m.Submit(context.Background(), 79, testBasicOrder(client)); err != nil {
+		t.Errorf("Unexpected error on resubmission: %v", err)
+	}
+}
+
+func TestSubmitManagerRetriesUnsent(t *testing.T) {
+	server := newSubmitTestServer(t, http.StatusOK)
+	m, client := newTestSubmitManager(server)
+	var dials int
+	dialer := &net.Dialer{}
+	client.HTTPClient = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if dials++; dials == 1 {
+				return nil, errors.New("connection refused")
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}}
+
+	res, err := m.Submit(context.Background(), 80, testBasicOrder(client))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.OrderID != 101 || server.creates != 1 || server.lookups != 0 {
+		t.Errorf("Expected a retry without a lookup, got %+v after %d creates and %d lookups", res, server.creates, server.lookups)
+	}
+
+	// An order that never reached the server is not of unknown outcome
+	client.HTTPClient = &http.Client{Transport: &http.Transport{
+		DialContext: func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		},
+	}}
+	_, err = m.Submit(context.Background(), 81, testBasicOrder(client))
+	var reqErr *RequestError
+	if errors.Is(err, ErrOrderOutcomeUnknown) || !errors.As(err, &reqErr) || !reqErr.NotSent() {
+		t.Errorf("Expected an unsent request error, got %v", err)
+	}
+	if server.lookups != 0 {
+		t.Errorf("Expected no lookups, got %d", server.lookups)
+	}
+}
+
+func TestSubmitManagerConcurrentDedup(t *testing.T) {
+	server := newSubmitTestServer(t, http.StatusOK)
+	m, client := newTestSubmitManager(server)
+
+	var wg sync.WaitGroup
+	results := make([]*OrderResponse, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = m.Submit(context.Background(), 80, testBasicOrder(client))
+		}(i)
+	}
+	wg.Wait()
+
+	for _, res := range results {
+		if res == nil || res.OrderID != results[0].OrderID {
+			t.Fatalf("Expected every caller to share one outcome, got %+v", results)
+		}
+	}
+	if server.creates != 1 {
+		t.Errorf("Expected 1 create request, got %d", server.creates)
+	}
+}
+
+func TestSubmitManagerLookupExhausted(t *testing.T) {
+	s := newSubmitTestServer(t, http.StatusBadGateway)
+	// Full pages of other orders: the order may be further down the list
+	for i := 0; i < submitLookupPageSize; i++ {
+		s.orders = append(s.orders, ListOrderItem{OrderID: int64(i), ClientOrderID: int64(1000 + i), Status: "FILLED"})
+	}
+	m, client := newTestSubmitManager(s)
+	m.LookupPages = 2
+
+	_, err := m.Submit(context.Background(), 42, testBasicOrder(client))
+	if !errors.Is(err, ErrOrderOutcomeUnknown) {
+		t.Fatalf("Expected ErrOrderOutcomeUnknown, got %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.creates != 1 || s.lookups != 2 {
+		t.Errorf("Expected no retry after an exhausted lookup, got %d creates and %d lookups", s.creates, s.lookups)
+	}
+}
+
+func TestSubmitManagerFindsFinalOrder(t *testing.T) {
+	s := newSubmitTestServer(t, http.StatusBadGateway)
+	s.orders = append(s.orders, ListOrderItem{OrderID: 7, ClientOrderID: 42, Status: "FILLED"})
+	m, client := newTestSubmitManager(s)
+
+	res, err := m.Submit(context.Background(), 42, testBasicOrder(client))
+	if err != nil || res.OrderID != 7 || res.Status != OrderStatusFilled {
+		t.Fatalf("Expected the filled order, got %+v (%v)", res, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.creates != 1 {
+		t.Errorf("Expected no retry once the order was found, got %d creates", s.creates)
+	}
+}
+
+func TestSubmitManagerKillSwitch(t *testing.T) {
+	s := newSubmitTestServer(t, http.StatusOK)
+	m, client := newTestSubmitManager(s)
+
+	if _, err := client.KillSwitch(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s.mu.Lock()
+	lookups := s.lookups
+	s.mu.Unlock()
+
+	if _, err := m.Submit(context.Background(), 43, testBasicOrder(client)); !errors.Is(err, ErrKillSwitchEngaged) || errors.Is(err, ErrOrderOutcomeUnknown) {
+		t.Fatalf("Expected ErrKillSwitchEngaged, got %v", err)
+	}
+	s.mu.Lock()
+	if s.creates != 0 || s.lookups != lookups {
+		t.Errorf("Expected no create or lookup requests, got %d creates and %d lookups", s.creates, s.lookups-lookups)
+	}
+	s.mu.Unlock()
+
+	// The failure is not remembered once the kill switch is reset
+	client.ResetKillSwitch()
+	res, err := m.Submit(context.Background(), 43, testBasicOrder(client))
+	if err != nil || res.ClientOrderID != 43 {
+		t.Fatalf("Expected the resubmitted order to be placed, got %+v (%v)", res, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.creates != 1 {
+		t.Errorf("Expected 1 create request, got %d", s.creates)
+	}
+}
+
+func TestSubmitManagerRiskRejected(t *testing.T) {
+	s := newSubmitTestServer(t, http.StatusOK)
+	m, client := newTestSubmitManager(s)
+	risk := NewRiskChecker(RiskLimits{MaxOrderQuantity: "0.5"})
+	risk.SetAuditHandler(func(*RiskError, bool) {})
+	client.SetRiskChecker(risk)
+
+	_, err := m.Submit(context.Background(), 44, testBasicOrder(client))
+	var riskErr *RiskError
+	if !errors.As(err, &riskErr) || errors.Is(err, ErrOrderOutcomeUnknown) {
+		t.Fatalf("Expected the risk error, got %v", err)
+	}
+	s.mu.Lock()
+	if s.creates != 0 || s.lookups != 0 {
+		t.Errorf("Expected no create or lookup requests, got %d creates and %d lookups", s.creates, s.lookups)
+	}
+	s.mu.Unlock()
+
+	// The rejection is not remembered once the limit is raised
+	risk.SetLimits(RiskLimits{MaxOrderQuantity: "1"})
+	if res, err := m.Submit(context.Background(), 44, testBasicOrder(client)); err != nil || res.ClientOrderID != 44 {
+		t.Fatalf("Expected the resubmitted order to be placed, got %+v (%v)", res, err)
+	}
+}
+
+func TestSubmitManagerExchangeRejected(t *testing.T) {
+	s := newSubmitTestServer(t, http.StatusOK)
+	m, client := newTestSubmitManager(s)
+	exchanges := NewExchangeRegistry()
+	exchanges.Unregister(ExchangeBinanceSpot)
+	client.SetExchangeRegistry(exchanges)
+
+	if _, err := m.Submit(context.Background(), 45, testBasicOrder(client)); !errors.Is(err, ErrUnknownExchange) || errors.Is(err, ErrOrderOutcomeUnknown) {
+		t.Fatalf("Expected ErrUnknownExchange, got %v", err)
+	}
+	s.mu.Lock()
+	if s.creates != 0 || s.lookups != 0 {
+		t.Errorf("Expected no create or lookup requests, got %d creates and %d lookups", s.creates, s.lookups)
+	}
+	s.mu.Unlock()
+
+	// The rejection is not remembered once the exchange is registered
+	if err := exchanges.Register(ExchangeCapabilities{Exchange: ExchangeBinanceSpot, Market: MarketSpot}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res, err := m.Submit(context.Background(), 45, testBasicOrder(client)); err != nil || res.ClientOrderID != 45 {
+		t.Fatalf("Expected the resubmitted order to be placed, got %+v (%v)", res, err)
+	}
+}
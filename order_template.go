@@ -0,0 +1,279 @@
+package versifi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrTemplateNotFound is returned for names that were never registered
+	ErrTemplateNotFound = errors.New("order template not found")
+	// ErrTemplateExists is returned when registering a name twice
+	ErrTemplateExists = errors.New("order template already registered")
+)
+
+// OrderTemplate is a common order shape defined once and instantiated with
+// per-order overrides. Exactly one of BasicOrderType and AlgoOrderType is
+// set. Fields left empty must be supplied by the overrides.
+//
+//	twap1h := versifi.OrderTemplate{
+//		Exchange:      versifi.ExchangeOKXFutures,
+//		AlgoOrderType: versifi.AlgoOrderTypeTWAP,
+//		Params:        map[string]interface{}{"duration": 3600, "volume_percentage": 5},
+//	}
+type OrderTemplate struct {
+	Exchange       ExchangeType           `json:"exchange,omitempty"`
+	Symbol         string                 `json:"symbol,omitempty"`
+	Side           SideType               `json:"side,omitempty"`
+	Quantity       string                 `json:"quantity,omitempty"`
+	BasicOrderType BasicOrderType         `json:"basic_order_type,omitempty"`
+	AlgoOrderType  AlgoOrderType          `json:"algo_order_type,omitempty"`
+	Price          string                 `json:"price,omitempty"`
+	StopPrice      string                 `json:"stop_price,omitempty"`
+	TimeInForce    TimeInForceType        `json:"tif,omitempty"`
+	Params         map[string]interface{} `json:"params,omitempty"` // Algo orders only
+	AutoRound      bool                   `json:"auto_round,omitempty"`
+}
+
+// OrderOverrides are the per-order values applied to a template. Empty
+// fields keep the template's values; Params entries are merged over the
+// template's params.
+type OrderOverrides struct {
+	Exchange      ExchangeType
+	Symbol        string
+	Side          SideType
+	Quantity      string
+	Price         string
+	StopPrice     string
+	ClientOrderID *int64
+	Params        map[string]interface{}
+}
+
+// Apply returns a copy of the template with overrides applied. The
+// template itself, including its Params map, is not modified.
+func (t OrderTemplate) Apply(o OrderOverrides) OrderTemplate {
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	if o.Exchange != "" {
+		t.Exchange = o.Exchange
+	}
+	if o.Side != "" {
+		t.Side = o.Side
+	}
+	set(&t.Symbol, o.Symbol)
+	set(&t.Quantity, o.Quantity)
+	set(&t.Price, o.Price)
+	set(&t.StopPrice, o.StopPrice)
+
+	if t.Params != nil || o.Params != nil {
+		params := make(map[string]interface{}, len(t.Params)+len(o.Params))
+		for k, v := range t.Params {
+			params[k] = v
+		}
+		for k, v := range o.Params {
+			params[k] = v
+		}
+		t.Params = params
+	}
+	return t
+}
+
+// Validate checks that the template, after overrides, describes a complete
+// order
+func (t OrderTemplate) Validate() error {
+	var missing []string
+	for _, f := range []struct {
+		name  string
+		value string
+	}{
+		{"exchange", string(t.Exchange)},
+		{"symbol", t.Symbol},
+		{"side", string(t.Side)},
+		{"quantity", t.Quantity},
+	} {
+		if f.value == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("order template: missing %v", missing)
+	}
+	switch {
+	case t.BasicOrderType != "" && t.AlgoOrderType != "":
+		return errors.New("order template: both basic_order_type and algo_order_type are set")
+	case t.BasicOrderType == "" && t.AlgoOrderType == "":
+		return errors.New("order template: one of basic_order_type and algo_order_type is required")
+	case t.BasicOrderType != "" && len(t.Params) > 0:
+		return errors.New("order template: params are only used by algo orders")
+	}
+	return nil
+}
+
+// IsAlgo reports whether the template creates algo orders
+func (t OrderTemplate) IsAlgo() bool {
+	return t.AlgoOrderType != ""
+}
+
+// NewBasicOrder applies overrides and returns a ready-to-send basic order
+// builder
+func (t OrderTemplate) NewBasicOrder(c *Client, o OrderOverrides) (*CreateBasicOrderService, error) {
+	t = t.Apply(o)
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if t.IsAlgo() {
+		return nil, fmt.Errorf("order template: %s is an algo order type", t.AlgoOrderType)
+	}
+
+	s := c.NewCreateBasicOrderService().
+		Exchange(t.Exchange).
+		Symbol(t.Symbol).
+		Side(t.Side).
+		OrderType(t.BasicOrderType).
+		Quantity(t.Quantity)
+	if t.Price != "" {
+		s.Price(t.Price)
+	}
+	if t.StopPrice != "" {
+		s.StopPrice(t.StopPrice)
+	}
+	if t.TimeInForce != "" {
+		s.TimeInForce(t.TimeInForce)
+	}
+	if o.ClientOrderID != nil {
+		s.ClientOrderID(*o.ClientOrderID)
+	}
+	if t.AutoRound {
+		s.AutoRound()
+	}
+	return s, nil
+}
+
+// NewAlgoOrder applies overrides and returns a ready-to-send algo order
+// builder
+func (t OrderTemplate) NewAlgoOrder(c *Client, o OrderOverrides) (*CreateAlgoOrderService, error) {
+	t = t.Apply(o)
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if !t.IsAlgo() {
+		return nil, fmt.Errorf("order template: %s is a basic order type", t.BasicOrderType)
+	}
+
+	s := c.NewCreateAlgoOrderService().
+		Exchange(t.Exchange).
+		Symbol(t.Symbol).
+		Side(t.Side).
+		OrderType(t.AlgoOrderType).
+		Quantity(t.Quantity).
+		Params(t.Params)
+	if o.ClientOrderID != nil {
+		s.ClientOrderID(*o.ClientOrderID)
+	}
+	if t.AutoRound {
+		s.AutoRound()
+	}
+	return s, nil
+}
+
+// TemplateRegistry holds named order templates shared across strategies.
+// It is safe for concurrent use.
+type TemplateRegistry struct {
+	mu        sync.RWMutex
+	templates map[string]OrderTemplate
+}
+
+// NewTemplateRegistry creates an empty registry
+func NewTemplateRegistry() *TemplateRegistry {
+	return &TemplateRegistry{templates: make(map[string]OrderTemplate)}
+}
+
+// Register adds a template under name. Templates that set both or neither
+// order type are rejected.
+func (r *TemplateRegistry) Register(name string, t OrderTemplate) error {
+	if (t.BasicOrderType == "") == (t.AlgoOrderType == "") {
+		return fmt.Errorf("order template %q: set exactly one of basic_order_type and algo_order_type", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.templates[name]; ok {
+		return fmt.Errorf("%w: %q", ErrTemplateExists, name)
+	}
+	r.templates[name] = t.Apply(OrderOverrides{}) // Copy Params
+	return nil
+}
+
+// Load registers the templates in a JSON object keyed by name:
+//
+//	{"twap-1h-okx": {"exchange": "OKX_FUTURES", "algo_order_type": "TWAP",
+//	                 "params": {"duration": 3600, "volume_percentage": 5}}}
+func (r *TemplateRegistry) Load(reader io.Reader) error {
+	var templates map[string]OrderTemplate
+	dec := json.NewDecoder(reader)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&templates); err != nil {
+		return fmt.Errorf("order templates: %w", err)
+	}
+
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		if err := r.Register(name, templates[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Get returns the template registered under name
+func (r *TemplateRegistry) Get(name string) (OrderTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.templates[name]
+	if !ok {
+		return OrderTemplate{}, fmt.Errorf("%w: %q", ErrTemplateNotFound, name)
+	}
+	return t.Apply(OrderOverrides{}), nil
+}
+
+// Names returns the registered names in sorted order
+func (r *TemplateRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewBasicOrder instantiates the basic order template registered under name
+func (r *TemplateRegistry) NewBasicOrder(c *Client, name string, o OrderOverrides) (*CreateBasicOrderService, error) {
+	t, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewBasicOrder(c, o)
+}
+
+// NewAlgoOrder instantiates the algo order template registered under name
+func (r *TemplateRegistry) NewAlgoOrder(c *Client, name string, o OrderOverrides) (*CreateAlgoOrderService, error) {
+	t, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewAlgoOrder(c, o)
+}
@@ -0,0 +1,106 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTemplateRegistry(t *testing.T) {
+	bodies := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies <- body
+		w.Write([]byte(`{"order_id": 1, "status": "NEW"}`))
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	registry := NewTemplateRegistry()
+	err := registry.Load(strings.NewReader(`{
+		"twap-1h-okx": {"exchange": "OKX_FUTURES", "algo_order_type": "TWAP",
+		                "params": {"duration": 3600, "volume_percentage": 5}},
+		"ioc-binance": {"exchange": "BINANCE_SPOT", "basic_order_type": "LIMIT", "tif": "IOC"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := registry.Names(); len(names) != 2 || names[0] != "ioc-binance" {
+		t.Errorf("Unexpected names %v", names)
+	}
+
+	ctx := context.Background()
+	algo, err := registry.NewAlgoOrder(client, "twap-1h-okx", OrderOverrides{
+		Symbol:   "BTC/USDT",
+		Side:     SideTypeBuy,
+		Quantity: "2",
+		Params:   map[string]interface{}{"duration": 1800},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := algo.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	body := <-bodies
+	params := body["params"].(map[string]interface{})
+	if body["exchange"] != "OKX_FUTURES" || body["order_type"] != "TWAP" || body["symbol"] != "BTC/USDT" ||
+		params["duration"] != 1800.0 || params["volume_percentage"] != 5.0 {
+		t.Errorf("Unexpected algo order %v", body)
+	}
+
+	// Overrides never leak into the registered template
+	tmpl, _ := registry.Get("twap-1h-okx")
+	if tmpl.Params["duration"] != 3600.0 || tmpl.Symbol != "" {
+		t.Errorf("Template was modified: %+v", tmpl)
+	}
+
+	id := int64(9)
+	basic, err := registry.NewBasicOrder(client, "ioc-binance", OrderOverrides{
+		Symbol: "ETH/USDT", Side: SideTypeSell, Quantity: "1", Price: "2000", ClientOrderID: &id,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := basic.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	body = <-bodies
+	if body["order_type"] != "LIMIT" || body["tif"] != "IOC" || body["price"] != "2000" || body["client_order_id"] != 9.0 {
+		t.Errorf("Unexpected basic order %v", body)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	client := NewClient("test-key", "test-secret")
+	registry := NewTemplateRegistry()
+
+	if err := registry.Register("none", OrderTemplate{Exchange: ExchangeBinanceSpot}); err == nil {
+		t.Error("Expected an error for a template without an order type")
+	}
+	twap := OrderTemplate{Exchange: ExchangeBinanceSpot, AlgoOrderType: AlgoOrderTypeTWAP}
+	if err := registry.Register("twap", twap); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("twap", twap); !errors.Is(err, ErrTemplateExists) {
+		t.Errorf("Expected ErrTemplateExists, got %v", err)
+	}
+	if _, err := registry.NewAlgoOrder(client, "vwap", OrderOverrides{}); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+	if _, err := registry.NewAlgoOrder(client, "twap", OrderOverrides{Symbol: "BTC/USDT"}); err == nil || !strings.Contains(err.Error(), "side quantity") {
+		t.Errorf("Expected missing side and quantity, got %v", err)
+	}
+	if _, err := registry.NewBasicOrder(client, "twap", OrderOverrides{Symbol: "BTC/USDT", Side: SideTypeBuy, Quantity: "1"}); err == nil {
+		t.Error("Expected an error instantiating an algo template as a basic order")
+	}
+	if err := registry.Load(strings.NewReader(`{"x": {"exchnage": "OKX_SPOT"}}`)); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
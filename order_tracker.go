@@ -0,0 +1,550 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// reconcileTimeout bounds the reconciliation run after a reconnect
+const reconcileTimeout = 30 * time.Second
+
+// OrderCreator is satisfied by the Create*OrderService builders
+type OrderCreator interface {
+	Do(ctx context.Context, opts ...RequestOption) (*OrderResponse, error)
+}
+
+// OrderUpdateSource identifies what produced an order update
+type OrderUpdateSource string
+
+const (
+	OrderSourceSubmit    OrderUpdateSource = "SUBMIT"
+	OrderSourceWebsocket OrderUpdateSource = "WEBSOCKET"
+	OrderSourceREST      OrderUpdateSource = "REST"
+)
+
+// OrderState is the tracked state of one order
+type OrderState struct {
+	OrderID          int64           `json:"order_id"`
+	ClientOrderID    int64           `json:"client_order_id"`
+	RequestOrderType string          `json:"request_order_type,omitempty"`
+	Exchange         ExchangeType    `json:"exchange,omitempty"`
+	Symbol           string          `json:"symbol,omitempty"`
+	Side             SideType        `json:"side,omitempty"`
+	Status           OrderStatusType `json:"status"`
+	FilledQuantity   string          `json:"filled_quantity,omitempty"` // Not set for pair orders, see Legs
+	AveragePrice     string          `json:"average_price,omitempty"`
+	RejectReason     string          `json:"reject_reason,omitempty"`
+	Legs             []LegState      `json:"legs,omitempty"` // Pair orders only
+	UpdatedAt        Timestamp       `json:"updated_at"`     // Timestamp of the latest execution report or REST order applied
+}
+
+// LegState is the fill state of one leg of a pair order
+type LegState struct {
+	LegID          int64  `json:"leg_id"`
+	FilledQuantity string `json:"filled_quantity"`
+	AveragePrice   string `json:"average_price,omitempty"`
+}
+
+// OrderUpdate describes a change to a tracked order.
+// Previous is the zero value when the order was not tracked before.
+type OrderUpdate struct {
+	Order    OrderState
+	Previous OrderState
+	Source   OrderUpdateSource
+}
+
+// OrderUpdateHandler handles order updates
+type OrderUpdateHandler func(update OrderUpdate)
+
+// OrderTracker maintains the current state of orders from REST responses and
+// websocket execution reports, and reconciles them over REST after a reconnect.
+//
+//	tracker := versifi.NewOrderTracker(client)
+//	tracker.Attach(wsClient)
+//	tracker.Subscribe(func(u versifi.OrderUpdate) { ... })
+//	res, err := tracker.Submit(ctx, client.NewCreateBasicOrderService()...)
+type OrderTracker struct {
+	c *Client
+
+	mu         sync.RWMutex
+	orders     map[int64]*trackedOrder
+	handlers   map[int]OrderUpdateHandler
+	nextID     int
+	errHandler ErrHandler
+	onAnomaly  OrderAnomalyHandler
+}
+
+// trackedOrder holds an order's state and the per child order fills it is computed from
+type trackedOrder struct {
+	state  OrderState
+	fills  map[int64]*childFill
+	trades map[int64]bool
+	// status guards state.Status, collecting invalid transitions in
+	// anomalies until they are reported outside the tracker's lock
+	status    *OrderStateMachine
+	anomalies []OrderAnomaly
+}
+
+type childFill struct {
+	legID    int64
+	filled   decimal.Decimal
+	notional decimal.Decimal
+}
+
+// NewOrderTracker creates a tracker that loads orders through client
+func NewOrderTracker(client *Client) *OrderTracker {
+	return &OrderTracker{
+		c:        client,
+		orders:   make(map[int64]*trackedOrder),
+		handlers: make(map[int]OrderUpdateHandler),
+	}
+}
+
+// SetErrorHandler sets the handler for errors from background reconciliation
+// and undecodable execution reports
+func (t *OrderTracker) SetErrorHandler(handler ErrHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errHandler = handler
+}
+
+// SetAnomalyHandler sets the handler for status changes outside the order
+// transition graph, such as a FILLED order reported as NEW. Such changes are
+// not applied.
+func (t *OrderTracker) SetAnomalyHandler(handler OrderAnomalyHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onAnomaly = handler
+}
+
+// Attach subscribes to execution reports on ws and reconciles open orders
+// after each reconnect. It registers with OnExecutionReport and OnResume, so
+// the client's execution_report and resume handlers, and other attached
+// helpers, keep receiving their messages.
+func (t *OrderTracker) Attach(ws *WsClient) error {
+	if err := ws.EnsureSubscribed("execution_report"); err != nil {
+		return err
+	}
+	ws.OnExecutionReport(t.ApplyExecutionReport)
+	ws.OnResume(func(int64) {
+		ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+		defer cancel()
+		if err := t.Reconcile(ctx); err != nil {
+			t.error(err)
+		}
+	})
+	return nil
+}
+
+// Subscribe registers handler for every order update and returns a function
+// that removes it. Handlers are called synchronously, in update order.
+func (t *OrderTracker) Subscribe(handler OrderUpdateHandler) (unsubscribe func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.nextID
+	t.nextID++
+	t.handlers[id] = handler
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.handlers, id)
+	}
+}
+
+// Submit creates an order and starts tracking it
+func (t *OrderTracker) Submit(ctx context.Context, order OrderCreator, opts ...RequestOption) (*OrderResponse, error) {
+	res, err := order.Do(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	t.apply(res.OrderID, OrderSourceSubmit, func(o *trackedOrder) {
+		o.state.ClientOrderID = res.ClientOrderID
+		// Execution reports may have moved the order on before the create
+		// response arrived, which is not an anomaly
+		if o.status.CanTransition(res.Status) {
+			o.setStatus(res.Status)
+		}
+	})
+	return res, nil
+}
+
+// Track loads an existing order over REST and starts tracking it
+func (t *OrderTracker) Track(ctx context.Context, orderID int64, opts ...RequestOption) (OrderState, error) {
+	res, err := t.c.NewGetOrderService().OrderID(orderID).Do(ctx, opts...)
+	if err != nil {
+		return OrderState{}, err
+	}
+	return t.applyOrder(res), nil
+}
+
+// Forget stops tracking an order
+func (t *OrderTracker) Forget(orderID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.orders, orderID)
+}
+
+// Order returns the state of a tracked order
+func (t *OrderTracker) Order(orderID int64) (OrderState, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	o, ok := t.orders[orderID]
+	if !ok {
+		return OrderState{}, false
+	}
+	return o.snapshot(), true
+}
+
+// OrderByClientID returns the state of a tracked order by its client order ID
+func (t *OrderTracker) OrderByClientID(clientOrderID int64) (OrderState, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, o := range t.orders {
+		if o.state.ClientOrderID == clientOrderID {
+			return o.snapshot(), true
+		}
+	}
+	return OrderState{}, false
+}
+
+// Orders returns every tracked order, sorted by order ID
+func (t *OrderTracker) Orders() []OrderState {
+	return t.filter(func(OrderState) bool { return true })
+}
+
+// OpenOrders returns tracked orders that have not reached a final status
+func (t *OrderTracker) OpenOrders() []OrderState {
+	return t.filter(func(s OrderState) bool { return !s.Status.IsFinal() })
+}
+
+func (t *OrderTracker) filter(keep func(OrderState) bool) []OrderState {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	res := make([]OrderState, 0, len(t.orders))
+	for _, o := range t.orders {
+		if s := o.snapshot(); keep(s) {
+			res = append(res, s)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].OrderID < res[j].OrderID })
+	return res
+}
+
+// Reconcile refreshes every open order over REST, picking up changes missed
+// while the websocket was disconnected
+func (t *OrderTracker) Reconcile(ctx context.Context, opts ...RequestOption) error {
+	open := t.OpenOrders()
+	for _, o := range open {
+		res, err := t.c.NewGetOrderService().OrderID(o.OrderID).Do(ctx, opts...)
+		if err != nil {
+			return err
+		}
+		t.applyOrder(res)
+	}
+	return nil
+}
+
+// HandleExecutionReport applies an execution_report message. It is a
+// WsHandler, for use when the handler is registered by hand instead of Attach.
+func (t *OrderTracker) HandleExecutionReport(message []byte) {
+	var report WsExecutionReport
+	if err := json.Unmarshal(message, &report); err != nil {
+		t.error(err)
+		return
+	}
+	t.ApplyExecutionReport(&report.Message)
+}
+
+// ApplyExecutionReport applies a decoded execution report. Reports older than
+// the latest one applied are ignored, and status changes outside the order
+// transition graph are reported to the anomaly handler rather than applied.
+func (t *OrderTracker) ApplyExecutionReport(detail *WsExecutionReportDetail) {
+	t.apply(detail.OrderID, OrderSourceWebsocket, func(o *trackedOrder) {
+		if detail.Timestamp.Before(o.state.UpdatedAt.Time) {
+			return
+		}
+		o.state.UpdatedAt = detail.Timestamp
+		o.state.ClientOrderID = detail.ClientOrderID
+		o.state.RequestOrderType = strings.ToLower(detail.RequestOrderType)
+		o.setStatus(detail.Status)
+
+		switch {
+		case detail.Basic != nil:
+			o.setInstrument(detail.Basic.Exchange, detail.Basic.Symbol, detail.Basic.Side)
+			o.applyWsChild(detail.Basic.ChildOrder, 0)
+		case detail.Algo != nil:
+			o.setInstrument(detail.Algo.Exchange, detail.Algo.Symbol, detail.Algo.Side)
+			o.applyWsChild(detail.Algo.ChildOrder, 0)
+		case detail.Pair != nil:
+			if leg := detail.Pair.LeadLeg; leg != nil {
+				o.setInstrument(leg.Exchange, leg.Symbol, "")
+				o.applyWsChild(leg.ChildOrder, -1)
+			}
+			if leg := detail.Pair.Leg; leg != nil {
+				o.applyWsChild(leg.ChildOrder, -1)
+			}
+		}
+	})
+}
+
+// applyOrder applies a REST order and returns the resulting state. Like
+// execution reports, orders older than the latest update applied are ignored.
+func (t *OrderTracker) applyOrder(res *GetOrderResponse) OrderState {
+	return t.apply(res.OrderID, OrderSourceREST, func(o *trackedOrder) {
+		if !res.Timestamp.IsZero() {
+			if res.Timestamp.Before(o.state.UpdatedAt.Time) {
+				return
+			}
+			o.state.UpdatedAt = res.Timestamp
+		}
+		o.state.ClientOrderID = res.ClientOrderID
+		o.state.RequestOrderType = strings.ToLower(res.RequestOrderType)
+		o.setStatus(res.Status)
+
+		switch {
+		case res.BasicOrder != nil:
+			d := res.BasicOrder
+			o.setInstrument(d.Exchange, d.Symbol, d.Side)
+			o.state.RejectReason = d.RejectReason
+			o.applyRESTChildren(d.ChildOrders, d.FilledQuantity, d.AveragePrice)
+		case res.AlgoOrder != nil:
+			d := res.AlgoOrder
+			o.setInstrument(d.Exchange, d.Symbol, d.Side)
+			o.state.RejectReason = d.RejectReason
+			o.applyRESTChildren(d.ChildOrders, d.FilledQuantity, d.AveragePrice)
+		case res.PairOrder != nil:
+			d := res.PairOrder
+			o.state.RejectReason = d.RejectReason
+			if d.LeadLeg != nil {
+				o.setInstrument(d.LeadLeg.Exchange, d.LeadLeg.Symbol, "")
+				o.applyRESTChildren(d.LeadLeg.ChildOrders, "", "")
+			}
+			if d.Secondary != nil {
+				o.applyRESTChildren(d.Secondary.ChildOrders, "", "")
+			}
+		}
+	})
+}
+
+// apply updates an order under the lock and notifies subscribers if its
+// status or fills changed
+func (t *OrderTracker) apply(orderID int64, source OrderUpdateSource, update func(o *trackedOrder)) OrderState {
+	t.mu.Lock()
+	o, ok := t.orders[orderID]
+	if !ok {
+		o = &trackedOrder{
+			state:  OrderState{OrderID: orderID},
+			fills:  make(map[int64]*childFill),
+			trades: make(map[int64]bool),
+		}
+		o.status = NewOrderStateMachine(orderID, func(a OrderAnomaly) {
+			o.anomalies = append(o.anomalies, a)
+		})
+		t.orders[orderID] = o
+	}
+
+	var previous OrderState
+	if ok {
+		previous = o.snapshot()
+	}
+	update(o)
+	o.summarize()
+	current := o.snapshot()
+	anomalies, onAnomaly := o.anomalies, t.onAnomaly
+	o.anomalies = nil
+
+	var handlers []OrderUpdateHandler
+	if !ok || changed(previous, current) {
+		handlers = t.handlersLocked()
+	}
+	t.mu.Unlock()
+
+	if onAnomaly != nil {
+		for _, a := range anomalies {
+			onAnomaly(a)
+		}
+	}
+	for _, handler := range handlers {
+		handler(OrderUpdate{Order: current, Previous: previous, Source: source})
+	}
+	return current
+}
+
+func (t *OrderTracker) handlersLocked() []OrderUpdateHandler {
+	ids := make([]int, 0, len(t.handlers))
+	for id := range t.handlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	handlers := make([]OrderUpdateHandler, len(ids))
+	for i, id := range ids {
+		handlers[i] = t.handlers[id]
+	}
+	return handlers
+}
+
+func (t *OrderTracker) error(err error) {
+	t.mu.RLock()
+	handler := t.errHandler
+	t.mu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+func changed(a, b OrderState) bool {
+	if a.Status != b.Status || a.FilledQuantity != b.FilledQuantity ||
+		a.AveragePrice != b.AveragePrice || len(a.Legs) != len(b.Legs) {
+		return true
+	}
+	for i := range a.Legs {
+		if a.Legs[i] != b.Legs[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *trackedOrder) snapshot() OrderState {
+	s := o.state
+	s.Legs = append([]LegState(nil), o.state.Legs...)
+	return s
+}
+
+func (o *trackedOrder) setStatus(status OrderStatusType) {
+	o.status.Apply(status)
+	o.state.Status = o.status.Status()
+}
+
+func (o *trackedOrder) setInstrument(exchange ExchangeType, symbol string, side SideType) {
+	if exchange != "" {
+		o.state.Exchange = exchange
+	}
+	if symbol != "" {
+		o.state.Symbol = symbol
+	}
+	if side != "" {
+		o.state.Side = side
+	}
+}
+
+// applyWsChild folds a child order's trades into its fill. legID -1 takes
+// the leg from the trades; non-pair children use leg 0.
+func (o *trackedOrder) applyWsChild(child *WsChildOrder, legID int64) {
+	if child == nil {
+		return
+	}
+
+	for _, trade := range child.Trades {
+		fill, ok := o.fills[child.ID]
+		if !ok {
+			fill = &childFill{legID: legID}
+			o.fills[child.ID] = fill
+		}
+		if legID < 0 && trade.LegID != nil {
+			fill.legID = *trade.LegID
+		}
+
+		if trade.CummulativeFilledQuantity != "" {
+			// Cumulative figures make the fill independent of duplicate or missed trades
+			filled := toDecimal(trade.CummulativeFilledQuantity)
+			if filled.LessThan(fill.filled) {
+				continue
+			}
+			price := toDecimal(trade.AveragePrice)
+			if price.IsZero() {
+				price = toDecimal(trade.ExecutedPrice)
+			}
+			fill.filled = filled
+			fill.notional = filled.Mul(price)
+			continue
+		}
+
+		if o.trades[trade.TradeID] {
+			continue
+		}
+		o.trades[trade.TradeID] = true
+		qty := toDecimal(trade.ExecutedQuantity)
+		fill.filled = fill.filled.Add(qty)
+		fill.notional = fill.notional.Add(qty.Mul(toDecimal(trade.ExecutedPrice)))
+	}
+}
+
+// applyRESTChildren replaces child fills with those reported over REST.
+// filled and average are the order level figures used when there are no children.
+func (o *trackedOrder) applyRESTChildren(children []ChildOrder, filled, average string) {
+	if len(children) == 0 {
+		if filled != "" {
+			qty := toDecimal(filled)
+			o.fills = map[int64]*childFill{0: {filled: qty, notional: qty.Mul(toDecimal(average))}}
+		}
+		return
+	}
+
+	for _, child := range children {
+		id := child.ID
+		if id == 0 {
+			id = child.ChildOrderID
+		}
+		qty := toDecimal(child.FilledQuantity)
+		if existing, ok := o.fills[id]; ok && existing.filled.GreaterThan(qty) {
+			continue
+		}
+		o.fills[id] = &childFill{legID: child.LegID, filled: qty, notional: qty.Mul(toDecimal(child.AveragePrice))}
+	}
+}
+
+// summarize recomputes the order's fill figures from its child fills
+func (o *trackedOrder) summarize() {
+	if len(o.fills) == 0 {
+		return
+	}
+
+	if o.state.RequestOrderType != RequestOrderTypePair {
+		var filled, notional decimal.Decimal
+		for _, fill := range o.fills {
+			filled = filled.Add(fill.filled)
+			notional = notional.Add(fill.notional)
+		}
+		o.state.FilledQuantity = filled.String()
+		o.state.AveragePrice = averagePrice(filled, notional)
+		return
+	}
+
+	legs := make(map[int64]*childFill)
+	for _, fill := range o.fills {
+		leg, ok := legs[fill.legID]
+		if !ok {
+			leg = &childFill{legID: fill.legID}
+			legs[fill.legID] = leg
+		}
+		leg.filled = leg.filled.Add(fill.filled)
+		leg.notional = leg.notional.Add(fill.notional)
+	}
+
+	o.state.Legs = o.state.Legs[:0]
+	for _, leg := range legs {
+		o.state.Legs = append(o.state.Legs, LegState{
+			LegID:          leg.legID,
+			FilledQuantity: leg.filled.String(),
+			AveragePrice:   averagePrice(leg.filled, leg.notional),
+		})
+	}
+	sort.Slice(o.state.Legs, func(i, j int) bool { return o.state.Legs[i].LegID < o.state.Legs[j].LegID })
+}
+
+func averagePrice(filled, notional decimal.Decimal) string {
+	if filled.IsZero() {
+		return ""
+	}
+	return notional.Div(filled).String()
+}
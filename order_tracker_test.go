@@ -0,0 +1,235 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func basicExecutionReport(orderID int64, status OrderStatusType, timestamp int64, trades ...WsTrade) map[string]interface{} {
+	return map[string]interface{}{
+		"op":      "execution_report",
+		"success": true,
+		"message": WsExecutionReportDetail{
+			OrderID:          orderID,
+			ClientOrderID:    7,
+			Status:           status,
+			Timestamp:        TimestampFromEpoch(timestamp),
+			RequestOrderType: "BASIC",
+			Basic: &WsBasicOrderDetail{
+				Symbol:     "BTC/USDT",
+				Exchange:   ExchangeBinanceSpot,
+				Side:       SideTypeBuy,
+				Quantity:   "2",
+				ChildOrder: &WsChildOrder{ID: 1, Trades: trades},
+			},
+		},
+	}
+}
+
+func TestOrderTracker(t *testing.T) {
+	var mu sync.Mutex
+	restStatus := OrderStatusPartiallyFilled
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/orders/basic/":
+			json.NewEncoder(w).Encode(OrderResponse{OrderID: 100, ClientOrderID: 7, Status: OrderStatusNew})
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/orders/100":
+			json.NewEncoder(w).Encode(GetOrderResponse{
+				OrderID:          100,
+				ClientOrderID:    7,
+				Status:           restStatus,
+				RequestOrderType: "basic",
+				BasicOrder: &BasicOrderDetail{
+					Symbol:   "BTC/USDT",
+					Exchange: ExchangeBinanceSpot,
+					Side:     SideTypeBuy,
+					ChildOrders: []ChildOrder{
+						{ID: 1, FilledQuantity: "2", AveragePrice: "101"},
+					},
+				},
+			})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer rest.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = rest.URL
+	tracker := NewOrderTracker(client)
+
+	updates := make(chan OrderUpdate, 10)
+	tracker.Subscribe(func(u OrderUpdate) { updates <- u })
+	next := func() OrderUpdate {
+		t.Helper()
+		select {
+		case u := <-updates:
+			return u
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for order update")
+			return OrderUpdate{}
+		}
+	}
+
+	server := newTestWsServer(t)
+	ws := newTestWsClient(t, server, func(c *WsClient) {
+		c.reconnectDelay = 10 * time.Millisecond
+	})
+	// Handlers set before Attach keep their messages
+	reports := make(chan struct{}, 10)
+	resumed := make(chan struct{}, 1)
+	if err := ws.SubscribeExecutionReport(func([]byte) { reports <- struct{}{} }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ws.SetResumeHandler(func(int64) { resumed <- struct{}{} })
+	if err := tracker.Attach(ws); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	res, err := tracker.Submit(context.Background(), client.NewCreateBasicOrderService().
+		Symbol("BTC/USDT").
+		Exchange(ExchangeBinanceSpot).
+		Side(SideTypeBuy).
+		OrderType(BasicOrderTypeMarket).
+		Quantity("2"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if u := next(); u.Source != OrderSourceSubmit || u.Order.OrderID != res.OrderID || u.Order.Status != OrderStatusNew {
+		t.Errorf("Unexpected submit update %+v", u)
+	}
+
+	// Two partial fills; the second carries cumulative figures
+	server.push(basicExecutionReport(100, OrderStatusPartiallyFilled, 1000,
+		WsTrade{TradeID: 1, ExecutedPrice: "100", ExecutedQuantity: "0.5"}))
+	u := next()
+	if u.Source != OrderSourceWebsocket || u.Previous.Status != OrderStatusNew ||
+		u.Order.FilledQuantity != "0.5" || u.Order.AveragePrice != "100" || u.Order.Symbol != "BTC/USDT" {
+		t.Errorf("Unexpected first fill %+v", u)
+	}
+
+	server.push(basicExecutionReport(100, OrderStatusPartiallyFilled, 2000,
+		WsTrade{TradeID: 2, ExecutedPrice: "102", ExecutedQuantity: "0.5", CummulativeFilledQuantity: "1", AveragePrice: "101"}))
+	if u := next(); u.Order.FilledQuantity != "1" || u.Order.AveragePrice != "101" {
+		t.Errorf("Unexpected second fill %+v", u.Order)
+	}
+
+	// A stale report is ignored
+	server.push(basicExecutionReport(100, OrderStatusNew, 500))
+
+	// The fill completes while the stream is down and is picked up by reconciliation
+	mu.Lock()
+	restStatus = OrderStatusFilled
+	mu.Unlock()
+
+	server.mu.Lock()
+	server.conn.Close()
+	server.mu.Unlock()
+
+	u = next()
+	if u.Source != OrderSourceREST || u.Order.Status != OrderStatusFilled || u.Order.FilledQuantity != "2" {
+		t.Errorf("Unexpected reconciled update %+v", u)
+	}
+
+	select {
+	case <-resumed:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the client's resume handler to be called")
+	}
+	if len(reports) != 3 {
+		t.Errorf("Expected the client's handler to receive 3 reports, got %d", len(reports))
+	}
+
+	if open := tracker.OpenOrders(); len(open) != 0 {
+		t.Errorf("Expected no open orders, got %+v", open)
+	}
+	if s, ok := tracker.OrderByClientID(7); !ok || s.OrderID != 100 || s.UpdatedAt.Epoch() != 2000 {
+		t.Errorf("Unexpected order by client ID %+v", s)
+	}
+}
+
+func TestOrderTrackerPairLegs(t *testing.T) {
+	tracker := NewOrderTracker(NewClient("test-key", "test-secret"))
+
+	lead, secondary := int64(1), int64(2)
+	tracker.ApplyExecutionReport(&WsExecutionReportDetail{
+		OrderID:          200,
+		Status:           OrderStatusPartiallyFilled,
+		RequestOrderType: "pair",
+		Pair: &WsPairOrderDetail{
+			LeadLeg: &WsPairLeg{
+				Symbol:     "BTC/USDT",
+				Exchange:   ExchangeBinanceSpot,
+				ChildOrder: &WsChildOrder{ID: 10, Trades: []WsTrade{{TradeID: 1, LegID: &lead, ExecutedPrice: "100", ExecutedQuantity: "1"}}},
+			},
+			Leg: &WsPairLeg{
+				Symbol:     "BTC/USDT",
+				Exchange:   ExchangeBinanceFutures,
+				ChildOrder: &WsChildOrder{ID: 11, Trades: []WsTrade{{TradeID: 2, LegID: &secondary, ExecutedPrice: "105", ExecutedQuantity: "1"}}},
+			},
+		},
+	})
+
+	s, ok := tracker.Order(200)
+	if !ok {
+		t.Fatal("Expected order to be tracked")
+	}
+	want := []LegState{
+		{LegID: 1, FilledQuantity: "1", AveragePrice: "100"},
+		{LegID: 2, FilledQuantity: "1", AveragePrice: "105"},
+	}
+	if len(s.Legs) != 2 || s.Legs[0] != want[0] || s.Legs[1] != want[1] || s.FilledQuantity != "" {
+		t.Errorf("Expected legs %+v, got %+v", want, s)
+	}
+}
+
+func TestOrderTrackerStaleREST(t *testing.T) {
+	var mu sync.Mutex
+	restStatus, restTimestamp := OrderStatusNew, int64(1000)
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(GetOrderResponse{
+			OrderID:          300,
+			Status:           restStatus,
+			Timestamp:        TimestampFromEpoch(restTimestamp),
+			RequestOrderType: "basic",
+		})
+	}))
+	defer rest.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = rest.URL
+	tracker := NewOrderTracker(client)
+	tracker.ApplyExecutionReport(&WsExecutionReportDetail{
+		OrderID:   300,
+		Status:    OrderStatusPartiallyFilled,
+		Timestamp: TimestampFromEpoch(2000),
+	})
+
+	// A REST result older than the execution report is ignored
+	s, err := tracker.Track(context.Background(), 300)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.Status != OrderStatusPartiallyFilled || s.UpdatedAt.Epoch() != 2000 {
+		t.Errorf("Expected the stale REST result to be ignored, got %+v", s)
+	}
+
+	mu.Lock()
+	restStatus, restTimestamp = OrderStatusFilled, 3000
+	mu.Unlock()
+	if err := tracker.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s, _ := tracker.Order(300); s.Status != OrderStatusFilled || s.UpdatedAt.Epoch() != 3000 {
+		t.Errorf("Expected the newer REST result to be applied, got %+v", s)
+	}
+}
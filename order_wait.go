@@ -0,0 +1,76 @@
+package versifi
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultWaitPollInterval        = time.Second
+	defaultWaitTrackerPollInterval = 10 * time.Second
+)
+
+// WaitForOrderOptions configures WaitForOrder
+type WaitForOrderOptions struct {
+	// PollInterval is the time between REST polls. It defaults to 1s, or to
+	// 10s when Tracker is set, since polling is then only a fallback.
+	PollInterval time.Duration
+	// Tracker, when set, ends the wait as soon as an execution report with
+	// a final status arrives instead of at the next poll
+	Tracker *OrderTracker
+	// RequestOptions are passed to every GetOrderService call
+	RequestOptions []RequestOption
+}
+
+// WaitForOrder blocks until the order reaches a final status (FILLED,
+// CANCELED, REJECTED or EXPIRED) and returns it, or until ctx is done.
+// opts may be nil.
+func (c *Client) WaitForOrder(ctx context.Context, orderID int64, opts *WaitForOrderOptions) (*GetOrderResponse, error) {
+	if opts == nil {
+		opts = &WaitForOrderOptions{}
+	}
+
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultWaitPollInterval
+		if opts.Tracker != nil {
+			interval = defaultWaitTrackerPollInterval
+		}
+	}
+
+	final := make(chan struct{}, 1)
+	if opts.Tracker != nil {
+		unsubscribe := opts.Tracker.Subscribe(func(u OrderUpdate) {
+			if u.Order.OrderID == orderID && u.Order.Status.IsFinal() {
+				select {
+				case final <- struct{}{}:
+				default:
+				}
+			}
+		})
+		defer unsubscribe()
+	}
+
+	ticker := c.clock().NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		order, err := c.NewGetOrderService().OrderID(orderID).Do(ctx, opts.RequestOptions...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		if order.Status.IsFinal() {
+			return order, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C():
+		case <-final:
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package parquetexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// thriftReader decodes the compact protocol into map[int16]interface{} for
+// structs and []interface{} for lists
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case compactI32, compactI64:
+		return r.varint()
+	case compactBinary:
+		n := int(r.uvarint())
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case compactList:
+		h := r.byte()
+		size, elem := int(h>>4), h&0x0f
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(elem)
+		}
+		return list
+	case compactStruct:
+		return r.structure()
+	}
+	panic(fmt.Sprintf("unexpected type %d", typ))
+}
+
+func (r *thriftReader) structure() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		h := r.byte()
+		if h == 0 {
+			return fields
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(h & 0x0f)
+		last = id
+	}
+}
+
+// readColumn decodes the PLAIN values of column i across all row groups
+func readColumn(t *testing.T, file []byte, footer map[int16]interface{}, i int) []interface{} {
+	var values []interface{}
+	for _, rg := range footer[4].([]interface{}) {
+		chunk := rg.(map[int16]interface{})[1].([]interface{})[i].(map[int16]interface{})
+		meta := chunk[3].(map[int16]interface{})
+		r := &thriftReader{data: file, pos: int(meta[9].(int64))}
+		header := r.structure()
+		n := int(header[5].(map[int16]interface{})[1].(int64))
+		for j := 0; j < n; j++ {
+			if meta[1].(int64) == typeInt64 {
+				values = append(values, int64(binary.LittleEndian.Uint64(file[r.pos:])))
+				r.pos += 8
+			} else {
+				l := int(binary.LittleEndian.Uint32(file[r.pos:]))
+				values = append(values, string(file[r.pos+4:r.pos+4+l]))
+				r.pos += 4 + l
+			}
+		}
+	}
+	return values
+}
+
+func readFooter(t *testing.T, file []byte) map[int16]interface{} {
+	if !bytes.HasPrefix(file, []byte(magic)) || !bytes.HasSuffix(file, []byte(magic)) {
+		t.Fatal("Missing PAR1 magic")
+	}
+	n := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	r := &thriftReader{data: file[len(file)-8-n : len(file)-8]}
+	return r.structure()
+}
+
+func TestThriftEncoding(t *testing.T) {
+	w := newThriftWriter()
+	w.i32(1, 1)
+	w.i64(20, -2)
+	w.binaryList(21, "a")
+	got := w.end()
+	want := []byte{0x15, 0x02, 0x06, 0x28, 0x03, 0x19, 0x18, 0x01, 'a', 0x00}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Expected % x, got % x", want, got)
+	}
+}
+
+func TestExport(t *testing.T) {
+	const numOrders = 5
+	const epoch = 1_700_000_000_000 // Order n is created at epoch + n seconds, in milliseconds
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/orders":
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			var page []versifi.ListOrderItem
+			for id := offset + 1; id <= numOrders && id <= offset+limit; id++ {
+				page = append(page, versifi.ListOrderItem{OrderID: int64(id), Timestamp: versifi.TimestampFromEpoch(epoch + int64(id)*1000)})
+			}
+			json.NewEncoder(w).Encode(page)
+		case strings.HasPrefix(r.URL.Path, "/v2/orders/"):
+			id, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/v2/orders/"), 10, 64)
+			json.NewEncoder(w).Encode(versifi.GetOrderResponse{
+				OrderID:          id,
+				Status:           versifi.OrderStatusFilled,
+				Timestamp:        versifi.TimestampFromEpoch(epoch + id*1000),
+				RequestOrderType: "BASIC",
+				BasicOrder: &versifi.BasicOrderDetail{
+					Exchange: versifi.ExchangeBinanceSpot,
+					Symbol:   "BTC/USDT",
+					Side:     versifi.SideTypeBuy,
+					Quantity: "1",
+					ChildOrders: []versifi.ChildOrder{{ID: id * 10, Trades: []versifi.Trade{
+						{TradeID: id*100 + 1, Price: "100", Quantity: "0.4", Fee: "0.01"},
+						{TradeID: id*100 + 2, Price: "101", Quantity: "0.6", Fee: "0.01"},
+					}}},
+				},
+			})
+		}
+	}))
+	defer server.Close()
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	var orders, trades bytes.Buffer
+	stats, err := Export(context.Background(), client, &orders, &trades, Options{Since: epoch + 2000, PageSize: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stats.Orders != 4 || stats.Trades != 8 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	footer := readFooter(t, orders.Bytes())
+	if footer[3].(int64) != 4 {
+		t.Errorf("Expected 4 order rows, got %v", footer[3])
+	}
+	schema := footer[2].([]interface{})
+	if len(schema) != len(orderColumns)+1 || schema[1].(map[int16]interface{})[4] != "order_id" {
+		t.Errorf("Unexpected schema %v", schema)
+	}
+	if ids := readColumn(t, orders.Bytes(), footer, 0); fmt.Sprint(ids) != "[2 3 4 5]" {
+		t.Errorf("Unexpected order IDs %v", ids)
+	}
+	if symbols := readColumn(t, orders.Bytes(), footer, 7); fmt.Sprint(symbols) != "[BTC/USDT BTC/USDT BTC/USDT BTC/USDT]" {
+		t.Errorf("Unexpected symbols %v", symbols)
+	}
+
+	footer = readFooter(t, trades.Bytes())
+	if prices := readColumn(t, trades.Bytes(), footer, 9); len(prices) != 8 || prices[1] != "101" {
+		t.Errorf("Unexpected trade prices %v", prices)
+	}
+	if orderIDs := readColumn(t, trades.Bytes(), footer, 1); orderIDs[0] != int64(2) {
+		t.Errorf("Expected trade order IDs to default to the order, got %v", orderIDs)
+	}
+}
+
+func TestRowGroups(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewOrderWriter(&buf)
+	w.f.rowGroupSize = 2
+	for id := int64(1); id <= 5; id++ {
+		w.Write(&versifi.GetOrderResponse{OrderID: id})
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	footer := readFooter(t, buf.Bytes())
+	if n := len(footer[4].([]interface{})); n != 3 {
+		t.Errorf("Expected 3 row groups, got %d", n)
+	}
+	if ids := readColumn(t, buf.Bytes(), footer, 0); fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("Unexpected order IDs %v", ids)
+	}
+	if err := w.Write(&versifi.GetOrderResponse{}); err != errClosed {
+		t.Errorf("Expected errClosed, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package parquetexport
+
+import "encoding/binary"
+
+// Thrift compact protocol type codes
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// thriftWriter encodes the Thrift compact protocol structures of the
+// Parquet footer and page headers
+type thriftWriter struct {
+	buf  []byte
+	last []int16 // Last field ID of every open struct
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{last: []int16{0}}
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.last[len(t.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	t.buf = binary.AppendUvarint(t.buf, uint64((v<<1)^(v>>63)))
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	t.buf = binary.AppendUvarint(t.buf, v)
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, compactI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, compactI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, v string) {
+	t.field(id, compactBinary)
+	t.uvarint(uint64(len(v)))
+	t.buf = append(t.buf, v...)
+}
+
+// structField writes a nested struct whose fields are written by body
+func (t *thriftWriter) structField(id int16, body func()) {
+	t.field(id, compactStruct)
+	t.structBody(body)
+}
+
+func (t *thriftWriter) structBody(body func()) {
+	t.last = append(t.last, 0)
+	body()
+	t.buf = append(t.buf, 0) // Stop
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thriftWriter) listHeader(id int16, elem byte, size int) {
+	t.field(id, compactList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elem)
+	} else {
+		t.buf = append(t.buf, 0xf0|elem)
+		t.uvarint(uint64(size))
+	}
+}
+
+func (t *thriftWriter) i32List(id int16, values ...int32) {
+	t.listHeader(id, compactI32, len(values))
+	for _, v := range values {
+		t.varint(int64(v))
+	}
+}
+
+func (t *thriftWriter) binaryList(id int16, values ...string) {
+	t.listHeader(id, compactBinary, len(values))
+	for _, v := range values {
+		t.uvarint(uint64(len(v)))
+		t.buf = append(t.buf, v...)
+	}
+}
+
+// structList writes n structs, the fields of the i-th written by body(i)
+func (t *thriftWriter) structList(id int16, n int, body func(i int)) {
+	t.listHeader(id, compactStruct, n)
+	for i := 0; i < n; i++ {
+		t.structBody(func() { body(i) })
+	}
+}
+
+// end terminates the top-level struct and returns the encoding
+func (t *thriftWriter) end() []byte {
+	return append(t.buf, 0)
+}
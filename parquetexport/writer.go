@@ -0,0 +1,202 @@
+package parquetexport
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	magic = "PAR1"
+	// defaultRowGroupSize is the number of rows buffered before a row group is written
+	defaultRowGroupSize = 8192
+	createdBy           = "versifi-go parquetexport"
+)
+
+// Parquet physical and converted types, encodings and page types
+const (
+	typeInt64     = 2
+	typeByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	pageTypeData      = 0
+)
+
+var errClosed = errors.New("parquetexport: writer closed")
+
+// column is one required, flat column of a schema
+type column struct {
+	name      string
+	typ       int32
+	converted int32 // -1 when none
+}
+
+func stringColumn(name string) column    { return column{name, typeByteArray, convertedUTF8} }
+func int64Column(name string) column     { return column{name, typeInt64, -1} }
+func timestampColumn(name string) column { return column{name, typeInt64, convertedTimestampMillis} }
+
+type columnChunk struct {
+	offset int64 // Of the page header
+	size   int64 // Page header and data
+	values int64
+}
+
+type rowGroup struct {
+	rows    int64
+	size    int64
+	columns []columnChunk
+}
+
+// fileWriter writes uncompressed, PLAIN encoded Parquet files with required
+// columns, one data page per column chunk. Rows are buffered per column and
+// written as a row group every rowGroupSize rows.
+type fileWriter struct {
+	w            io.Writer
+	offset       int64
+	schema       []column
+	pages        [][]byte // Encoded values of the buffered rows, per column
+	rows         int64
+	rowGroupSize int64
+	rowGroups    []rowGroup
+	totalRows    int64
+	err          error
+	closed       bool
+}
+
+func newFileWriter(w io.Writer, schema []column) *fileWriter {
+	f := &fileWriter{
+		w:            w,
+		schema:       schema,
+		pages:        make([][]byte, len(schema)),
+		rowGroupSize: defaultRowGroupSize,
+	}
+	f.write([]byte(magic))
+	return f
+}
+
+// writeRow appends a row. values holds an int64 or a string per column.
+func (f *fileWriter) writeRow(values ...interface{}) error {
+	if f.closed {
+		return errClosed
+	}
+	if f.err != nil {
+		return f.err
+	}
+	for i, v := range values {
+		switch v := v.(type) {
+		case int64:
+			f.pages[i] = binary.LittleEndian.AppendUint64(f.pages[i], uint64(v))
+		case string:
+			f.pages[i] = binary.LittleEndian.AppendUint32(f.pages[i], uint32(len(v)))
+			f.pages[i] = append(f.pages[i], v...)
+		}
+	}
+	f.rows++
+	if f.rows >= f.rowGroupSize {
+		f.flush()
+	}
+	return f.err
+}
+
+// flush writes the buffered rows as a row group
+func (f *fileWriter) flush() {
+	if f.rows == 0 || f.err != nil {
+		return
+	}
+
+	rg := rowGroup{rows: f.rows}
+	for i, data := range f.pages {
+		t := newThriftWriter()
+		t.i32(1, pageTypeData)
+		t.i32(2, int32(len(data)))
+		t.i32(3, int32(len(data)))
+		t.structField(5, func() {
+			t.i32(1, int32(f.rows))
+			t.i32(2, encodingPlain)
+			t.i32(3, encodingRLE)
+			t.i32(4, encodingRLE)
+		})
+		header := t.end()
+
+		chunk := columnChunk{offset: f.offset, size: int64(len(header) + len(data)), values: f.rows}
+		f.write(header)
+		f.write(data)
+		rg.columns = append(rg.columns, chunk)
+		rg.size += chunk.size
+		f.pages[i] = data[:0]
+	}
+
+	f.rowGroups = append(f.rowGroups, rg)
+	f.totalRows += f.rows
+	f.rows = 0
+}
+
+// close writes the remaining rows and the footer
+func (f *fileWriter) close() error {
+	if f.closed {
+		return f.err
+	}
+	f.flush()
+	f.closed = true
+
+	t := newThriftWriter()
+	t.i32(1, 1) // Version
+	t.structList(2, len(f.schema)+1, func(i int) {
+		if i == 0 {
+			t.binary(4, "schema")
+			t.i32(5, int32(len(f.schema)))
+			return
+		}
+		c := f.schema[i-1]
+		t.i32(1, c.typ)
+		t.i32(3, repetitionRequired)
+		t.binary(4, c.name)
+		if c.converted >= 0 {
+			t.i32(6, c.converted)
+		}
+	})
+	t.i64(3, f.totalRows)
+	t.structList(4, len(f.rowGroups), func(i int) {
+		rg := f.rowGroups[i]
+		t.structList(1, len(rg.columns), func(j int) {
+			chunk := rg.columns[j]
+			t.i64(2, chunk.offset)
+			t.structField(3, func() {
+				t.i32(1, f.schema[j].typ)
+				t.i32List(2, encodingPlain, encodingRLE)
+				t.binaryList(3, f.schema[j].name)
+				t.i32(4, codecUncompressed)
+				t.i64(5, chunk.values)
+				t.i64(6, chunk.size)
+				t.i64(7, chunk.size)
+				t.i64(9, chunk.offset)
+			})
+		})
+		t.i64(2, rg.size)
+		t.i64(3, rg.rows)
+	})
+	t.binary(6, createdBy)
+	footer := t.end()
+
+	f.write(footer)
+	f.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	f.write([]byte(magic))
+	return f.err
+}
+
+func (f *fileWriter) write(p []byte) {
+	if f.err != nil {
+		return
+	}
+	n, err := f.w.Write(p)
+	f.offset += int64(n)
+	f.err = err
+}
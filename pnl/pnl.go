@@ -0,0 +1,339 @@
+// Package pnl computes realized PnL per exchange and symbol from trade
+// history, from the REST order details or from a blotter.
+//
+//	calc := pnl.New(pnl.FIFO)
+//	calc.Seed(openingPositions...) // positions carried from the previous day
+//	entries, err := b.Query(ctx, blotter.Filter{Since: start, Until: end})
+//	if err != nil {
+//		return err
+//	}
+//	if err := calc.AddEntries(entries); err != nil {
+//		return err
+//	}
+//	report := calc.Report()
+//
+// Trades must be added in execution order. Prices and fees are taken to be
+// in the quote asset, so PnL is in the quote asset of each symbol. Fees are
+// costs and are subtracted from the realized PnL in NetPnL.
+package pnl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	versifi "github.com/drinkthere/versifi-go"
+	"github.com/drinkthere/versifi-go/blotter"
+)
+
+// Method is the cost basis method used to match closing trades
+type Method string
+
+const (
+	// FIFO closes the oldest open lots first
+	FIFO Method = "FIFO"
+	// AverageCost closes at the average price of the open position
+	AverageCost Method = "AVERAGE_COST"
+)
+
+// Result is the PnL of one symbol on one exchange
+type Result struct {
+	Exchange    versifi.ExchangeType `json:"exchange"`
+	Symbol      string               `json:"symbol"`
+	Method      Method               `json:"method"`
+	Trades      int                  `json:"trades"`
+	Bought      string               `json:"bought"` // Quantity bought
+	Sold        string               `json:"sold"`   // Quantity sold
+	RealizedPnL string               `json:"realized_pnl"`
+	Fees        string               `json:"fees"`
+	NetPnL      string               `json:"net_pnl"` // RealizedPnL - Fees
+	// Position is the open quantity, negative when short
+	Position string `json:"position"`
+	// AverageCost is the average price of the open quantity
+	AverageCost string `json:"average_cost,omitempty"`
+}
+
+// Report is the PnL of every symbol. Totals add up the results of all
+// symbols and are only meaningful when they share a quote asset.
+type Report struct {
+	Method      Method   `json:"method"`
+	Results     []Result `json:"results"` // Sorted by exchange and symbol
+	RealizedPnL string   `json:"realized_pnl"`
+	Fees        string   `json:"fees"`
+	NetPnL      string   `json:"net_pnl"`
+}
+
+// Calculator accumulates trades and computes the PnL. It is not safe for
+// concurrent use.
+type Calculator struct {
+	method Method
+	books  map[bookKey]*book
+	trades map[int64]struct{}
+}
+
+type bookKey struct {
+	exchange versifi.ExchangeType
+	symbol   string
+}
+
+// book holds the open lots of one symbol. All lots share the sign of the
+// position; AverageCost keeps at most one lot.
+type book struct {
+	lots     []lot
+	trades   int
+	bought   decimal.Decimal
+	sold     decimal.Decimal
+	realized decimal.Decimal
+	fees     decimal.Decimal
+}
+
+type lot struct {
+	quantity decimal.Decimal // Signed
+	price    decimal.Decimal
+}
+
+// New creates a calculator using method, FIFO if empty
+func New(method Method) *Calculator {
+	if method == "" {
+		method = FIFO
+	}
+	return &Calculator{
+		method: method,
+		books:  make(map[bookKey]*book),
+		trades: make(map[int64]struct{}),
+	}
+}
+
+// Seed opens positions carried over from before the trades, at their entry
+// price
+func (c *Calculator) Seed(positions ...versifi.Position) {
+	for _, p := range positions {
+		qty := parse(p.Quantity)
+		if qty.IsZero() {
+			continue
+		}
+		b := c.book(p.Exchange, p.Symbol)
+		b.lots = append(b.lots, lot{quantity: qty, price: parse(p.EntryPrice)})
+		if c.method == AverageCost {
+			b.lots = []lot{average(b.lots)}
+		}
+	}
+}
+
+// Add applies trades with their own exchange, symbol and side. Trades with
+// a non-zero TradeID already applied are skipped.
+func (c *Calculator) Add(trades ...versifi.Trade) {
+	for _, t := range trades {
+		c.apply(t.Exchange, t.Symbol, t.Side, t.TradeID, parse(t.Quantity), parse(t.Price), parse(t.Fee))
+	}
+}
+
+// AddOrder applies the trades of a basic or algo order, filling in the
+// order's exchange, symbol and side where a trade lacks them. Pair order
+// trades carry no side and are skipped.
+func (c *Calculator) AddOrder(order *versifi.GetOrderResponse) {
+	var exchange versifi.ExchangeType
+	var symbol string
+	var side versifi.SideType
+	var children []versifi.ChildOrder
+
+	switch {
+	case order.BasicOrder != nil:
+		d := order.BasicOrder
+		exchange, symbol, side, children = d.Exchange, d.Symbol, d.Side, d.ChildOrders
+	case order.AlgoOrder != nil:
+		d := order.AlgoOrder
+		exchange, symbol, side, children = d.Exchange, d.Symbol, d.Side, d.ChildOrders
+	default:
+		return
+	}
+
+	for _, child := range children {
+		for _, t := range child.Trades {
+			if t.Exchange == "" {
+				t.Exchange = exchange
+			}
+			if t.Symbol == "" {
+				t.Symbol = symbol
+			}
+			if t.Side == "" {
+				t.Side = side
+			}
+			c.Add(t)
+		}
+	}
+}
+
+// AddExecutionReport applies the trades of an execution report. Execution
+// reports carry no fees.
+func (c *Calculator) AddExecutionReport(detail *versifi.WsExecutionReportDetail) {
+	var exchange versifi.ExchangeType
+	var symbol string
+	var side versifi.SideType
+	var child *versifi.WsChildOrder
+
+	switch {
+	case detail.Basic != nil:
+		exchange, symbol, side, child = detail.Basic.Exchange, detail.Basic.Symbol, detail.Basic.Side, detail.Basic.ChildOrder
+	case detail.Algo != nil:
+		exchange, symbol, side, child = detail.Algo.Exchange, detail.Algo.Symbol, detail.Algo.Side, detail.Algo.ChildOrder
+	default:
+		return
+	}
+	if child == nil {
+		return
+	}
+
+	for _, t := range child.Trades {
+		c.apply(exchange, symbol, side, t.TradeID, parse(t.ExecutedQuantity), parse(t.ExecutedPrice), decimal.Zero)
+	}
+}
+
+// AddEntries applies the trades of blotter entries, see AddExecutionReport
+func (c *Calculator) AddEntries(entries []blotter.Entry) error {
+	for _, e := range entries {
+		var detail versifi.WsExecutionReportDetail
+		if err := json.Unmarshal(e.Report, &detail); err != nil {
+			return fmt.Errorf("pnl: failed to decode report of order %d: %w", e.OrderID, err)
+		}
+		c.AddExecutionReport(&detail)
+	}
+	return nil
+}
+
+// Result returns the PnL of symbol on exchange
+func (c *Calculator) Result(exchange versifi.ExchangeType, symbol string) (Result, bool) {
+	b, ok := c.books[bookKey{exchange, symbol}]
+	if !ok {
+		return Result{}, false
+	}
+	return c.result(bookKey{exchange, symbol}, b), true
+}
+
+// Report returns the PnL of every symbol and the totals
+func (c *Calculator) Report() *Report {
+	r := &Report{Method: c.method, Results: make([]Result, 0, len(c.books))}
+
+	var realized, fees decimal.Decimal
+	for key, b := range c.books {
+		r.Results = append(r.Results, c.result(key, b))
+		realized = realized.Add(b.realized)
+		fees = fees.Add(b.fees)
+	}
+	sort.Slice(r.Results, func(i, j int) bool {
+		a, b := r.Results[i], r.Results[j]
+		if a.Exchange != b.Exchange {
+			return a.Exchange < b.Exchange
+		}
+		return a.Symbol < b.Symbol
+	})
+
+	r.RealizedPnL = realized.String()
+	r.Fees = fees.String()
+	r.NetPnL = realized.Sub(fees).String()
+	return r
+}
+
+func (c *Calculator) book(exchange versifi.ExchangeType, symbol string) *book {
+	key := bookKey{exchange, symbol}
+	b, ok := c.books[key]
+	if !ok {
+		b = &book{}
+		c.books[key] = b
+	}
+	return b
+}
+
+func (c *Calculator) apply(exchange versifi.ExchangeType, symbol string, side versifi.SideType, tradeID int64, qty, price, fee decimal.Decimal) {
+	if !qty.IsPositive() || (side != versifi.SideTypeBuy && side != versifi.SideTypeSell) {
+		return
+	}
+	if tradeID != 0 {
+		if _, ok := c.trades[tradeID]; ok {
+			return
+		}
+		c.trades[tradeID] = struct{}{}
+	}
+
+	b := c.book(exchange, symbol)
+	b.trades++
+	b.fees = b.fees.Add(fee)
+	if side == versifi.SideTypeBuy {
+		b.bought = b.bought.Add(qty)
+	} else {
+		b.sold = b.sold.Add(qty)
+		qty = qty.Neg()
+	}
+
+	// Close open lots of the opposite sign, oldest first
+	for len(b.lots) > 0 && !qty.IsZero() && b.lots[0].quantity.Sign() != qty.Sign() {
+		open := &b.lots[0]
+		closed := decimal.Min(open.quantity.Abs(), qty.Abs())
+		pnl := closed.Mul(price.Sub(open.price))
+		if open.quantity.IsNegative() {
+			pnl = pnl.Neg()
+		}
+		b.realized = b.realized.Add(pnl)
+
+		if open.quantity.IsPositive() {
+			open.quantity = open.quantity.Sub(closed)
+			qty = qty.Add(closed)
+		} else {
+			open.quantity = open.quantity.Add(closed)
+			qty = qty.Sub(closed)
+		}
+		if open.quantity.IsZero() {
+			b.lots = b.lots[1:]
+		}
+	}
+
+	if !qty.IsZero() {
+		b.lots = append(b.lots, lot{quantity: qty, price: price})
+		if c.method == AverageCost {
+			b.lots = []lot{average(b.lots)}
+		}
+	}
+}
+
+func (c *Calculator) result(key bookKey, b *book) Result {
+	open := average(b.lots)
+	r := Result{
+		Exchange:    key.exchange,
+		Symbol:      key.symbol,
+		Method:      c.method,
+		Trades:      b.trades,
+		Bought:      b.bought.String(),
+		Sold:        b.sold.String(),
+		RealizedPnL: b.realized.String(),
+		Fees:        b.fees.String(),
+		NetPnL:      b.realized.Sub(b.fees).String(),
+		Position:    open.quantity.String(),
+	}
+	if !open.quantity.IsZero() {
+		r.AverageCost = open.price.String()
+	}
+	return r
+}
+
+// average merges lots of the same sign into one at their weighted price
+func average(lots []lot) lot {
+	var qty, notional decimal.Decimal
+	for _, l := range lots {
+		qty = qty.Add(l.quantity)
+		notional = notional.Add(l.quantity.Mul(l.price))
+	}
+	if qty.IsZero() {
+		return lot{}
+	}
+	return lot{quantity: qty, price: notional.DivRound(qty, 12)}
+}
+
+func parse(s string) decimal.Decimal {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero
+	}
+	return d
+}
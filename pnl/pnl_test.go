@@ -0,0 +1,136 @@
+package pnl
+
+import (
+	"encoding/json"
+	"testing"
+
+	versifi "github.com/drinkthere/versifi-go"
+	"github.com/drinkthere/versifi-go/blotter"
+)
+
+func trade(id int64, side versifi.SideType, qty, price string) versifi.Trade {
+	return versifi.Trade{
+		TradeID:  id,
+		Exchange: versifi.ExchangeBinanceSpot,
+		Symbol:   "BTC/USDT",
+		Side:     side,
+		Quantity: qty,
+		Price:    price,
+		Fee:      "0.1",
+	}
+}
+
+func TestCalculatorMethods(t *testing.T) {
+	trades := []versifi.Trade{
+		trade(1, versifi.SideTypeBuy, "1", "100"),
+		trade(2, versifi.SideTypeBuy, "1", "110"),
+		trade(3, versifi.SideTypeSell, "1.5", "120"),
+		trade(3, versifi.SideTypeSell, "1.5", "120"), // Repeated
+		trade(4, versifi.SideTypeSell, "1", "100"),
+	}
+
+	for _, tc := range []struct {
+		method      Method
+		realized    string
+		net         string
+		averageCost string
+	}{
+		// 1 * 20 + 0.5 * 10, then 0.5 * -10 before flipping short
+		{FIFO, "20", "19.6", "100"},
+		// 1.5 * 15, then 0.5 * -5
+		{AverageCost, "20", "19.6", "100"},
+	} {
+		calc := New(tc.method)
+		calc.Add(trades...)
+
+		r, ok := calc.Result(versifi.ExchangeBinanceSpot, "BTC/USDT")
+		if !ok {
+			t.Fatalf("%s: no result", tc.method)
+		}
+		if r.RealizedPnL != tc.realized || r.Fees != "0.4" || r.NetPnL != tc.net || r.Trades != 4 ||
+			r.Position != "-0.5" || r.AverageCost != tc.averageCost || r.Bought != "2" || r.Sold != "2.5" {
+			t.Errorf("%s: unexpected result %+v", tc.method, r)
+		}
+	}
+
+	// The methods differ while a position is still open
+	fifo, avg := New(FIFO), New(AverageCost)
+	fifo.Add(trades[:3]...)
+	avg.Add(trades[:3]...)
+	if r, _ := fifo.Result(versifi.ExchangeBinanceSpot, "BTC/USDT"); r.RealizedPnL != "25" || r.AverageCost != "110" {
+		t.Errorf("Unexpected FIFO result %+v", r)
+	}
+	if r, _ := avg.Result(versifi.ExchangeBinanceSpot, "BTC/USDT"); r.RealizedPnL != "22.5" || r.AverageCost != "105" {
+		t.Errorf("Unexpected average cost result %+v", r)
+	}
+}
+
+func TestCalculatorSeedAndOrder(t *testing.T) {
+	calc := New("")
+	calc.Seed(versifi.Position{Exchange: versifi.ExchangeOKXFutures, Symbol: "ETH/USDT", Quantity: "-2", EntryPrice: "2000"})
+
+	calc.AddOrder(&versifi.GetOrderResponse{
+		AlgoOrder: &versifi.AlgoOrderDetail{
+			Exchange: versifi.ExchangeOKXFutures,
+			Symbol:   "ETH/USDT",
+			Side:     versifi.SideTypeBuy,
+			ChildOrders: []versifi.ChildOrder{
+				{Trades: []versifi.Trade{{TradeID: 1, Quantity: "1", Price: "1900", Fee: "1"}}},
+				{Trades: []versifi.Trade{{TradeID: 2, Quantity: "1", Price: "1950", Fee: "1"}}},
+			},
+		},
+	})
+
+	report := calc.Report()
+	if report.Method != FIFO || len(report.Results) != 1 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	r := report.Results[0]
+	if r.RealizedPnL != "150" || r.NetPnL != "148" || r.Position != "0" || r.AverageCost != "" {
+		t.Errorf("Unexpected result %+v", r)
+	}
+	if report.RealizedPnL != "150" || report.Fees != "2" || report.NetPnL != "148" {
+		t.Errorf("Unexpected totals %+v", report)
+	}
+}
+
+func TestCalculatorAddEntries(t *testing.T) {
+	entry := func(side versifi.SideType, trades ...versifi.WsTrade) blotter.Entry {
+		data, err := json.Marshal(&versifi.WsExecutionReportDetail{
+			RequestOrderType: versifi.RequestOrderTypeBasic,
+			Basic: &versifi.WsBasicOrderDetail{
+				Exchange:   versifi.ExchangeBinanceSpot,
+				Symbol:     "BTC/USDT",
+				Side:       side,
+				ChildOrder: &versifi.WsChildOrder{ID: 1, Trades: trades},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blotter.Entry{Report: data}
+	}
+
+	calc := New(FIFO)
+	err := calc.AddEntries([]blotter.Entry{
+		entry(versifi.SideTypeBuy, versifi.WsTrade{TradeID: 1, ExecutedQuantity: "2", ExecutedPrice: "100"}),
+		// Later reports of the same order repeat earlier trades
+		entry(versifi.SideTypeBuy,
+			versifi.WsTrade{TradeID: 1, ExecutedQuantity: "2", ExecutedPrice: "100"},
+			versifi.WsTrade{TradeID: 2, ExecutedQuantity: "2", ExecutedPrice: "104"},
+		),
+		entry(versifi.SideTypeSell, versifi.WsTrade{TradeID: 3, ExecutedQuantity: "3", ExecutedPrice: "110"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, _ := calc.Result(versifi.ExchangeBinanceSpot, "BTC/USDT")
+	if r.RealizedPnL != "26" || r.Position != "1" || r.AverageCost != "104" || r.Fees != "0" {
+		t.Errorf("Unexpected result %+v", r)
+	}
+
+	if err := calc.AddEntries([]blotter.Entry{{OrderID: 9, Report: []byte("{")}}); err == nil {
+		t.Error("Expected decode error")
+	}
+}
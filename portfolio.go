@@ -0,0 +1,250 @@
+package versifi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultValuationAsset is the asset portfolio notionals are expressed in
+const defaultValuationAsset = "USDT"
+
+// PriceSource prices assets in a valuation asset, for example from a
+// ticker feed, for Portfolio notionals
+type PriceSource interface {
+	Price(ctx context.Context, asset, valuationAsset string) (string, error)
+}
+
+// PriceSourceFunc adapts a function to PriceSource
+type PriceSourceFunc func(ctx context.Context, asset, valuationAsset string) (string, error)
+
+// Price calls f(ctx, asset, valuationAsset)
+func (f PriceSourceFunc) Price(ctx context.Context, asset, valuationAsset string) (string, error) {
+	return f(ctx, asset, valuationAsset)
+}
+
+// AssetExposure is the combined exposure to one asset across exchanges
+type AssetExposure struct {
+	Asset    string `json:"asset"`
+	Balance  string `json:"balance"`  // Sum of expected balances
+	Position string `json:"position"` // Sum of signed derivatives positions in the asset
+	Net      string `json:"net"`      // Balance plus position
+	// Price and notionals are empty when no PriceSource is set
+	Price         string `json:"price,omitempty"`
+	NetNotional   string `json:"net_notional,omitempty"`
+	GrossNotional string `json:"gross_notional,omitempty"` // Sum of absolute per-exchange holdings times price
+}
+
+// PortfolioSnapshot is the portfolio at one point in time
+type PortfolioSnapshot struct {
+	ValuationAsset string          `json:"valuation_asset"`
+	Assets         []AssetExposure `json:"assets"` // Sorted by asset
+	NetNotional    string          `json:"net_notional,omitempty"`
+	GrossNotional  string          `json:"gross_notional,omitempty"`
+	Time           time.Time       `json:"time"`
+}
+
+// PortfolioHandler handles refreshed portfolio snapshots
+type PortfolioHandler func(snapshot *PortfolioSnapshot)
+
+// Portfolio merges the balances and positions of every exchange into one
+// view of net exposure per asset.
+//
+// Spot holdings are taken from the balance tracker. Positions on futures
+// exchanges are added to the base asset of their BASE/QUOTE symbol; spot
+// positions are skipped because their fills already move the balances.
+type Portfolio struct {
+	// ValuationAsset is the asset notionals are expressed in, default USDT
+	ValuationAsset string
+	// Prices prices assets for notionals. Without it only quantities are reported.
+	Prices PriceSource
+
+	balances  *BalanceTracker
+	positions *PositionTracker
+
+	mu         sync.RWMutex
+	snapshot   *PortfolioSnapshot
+	handlers   map[int]PortfolioHandler
+	nextID     int
+	errHandler ErrHandler
+}
+
+// NewPortfolio creates a portfolio over balances and positions, either of
+// which may be nil
+func NewPortfolio(balances *BalanceTracker, positions *PositionTracker) *Portfolio {
+	return &Portfolio{
+		ValuationAsset: defaultValuationAsset,
+		balances:       balances,
+		positions:      positions,
+		handlers:       make(map[int]PortfolioHandler),
+	}
+}
+
+// SetErrorHandler sets the handler for refresh errors of an attached portfolio
+func (p *Portfolio) SetErrorHandler(handler ErrHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errHandler = handler
+}
+
+// Subscribe registers handler for refreshed snapshots and returns a function
+// that removes it. Handlers are called synchronously by Refresh.
+func (p *Portfolio) Subscribe(handler PortfolioHandler) (unsubscribe func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := p.nextID
+	p.nextID++
+	p.handlers[id] = handler
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.handlers, id)
+	}
+}
+
+// Attach refreshes the portfolio on every position change until detach is
+// called. Balance updates are picked up by the next refresh.
+func (p *Portfolio) Attach(ctx context.Context) (detach func()) {
+	if p.positions == nil {
+		return func() {}
+	}
+	return p.positions.Subscribe(func(PositionChange) {
+		if _, err := p.Refresh(ctx); err != nil {
+			p.mu.RLock()
+			handler := p.errHandler
+			p.mu.RUnlock()
+			if handler != nil {
+				handler(err)
+			}
+		}
+	})
+}
+
+// Snapshot returns the last refreshed snapshot, or nil before the first refresh
+func (p *Portfolio) Snapshot() *PortfolioSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.snapshot
+}
+
+// Refresh rebuilds the snapshot from the trackers, prices it and notifies
+// subscribers
+func (p *Portfolio) Refresh(ctx context.Context) (*PortfolioSnapshot, error) {
+	valuation := p.ValuationAsset
+	if valuation == "" {
+		valuation = defaultValuationAsset
+	}
+
+	assets := make(map[string]*exposure)
+	get := func(asset string) *exposure {
+		e, ok := assets[asset]
+		if !ok {
+			e = &exposure{}
+			assets[asset] = e
+		}
+		return e
+	}
+	if p.balances != nil {
+		for _, b := range p.balances.Snapshot() {
+			qty := toDecimal(b.Expected)
+			e := get(b.Asset)
+			e.balance = e.balance.Add(qty)
+			e.gross = e.gross.Add(qty.Abs())
+		}
+	}
+	if p.positions != nil {
+		for _, pos := range p.positions.Positions() {
+			if !isFuturesExchange(pos.Exchange) {
+				continue
+			}
+			base, _, ok := strings.Cut(pos.Symbol, "/")
+			if !ok {
+				continue
+			}
+			qty := toDecimal(pos.Quantity)
+			e := get(base)
+			e.position = e.position.Add(qty)
+			e.gross = e.gross.Add(qty.Abs())
+		}
+	}
+
+	snapshot := &PortfolioSnapshot{
+		ValuationAsset: valuation,
+		Assets:         make([]AssetExposure, 0, len(assets)),
+		Time:           time.Now(),
+	}
+	var netNotional, grossNotional decimal.Decimal
+	for asset, e := range assets {
+		net := e.balance.Add(e.position)
+		exp := AssetExposure{
+			Asset:    asset,
+			Balance:  e.balance.String(),
+			Position: e.position.String(),
+			Net:      net.String(),
+		}
+		if p.Prices != nil {
+			price := decimal.NewFromInt(1)
+			if asset != valuation {
+				s, err := p.Prices.Price(ctx, asset, valuation)
+				if err != nil {
+					return nil, fmt.Errorf("price %s: %w", asset, err)
+				}
+				price = toDecimal(s)
+			}
+			exp.Price = price.String()
+			exp.NetNotional = net.Mul(price).String()
+			exp.GrossNotional = e.gross.Mul(price).String()
+			netNotional = netNotional.Add(net.Mul(price))
+			grossNotional = grossNotional.Add(e.gross.Mul(price))
+		}
+		snapshot.Assets = append(snapshot.Assets, exp)
+	}
+	sort.Slice(snapshot.Assets, func(i, j int) bool {
+		return snapshot.Assets[i].Asset < snapshot.Assets[j].Asset
+	})
+	if p.Prices != nil {
+		snapshot.NetNotional = netNotional.String()
+		snapshot.GrossNotional = grossNotional.String()
+	}
+
+	p.mu.Lock()
+	p.snapshot = snapshot
+	handlers := p.handlersLocked()
+	p.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(snapshot)
+	}
+	return snapshot, nil
+}
+
+func (p *Portfolio) handlersLocked() []PortfolioHandler {
+	ids := make([]int, 0, len(p.handlers))
+	for id := range p.handlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	handlers := make([]PortfolioHandler, len(ids))
+	for i, id := range ids {
+		handlers[i] = p.handlers[id]
+	}
+	return handlers
+}
+
+type exposure struct {
+	balance  decimal.Decimal
+	position decimal.Decimal
+	gross    decimal.Decimal
+}
+
+// isFuturesExchange reports whether positions on exchange are derivatives
+// rather than spot holdings
+func isFuturesExchange(exchange ExchangeType) bool {
+	return exchange == ExchangeBinanceFutures || exchange == ExchangeOKXFutures
+}
@@ -0,0 +1,54 @@
+package versifi
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPortfolio(t *testing.T) {
+	balances := NewBalanceTracker()
+	balances.Update(
+		Balance{Exchange: ExchangeBinanceSpot, Asset: "BTC", Total: "2"},
+		Balance{Exchange: ExchangeOKXSpot, Asset: "BTC", Total: "1"},
+		Balance{Exchange: ExchangeBinanceSpot, Asset: "USDT", Total: "1000"},
+	)
+	positions := NewPositionTracker()
+	positions.ApplyTrade(ExchangeBinanceFutures, "BTC/USDT", SideTypeSell, 1, "2.5", "100")
+	// Spot positions are already reflected in balances
+	positions.ApplyTrade(ExchangeOKXSpot, "BTC/USDT", SideTypeBuy, 2, "1", "100")
+
+	portfolio := NewPortfolio(balances, positions)
+	portfolio.Prices = PriceSourceFunc(func(ctx context.Context, asset, valuation string) (string, error) {
+		return "100", nil
+	})
+
+	var refreshed []*PortfolioSnapshot
+	portfolio.Subscribe(func(s *PortfolioSnapshot) { refreshed = append(refreshed, s) })
+
+	snapshot, err := portfolio.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(snapshot.Assets) != 2 {
+		t.Fatalf("Expected 2 assets, got %+v", snapshot.Assets)
+	}
+	btc := snapshot.Assets[0]
+	if btc.Asset != "BTC" || btc.Balance != "3" || btc.Position != "-2.5" || btc.Net != "0.5" ||
+		btc.NetNotional != "50" || btc.GrossNotional != "550" {
+		t.Errorf("Unexpected BTC exposure %+v", btc)
+	}
+	usdt := snapshot.Assets[1]
+	if usdt.Price != "1" || usdt.NetNotional != "1000" {
+		t.Errorf("Unexpected USDT exposure %+v", usdt)
+	}
+	if snapshot.NetNotional != "1050" || snapshot.GrossNotional != "1550" {
+		t.Errorf("Unexpected totals net %s gross %s", snapshot.NetNotional, snapshot.GrossNotional)
+	}
+
+	detach := portfolio.Attach(context.Background())
+	positions.ApplyTrade(ExchangeBinanceFutures, "BTC/USDT", SideTypeBuy, 3, "2.5", "100")
+	detach()
+	if len(refreshed) != 2 || portfolio.Snapshot().Assets[0].Net != "3" {
+		t.Errorf("Expected refresh on position change, got %d snapshots", len(refreshed))
+	}
+}
@@ -0,0 +1,156 @@
+package versifi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PositionBreakType is the kind of disagreement in a PositionBreak
+type PositionBreakType string
+
+const (
+	// PositionBreakQuantity: tracker and positions endpoint both hold the
+	// position with different quantities
+	PositionBreakQuantity PositionBreakType = "QUANTITY"
+	// PositionBreakMissing: the tracker holds a position the positions
+	// endpoint does not report
+	PositionBreakMissing PositionBreakType = "MISSING"
+	// PositionBreakUnknown: the positions endpoint reports a position the
+	// tracker does not hold
+	PositionBreakUnknown PositionBreakType = "UNKNOWN"
+	// PositionBreakExpected: the reported quantity differs from the
+	// expected one
+	PositionBreakExpected PositionBreakType = "EXPECTED"
+)
+
+// ReconcileConfig configures ReconcilePositions
+type ReconcileConfig struct {
+	// Expected, when set, are the positions the caller believes it holds,
+	// for example from an internal book. Symbols not listed are expected
+	// to be flat.
+	Expected []Position
+	// Tolerance is the absolute quantity difference ignored, default 0
+	Tolerance string
+}
+
+// PositionBreak is one symbol whose quantities disagree. Quantities of
+// positions a source does not hold are "0".
+type PositionBreak struct {
+	Exchange ExchangeType        `json:"exchange"`
+	Symbol   string              `json:"symbol"`
+	Types    []PositionBreakType `json:"types"`
+	Reported string              `json:"reported"`
+	Tracked  string              `json:"tracked"`
+	Expected string              `json:"expected,omitempty"` // Only with ReconcileConfig.Expected
+	// TrackedDifference is Reported - Tracked
+	TrackedDifference string `json:"tracked_difference"`
+	// ExpectedDifference is Reported - Expected
+	ExpectedDifference string `json:"expected_difference,omitempty"`
+}
+
+// ReconciliationReport is the result of ReconcilePositions
+type ReconciliationReport struct {
+	Time    time.Time       `json:"time"`
+	Checked int             `json:"checked"` // Number of symbols compared
+	Breaks  []PositionBreak `json:"breaks"`  // Sorted by exchange and symbol
+}
+
+// OK reports whether every position reconciled
+func (r *ReconciliationReport) OK() bool {
+	return len(r.Breaks) == 0
+}
+
+// ReconcilePositions compares the positions derived by tracker with those
+// loaded from source, typically the positions endpoint, and with
+// cfg.Expected if set. The tracker is not modified; reseed it with
+// PositionTracker.Seed to accept the reported positions.
+func ReconcilePositions(ctx context.Context, tracker *PositionTracker, source PositionSource, cfg ReconcileConfig) (*ReconciliationReport, error) {
+	var tolerance decimal.Decimal
+	if cfg.Tolerance != "" {
+		var err error
+		tolerance, err = decimal.NewFromString(cfg.Tolerance)
+		if err != nil || tolerance.IsNegative() {
+			return nil, fmt.Errorf("reconcile: invalid tolerance %q", cfg.Tolerance)
+		}
+	}
+
+	reported, err := source.Positions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type quantities struct {
+		reported, tracked, expected decimal.Decimal
+		hasReported, hasTracked     bool
+	}
+	rows := make(map[positionKey]*quantities)
+	row := func(exchange ExchangeType, symbol string) *quantities {
+		key := positionKey{exchange, symbol}
+		q, ok := rows[key]
+		if !ok {
+			q = &quantities{}
+			rows[key] = q
+		}
+		return q
+	}
+	for _, p := range reported {
+		q := row(p.Exchange, p.Symbol)
+		q.reported = q.reported.Add(toDecimal(p.Quantity))
+		q.hasReported = true
+	}
+	for _, p := range tracker.Positions() {
+		q := row(p.Exchange, p.Symbol)
+		q.tracked = toDecimal(p.Quantity)
+		q.hasTracked = true
+	}
+	for _, p := range cfg.Expected {
+		q := row(p.Exchange, p.Symbol)
+		q.expected = q.expected.Add(toDecimal(p.Quantity))
+	}
+
+	report := &ReconciliationReport{Time: time.Now(), Checked: len(rows)}
+	for key, q := range rows {
+		b := PositionBreak{
+			Exchange:          key.exchange,
+			Symbol:            key.symbol,
+			Reported:          q.reported.String(),
+			Tracked:           q.tracked.String(),
+			TrackedDifference: q.reported.Sub(q.tracked).String(),
+		}
+
+		if q.reported.Sub(q.tracked).Abs().GreaterThan(tolerance) {
+			switch {
+			case !q.hasReported:
+				b.Types = append(b.Types, PositionBreakMissing)
+			case !q.hasTracked:
+				b.Types = append(b.Types, PositionBreakUnknown)
+			default:
+				b.Types = append(b.Types, PositionBreakQuantity)
+			}
+		}
+		if cfg.Expected != nil {
+			b.Expected = q.expected.String()
+			b.ExpectedDifference = q.reported.Sub(q.expected).String()
+			if q.reported.Sub(q.expected).Abs().GreaterThan(tolerance) {
+				b.Types = append(b.Types, PositionBreakExpected)
+			}
+		}
+
+		if len(b.Types) > 0 {
+			report.Breaks = append(report.Breaks, b)
+		}
+	}
+
+	sort.Slice(report.Breaks, func(i, j int) bool {
+		a, b := report.Breaks[i], report.Breaks[j]
+		if a.Exchange != b.Exchange {
+			return a.Exchange < b.Exchange
+		}
+		return a.Symbol < b.Symbol
+	})
+	return report, nil
+}
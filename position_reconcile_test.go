@@ -0,0 +1,60 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReconcilePositions(t *testing.T) {
+	tracker := NewPositionTracker()
+	tracker.ApplyTrade(ExchangeBinanceFutures, "BTC/USDT", SideTypeBuy, 1, "2", "100")
+	tracker.ApplyTrade(ExchangeBinanceFutures, "ETH/USDT", SideTypeSell, 2, "5", "2000")
+	tracker.ApplyTrade(ExchangeOKXFutures, "SOL/USDT", SideTypeBuy, 3, "10", "20")
+
+	source := PositionSourceFunc(func(ctx context.Context) ([]Position, error) {
+		return []Position{
+			{Exchange: ExchangeBinanceFutures, Symbol: "BTC/USDT", Quantity: "2.0000001"},
+			{Exchange: ExchangeBinanceFutures, Symbol: "ETH/USDT", Quantity: "-4"},
+			{Exchange: ExchangeOKXFutures, Symbol: "DOGE/USDT", Quantity: "100"},
+		}, nil
+	})
+
+	report, err := ReconcilePositions(context.Background(), tracker, source, ReconcileConfig{
+		Expected: []Position{
+			{Exchange: ExchangeBinanceFutures, Symbol: "BTC/USDT", Quantity: "1"},
+			{Exchange: ExchangeBinanceFutures, Symbol: "ETH/USDT", Quantity: "-4"},
+			{Exchange: ExchangeOKXFutures, Symbol: "DOGE/USDT", Quantity: "100"},
+		},
+		Tolerance: "0.000001",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || report.Checked != 4 || len(report.Breaks) != 4 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+
+	btc, eth, doge, sol := report.Breaks[0], report.Breaks[1], report.Breaks[2], report.Breaks[3]
+	if btc.Symbol != "BTC/USDT" || len(btc.Types) != 1 || btc.Types[0] != PositionBreakExpected || btc.ExpectedDifference != "1.0000001" {
+		t.Errorf("Expected only an expected break within tolerance of the tracker, got %+v", btc)
+	}
+	if eth.Symbol != "ETH/USDT" || len(eth.Types) != 1 || eth.Types[0] != PositionBreakQuantity || eth.TrackedDifference != "1" {
+		t.Errorf("Unexpected ETH break %+v", eth)
+	}
+	if doge.Symbol != "DOGE/USDT" || len(doge.Types) != 1 || doge.Types[0] != PositionBreakUnknown {
+		t.Errorf("Unexpected DOGE break %+v", doge)
+	}
+	// Unlisted symbols are expected flat, which the endpoint agrees with
+	if sol.Symbol != "SOL/USDT" || len(sol.Types) != 1 || sol.Types[0] != PositionBreakMissing ||
+		sol.Reported != "0" || sol.Expected != "0" || sol.TrackedDifference != "-10" {
+		t.Errorf("Unexpected SOL break %+v", sol)
+	}
+
+	failing := PositionSourceFunc(func(ctx context.Context) ([]Position, error) {
+		return nil, errors.New("down")
+	})
+	if _, err := ReconcilePositions(context.Background(), tracker, failing, ReconcileConfig{}); err == nil {
+		t.Error("Expected source error")
+	}
+}
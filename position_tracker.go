@@ -0,0 +1,354 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// tradeHistorySize bounds how many trade IDs are remembered for deduplication
+const tradeHistorySize = 1 << 16
+
+// Position is the net position in one symbol on one exchange.
+// Quantity is negative for short positions.
+type Position struct {
+	Exchange    ExchangeType `json:"exchange"`
+	Symbol      string       `json:"symbol"`
+	Quantity    string       `json:"quantity"`
+	EntryPrice  string       `json:"entry_price,omitempty"` // Average price of the open quantity
+	RealizedPnL string       `json:"realized_pnl,omitempty"`
+	LastTradeID int64        `json:"last_trade_id,omitempty"`
+}
+
+// PositionSource loads current positions, for example from an account or
+// exchange positions endpoint, to seed a PositionTracker
+type PositionSource interface {
+	Positions(ctx context.Context) ([]Position, error)
+}
+
+// PositionSourceFunc adapts a function to PositionSource
+type PositionSourceFunc func(ctx context.Context) ([]Position, error)
+
+// Positions calls f(ctx)
+func (f PositionSourceFunc) Positions(ctx context.Context) ([]Position, error) {
+	return f(ctx)
+}
+
+// PositionChange describes a change to one position
+type PositionChange struct {
+	Position Position
+	Previous Position
+}
+
+// PositionHandler handles position changes
+type PositionHandler func(change PositionChange)
+
+// PositionTracker derives per-exchange, per-symbol positions from the trades
+// in execution reports. Trades of pair orders carry no side and are ignored.
+//
+// Feed it from the execution report handler, alongside any other consumers:
+//
+//	wsClient.SubscribeExecutionReport(func(message []byte) {
+//		orders.HandleExecutionReport(message)
+//		positions.HandleExecutionReport(message)
+//	})
+type PositionTracker struct {
+	mu         sync.RWMutex
+	positions  map[positionKey]*position
+	trades     map[int64]struct{}
+	tradeOrder []int64
+	handlers   map[int]PositionHandler
+	nextID     int
+	errHandler ErrHandler
+}
+
+type positionKey struct {
+	exchange ExchangeType
+	symbol   string
+}
+
+type position struct {
+	quantity    decimal.Decimal
+	entryPrice  decimal.Decimal
+	realizedPnL decimal.Decimal
+	lastTradeID int64
+}
+
+// NewPositionTracker creates an empty position tracker
+func NewPositionTracker() *PositionTracker {
+	return &PositionTracker{
+		positions: make(map[positionKey]*position),
+		trades:    make(map[int64]struct{}),
+		handlers:  make(map[int]PositionHandler),
+	}
+}
+
+// SetErrorHandler sets the handler for undecodable execution reports
+func (t *PositionTracker) SetErrorHandler(handler ErrHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errHandler = handler
+}
+
+// Subscribe registers handler for position changes and returns a function
+// that removes it. Handlers are called synchronously, in change order.
+func (t *PositionTracker) Subscribe(handler PositionHandler) (unsubscribe func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.nextID
+	t.nextID++
+	t.handlers[id] = handler
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.handlers, id)
+	}
+}
+
+// Seed replaces the tracked positions with those loaded from source.
+// Subscribers are notified of every position that changed.
+func (t *PositionTracker) Seed(ctx context.Context, source PositionSource) error {
+	positions, err := source.Positions(ctx)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	previous := t.snapshotLocked()
+	t.positions = make(map[positionKey]*position, len(positions))
+	for _, p := range positions {
+		t.positions[positionKey{p.Exchange, p.Symbol}] = &position{
+			quantity:    toDecimal(p.Quantity),
+			entryPrice:  toDecimal(p.EntryPrice),
+			realizedPnL: toDecimal(p.RealizedPnL),
+			lastTradeID: p.LastTradeID,
+		}
+	}
+	current := t.snapshotLocked()
+	handlers := t.handlersLocked()
+	t.mu.Unlock()
+
+	var changes []PositionChange
+	for key, p := range current {
+		if prev, ok := previous[key]; !ok || prev != p {
+			changes = append(changes, PositionChange{Position: p, Previous: prev})
+		}
+	}
+	for key, p := range previous {
+		if _, ok := current[key]; !ok {
+			changes = append(changes, PositionChange{
+				Position: Position{Exchange: p.Exchange, Symbol: p.Symbol, Quantity: "0"},
+				Previous: p,
+			})
+		}
+	}
+	sortPositionChanges(changes)
+
+	for _, change := range changes {
+		for _, handler := range handlers {
+			handler(change)
+		}
+	}
+	return nil
+}
+
+// Position returns the position in symbol on exchange
+func (t *PositionTracker) Position(exchange ExchangeType, symbol string) (Position, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	p, ok := t.positions[positionKey{exchange, symbol}]
+	if !ok {
+		return Position{}, false
+	}
+	return p.export(positionKey{exchange, symbol}), true
+}
+
+// Positions returns every tracked position, sorted by exchange and symbol
+func (t *PositionTracker) Positions() []Position {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	res := make([]Position, 0, len(t.positions))
+	for key, p := range t.positions {
+		res = append(res, p.export(key))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Exchange != res[j].Exchange {
+			return res[i].Exchange < res[j].Exchange
+		}
+		return res[i].Symbol < res[j].Symbol
+	})
+	return res
+}
+
+// HandleExecutionReport applies the trades in an execution_report message
+func (t *PositionTracker) HandleExecutionReport(message []byte) {
+	var report WsExecutionReport
+	if err := json.Unmarshal(message, &report); err != nil {
+		t.mu.RLock()
+		handler := t.errHandler
+		t.mu.RUnlock()
+		if handler != nil {
+			handler(err)
+		}
+		return
+	}
+	t.ApplyExecutionReport(&report.Message)
+}
+
+// ApplyExecutionReport applies the trades in a decoded execution report.
+// Trades already applied are skipped.
+func (t *PositionTracker) ApplyExecutionReport(detail *WsExecutionReportDetail) {
+	var exchange ExchangeType
+	var symbol string
+	var side SideType
+	var child *WsChildOrder
+
+	switch {
+	case detail.Basic != nil:
+		exchange, symbol, side, child = detail.Basic.Exchange, detail.Basic.Symbol, detail.Basic.Side, detail.Basic.ChildOrder
+	case detail.Algo != nil:
+		exchange, symbol, side, child = detail.Algo.Exchange, detail.Algo.Symbol, detail.Algo.Side, detail.Algo.ChildOrder
+	default:
+		return
+	}
+	if child == nil {
+		return
+	}
+
+	for _, trade := range child.Trades {
+		t.ApplyTrade(exchange, symbol, side, trade.TradeID, trade.ExecutedQuantity, trade.ExecutedPrice)
+	}
+}
+
+// ApplyTrade applies a single trade. A non-zero tradeID that was already
+// applied is skipped.
+func (t *PositionTracker) ApplyTrade(exchange ExchangeType, symbol string, side SideType, tradeID int64, quantity, price string) {
+	qty := toDecimal(quantity)
+	if qty.IsZero() {
+		return
+	}
+	if side == SideTypeSell {
+		qty = qty.Neg()
+	}
+
+	key := positionKey{exchange, symbol}
+
+	t.mu.Lock()
+	if tradeID != 0 {
+		if _, ok := t.trades[tradeID]; ok {
+			t.mu.Unlock()
+			return
+		}
+		t.rememberTradeLocked(tradeID)
+	}
+
+	p, ok := t.positions[key]
+	if !ok {
+		p = &position{}
+		t.positions[key] = p
+	}
+	var previous Position
+	if ok {
+		previous = p.export(key)
+	}
+	p.apply(qty, toDecimal(price))
+	if tradeID != 0 {
+		p.lastTradeID = tradeID
+	}
+	current := p.export(key)
+	handlers := t.handlersLocked()
+	t.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(PositionChange{Position: current, Previous: previous})
+	}
+}
+
+// apply adds a signed quantity traded at price, realizing PnL on the part
+// that reduces the position
+func (p *position) apply(qty, price decimal.Decimal) {
+	if p.quantity.IsZero() || p.quantity.Sign() == qty.Sign() {
+		total := p.quantity.Abs().Add(qty.Abs())
+		p.entryPrice = p.quantity.Abs().Mul(p.entryPrice).Add(qty.Abs().Mul(price)).Div(total)
+		p.quantity = p.quantity.Add(qty)
+		return
+	}
+
+	closed := decimal.Min(qty.Abs(), p.quantity.Abs())
+	pnl := closed.Mul(price.Sub(p.entryPrice))
+	if p.quantity.IsNegative() {
+		pnl = pnl.Neg()
+	}
+	p.realizedPnL = p.realizedPnL.Add(pnl)
+
+	remaining := p.quantity.Add(qty)
+	switch {
+	case remaining.IsZero():
+		p.entryPrice = decimal.Zero
+	case remaining.Sign() != p.quantity.Sign():
+		// The trade flipped the position, the new side opens at price
+		p.entryPrice = price
+	}
+	p.quantity = remaining
+}
+
+func (p *position) export(key positionKey) Position {
+	res := Position{
+		Exchange:    key.exchange,
+		Symbol:      key.symbol,
+		Quantity:    p.quantity.String(),
+		LastTradeID: p.lastTradeID,
+	}
+	if !p.quantity.IsZero() {
+		res.EntryPrice = p.entryPrice.String()
+	}
+	if !p.realizedPnL.IsZero() {
+		res.RealizedPnL = p.realizedPnL.String()
+	}
+	return res
+}
+
+func (t *PositionTracker) rememberTradeLocked(tradeID int64) {
+	t.trades[tradeID] = struct{}{}
+	t.tradeOrder = append(t.tradeOrder, tradeID)
+	if len(t.tradeOrder) > tradeHistorySize {
+		delete(t.trades, t.tradeOrder[0])
+		t.tradeOrder = t.tradeOrder[1:]
+	}
+}
+
+func (t *PositionTracker) snapshotLocked() map[positionKey]Position {
+	res := make(map[positionKey]Position, len(t.positions))
+	for key, p := range t.positions {
+		res[key] = p.export(key)
+	}
+	return res
+}
+
+func (t *PositionTracker) handlersLocked() []PositionHandler {
+	ids := make([]int, 0, len(t.handlers))
+	for id := range t.handlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	handlers := make([]PositionHandler, len(ids))
+	for i, id := range ids {
+		handlers[i] = t.handlers[id]
+	}
+	return handlers
+}
+
+func sortPositionChanges(changes []PositionChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i].Position, changes[j].Position
+		if a.Exchange != b.Exchange {
+			return a.Exchange < b.Exchange
+		}
+		return a.Symbol < b.Symbol
+	})
+}
@@ -0,0 +1,82 @@
+package versifi
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPositionTracker(t *testing.T) {
+	tracker := NewPositionTracker()
+
+	var changes []PositionChange
+	tracker.Subscribe(func(c PositionChange) { changes = append(changes, c) })
+
+	report := func(side SideType, trades ...WsTrade) *WsExecutionReportDetail {
+		return &WsExecutionReportDetail{
+			OrderID:          1,
+			RequestOrderType: "basic",
+			Basic: &WsBasicOrderDetail{
+				Symbol:     "BTC/USDT",
+				Exchange:   ExchangeBinanceSpot,
+				Side:       side,
+				ChildOrder: &WsChildOrder{ID: 1, Trades: trades},
+			},
+		}
+	}
+
+	tracker.ApplyExecutionReport(report(SideTypeBuy,
+		WsTrade{TradeID: 1, ExecutedPrice: "100", ExecutedQuantity: "1"},
+		WsTrade{TradeID: 2, ExecutedPrice: "110", ExecutedQuantity: "1"},
+	))
+	// Repeated trades are skipped
+	tracker.ApplyExecutionReport(report(SideTypeBuy,
+		WsTrade{TradeID: 2, ExecutedPrice: "110", ExecutedQuantity: "1"},
+	))
+
+	p, ok := tracker.Position(ExchangeBinanceSpot, "BTC/USDT")
+	if !ok || p.Quantity != "2" || p.EntryPrice != "105" || p.LastTradeID != 2 {
+		t.Errorf("Unexpected position after buys %+v", p)
+	}
+
+	// Selling through the position realizes PnL on the closed part and opens a short
+	tracker.ApplyExecutionReport(report(SideTypeSell,
+		WsTrade{TradeID: 3, ExecutedPrice: "120", ExecutedQuantity: "3"},
+	))
+	p, _ = tracker.Position(ExchangeBinanceSpot, "BTC/USDT")
+	if p.Quantity != "-1" || p.EntryPrice != "120" || p.RealizedPnL != "30" {
+		t.Errorf("Unexpected position after sell %+v", p)
+	}
+
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %d", len(changes))
+	}
+	if changes[2].Previous.Quantity != "2" || changes[2].Position.Quantity != "-1" {
+		t.Errorf("Unexpected change %+v", changes[2])
+	}
+}
+
+func TestPositionTrackerSeed(t *testing.T) {
+	tracker := NewPositionTracker()
+	tracker.ApplyTrade(ExchangeOKXSpot, "ETH/USDT", SideTypeBuy, 1, "5", "2000")
+
+	var changes []PositionChange
+	tracker.Subscribe(func(c PositionChange) { changes = append(changes, c) })
+
+	err := tracker.Seed(context.Background(), PositionSourceFunc(func(context.Context) ([]Position, error) {
+		return []Position{{Exchange: ExchangeBinanceFutures, Symbol: "BTC/USDT", Quantity: "-0.5", EntryPrice: "30000"}}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	positions := tracker.Positions()
+	if len(positions) != 1 || positions[0].Symbol != "BTC/USDT" || positions[0].Quantity != "-0.5" {
+		t.Errorf("Unexpected positions %+v", positions)
+	}
+
+	// One change for the seeded position and one for the dropped one
+	if len(changes) != 2 || changes[0].Position.Exchange != ExchangeBinanceFutures ||
+		changes[1].Previous.Symbol != "ETH/USDT" || changes[1].Position.Quantity != "0" {
+		t.Errorf("Unexpected changes %+v", changes)
+	}
+}
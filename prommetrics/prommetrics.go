@@ -0,0 +1,133 @@
+// Package prommetrics adapts versifi.WsMetrics to Prometheus collectors.
+//
+//	m := prommetrics.NewWsMetrics("versifi")
+//	prometheus.MustRegister(m)
+//	wsClient.SetMetrics(m)
+package prommetrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+var _ versifi.WsMetrics = (*WsMetrics)(nil)
+
+// WsMetrics implements versifi.WsMetrics and prometheus.Collector
+type WsMetrics struct {
+	messagesIn     *prometheus.CounterVec
+	bytesIn        *prometheus.CounterVec
+	messagesOut    prometheus.Counter
+	bytesOut       prometheus.Counter
+	handlerLatency *prometheus.HistogramVec
+	reconnects     *prometheus.CounterVec
+	authFailures   prometheus.Counter
+}
+
+// NewWsMetrics creates websocket collectors under the given namespace
+func NewWsMetrics(namespace string) *WsMetrics {
+	return &WsMetrics{
+		messagesIn: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "messages_received_total",
+			Help:      "Number of websocket messages received, by op.",
+		}, []string{"topic"}),
+		bytesIn: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "bytes_received_total",
+			Help:      "Number of websocket bytes received, by op.",
+		}, []string{"topic"}),
+		messagesOut: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "messages_sent_total",
+			Help:      "Number of websocket messages sent.",
+		}),
+		bytesOut: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "bytes_sent_total",
+			Help:      "Number of websocket bytes sent.",
+		}),
+		handlerLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "handler_duration_seconds",
+			Help:      "Time spent in subscription handlers, by topic.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, []string{"topic"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "reconnects_total",
+			Help:      "Number of reconnection attempts, by result.",
+		}, []string{"result"}),
+		authFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "auth_failures_total",
+			Help:      "Number of failed websocket authentications.",
+		}),
+	}
+}
+
+// MessageReceived implements versifi.WsMetrics
+func (m *WsMetrics) MessageReceived(topic string, bytes int) {
+	m.messagesIn.WithLabelValues(topic).Inc()
+	m.bytesIn.WithLabelValues(topic).Add(float64(bytes))
+}
+
+// MessageSent implements versifi.WsMetrics
+func (m *WsMetrics) MessageSent(bytes int) {
+	m.messagesOut.Inc()
+	m.bytesOut.Add(float64(bytes))
+}
+
+// HandlerLatency implements versifi.WsMetrics
+func (m *WsMetrics) HandlerLatency(topic string, d time.Duration) {
+	m.handlerLatency.WithLabelValues(topic).Observe(d.Seconds())
+}
+
+// Reconnect implements versifi.WsMetrics
+func (m *WsMetrics) Reconnect(err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.reconnects.WithLabelValues(result).Inc()
+}
+
+// AuthFailure implements versifi.WsMetrics
+func (m *WsMetrics) AuthFailure() {
+	m.authFailures.Inc()
+}
+
+func (m *WsMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.messagesIn,
+		m.bytesIn,
+		m.messagesOut,
+		m.bytesOut,
+		m.handlerLatency,
+		m.reconnects,
+		m.authFailures,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *WsMetrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (m *WsMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
@@ -0,0 +1,107 @@
+package prommetrics
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	versifi "github.com/drinkthere/versifi-go"
+	"github.com/drinkthere/versifi-go/versifitest"
+)
+
+// sample returns the metric of family name with the given topic label, or
+// the unlabeled one for an empty topic
+func sample(t *testing.T, reg *prometheus.Registry, name, topic string) *dto.Metric {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if topic == "" || (len(m.GetLabel()) == 1 && m.GetLabel()[0].GetValue() == topic) {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func TestWsMetrics(t *testing.T) {
+	m := NewWsMetrics("versifi")
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(m)
+
+	server := versifitest.NewWsServer("test-key", "test-secret")
+	defer server.Close()
+	client := versifi.NewWsClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	client.Logger = log.New(io.Discard, "", 0)
+	client.SetMetrics(m)
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect()
+
+	handled := make(chan struct{}, 2)
+	if err := client.SubscribeExecutionReport(func([]byte) { handled <- struct{}{} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.WaitForSubscription("execution_report", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(1); i <= 2; i++ {
+		if _, err := server.SendExecutionReport(versifi.WsExecutionReportDetail{OrderID: i, Status: versifi.OrderStatusNew}); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-handled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for execution report")
+		}
+	}
+
+	// Handler latency is observed once the handler has returned
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h := sample(t, reg, "versifi_ws_handler_duration_seconds", "execution_report")
+		if h != nil && h.GetHistogram().GetSampleCount() == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 2 handler observations, got %v", h)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := sample(t, reg, "versifi_ws_messages_received_total", "execution_report").GetCounter().GetValue(); got != 2 {
+		t.Errorf("Expected 2 execution reports received, got %v", got)
+	}
+	if got := sample(t, reg, "versifi_ws_bytes_received_total", "execution_report").GetCounter().GetValue(); got <= 0 {
+		t.Errorf("Expected received bytes to be counted, got %v", got)
+	}
+	// auth and subscribe
+	if got := sample(t, reg, "versifi_ws_messages_sent_total", "").GetCounter().GetValue(); got < 2 {
+		t.Errorf("Expected at least 2 messages sent, got %v", got)
+	}
+
+	server.RejectAuth(true)
+	rejected := versifi.NewWsClient("test-key", "test-secret")
+	rejected.BaseURL = server.URL
+	rejected.Logger = log.New(io.Discard, "", 0)
+	rejected.SetMetrics(m)
+	if err := rejected.Connect(); err == nil {
+		rejected.Disconnect()
+		t.Fatal("Expected authentication to fail")
+	}
+	if got := sample(t, reg, "versifi_ws_auth_failures_total", "").GetCounter().GetValue(); got != 1 {
+		t.Errorf("Expected 1 auth failure, got %v", got)
+	}
+}
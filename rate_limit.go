@@ -0,0 +1,101 @@
+package versifi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces outgoing requests. Wait blocks until one more request
+// may be sent or ctx is done. *rate.Limiter from golang.org/x/time/rate
+// satisfies this interface.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// SetRateLimiter paces every REST request made by c through limiter, so a
+// budget can be shared with other clients or processes using the same API
+// key. A nil limiter disables pacing.
+func (c *Client) SetRateLimiter(limiter RateLimiter) {
+	if limiter == nil {
+		c.limiter.Store(nil)
+		return
+	}
+	c.limiter.Store(&limiterRef{limiter})
+}
+
+// limiterRef lets an interface value be stored atomically
+type limiterRef struct {
+	RateLimiter
+}
+
+// NewRateLimiter returns a token bucket limiter allowing perSecond requests
+// on average with bursts of up to burst. Waiters are served in arrival order.
+func NewRateLimiter(perSecond float64, burst int) RateLimiter {
+	return NewRateLimiterWithClock(perSecond, burst, SystemClock{})
+}
+
+// NewRateLimiterWithClock returns a limiter as NewRateLimiter, refilling
+// its tokens by clock
+func NewRateLimiterWithClock(perSecond float64, burst int, clock Clock) RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	clock = clockOrSystem(clock)
+	return &tokenBucket{
+		clock:    clock,
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     clock.Now(),
+	}
+}
+
+type tokenBucket struct {
+	clock    Clock
+	mu       sync.Mutex
+	interval time.Duration // time to earn one token
+	burst    int
+	tokens   float64 // negative while waiters hold reservations
+	last     time.Time
+}
+
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	now := b.clock.Now()
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if b.tokens > float64(b.burst) {
+		b.tokens = float64(b.burst)
+	}
+	b.last = now
+
+	// Reserve a token; if none is left the wait covers the deficit
+	b.tokens--
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens * float64(b.interval))
+	}
+	b.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := b.clock.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		// Return the reservation so later waiters are not delayed by it
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
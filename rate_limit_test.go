@@ -0,0 +1,79 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstAndPacing(t *testing.T) {
+	limiter := NewRateLimiter(50, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Two tokens are available immediately, the other two take 20ms each
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected pacing after the burst, finished in %v", elapsed)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+}
+
+type countingLimiter struct {
+	calls int
+	err   error
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.calls++
+	return l.err
+}
+
+func TestClientSetRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	limiter := &countingLimiter{}
+	client.SetRateLimiter(limiter)
+
+	ctx := context.Background()
+	if _, err := client.NewGetOrderService().OrderID(1).Do(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if limiter.calls != 1 {
+		t.Errorf("Expected 1 wait, got %d", limiter.calls)
+	}
+
+	limiter.err = context.DeadlineExceeded
+	_, err := client.NewGetOrderService().OrderID(1).Do(ctx)
+	var reqErr *RequestError
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &reqErr) || !reqErr.NotSent() {
+		t.Errorf("Expected the unsent limiter error, got %v", err)
+	}
+
+	client.SetRateLimiter(nil)
+	if _, err := client.NewGetOrderService().OrderID(1).Do(ctx); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Package redislimit provides a versifi.RateLimiter whose budget is kept in
+// Redis, so every process sharing an API key draws from one global budget
+// instead of each tripping 429s on its own.
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	limiter := redislimit.New(rdb, "versifi:"+apiKey, 10, 20)
+//	client.SetRateLimiter(limiter)
+//
+// The limiter implements the generic cell rate algorithm against the Redis
+// server clock, so hosts with skewed clocks still agree on the schedule.
+// Each Wait costs one round trip to Redis.
+package redislimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// reserveScript advances the theoretical arrival time (TAT) stored at
+// KEYS[1] by one emission interval and returns how long the caller must
+// wait, in microseconds, for its slot. ARGV[1] is the interval and ARGV[2]
+// the burst tolerance, both in microseconds.
+var reserveScript = redis.NewScript(`
+if redis.replicate_commands then redis.replicate_commands() end
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then tat = now end
+tat = tat + interval
+local ttl = math.ceil((tat - now) / 1000)
+redis.call("SET", KEYS[1], tat, "PX", ttl)
+local wait = tat - tolerance - now
+if wait < 0 then wait = 0 end
+return wait
+`)
+
+// releaseScript hands back one interval reserved by a caller that gave up
+// waiting, so later callers are not delayed by it
+var releaseScript = redis.NewScript(`
+local tat = tonumber(redis.call("GET", KEYS[1]))
+if tat then
+	local ttl = redis.call("PTTL", KEYS[1])
+	if ttl > 0 then
+		redis.call("SET", KEYS[1], tat - tonumber(ARGV[1]), "PX", ttl)
+	end
+end
+return 0
+`)
+
+// Limiter is a token bucket shared through a Redis key
+type Limiter struct {
+	client    redis.Scripter
+	key       string
+	interval  time.Duration
+	tolerance time.Duration
+	// Fallback is used when Redis cannot be reached, typically a local
+	// limiter with this process's share of the budget. When nil, Wait
+	// returns the Redis error.
+	Fallback versifi.RateLimiter
+}
+
+// New returns a limiter allowing perSecond requests on average with bursts
+// of up to burst across every process using key. client may be any go-redis
+// client, including a cluster or ring client.
+func New(client redis.Scripter, key string, perSecond float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	interval := time.Duration(float64(time.Second) / perSecond)
+	return &Limiter{
+		client:    client,
+		key:       key,
+		interval:  interval,
+		tolerance: time.Duration(burst) * interval,
+	}
+}
+
+// Wait reserves the next slot in the shared budget and blocks until it
+// arrives or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	wait, err := reserveScript.Run(ctx, l.client, []string{l.key},
+		l.interval.Microseconds(), l.tolerance.Microseconds()).Int64()
+	if err != nil {
+		if l.Fallback != nil && ctx.Err() == nil {
+			return l.Fallback.Wait(ctx)
+		}
+		return fmt.Errorf("redislimit: %w", err)
+	}
+
+	delay := time.Duration(wait) * time.Microsecond
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Best effort: the reservation expires with the key regardless
+		releaseCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		releaseScript.Run(releaseCtx, l.client, []string{l.key}, l.interval.Microseconds())
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,91 @@
+package redislimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func newRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { rdb.Close() })
+	return mr, rdb
+}
+
+func TestLimiterSharesBudget(t *testing.T) {
+	_, rdb := newRedis(t)
+	ctx := context.Background()
+
+	// Two processes sharing one key draw from one budget
+	a := New(rdb, "versifi:test", 20, 2)
+	b := New(rdb, "versifi:test", 20, 2)
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := a.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("Burst should not wait, took %v", elapsed)
+	}
+	for i := 0; i < 4; i++ {
+		l := a
+		if i%2 == 1 {
+			l = b
+		}
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Four requests beyond the burst at 50ms each
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("Expected the shared budget to pace requests, took %v", elapsed)
+	}
+}
+
+func TestLimiterCancelReleasesReservation(t *testing.T) {
+	_, rdb := newRedis(t)
+	l := New(rdb, "versifi:test", 5, 1)
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	// The abandoned slot is handed back, so the next wait is one interval
+	// from the first request rather than two
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Expected the reservation to be released, waited %v", elapsed)
+	}
+}
+
+func TestLimiterFallback(t *testing.T) {
+	mr, rdb := newRedis(t)
+	mr.Close()
+
+	l := New(rdb, "versifi:test", 10, 1)
+	if err := l.Wait(context.Background()); err == nil {
+		t.Fatal("Expected an error with Redis down")
+	}
+
+	l.Fallback = versifi.NewRateLimiter(10, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("Expected the fallback limiter to admit the request, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package versifi
+
+import "strings"
+
+// RejectReasonType is the category of a free-text reject_reason
+type RejectReasonType string
+
+const (
+	RejectReasonUnknown             RejectReasonType = "UNKNOWN"
+	RejectReasonInsufficientBalance RejectReasonType = "INSUFFICIENT_BALANCE"
+	// RejectReasonPriceFilter covers prices off the tick size or outside
+	// the venue's allowed band
+	RejectReasonPriceFilter RejectReasonType = "PRICE_FILTER"
+	// RejectReasonQuantityFilter covers quantities off the lot size or
+	// below the minimum quantity or notional
+	RejectReasonQuantityFilter RejectReasonType = "QUANTITY_FILTER"
+	// RejectReasonPostOnly is a post-only order that would have taken
+	RejectReasonPostOnly RejectReasonType = "POST_ONLY"
+	// RejectReasonRiskLimit covers position, notional and leverage limits
+	RejectReasonRiskLimit RejectReasonType = "RISK_LIMIT"
+	RejectReasonRateLimit RejectReasonType = "RATE_LIMIT"
+	// RejectReasonVenueDown covers maintenance, outages and timeouts
+	RejectReasonVenueDown RejectReasonType = "VENUE_DOWN"
+)
+
+// Retryable reports whether the same order may succeed if sent again later
+// without changes
+func (t RejectReasonType) Retryable() bool {
+	return t == RejectReasonVenueDown || t == RejectReasonRateLimit
+}
+
+// RejectClassifier maps reject_reason strings to categories by
+// case-insensitive substring rules, checked in the order they were added
+type RejectClassifier struct {
+	rules []rejectRule
+}
+
+type rejectRule struct {
+	category RejectReasonType
+	patterns []string
+}
+
+// defaultRejectRules are checked in order, so the more specific risk
+// patterns such as "max_notional" come before the quantity filters
+var defaultRejectRules = []rejectRule{
+	{RejectReasonInsufficientBalance, []string{
+		"insufficient balance", "insufficient margin", "insufficient fund",
+		"not enough balance", "balance insufficient", "balance is insufficient",
+		"margin is insufficient",
+	}},
+	{RejectReasonPostOnly, []string{
+		"would immediately match", "post only", "post-only", "postonly",
+	}},
+	{RejectReasonRiskLimit, []string{
+		"risk limit", "max position", "maximum position", "max_position",
+		"max notional", "max_notional", "position limit", "leverage",
+		"reduce only", "reduce-only", "reduceonly", "kill switch",
+	}},
+	{RejectReasonRateLimit, []string{
+		"rate limit", "too many requests", "too many orders",
+	}},
+	{RejectReasonPriceFilter, []string{
+		"price_filter", "percent_price", "price filter", "price limit",
+		"tick size", "price out of range", "price is out of", "invalid price",
+	}},
+	{RejectReasonQuantityFilter, []string{
+		"lot_size", "lot size", "min_notional", "filter failure: notional",
+		"minimum notional", "minimum quantity", "min quantity", "quantity too small",
+		"step size", "invalid quantity",
+	}},
+	{RejectReasonVenueDown, []string{
+		"maintenance", "unavailable", "system busy", "system is busy",
+		"overloaded", "timeout", "timed out", "exchange down", "venue down",
+		"disconnected",
+	}},
+}
+
+// NewRejectClassifier returns a classifier with the built-in rules for the
+// messages of the supported venues. Rules added with Add take precedence.
+func NewRejectClassifier() *RejectClassifier {
+	return &RejectClassifier{rules: append([]rejectRule(nil), defaultRejectRules...)}
+}
+
+// Add classifies reasons containing any of patterns as category, ahead of
+// the rules already present
+func (c *RejectClassifier) Add(category RejectReasonType, patterns ...string) *RejectClassifier {
+	lower := make([]string, len(patterns))
+	for i, p := range patterns {
+		lower[i] = strings.ToLower(p)
+	}
+	c.rules = append([]rejectRule{{category, lower}}, c.rules...)
+	return c
+}
+
+// Classify returns the category of reason, RejectReasonUnknown when no rule
+// matches, or "" when reason is empty
+func (c *RejectClassifier) Classify(reason string) RejectReasonType {
+	if reason == "" {
+		return ""
+	}
+	reason = strings.ToLower(reason)
+	for _, rule := range c.rules {
+		for _, p := range rule.patterns {
+			if strings.Contains(reason, p) {
+				return rule.category
+			}
+		}
+	}
+	return RejectReasonUnknown
+}
+
+var defaultRejectClassifier = NewRejectClassifier()
+
+// ClassifyRejectReason returns the category of reason using the built-in
+// rules, see RejectClassifier
+func ClassifyRejectReason(reason string) RejectReasonType {
+	return defaultRejectClassifier.Classify(reason)
+}
+
+// RejectReasonType classifies RejectReason
+func (s OrderState) RejectReasonType() RejectReasonType { return ClassifyRejectReason(s.RejectReason) }
+
+// RejectReasonType classifies RejectReason
+func (d BasicOrderDetail) RejectReasonType() RejectReasonType {
+	return ClassifyRejectReason(d.RejectReason)
+}
+
+// RejectReasonType classifies RejectReason
+func (d AlgoOrderDetail) RejectReasonType() RejectReasonType {
+	return ClassifyRejectReason(d.RejectReason)
+}
+
+// RejectReasonType classifies RejectReason
+func (d PairOrderDetail) RejectReasonType() RejectReasonType {
+	return ClassifyRejectReason(d.RejectReason)
+}
+
+// RejectReasonType classifies RejectReason
+func (o ChildOrder) RejectReasonType() RejectReasonType { return ClassifyRejectReason(o.RejectReason) }
+
+// RejectReasonType classifies RejectReason
+func (i ListOrderItem) RejectReasonType() RejectReasonType {
+	return ClassifyRejectReason(i.RejectReason)
+}
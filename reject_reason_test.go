@@ -0,0 +1,45 @@
+package versifi
+
+import "testing"
+
+func TestClassifyRejectReason(t *testing.T) {
+	for reason, want := range map[string]RejectReasonType{
+		"": "",
+		"Account has insufficient balance for requested action.": RejectReasonInsufficientBalance,
+		"Insufficient margin":                                    RejectReasonInsufficientBalance,
+		"Filter failure: PRICE_FILTER":                           RejectReasonPriceFilter,
+		"Order price is not within the price limit":              RejectReasonPriceFilter,
+		"Filter failure: LOT_SIZE":                               RejectReasonQuantityFilter,
+		"Filter failure: MIN_NOTIONAL":                           RejectReasonQuantityFilter,
+		"Filter failure: NOTIONAL":                               RejectReasonQuantityFilter,
+		"max_notional_long exceeded":                             RejectReasonRiskLimit,
+		"Exceeded the maximum position for this risk limit tier": RejectReasonRiskLimit,
+		"order would immediately match and take":                 RejectReasonPostOnly,
+		"Too many requests":                                      RejectReasonRateLimit,
+		"Service temporarily unavailable":                        RejectReasonVenueDown,
+		"System is under maintenance":                            RejectReasonVenueDown,
+		"something new":                                          RejectReasonUnknown,
+	} {
+		if got := ClassifyRejectReason(reason); got != want {
+			t.Errorf("%q: got %s, want %s", reason, got, want)
+		}
+	}
+
+	if !RejectReasonVenueDown.Retryable() || RejectReasonInsufficientBalance.Retryable() {
+		t.Error("Unexpected Retryable")
+	}
+	state := OrderState{RejectReason: "Insufficient balance"}
+	if state.RejectReasonType() != RejectReasonInsufficientBalance {
+		t.Errorf("Unexpected category %s", state.RejectReasonType())
+	}
+}
+
+func TestRejectClassifierAdd(t *testing.T) {
+	c := NewRejectClassifier().Add(RejectReasonRiskLimit, "Desk Limit")
+	if got := c.Classify("desk limit breached: insufficient balance"); got != RejectReasonRiskLimit {
+		t.Errorf("Expected added rules to take precedence, got %s", got)
+	}
+	if got := ClassifyRejectReason("desk limit breached"); got != RejectReasonUnknown {
+		t.Errorf("Expected the default classifier to be unchanged, got %s", got)
+	}
+}
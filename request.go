@@ -1,9 +1,10 @@
 package versifi
 
 import (
-	"io"
+	"context"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type request struct {
@@ -11,9 +12,32 @@ type request struct {
 	endpoint string
 	query    url.Values
 	header   http.Header
-	body     io.Reader
+	body     *pooledBody
 	fullURL  string
 	secType  secType
+	// version overrides Client.APIVersion, and versions lists the API
+	// versions serving an endpoint with a "{version}" placeholder, nil
+	// meaning any
+	version  APIVersionType
+	versions []APIVersionType
+	// submitsOrder marks requests that place orders, which are rejected
+	// while the kill switch is engaged
+	submitsOrder bool
+	// exchangeOrders are checked by the client's ExchangeRegistry and
+	// riskOrders by its RiskChecker before sending
+	exchangeOrders []ExchangeOrder
+	riskOrders     []RiskOrder
+	skipRiskCheck  bool
+}
+
+// error wraps err from sending r as a *RequestError of kind
+func (r *request) error(kind, err error) error {
+	return &RequestError{Method: r.method, Endpoint: r.endpoint, Kind: kind, Err: err}
+}
+
+// transportError wraps err, which ended r in phase after elapsed
+func (r *request) transportError(phase RequestPhaseType, elapsed time.Duration, err error) error {
+	return &RequestError{Method: r.method, Endpoint: r.endpoint, Kind: ErrTransport, Phase: phase, Elapsed: elapsed, Err: err}
 }
 
 // setParam sets a query parameter
@@ -59,3 +83,81 @@ func WithHeaders(headers map[string]string) RequestOption {
 		}
 	}
 }
+
+// doRequest sends r, with body JSON-encoded as the request body unless it is
+// nil, and decodes the JSON response into a new T. An empty response body
+// leaves T at its zero value, and a T of struct{} discards the body.
+func doRequest[T any](ctx context.Context, c *Client, r *request, body interface{}, opts ...RequestOption) (*T, error) {
+	if body != nil {
+		buf, err := encodeJSON(body)
+		if err != nil {
+			return nil, r.error(ErrEncode, err)
+		}
+		r.body = newPooledBody(buf)
+		defer r.body.release()
+	}
+
+	data, err := c.callAPI(ctx, r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer putBuffer(data)
+
+	res := new(T)
+	if _, discard := any(res).(*struct{}); discard || data.Len() == 0 {
+		return res, nil
+	}
+	if err := c.decodeResponse(r, data.Bytes(), res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CustomRequest describes a call to an endpoint the client has no service
+// for, see Do
+type CustomRequest struct {
+	Method   string      // e.g. http.MethodGet
+	Endpoint string      // Path below Client.BaseURL, e.g. "/v2/orders" or "/{version}/orders"
+	Query    url.Values  // Optional query parameters
+	Body     interface{} // Optional, sent as JSON
+	// Unsigned sends the request with the API key but without a signature
+	Unsigned bool
+	// SubmitsOrder makes the request fail with ErrKillSwitchEngaged while
+	// the client's kill switch is engaged
+	SubmitsOrder bool
+}
+
+// Do sends a signed request to a custom endpoint and decodes the JSON
+// response into a new T, for endpoints the client does not wrap yet:
+//
+//	type Fee struct {
+//		Maker string `json:"maker"`
+//		Taker string `json:"taker"`
+//	}
+//	fee, err := versifi.Do[Fee](ctx, client, versifi.CustomRequest{
+//		Method:   http.MethodGet,
+//		Endpoint: "/v2/fees",
+//		Query:    url.Values{"exchange": {"BINANCE_SPOT"}},
+//	})
+//
+// Errors are the same as from the built-in services, including *APIError.
+func Do[T any](ctx context.Context, c *Client, req CustomRequest, opts ...RequestOption) (*T, error) {
+	r := &request{
+		method:       req.Method,
+		endpoint:     req.Endpoint,
+		secType:      secTypeSigned,
+		submitsOrder: req.SubmitsOrder,
+	}
+	if req.Unsigned {
+		r.secType = secTypeAPIKey
+	}
+	for k, values := range req.Query {
+		for _, v := range values {
+			if r.query == nil {
+				r.query = url.Values{}
+			}
+			r.query.Add(k, v)
+		}
+	}
+	return doRequest[T](ctx, c, r, req.Body, opts...)
+}
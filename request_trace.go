@@ -0,0 +1,72 @@
+package versifi
+
+import (
+	"context"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// RequestPhaseType is how far a REST request got before it failed
+type RequestPhaseType string
+
+const (
+	// RequestPhaseConnect: obtaining a connection (DNS, dial and TLS
+	// handshake). Nothing has reached the server.
+	RequestPhaseConnect RequestPhaseType = "CONNECT"
+	// RequestPhaseSend: writing the request to the connection
+	RequestPhaseSend RequestPhaseType = "SEND"
+	// RequestPhaseWait: the request was sent and the server has not
+	// answered yet
+	RequestPhaseWait RequestPhaseType = "WAIT"
+	// RequestPhaseRead: reading the response body
+	RequestPhaseRead RequestPhaseType = "READ"
+)
+
+// requestPhases are the phases in the order a request goes through them
+var requestPhases = []RequestPhaseType{RequestPhaseConnect, RequestPhaseSend, RequestPhaseWait, RequestPhaseRead}
+
+// requestTrace follows a request through its phases with httptrace. It
+// stays in an unknown phase when the HTTP client's transport does not
+// report them.
+type requestTrace struct {
+	// phase is 1 + the index of the furthest phase reached, 0 if none
+	phase atomic.Int32
+}
+
+// context returns ctx with hooks advancing t
+func (t *requestTrace) context(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) { t.advance(RequestPhaseConnect) },
+		GotConn: func(httptrace.GotConnInfo) { t.advance(RequestPhaseSend) },
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err == nil {
+				t.advance(RequestPhaseWait)
+			}
+		},
+	})
+}
+
+// advance moves t to phase. Phases never move back, so a request the
+// transport retries on a new connection is not reported as unsent.
+func (t *requestTrace) advance(phase RequestPhaseType) {
+	next := int32(1)
+	for i, p := range requestPhases {
+		if p == phase {
+			next = int32(i + 1)
+		}
+	}
+	for {
+		cur := t.phase.Load()
+		if cur >= next || t.phase.CompareAndSwap(cur, next) {
+			return
+		}
+	}
+}
+
+// current returns the furthest phase reached, or "" if unknown
+func (t *requestTrace) current() RequestPhaseType {
+	if i := t.phase.Load(); i > 0 {
+		return requestPhases[i-1]
+	}
+	return ""
+}
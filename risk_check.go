@@ -0,0 +1,246 @@
+package versifi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrRiskRejected is matched by every *RiskError with errors.Is
+var ErrRiskRejected = errors.New("order rejected by risk check")
+
+// RiskRuleType is the pre-trade check an order failed
+type RiskRuleType string
+
+const (
+	RiskRuleMaxQuantity      RiskRuleType = "MAX_QUANTITY"
+	RiskRuleMaxNotional      RiskRuleType = "MAX_NOTIONAL"
+	RiskRuleMaxOpenOrders    RiskRuleType = "MAX_OPEN_ORDERS"
+	RiskRulePriceCollar      RiskRuleType = "PRICE_COLLAR"
+	RiskRuleRestrictedSymbol RiskRuleType = "RESTRICTED_SYMBOL"
+)
+
+// RiskLimits are the pre-trade limits of a RiskChecker. Zero fields
+// disable their check.
+type RiskLimits struct {
+	MaxOrderQuantity string
+	// MaxOrderNotional caps quantity times the limit price, or the last
+	// price for orders without one, in the quote asset
+	MaxOrderNotional string
+	// MaxOpenOrders caps the orders open in Tracker, which is required
+	// for this check
+	MaxOpenOrders int
+	Tracker       *OrderTracker
+	// PriceCollar is the largest fraction a limit price may deviate from
+	// the last price, e.g. 0.05 for 5%. Orders on symbols without a last
+	// price pass.
+	PriceCollar float64
+	// RestrictedSymbols may not be traded, given as "SYMBOL" for every
+	// exchange or "EXCHANGE:SYMBOL"
+	RestrictedSymbols []string
+}
+
+// RiskOrder is the part of an order the risk checks look at. Pair orders
+// are checked once per leg, without quantity or price.
+type RiskOrder struct {
+	Exchange ExchangeType `json:"exchange"`
+	Symbol   string       `json:"symbol"`
+	Side     SideType     `json:"side,omitempty"`
+	Quantity string       `json:"quantity,omitempty"`
+	Price    string       `json:"price,omitempty"` // Limit price, if any
+}
+
+// RiskError is returned for orders failing a pre-trade check
+type RiskError struct {
+	Rule   RiskRuleType
+	Order  RiskOrder
+	Reason string
+}
+
+func (e *RiskError) Error() string {
+	return fmt.Sprintf("risk check %s failed for %s %s: %s", e.Rule, e.Order.Exchange, e.Order.Symbol, e.Reason)
+}
+
+func (e *RiskError) Unwrap() error {
+	return ErrRiskRejected
+}
+
+// RiskAuditHandler records orders failing a risk check. bypassed is true
+// for orders sent anyway with WithoutRiskCheck.
+type RiskAuditHandler func(err *RiskError, bypassed bool)
+
+// RiskChecker rejects orders breaching RiskLimits before they are sent.
+// Install it with Client.SetRiskChecker and feed it prices for the price
+// collar and notional checks:
+//
+//	risk := versifi.NewRiskChecker(versifi.RiskLimits{
+//		MaxOrderNotional: "50000",
+//		PriceCollar:      0.05,
+//	})
+//	client.SetRiskChecker(risk)
+//	risk.HandleQuote(q) // for every quote received
+//
+// Rejections are logged with the client's Logger unless an audit handler
+// is set.
+type RiskChecker struct {
+	mu           sync.RWMutex
+	limits       RiskLimits
+	maxQuantity  decimal.Decimal
+	maxNotional  decimal.Decimal
+	restricted   map[string]struct{}
+	prices       map[positionKey]decimal.Decimal
+	auditHandler RiskAuditHandler
+}
+
+// NewRiskChecker creates a checker enforcing limits
+func NewRiskChecker(limits RiskLimits) *RiskChecker {
+	r := &RiskChecker{prices: make(map[positionKey]decimal.Decimal)}
+	r.SetLimits(limits)
+	return r
+}
+
+// SetLimits replaces the limits
+func (r *RiskChecker) SetLimits(limits RiskLimits) {
+	restricted := make(map[string]struct{}, len(limits.RestrictedSymbols))
+	for _, s := range limits.RestrictedSymbols {
+		restricted[strings.ToUpper(s)] = struct{}{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = limits
+	r.maxQuantity = toDecimal(limits.MaxOrderQuantity)
+	r.maxNotional = toDecimal(limits.MaxOrderNotional)
+	r.restricted = restricted
+}
+
+// SetAuditHandler sets the handler for rejected and bypassed orders
+func (r *RiskChecker) SetAuditHandler(handler RiskAuditHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.auditHandler = handler
+}
+
+// UpdatePrice sets the last price of symbol on exchange
+func (r *RiskChecker) UpdatePrice(exchange ExchangeType, symbol, price string) {
+	p := toDecimal(price)
+	if !p.IsPositive() {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prices[positionKey{exchange, symbol}] = p
+}
+
+// HandleQuote sets the last price of the quote's symbol to its mid
+func (r *RiskChecker) HandleQuote(q Quote) {
+	bid, ask := toDecimal(q.Bid), toDecimal(q.Ask)
+	if !bid.IsPositive() || !ask.IsPositive() {
+		return
+	}
+	r.UpdatePrice(q.Exchange, q.Symbol, bid.Add(ask).Div(decimal.NewFromInt(2)).String())
+}
+
+// Check returns a *RiskError if order breaches a limit
+func (r *RiskChecker) Check(order RiskOrder) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.restricted[strings.ToUpper(order.Symbol)]; ok {
+		return &RiskError{Rule: RiskRuleRestrictedSymbol, Order: order, Reason: "symbol is restricted"}
+	}
+	if _, ok := r.restricted[strings.ToUpper(string(order.Exchange)+":"+order.Symbol)]; ok {
+		return &RiskError{Rule: RiskRuleRestrictedSymbol, Order: order, Reason: "symbol is restricted on exchange"}
+	}
+
+	if r.limits.MaxOpenOrders > 0 && r.limits.Tracker != nil {
+		if open := len(r.limits.Tracker.OpenOrders()); open >= r.limits.MaxOpenOrders {
+			return &RiskError{Rule: RiskRuleMaxOpenOrders, Order: order,
+				Reason: fmt.Sprintf("%d orders open, limit %d", open, r.limits.MaxOpenOrders)}
+		}
+	}
+
+	quantity := toDecimal(order.Quantity)
+	if r.maxQuantity.IsPositive() && quantity.GreaterThan(r.maxQuantity) {
+		return &RiskError{Rule: RiskRuleMaxQuantity, Order: order,
+			Reason: fmt.Sprintf("quantity %s exceeds %s", quantity, r.maxQuantity)}
+	}
+
+	price := toDecimal(order.Price)
+	last, hasLast := r.prices[positionKey{order.Exchange, order.Symbol}]
+	if r.limits.PriceCollar > 0 && price.IsPositive() && hasLast {
+		deviation := price.Sub(last).Abs().Div(last)
+		if deviation.GreaterThan(decimal.NewFromFloat(r.limits.PriceCollar)) {
+			return &RiskError{Rule: RiskRulePriceCollar, Order: order,
+				Reason: fmt.Sprintf("price %s is %s%% from last price %s", price, deviation.Shift(2).StringFixed(2), last)}
+		}
+	}
+
+	if !price.IsPositive() {
+		price = last
+	}
+	if r.maxNotional.IsPositive() && price.IsPositive() {
+		if notional := quantity.Mul(price); notional.GreaterThan(r.maxNotional) {
+			return &RiskError{Rule: RiskRuleMaxNotional, Order: order,
+				Reason: fmt.Sprintf("notional %s exceeds %s", notional, r.maxNotional)}
+		}
+	}
+	return nil
+}
+
+// audit reports err to the audit handler, or logs it with c's Logger
+func (r *RiskChecker) audit(c *Client, err *RiskError, bypassed bool) {
+	r.mu.RLock()
+	handler := r.auditHandler
+	r.mu.RUnlock()
+
+	if handler != nil {
+		handler(err, bypassed)
+		return
+	}
+	if c.Logger == nil {
+		return
+	}
+	if bypassed {
+		c.Logger.Printf("risk check bypassed: %v", err)
+	} else {
+		c.Logger.Printf("order rejected: %v", err)
+	}
+}
+
+// SetRiskChecker makes every order created by c pass checker before it is
+// sent. A nil checker disables the checks.
+func (c *Client) SetRiskChecker(checker *RiskChecker) {
+	c.risk.Store(checker)
+}
+
+// WithoutRiskCheck sends an order even if it fails the client's risk
+// checks. The breach is still audited.
+func WithoutRiskCheck() RequestOption {
+	return func(r *request) {
+		r.skipRiskCheck = true
+	}
+}
+
+// checkRisk runs the client's risk checker on the orders of r
+func (c *Client) checkRisk(r *request) error {
+	checker := c.risk.Load()
+	if checker == nil {
+		return nil
+	}
+	for _, order := range r.riskOrders {
+		err := checker.Check(order)
+		if err == nil {
+			continue
+		}
+		riskErr := err.(*RiskError)
+		checker.audit(c, riskErr, r.skipRiskCheck)
+		if !r.skipRiskCheck {
+			return riskErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRiskChecker(t *testing.T) {
+	risk := NewRiskChecker(RiskLimits{
+		MaxOrderQuantity:  "10",
+		MaxOrderNotional:  "900",
+		PriceCollar:       0.05,
+		RestrictedSymbols: []string{"LUNA/USDT", "okx_spot:ETH/USDT"},
+	})
+	risk.HandleQuote(Quote{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Bid: "99", Ask: "101"})
+
+	for _, tc := range []struct {
+		order RiskOrder
+		rule  RiskRuleType
+	}{
+		{RiskOrder{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Quantity: "5", Price: "104"}, ""},
+		{RiskOrder{Exchange: ExchangeBinanceSpot, Symbol: "LUNA/USDT", Quantity: "1"}, RiskRuleRestrictedSymbol},
+		{RiskOrder{Exchange: ExchangeOKXSpot, Symbol: "ETH/USDT", Quantity: "1"}, RiskRuleRestrictedSymbol},
+		{RiskOrder{Exchange: ExchangeBinanceSpot, Symbol: "ETH/USDT", Quantity: "1"}, ""},
+		{RiskOrder{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Quantity: "11"}, RiskRuleMaxQuantity},
+		{RiskOrder{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Quantity: "1", Price: "106"}, RiskRulePriceCollar},
+		// Market orders are valued at the last price
+		{RiskOrder{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Quantity: "9"}, ""},
+		{RiskOrder{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Quantity: "9.5"}, RiskRuleMaxNotional},
+		{RiskOrder{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Quantity: "8.8", Price: "103"}, RiskRuleMaxNotional},
+		// No last price: no collar, no notional for market orders
+		{RiskOrder{Exchange: ExchangeOKXFutures, Symbol: "BTC/USDT", Quantity: "9", Price: "1"}, ""},
+	} {
+		err := risk.Check(tc.order)
+		var riskErr *RiskError
+		switch {
+		case tc.rule == "" && err != nil:
+			t.Errorf("%+v: unexpected error %v", tc.order, err)
+		case tc.rule != "" && (!errors.As(err, &riskErr) || riskErr.Rule != tc.rule):
+			t.Errorf("%+v: expected %s, got %v", tc.order, tc.rule, err)
+		case tc.rule != "" && !errors.Is(err, ErrRiskRejected):
+			t.Errorf("%+v: expected ErrRiskRejected", tc.order)
+		}
+	}
+}
+
+func TestClientRiskChecker(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(OrderResponse{OrderID: 1, Status: OrderStatusNew})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	risk := NewRiskChecker(RiskLimits{MaxOrderQuantity: "1", RestrictedSymbols: []string{"DOGE/USDT"}})
+	var audits []bool
+	risk.SetAuditHandler(func(err *RiskError, bypassed bool) { audits = append(audits, bypassed) })
+	client.SetRiskChecker(risk)
+
+	order := func() *CreateBasicOrderService {
+		return client.NewCreateBasicOrderService().
+			Exchange(ExchangeBinanceSpot).
+			Symbol("BTC/USDT").
+			Side(SideTypeBuy).
+			OrderType(BasicOrderTypeMarket).
+			Quantity("2")
+	}
+	ctx := context.Background()
+
+	if _, err := order().Do(ctx); !errors.Is(err, ErrRiskRejected) || !notSent(err) {
+		t.Errorf("Expected ErrRiskRejected, got %v", err)
+	}
+	if _, err := client.NewCreateAlgoOrderService().Exchange(ExchangeBinanceSpot).Symbol("BTC/USDT").
+		Side(SideTypeBuy).OrderType(AlgoOrderTypeTWAP).Quantity("5").Do(ctx); !errors.Is(err, ErrRiskRejected) {
+		t.Errorf("Expected ErrRiskRejected for algo order, got %v", err)
+	}
+	if _, err := client.NewCreatePairOrderService().
+		Lead(&PairLeg{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT"}).
+		Secondary(&PairLeg{Exchange: ExchangeBinanceFutures, Symbol: "DOGE/USDT"}).
+		OrderType(PairOrderTypeBasis).Do(ctx); !errors.Is(err, ErrRiskRejected) {
+		t.Errorf("Expected ErrRiskRejected for pair order, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("Expected rejected orders not to be sent, got %d requests", calls.Load())
+	}
+
+	if _, err := order().Do(ctx, WithoutRiskCheck()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected the bypassed order to be sent")
+	}
+	if len(audits) != 4 || audits[0] || !audits[3] {
+		t.Errorf("Unexpected audits %v", audits)
+	}
+
+	client.SetRiskChecker(nil)
+	if _, err := order().Do(ctx); err != nil {
+		t.Errorf("Unexpected error without a checker: %v", err)
+	}
+}
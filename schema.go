@@ -0,0 +1,104 @@
+package versifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// unknownFields returns the JSON paths in data that do not map to a field of
+// typ, following nested objects and arrays. Fields typed as interface{}, maps
+// or json.RawMessage accept anything and are not inspected.
+func unknownFields(data []byte, typ reflect.Type) []string {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+
+	var out []string
+	collectUnknownFields(v, typ, "", &out)
+	sort.Strings(out)
+	return out
+}
+
+func collectUnknownFields(v interface{}, typ reflect.Type, path string, out *[]string) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if typ.Kind() != reflect.Struct {
+			return
+		}
+		fields := jsonFields(typ)
+		for key, child := range val {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			ft, ok := fields[key]
+			if !ok {
+				// encoding/json matches keys case-insensitively
+				ft, ok = fields[strings.ToLower(key)]
+			}
+			if !ok {
+				*out = append(*out, childPath)
+				continue
+			}
+			collectUnknownFields(child, ft, childPath, out)
+		}
+	case []interface{}:
+		if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+			return
+		}
+		for i, child := range val {
+			collectUnknownFields(child, typ.Elem(), fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	}
+}
+
+// jsonFields maps the JSON names of typ's fields (and their lower-case forms) to field types
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fields[name] = f.Type
+		if lower := strings.ToLower(name); lower != name {
+			if _, ok := fields[lower]; !ok {
+				fields[lower] = f.Type
+			}
+		}
+	}
+	return fields
+}
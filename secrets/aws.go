@@ -0,0 +1,217 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// AWSCredentials are the AWS access keys used to sign Secrets Manager
+// requests
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// EnvAWSCredentials reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN
+func EnvAWSCredentials(ctx context.Context) (AWSCredentials, error) {
+	creds := AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return AWSCredentials{}, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+	}
+	return creds, nil
+}
+
+// AWSSecretsManagerProvider reads credentials from a JSON secret string in
+// AWS Secrets Manager
+type AWSSecretsManagerProvider struct {
+	Region string
+	// SecretID is the secret name or ARN
+	SecretID string
+	// VersionStage selects a staging label, default AWSCURRENT
+	VersionStage string
+	KeyField     string
+	SecretField  string
+	// AWSCredentials signs the request, nil uses EnvAWSCredentials
+	AWSCredentials func(ctx context.Context) (AWSCredentials, error)
+	// Endpoint overrides https://secretsmanager.{Region}.amazonaws.com
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// NewAWSSecretsManagerProvider creates a provider reading secretID in region
+func NewAWSSecretsManagerProvider(region, secretID string) *AWSSecretsManagerProvider {
+	return &AWSSecretsManagerProvider{
+		Region:   region,
+		SecretID: secretID,
+	}
+}
+
+// Credentials fetches the secret value and returns its key and secret
+// fields
+func (p *AWSSecretsManagerProvider) Credentials(ctx context.Context) (versifi.Credentials, error) {
+	getCreds := p.AWSCredentials
+	if getCreds == nil {
+		getCreds = EnvAWSCredentials
+	}
+	awsCreds, err := getCreds(ctx)
+	if err != nil {
+		return versifi.Credentials{}, fmt.Errorf("secretsmanager: %w", err)
+	}
+
+	input := map[string]string{"SecretId": p.SecretID}
+	if p.VersionStage != "" {
+		input["VersionStage"] = p.VersionStage
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return versifi.Credentials{}, err
+	}
+
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", p.Region)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return versifi.Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(req, body, awsCreds, p.Region, "secretsmanager", time.Now())
+
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return versifi.Credentials{}, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return versifi.Credentials{}, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &e)
+		return versifi.Credentials{}, fmt.Errorf("secretsmanager: get %s: status %d: %s %s", p.SecretID, res.StatusCode, e.Type, e.Message)
+	}
+
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return versifi.Credentials{}, fmt.Errorf("secretsmanager: get %s: %w", p.SecretID, err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(out.SecretString), &fields); err != nil {
+		return versifi.Credentials{}, fmt.Errorf("secretsmanager: get %s: secret string is not JSON: %w", p.SecretID, err)
+	}
+	creds, err := credentialsFrom(fields, p.KeyField, p.SecretField)
+	if err != nil {
+		return versifi.Credentials{}, fmt.Errorf("secretsmanager: get %s: %w", p.SecretID, err)
+	}
+	return creds, nil
+}
+
+// signV4 adds AWS Signature Version 4 headers to req
+func signV4(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery sorts and percent-encodes query parameters
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, awsEscape(key)+"="+awsEscape(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,57 @@
+// Package secrets provides versifi.CredentialProvider implementations that
+// read the API key and secret from HashiCorp Vault or AWS Secrets Manager,
+// so the secret never has to live in an environment variable or file.
+//
+// Pair a provider with versifi.CredentialRefresher to cache the credentials
+// and rotate them on running clients:
+//
+//	provider := secrets.NewVaultProvider("https://vault:8200", token, "trading/versifi")
+//	refresher := versifi.NewCredentialRefresher(provider, 5*time.Minute)
+//	if err := refresher.Refresh(ctx); err != nil {
+//		return err
+//	}
+//	client := versifi.NewClient("", "")
+//	refresher.Attach(client)
+//	go refresher.Run(ctx)
+//
+// Both providers expect the secret to hold the fields api_key and
+// api_secret; KeyField and SecretField select other names.
+package secrets
+
+import (
+	"fmt"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// Default field names read from a secret
+const (
+	DefaultKeyField    = "api_key"
+	DefaultSecretField = "api_secret"
+)
+
+// credentialsFrom picks the key and secret fields out of a secret's data
+func credentialsFrom(data map[string]interface{}, keyField, secretField string) (versifi.Credentials, error) {
+	if keyField == "" {
+		keyField = DefaultKeyField
+	}
+	if secretField == "" {
+		secretField = DefaultSecretField
+	}
+
+	var creds versifi.Credentials
+	for _, f := range []struct {
+		name string
+		dst  *string
+	}{
+		{keyField, &creds.APIKey},
+		{secretField, &creds.APISecret},
+	} {
+		v, ok := data[f.name].(string)
+		if !ok || v == "" {
+			return versifi.Credentials{}, fmt.Errorf("secret has no string field %q", f.name)
+		}
+		*f.dst = v
+	}
+	return creds, nil
+}
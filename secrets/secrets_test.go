@@ -0,0 +1,111 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/trading/versifi":
+			w.Write([]byte(`{"data":{"data":{"api_key":"key","api_secret":"secret"},"metadata":{"version":3}}}`))
+		case "/v1/kv/versifi":
+			w.Write([]byte(`{"data":{"key":"v1-key","secret":"v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	creds, err := NewVaultProvider(server.URL, "root", "/trading/versifi").Credentials(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.APIKey != "key" || creds.APISecret != "secret" {
+		t.Errorf("Unexpected credentials %+v", creds)
+	}
+
+	v1 := &VaultProvider{Address: server.URL, Token: "root", Mount: "kv", Path: "versifi", KVVersion: 1, KeyField: "key", SecretField: "secret"}
+	if creds, err := v1.Credentials(ctx); err != nil || creds.APIKey != "v1-key" {
+		t.Errorf("Unexpected KV v1 result %+v, %v", creds, err)
+	}
+
+	if _, err := NewVaultProvider(server.URL, "wrong", "trading/versifi").Credentials(ctx); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected permission denied, got %v", err)
+	}
+	v1.SecretField = "api_secret"
+	if _, err := v1.Credentials(ctx); err == nil || !strings.Contains(err.Error(), `"api_secret"`) {
+		t.Errorf("Expected a missing field error, got %v", err)
+	}
+}
+
+func TestSignV4(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, creds, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Unexpected Authorization\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestAWSSecretsManagerProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.Contains(auth, "Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") ||
+			!strings.Contains(auth, "x-amz-security-token") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"InvalidSignatureException","message":"bad signature"}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var input map[string]string
+		json.Unmarshal(body, &input)
+		if input["SecretId"] != "prod/versifi" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"api_key":"key","api_secret":"secret"}`})
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	p := NewAWSSecretsManagerProvider("eu-west-1", "prod/versifi")
+	p.Endpoint = server.URL
+	p.AWSCredentials = func(ctx context.Context) (AWSCredentials, error) {
+		return AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}, nil
+	}
+	creds, err := p.Credentials(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.APIKey != "key" || creds.APISecret != "secret" {
+		t.Errorf("Unexpected credentials %+v", creds)
+	}
+
+	p.SecretID = "missing"
+	if _, err := p.Credentials(ctx); err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+		t.Errorf("Expected ResourceNotFoundException, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// VaultProvider reads credentials from a Vault KV secrets engine
+type VaultProvider struct {
+	// Address is the Vault server URL, e.g. https://vault:8200
+	Address string
+	// Token authenticates the request
+	Token string
+	// Namespace is sent as X-Vault-Namespace when set (Vault Enterprise)
+	Namespace string
+	// Mount is the KV engine mount path, default "secret"
+	Mount string
+	// Path is the secret path within the mount
+	Path string
+	// KVVersion selects the KV engine version, 1 or 2 (default)
+	KVVersion   int
+	KeyField    string
+	SecretField string
+	HTTPClient  *http.Client
+}
+
+// NewVaultProvider creates a provider reading path from the KV v2 engine
+// mounted at "secret"
+func NewVaultProvider(address, token, path string) *VaultProvider {
+	return &VaultProvider{
+		Address:   address,
+		Token:     token,
+		Mount:     "secret",
+		Path:      path,
+		KVVersion: 2,
+	}
+}
+
+// Credentials reads the secret and returns its key and secret fields
+func (p *VaultProvider) Credentials(ctx context.Context) (versifi.Credentials, error) {
+	mount := strings.Trim(p.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+	path := strings.Trim(p.Path, "/")
+	url := fmt.Sprintf("%s/v1/%s/%s", strings.TrimRight(p.Address, "/"), mount, path)
+	if p.KVVersion != 1 {
+		url = fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(p.Address, "/"), mount, path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return versifi.Credentials{}, err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return versifi.Credentials{}, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return versifi.Credentials{}, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return versifi.Credentials{}, fmt.Errorf("vault: read %s: status %d: %s", path, res.StatusCode, strings.Join(e.Errors, "; "))
+	}
+
+	var secret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return versifi.Credentials{}, fmt.Errorf("vault: read %s: %w", path, err)
+	}
+	data := secret.Data
+	if p.KVVersion != 1 {
+		// KV v2 nests the secret under data.data next to its metadata
+		var v2 struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(secret.Data, &v2); err != nil {
+			return versifi.Credentials{}, fmt.Errorf("vault: read %s: %w", path, err)
+		}
+		data = v2.Data
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return versifi.Credentials{}, fmt.Errorf("vault: read %s: %w", path, err)
+	}
+	creds, err := credentialsFrom(fields, p.KeyField, p.SecretField)
+	if err != nil {
+		return versifi.Credentials{}, fmt.Errorf("vault: read %s: %w", path, err)
+	}
+	return creds, nil
+}
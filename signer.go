@@ -0,0 +1,43 @@
+package versifi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"sync"
+)
+
+// signer signs payloads with one API secret. Keying an HMAC hashes the
+// secret, so keyed states are pooled and reset between requests instead.
+type signer struct {
+	secret string
+	pool   sync.Pool
+}
+
+func newSigner(secret string) *signer {
+	s := &signer{secret: secret}
+	s.pool.New = func() interface{} { return hmac.New(sha256.New, []byte(secret)) }
+	return s
+}
+
+// sign returns the hex HMAC SHA256 signature of payload
+func (s *signer) sign(payload []byte) string {
+	h := s.pool.Get().(hash.Hash)
+	defer s.pool.Put(h)
+	h.Reset()
+	h.Write(payload)
+	var sum [sha256.Size]byte
+	return hex.EncodeToString(h.Sum(sum[:0]))
+}
+
+// signer returns the client's signer for secret, replacing it when the
+// credentials have changed
+func (c *Client) signer(secret string) *signer {
+	if s := c.signers.Load(); s != nil && s.secret == secret {
+		return s
+	}
+	s := newSigner(secret)
+	c.signers.Store(s)
+	return s
+}
@@ -0,0 +1,74 @@
+package versifi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestSigner(t *testing.T) {
+	s := newSigner("test-secret")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := s.sign([]byte("symbol=BTC/USDT")); got != sign("test-secret", "symbol=BTC/USDT") {
+					t.Errorf("Unexpected signature %s", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := s.sign(nil); got != sign("test-secret", "") {
+		t.Errorf("Unexpected signature of an empty payload %s", got)
+	}
+}
+
+func TestSignerRotatesWithCredentials(t *testing.T) {
+	var got http.Header
+	client := NewClient("old-key", "old-secret")
+	client.do = func(req *http.Request) (*http.Response, error) {
+		got = req.Header
+		return newStubClient([]byte(`[]`)).do(req)
+	}
+	list := func() {
+		if _, err := client.NewListOpenOrdersService().Limit(10).Do(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list()
+	if got.Get("X-VERSIFI-API-KEY") != "old-key" || got.Get("X-VERSIFI-API-SIGN") != sign("old-secret", "limit=10") {
+		t.Errorf("Unexpected headers %v", got)
+	}
+	client.SetCredentials("new-key", "new-secret")
+	list()
+	if got.Get("X-VERSIFI-API-KEY") != "new-key" || got.Get("X-VERSIFI-API-SIGN") != sign("new-secret", "limit=10") {
+		t.Errorf("Expected the new credentials to sign, got %v", got)
+	}
+}
+
+// BenchmarkSign compares keying a new HMAC for every request with the
+// client's pooled signer
+func BenchmarkSign(b *testing.B) {
+	payload := []byte(`{"clientOrderId":987654321,"exchange":"BINANCE_SPOT","orderType":"LIMIT","price":"43210.5","quantity":"0.015","side":"BUY","symbol":"BTC/USDT"}`)
+
+	b.Run("previous", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = sign("secret", string(payload))
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		client := NewClient("key", "secret")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = client.signer(client.credentials().APISecret).sign(payload)
+		}
+	})
+}
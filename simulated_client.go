@@ -0,0 +1,707 @@
+package versifi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// simulatorBaseURL is the BaseURL of simulated clients; requests to it never
+// leave the process
+const simulatorBaseURL = "http://simulator.versifi.invalid"
+
+// SimulatedClient is a Client for paper trading. Every service works as
+// with a real Client, but requests are served in process by a simulator
+// that fills orders against the quotes passed to SetQuote or Run, and
+// execution reports go to the handlers registered with
+// SubscribeExecutionReport.
+//
+//	sim := versifi.NewSimulatedClient()
+//	sim.SubscribeExecutionReport(tracker.HandleExecutionReport)
+//	go sim.Run(ctx, versifi.RecordedQuotes(quotes...))
+//	res, err := sim.NewCreateBasicOrderService().
+//		Exchange(versifi.ExchangeBinanceSpot).
+//		Symbol("BTC/USDT").
+//		Side(versifi.SideTypeBuy).
+//		OrderType(versifi.BasicOrderTypeMarket).
+//		Quantity("0.1").
+//		Do(ctx)
+//
+// Orders are only matched when a quote arrives, never on submission, so a
+// strategy cannot trade on a price it has not yet seen. Fills happen at the
+// ask for buys and the bid for sells, in full; resting limit orders fill at
+// their limit price. Stop and take profit orders trigger on the quote side
+// they would trade against. Algo orders fill at the quote in proportion to
+// the simulated time elapsed out of their duration param, in seconds. Pair
+// orders and trailing deltas are not simulated.
+type SimulatedClient struct {
+	*Client
+
+	// FeeRate is the fee charged on each fill as a fraction of its notional
+	FeeRate decimal.Decimal
+
+	mu          sync.Mutex
+	orders      map[int64]*simOrder
+	nextOrderID int64
+	nextChildID int64
+	nextTradeID int64
+	now         time.Time
+	handlers    map[int]WsHandler
+	nextID      int
+}
+
+type simQuote struct {
+	bid, ask decimal.Decimal
+}
+
+type simOrder struct {
+	id            int64
+	clientOrderID int64
+	requestType   string
+	exchange      ExchangeType
+	symbol        string
+	side          SideType
+	basicType     BasicOrderType
+	algoType      AlgoOrderType
+	tif           TimeInForceType
+	quantity      decimal.Decimal
+	price         decimal.Decimal
+	stopPrice     decimal.Decimal
+	params        map[string]interface{}
+	start         time.Time // When an algo order's schedule began
+	duration      time.Duration
+	startTime     time.Time // Basic orders only
+	quoted        bool      // Whether a quote has been matched against the order
+	triggered     bool
+	status        OrderStatusType
+	filled        decimal.Decimal
+	notional      decimal.Decimal
+	rejectReason  string
+	childID       int64
+	trades        []Trade
+	timestamp     int64
+}
+
+// NewSimulatedClient creates a paper trading client with no quotes and no orders
+func NewSimulatedClient() *SimulatedClient {
+	s := &SimulatedClient{
+		orders:   make(map[int64]*simOrder),
+		handlers: make(map[int]WsHandler),
+	}
+	s.Client = NewClientWithHTTPClient("simulator", "simulator", &http.Client{Transport: simTransport{s}})
+	s.Client.BaseURL = simulatorBaseURL
+	return s
+}
+
+// SubscribeExecutionReport registers handler for execution_report messages,
+// encoded as the WebSocket API sends them, and returns a function that
+// removes it. Handlers are called synchronously, outside the simulator lock,
+// so they may place and cancel orders.
+func (s *SimulatedClient) SubscribeExecutionReport(handler WsHandler) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.handlers[id] = handler
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.handlers, id)
+	}
+}
+
+// SetQuote advances the simulated time to the quote's and matches the open
+// orders of its symbol against it, in order of submission
+func (s *SimulatedClient) SetQuote(q Quote) {
+	s.mu.Lock()
+	if q.Time.IsZero() {
+		s.now = time.Now()
+	} else {
+		s.now = q.Time
+	}
+	quote := simQuote{bid: toDecimal(q.Bid), ask: toDecimal(q.Ask)}
+
+	var reports [][]byte
+	for _, o := range s.sortedOrdersLocked() {
+		if o.status.IsFinal() || o.exchange != q.Exchange || o.symbol != q.Symbol {
+			continue
+		}
+		if report := s.matchLocked(o, quote); report != nil {
+			reports = append(reports, report)
+		}
+	}
+	handlers := s.handlersLocked()
+	s.mu.Unlock()
+
+	s.emit(handlers, reports...)
+}
+
+// Run passes every quote from source to SetQuote until the source is
+// exhausted or fails. It returns nil at io.EOF.
+func (s *SimulatedClient) Run(ctx context.Context, source QuoteSource) error {
+	for {
+		q, err := source.NextQuote(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.SetQuote(q)
+	}
+}
+
+// matchLocked fills o against quote and returns the execution report for
+// any change, or nil
+func (s *SimulatedClient) matchLocked(o *simOrder, quote simQuote) []byte {
+	if s.now.Before(o.startTime) {
+		return nil
+	}
+	first := !o.quoted
+	o.quoted = true
+
+	// The price a fill would happen at now
+	market := quote.ask
+	if o.side == SideTypeSell {
+		market = quote.bid
+	}
+	if market.Sign() <= 0 {
+		return nil
+	}
+
+	if o.requestType == RequestOrderTypeAlgo {
+		// The schedule starts with the first quote the order sees
+		if first {
+			o.start = s.now
+		}
+		target := o.quantity
+		if elapsed := s.now.Sub(o.start); o.duration > 0 && elapsed < o.duration {
+			target = o.quantity.Mul(decimal.NewFromInt(int64(elapsed))).Div(decimal.NewFromInt(int64(o.duration)))
+		}
+		qty := target.Sub(o.filled)
+		if qty.Sign() <= 0 {
+			return nil
+		}
+		return s.fillLocked(o, qty, market)
+	}
+
+	limit := false
+	switch o.basicType {
+	case BasicOrderTypeMarket:
+	case BasicOrderTypeLimit, BasicOrderTypeLimitMaker:
+		limit = true
+	case BasicOrderTypeStop, BasicOrderTypeStopLoss, BasicOrderTypeStopLossLimit,
+		BasicOrderTypeTakeProfit, BasicOrderTypeTakeProfitLimit:
+		if !o.triggered {
+			o.triggered = o.stopTriggered(market)
+			if !o.triggered {
+				return nil
+			}
+		}
+		limit = o.basicType == BasicOrderTypeStopLossLimit || o.basicType == BasicOrderTypeTakeProfitLimit
+	}
+
+	if !limit {
+		return s.fillLocked(o, o.quantity.Sub(o.filled), market)
+	}
+
+	marketable := market.LessThanOrEqual(o.price)
+	if o.side == SideTypeSell {
+		marketable = market.GreaterThanOrEqual(o.price)
+	}
+	switch {
+	case first && marketable && o.basicType == BasicOrderTypeLimitMaker:
+		o.rejectReason = "order would immediately match and take"
+		return s.finishLocked(o, OrderStatusRejected)
+	case marketable && first:
+		return s.fillLocked(o, o.quantity.Sub(o.filled), market)
+	case marketable:
+		// Resting orders are filled at their limit price
+		return s.fillLocked(o, o.quantity.Sub(o.filled), o.price)
+	case first && (o.tif == TimeInForceIOC || o.tif == TimeInForceFOK):
+		return s.finishLocked(o, OrderStatusExpired)
+	}
+	return nil
+}
+
+// stopTriggered reports whether a stop or take profit order triggers when
+// it would trade at market
+func (o *simOrder) stopTriggered(market decimal.Decimal) bool {
+	takeProfit := o.basicType == BasicOrderTypeTakeProfit || o.basicType == BasicOrderTypeTakeProfitLimit
+	// Stops buy on the way up and sell on the way down, take profits the reverse
+	if (o.side == SideTypeBuy) != takeProfit {
+		return market.GreaterThanOrEqual(o.stopPrice)
+	}
+	return market.LessThanOrEqual(o.stopPrice)
+}
+
+func (s *SimulatedClient) fillLocked(o *simOrder, qty, price decimal.Decimal) []byte {
+	s.nextTradeID++
+	notional := qty.Mul(price)
+	trade := Trade{
+		TradeID:      s.nextTradeID,
+		OrderID:      o.id,
+		ChildOrderID: o.childID,
+		Exchange:     o.exchange,
+		Symbol:       o.symbol,
+		Price:        price.String(),
+		Quantity:     qty.String(),
+		Side:         o.side,
+		Fee:          notional.Mul(s.FeeRate).String(),
+	}
+	o.trades = append(o.trades, trade)
+	o.filled = o.filled.Add(qty)
+	o.notional = o.notional.Add(notional)
+
+	status := OrderStatusPartiallyFilled
+	if o.filled.GreaterThanOrEqual(o.quantity) {
+		status = OrderStatusFilled
+	}
+	o.status = status
+	o.touch(s.nowLocked())
+	return o.report(&trade)
+}
+
+func (s *SimulatedClient) finishLocked(o *simOrder, status OrderStatusType) []byte {
+	o.status = status
+	o.touch(s.nowLocked())
+	return o.report(nil)
+}
+
+// touch sets the update time of o to now, never moving it backwards, so
+// that replaying older market data does not make reports look stale
+func (o *simOrder) touch(now time.Time) {
+	if ms := now.UnixMilli(); ms > o.timestamp {
+		o.timestamp = ms
+	}
+}
+
+// updatedAt returns the update time of o as the API sends it
+func (o *simOrder) updatedAt() Timestamp {
+	return NewTimestamp(time.UnixMilli(o.timestamp), PrecisionMilliseconds)
+}
+
+func (s *SimulatedClient) nowLocked() time.Time {
+	if s.now.IsZero() {
+		return time.Now()
+	}
+	return s.now
+}
+
+func (o *simOrder) averagePrice() string {
+	if o.filled.IsZero() {
+		return ""
+	}
+	return o.notional.Div(o.filled).String()
+}
+
+// report encodes an execution_report frame for o, carrying trade if set
+func (o *simOrder) report(trade *Trade) []byte {
+	child := &WsChildOrder{ID: o.childID, Trades: []WsTrade{}}
+	if trade != nil {
+		child.Trades = append(child.Trades, WsTrade{
+			TradeID:                   trade.TradeID,
+			OrderID:                   o.id,
+			ExecutedPrice:             trade.Price,
+			ExecutedQuantity:          trade.Quantity,
+			AveragePrice:              o.averagePrice(),
+			CummulativeFilledQuantity: o.filled.String(),
+		})
+	}
+
+	detail := WsExecutionReportDetail{
+		OrderID:          o.id,
+		ClientOrderID:    o.clientOrderID,
+		Status:           o.status,
+		Timestamp:        o.updatedAt(),
+		RequestOrderType: strings.ToUpper(o.requestType),
+	}
+	if o.requestType == RequestOrderTypeAlgo {
+		detail.OrderType = string(o.algoType)
+		detail.Algo = &WsAlgoOrderDetail{
+			ID:          o.id,
+			Exchange:    o.exchange,
+			OrderType:   o.algoType,
+			Quantity:    o.quantity.String(),
+			Side:        o.side,
+			Symbol:      o.symbol,
+			OrderParams: o.params,
+			ChildOrder:  child,
+		}
+	} else {
+		detail.OrderType = string(o.basicType)
+		detail.Basic = &WsBasicOrderDetail{
+			Symbol:        o.symbol,
+			ClientOrderID: o.clientOrderID,
+			StopPrice:     decimalString(o.stopPrice),
+			Exchange:      o.exchange,
+			Price:         decimalString(o.price),
+			Quantity:      o.quantity.String(),
+			Side:          o.side,
+			OrderType:     o.basicType,
+			ChildOrder:    child,
+		}
+	}
+
+	data, _ := json.Marshal(WsExecutionReport{Op: "execution_report", Success: true, Message: detail})
+	return data
+}
+
+func (s *SimulatedClient) emit(handlers []WsHandler, reports ...[]byte) {
+	for _, report := range reports {
+		for _, handler := range handlers {
+			handler(report)
+		}
+	}
+}
+
+func (s *SimulatedClient) handlersLocked() []WsHandler {
+	ids := make([]int, 0, len(s.handlers))
+	for id := range s.handlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	handlers := make([]WsHandler, len(ids))
+	for i, id := range ids {
+		handlers[i] = s.handlers[id]
+	}
+	return handlers
+}
+
+func (s *SimulatedClient) sortedOrdersLocked() []*simOrder {
+	orders := make([]*simOrder, 0, len(s.orders))
+	for _, o := range s.orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].id < orders[j].id })
+	return orders
+}
+
+// simTransport serves the REST API from the simulator
+type simTransport struct {
+	s *SimulatedClient
+}
+
+func (t simTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err == nil && req.Header.Get("Content-Encoding") == "gzip" {
+			body, err = gunzip(body)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	status, res := t.s.serve(req.Method, req.URL, body)
+	var data []byte
+	if res != nil {
+		var err error
+		if data, err = json.Marshal(res); err != nil {
+			return nil, err
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+func simError(status int, format string, args ...interface{}) (int, interface{}) {
+	return status, APIError{Code: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// serve handles one REST request and returns the status and the value to
+// encode as the response body
+func (s *SimulatedClient) serve(method string, u *url.URL, body []byte) (int, interface{}) {
+	path := strings.TrimSuffix(u.Path, "/")
+	switch {
+	case method == http.MethodPost && path == "/v2/orders/basic":
+		var req BasicOrderRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return simError(http.StatusBadRequest, "invalid request body: %v", err)
+		}
+		return s.createBasic(&req)
+	case method == http.MethodPost && path == "/v2/orders/algo":
+		var req AlgoOrderRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return simError(http.StatusBadRequest, "invalid request body: %v", err)
+		}
+		return s.createAlgo(&req)
+	case method == http.MethodPost && path == "/v2/orders/pair":
+		return simError(http.StatusBadRequest, "pair orders are not supported by the simulator")
+	case method == http.MethodGet && path == "/v2/orders":
+		return s.list(u.Query())
+	case method == http.MethodDelete && path == "/v2/orders/batch":
+		var req CancelBatchRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return simError(http.StatusBadRequest, "invalid request body: %v", err)
+		}
+		s.cancel(req.IDs...)
+		return http.StatusNoContent, nil
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(path, "/v2/orders/"), 10, 64)
+	if err != nil || !strings.HasPrefix(path, "/v2/orders/") {
+		return simError(http.StatusNotFound, "not found")
+	}
+	switch method {
+	case http.MethodGet:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		o, ok := s.orders[id]
+		if !ok {
+			return simError(http.StatusNotFound, "order %d not found", id)
+		}
+		return http.StatusOK, o.export()
+	case http.MethodDelete:
+		s.mu.Lock()
+		o, ok := s.orders[id]
+		final := ok && o.status.IsFinal()
+		s.mu.Unlock()
+		if !ok {
+			return simError(http.StatusNotFound, "order %d not found", id)
+		}
+		if final {
+			return simError(http.StatusBadRequest, "order %d is already %s", id, o.status)
+		}
+		s.cancel(id)
+		return http.StatusNoContent, nil
+	}
+	return simError(http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func (s *SimulatedClient) createBasic(req *BasicOrderRequest) (int, interface{}) {
+	o := &simOrder{
+		requestType: RequestOrderTypeBasic,
+		exchange:    req.Exchange,
+		symbol:      req.Symbol,
+		side:        req.Side,
+		basicType:   req.OrderType,
+		quantity:    toDecimal(req.Quantity),
+	}
+	if req.Price != nil {
+		o.price = toDecimal(*req.Price)
+	}
+	if req.StopPrice != nil {
+		o.stopPrice = toDecimal(*req.StopPrice)
+	}
+	if req.TIF != nil {
+		o.tif = *req.TIF
+	}
+	if req.StartTime != nil {
+		o.startTime = req.StartTime.Time
+	}
+
+	switch req.OrderType {
+	case BasicOrderTypeMarket:
+	case BasicOrderTypeLimit, BasicOrderTypeLimitMaker:
+		if o.price.Sign() <= 0 {
+			return simError(http.StatusBadRequest, "price is required for %s orders", req.OrderType)
+		}
+	case BasicOrderTypeStop, BasicOrderTypeStopLoss, BasicOrderTypeTakeProfit:
+		if o.stopPrice.Sign() <= 0 {
+			return simError(http.StatusBadRequest, "stop_price is required for %s orders", req.OrderType)
+		}
+	case BasicOrderTypeStopLossLimit, BasicOrderTypeTakeProfitLimit:
+		if o.price.Sign() <= 0 || o.stopPrice.Sign() <= 0 {
+			return simError(http.StatusBadRequest, "price and stop_price are required for %s orders", req.OrderType)
+		}
+	default:
+		return simError(http.StatusBadRequest, "unsupported order type %q", req.OrderType)
+	}
+	return s.create(o, req.ClientOrderID)
+}
+
+func (s *SimulatedClient) createAlgo(req *AlgoOrderRequest) (int, interface{}) {
+	o := &simOrder{
+		requestType: RequestOrderTypeAlgo,
+		exchange:    req.Exchange,
+		symbol:      req.Symbol,
+		side:        req.Side,
+		algoType:    req.OrderType,
+		quantity:    toDecimal(req.Quantity),
+		params:      req.Params,
+	}
+	switch req.OrderType {
+	case AlgoOrderTypeTWAP, AlgoOrderTypeVWAP, AlgoOrderTypeIS:
+	default:
+		return simError(http.StatusBadRequest, "unsupported algo order type %q", req.OrderType)
+	}
+	if seconds, ok := req.Params["duration"].(float64); ok && seconds > 0 {
+		o.duration = time.Duration(seconds * float64(time.Second))
+	}
+	return s.create(o, req.ClientOrderID)
+}
+
+func (s *SimulatedClient) create(o *simOrder, clientOrderID *int64) (int, interface{}) {
+	if o.exchange == "" || o.symbol == "" {
+		return simError(http.StatusBadRequest, "exchange and symbol are required")
+	}
+	if o.side != SideTypeBuy && o.side != SideTypeSell {
+		return simError(http.StatusBadRequest, "invalid side %q", o.side)
+	}
+	if o.quantity.Sign() <= 0 {
+		return simError(http.StatusBadRequest, "quantity must be positive")
+	}
+	if clientOrderID != nil {
+		o.clientOrderID = *clientOrderID
+	}
+
+	s.mu.Lock()
+	s.nextOrderID++
+	s.nextChildID++
+	o.id = s.nextOrderID
+	o.childID = s.nextChildID
+	o.status = OrderStatusNew
+	o.timestamp = s.nowLocked().UnixMilli()
+	s.orders[o.id] = o
+	report := o.report(nil)
+	handlers := s.handlersLocked()
+	res := OrderResponse{OrderID: o.id, ClientOrderID: o.clientOrderID, Status: o.status}
+	s.mu.Unlock()
+
+	s.emit(handlers, report)
+	return http.StatusOK, res
+}
+
+// cancel cancels the open orders among ids
+func (s *SimulatedClient) cancel(ids ...int64) {
+	s.mu.Lock()
+	var reports [][]byte
+	for _, id := range ids {
+		if o, ok := s.orders[id]; ok && !o.status.IsFinal() {
+			reports = append(reports, s.finishLocked(o, OrderStatusCanceled))
+		}
+	}
+	handlers := s.handlersLocked()
+	s.mu.Unlock()
+
+	s.emit(handlers, reports...)
+}
+
+func (s *SimulatedClient) list(query url.Values) (int, interface{}) {
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	status := OrderStatusType(query.Get("status"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []ListOrderItem{}
+	for _, o := range s.sortedOrdersLocked() {
+		if status != "" && o.status != status {
+			continue
+		}
+		items = append(items, ListOrderItem{
+			OrderID:          o.id,
+			ClientOrderID:    o.clientOrderID,
+			Status:           string(o.status),
+			Timestamp:        o.updatedAt(),
+			RequestOrderType: strings.ToUpper(o.requestType),
+			RejectReason:     o.rejectReason,
+		})
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return http.StatusOK, items
+}
+
+// export returns o as the get order endpoint does
+func (o *simOrder) export() *GetOrderResponse {
+	child := ChildOrder{
+		ID:             o.childID,
+		ChildOrderID:   o.childID,
+		OrderID:        o.id,
+		Exchange:       o.exchange,
+		Symbol:         o.symbol,
+		Quantity:       o.quantity.String(),
+		Side:           o.side,
+		OrderStatus:    o.status,
+		AveragePrice:   o.averagePrice(),
+		FilledQuantity: o.filled.String(),
+		RejectReason:   o.rejectReason,
+		Trades:         append([]Trade(nil), o.trades...),
+	}
+	res := &GetOrderResponse{
+		OrderID:          o.id,
+		ClientOrderID:    o.clientOrderID,
+		Status:           o.status,
+		Timestamp:        o.updatedAt(),
+		RequestOrderType: strings.ToUpper(o.requestType),
+	}
+	if o.requestType == RequestOrderTypeAlgo {
+		params, _ := json.Marshal(o.params)
+		res.OrderType = string(o.algoType)
+		child.OrderType = string(BasicOrderTypeMarket)
+		res.AlgoOrder = &AlgoOrderDetail{
+			Exchange:       o.exchange,
+			OrderType:      o.algoType,
+			Quantity:       o.quantity.String(),
+			Side:           o.side,
+			Symbol:         o.symbol,
+			OrderParams:    params,
+			AveragePrice:   child.AveragePrice,
+			FilledQuantity: child.FilledQuantity,
+			RejectReason:   o.rejectReason,
+			ChildOrders:    []ChildOrder{child},
+		}
+		return res
+	}
+
+	res.OrderType = string(o.basicType)
+	child.OrderType = string(o.basicType)
+	child.Price = decimalString(o.price)
+	res.BasicOrder = &BasicOrderDetail{
+		Exchange:       o.exchange,
+		OrderType:      o.basicType,
+		Price:          decimalString(o.price),
+		Quantity:       o.quantity.String(),
+		Side:           o.side,
+		StopPrice:      decimalString(o.stopPrice),
+		Symbol:         o.symbol,
+		TIF:            o.tif,
+		AveragePrice:   child.AveragePrice,
+		FilledQuantity: child.FilledQuantity,
+		RejectReason:   o.rejectReason,
+		ChildOrders:    []ChildOrder{child},
+	}
+	return res
+}
+
+// decimalString formats d, or returns "" when it is zero
+func decimalString(d decimal.Decimal) string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.String()
+}
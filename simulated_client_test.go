@@ -0,0 +1,186 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func simQuoteAt(bid, ask string, seconds int64) Quote {
+	return Quote{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Bid: bid, Ask: ask, Time: time.Unix(seconds, 0)}
+}
+
+func TestSimulatedClientBasicOrders(t *testing.T) {
+	ctx := context.Background()
+	sim := NewSimulatedClient()
+	sim.FeeRate = decimal.RequireFromString("0.001")
+
+	var reports []WsExecutionReportDetail
+	sim.SubscribeExecutionReport(func(message []byte) {
+		var report WsExecutionReport
+		if err := json.Unmarshal(message, &report); err != nil {
+			t.Fatal(err)
+		}
+		reports = append(reports, report.Message)
+	})
+	tracker := NewOrderTracker(sim.Client)
+	sim.SubscribeExecutionReport(tracker.HandleExecutionReport)
+
+	newOrder := func() *CreateBasicOrderService {
+		return sim.NewCreateBasicOrderService().Exchange(ExchangeBinanceSpot).Symbol("BTC/USDT")
+	}
+	market, err := newOrder().Side(SideTypeBuy).OrderType(BasicOrderTypeMarket).Quantity("2").ClientOrderID(7).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit, err := newOrder().Side(SideTypeSell).OrderType(BasicOrderTypeLimit).Quantity("1").Price("105").Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioc, err := newOrder().Side(SideTypeBuy).OrderType(BasicOrderTypeLimit).Quantity("1").Price("90").TimeInForce(TimeInForceIOC).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if market.Status != OrderStatusNew || market.ClientOrderID != 7 || len(reports) != 3 {
+		t.Fatalf("Unexpected ack %+v with %d reports", market, len(reports))
+	}
+
+	// Nothing fills before a quote
+	sim.SetQuote(simQuoteAt("99", "101", 1))
+	if len(reports) != 5 {
+		t.Fatalf("Expected 5 reports, got %d", len(reports))
+	}
+	if r := reports[3]; r.OrderID != market.OrderID || r.Status != OrderStatusFilled || r.Basic.ChildOrder.Trades[0].ExecutedPrice != "101" {
+		t.Errorf("Unexpected market fill %+v", r)
+	}
+	if r := reports[4]; r.OrderID != ioc.OrderID || r.Status != OrderStatusExpired {
+		t.Errorf("Unexpected IOC report %+v", r)
+	}
+
+	// The resting limit order fills at its price once the bid crosses it
+	sim.SetQuote(simQuoteAt("106", "107", 2))
+	order, err := sim.NewGetOrderService().OrderID(limit.OrderID).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != OrderStatusFilled || order.BasicOrder.AveragePrice != "105" || order.BasicOrder.ChildOrders[0].Trades[0].Fee != "0.105" {
+		t.Errorf("Unexpected order %+v", order.BasicOrder)
+	}
+	if state, ok := tracker.Order(market.OrderID); !ok || state.Status != OrderStatusFilled {
+		t.Errorf("Unexpected tracked state %+v", state)
+	}
+
+	open, err := newOrder().Side(SideTypeBuy).OrderType(BasicOrderTypeLimit).Quantity("1").Price("50").Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.NewCancelOrderService().OrderID(open.OrderID).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	items, err := sim.NewListOpenOrdersService().Status(OrderStatusCanceled).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].OrderID != open.OrderID {
+		t.Errorf("Unexpected canceled orders %+v", items)
+	}
+
+	var apiErr *APIError
+	if err := sim.NewCancelOrderService().OrderID(open.OrderID).Do(ctx); !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusBadRequest {
+		t.Errorf("Expected a 400 canceling a final order, got %v", err)
+	}
+	if _, err := sim.NewGetOrderService().OrderID(999).Do(ctx); !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusNotFound {
+		t.Errorf("Expected a 404, got %v", err)
+	}
+	if _, err := newOrder().Side(SideTypeBuy).OrderType(BasicOrderTypeLimit).Quantity("1").Do(ctx); !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusBadRequest {
+		t.Errorf("Expected a 400 for a limit order without price, got %v", err)
+	}
+}
+
+func TestSimulatedClientStopOrder(t *testing.T) {
+	ctx := context.Background()
+	sim := NewSimulatedClient()
+
+	stop, err := sim.NewCreateBasicOrderService().Exchange(ExchangeBinanceSpot).Symbol("BTC/USDT").
+		Side(SideTypeSell).OrderType(BasicOrderTypeStopLoss).Quantity("1").StopPrice("95").Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.SetQuote(simQuoteAt("99", "101", 1))
+	sim.SetQuote(simQuoteAt("94", "96", 2))
+
+	order, err := sim.NewGetOrderService().OrderID(stop.OrderID).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != OrderStatusFilled || order.BasicOrder.AveragePrice != "94" {
+		t.Errorf("Unexpected stop order %+v", order.BasicOrder)
+	}
+}
+
+func TestSimulatedClientAlgoOrder(t *testing.T) {
+	ctx := context.Background()
+	sim := NewSimulatedClient()
+
+	res, err := sim.NewCreateAlgoOrderService().Exchange(ExchangeBinanceSpot).Symbol("BTC/USDT").
+		Side(SideTypeBuy).OrderType(AlgoOrderTypeTWAP).Quantity("4").
+		Params(map[string]interface{}{"duration": 40}).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quotes := RecordedQuotes(
+		simQuoteAt("99", "100", 0),
+		simQuoteAt("99", "100", 10),
+		simQuoteAt("101", "102", 20),
+		simQuoteAt("101", "102", 60),
+	)
+	if err := sim.Run(ctx, quotes); err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := sim.NewGetOrderService().OrderID(res.OrderID).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trades := order.AlgoOrder.ChildOrders[0].Trades
+	if order.Status != OrderStatusFilled || len(trades) != 3 || trades[0].Quantity != "1" || trades[2].Quantity != "2" || order.AlgoOrder.AveragePrice != "101.5" {
+		t.Errorf("Unexpected algo order %+v", order.AlgoOrder)
+	}
+}
+
+func TestRandomWalkQuotes(t *testing.T) {
+	walk := func() []Quote {
+		w := &RandomWalkQuotes{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Price: 100, Count: 5, Seed: 1}
+		var quotes []Quote
+		for {
+			q, err := w.NextQuote(context.Background())
+			if err != nil {
+				break
+			}
+			quotes = append(quotes, q)
+		}
+		return quotes
+	}
+
+	a, b := walk(), walk()
+	if len(a) != 5 {
+		t.Fatalf("Expected 5 quotes, got %d", len(a))
+	}
+	if a[0].Bid != "99.99" || a[0].Ask != "100.01" || !a[1].Time.Equal(time.Unix(1, 0)) {
+		t.Errorf("Unexpected quotes %+v", a[:2])
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("Quote %d differs between runs: %+v and %+v", i, a[i], b[i])
+		}
+		if toDecimal(a[i].Bid).GreaterThanOrEqual(toDecimal(a[i].Ask)) {
+			t.Errorf("Crossed quote %+v", a[i])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package versifi
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Quote is the best bid and ask of one symbol on one exchange, the market
+// data a SimulatedClient fills orders against
+type Quote struct {
+	Exchange ExchangeType `json:"exchange"`
+	Symbol   string       `json:"symbol"`
+	Bid      string       `json:"bid"`
+	Ask      string       `json:"ask"`
+	// Time is the simulated time of the quote; zero means time.Now
+	Time time.Time `json:"time"`
+}
+
+// QuoteSource produces quotes for SimulatedClient.Run. NextQuote returns
+// io.EOF when the source is exhausted.
+type QuoteSource interface {
+	NextQuote(ctx context.Context) (Quote, error)
+}
+
+// QuoteSourceFunc adapts a function to QuoteSource
+type QuoteSourceFunc func(ctx context.Context) (Quote, error)
+
+// NextQuote calls f(ctx)
+func (f QuoteSourceFunc) NextQuote(ctx context.Context) (Quote, error) {
+	return f(ctx)
+}
+
+// RecordedQuotes replays quotes in order, for example market data recorded
+// from a venue
+func RecordedQuotes(quotes ...Quote) QuoteSource {
+	i := 0
+	return QuoteSourceFunc(func(ctx context.Context) (Quote, error) {
+		if err := ctx.Err(); err != nil {
+			return Quote{}, err
+		}
+		if i >= len(quotes) {
+			return Quote{}, io.EOF
+		}
+		q := quotes[i]
+		i++
+		return q, nil
+	})
+}
+
+// RandomWalkQuotes generates synthetic quotes whose mid price follows a
+// geometric random walk. The same Seed always produces the same quotes.
+type RandomWalkQuotes struct {
+	Exchange ExchangeType
+	Symbol   string
+	// Price is the starting mid price
+	Price float64
+	// Spread is the bid-ask spread as a fraction of the mid price, default 0.0002
+	Spread float64
+	// Volatility is the standard deviation of each step's return, default 0.001
+	Volatility float64
+	// Start is the time of the first quote, default the Unix epoch
+	Start time.Time
+	// Interval is the simulated time between quotes, default 1s
+	Interval time.Duration
+	// Count is the number of quotes produced; zero means no limit
+	Count int
+	// Seed seeds the walk
+	Seed int64
+
+	rng  *rand.Rand
+	n    int
+	mid  float64
+	last time.Time
+}
+
+// NextQuote returns the next step of the walk
+func (w *RandomWalkQuotes) NextQuote(ctx context.Context) (Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return Quote{}, err
+	}
+	if w.Count > 0 && w.n >= w.Count {
+		return Quote{}, io.EOF
+	}
+
+	if w.rng == nil {
+		w.rng = rand.New(rand.NewSource(w.Seed))
+		w.mid = w.Price
+		w.last = w.Start
+		if w.last.IsZero() {
+			w.last = time.Unix(0, 0)
+		}
+	} else {
+		interval := w.Interval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		volatility := w.Volatility
+		if volatility <= 0 {
+			volatility = 0.001
+		}
+		w.mid *= math.Exp(volatility * w.rng.NormFloat64())
+		w.last = w.last.Add(interval)
+	}
+	w.n++
+
+	spread := w.Spread
+	if spread <= 0 {
+		spread = 0.0002
+	}
+	mid := decimal.NewFromFloat(w.mid)
+	half := decimal.NewFromFloat(w.mid * spread / 2)
+	return Quote{
+		Exchange: w.Exchange,
+		Symbol:   w.Symbol,
+		Bid:      mid.Sub(half).Round(8).String(),
+		Ask:      mid.Add(half).Round(8).String(),
+		Time:     w.last,
+	}, nil
+}
@@ -0,0 +1,236 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Spread is the lead/secondary spread of a pair at one point in time, as
+// fractions of the lead price like the BASIS entry_spread_threshold and
+// exit_spread_threshold params
+type Spread struct {
+	// Mid is (secondary mid - lead mid) / lead mid
+	Mid float64 `json:"mid"`
+	// Entry is the spread captured by buying the lead at its ask and
+	// selling the secondary at its bid
+	Entry float64 `json:"entry"`
+	// Exit is the spread paid by selling the lead at its bid and buying
+	// the secondary back at its ask
+	Exit      float64   `json:"exit"`
+	Lead      Quote     `json:"lead"`
+	Secondary Quote     `json:"secondary"`
+	Time      time.Time `json:"time"`
+}
+
+// SpreadEventType is the kind of threshold crossing
+type SpreadEventType string
+
+const (
+	// SpreadAboveEntry: the entry spread rose to or above EntryThreshold
+	SpreadAboveEntry SpreadEventType = "ABOVE_ENTRY"
+	// SpreadBelowEntry: the entry spread fell back below EntryThreshold
+	SpreadBelowEntry SpreadEventType = "BELOW_ENTRY"
+	// SpreadBelowExit: the exit spread fell to or below ExitThreshold
+	SpreadBelowExit SpreadEventType = "BELOW_EXIT"
+	// SpreadAboveExit: the exit spread rose back above ExitThreshold
+	SpreadAboveExit SpreadEventType = "ABOVE_EXIT"
+)
+
+// SpreadEvent reports a threshold crossing
+type SpreadEvent struct {
+	Type      SpreadEventType `json:"type"`
+	Threshold float64         `json:"threshold"`
+	Spread    Spread          `json:"spread"`
+}
+
+// SpreadHandler handles spread threshold crossings
+type SpreadHandler func(event SpreadEvent)
+
+// SpreadMonitor computes the live spread between the two legs of a pair
+// from their quotes and reports when it crosses the entry or exit
+// threshold, to help tune BASIS orders before committing capital.
+//
+//	monitor := versifi.NewSpreadMonitor(lead, secondary, 0.01, 0.005)
+//	monitor.Subscribe(func(e versifi.SpreadEvent) {
+//		log.Printf("%s at %.4f", e.Type, e.Spread.Entry)
+//	})
+//	err := monitor.Run(ctx, quotes)
+//
+// The first spread computed reports SpreadAboveEntry or SpreadBelowExit if
+// it is already past a threshold. A zero threshold disables its events.
+type SpreadMonitor struct {
+	lead      PairLeg
+	secondary PairLeg
+
+	mu             sync.RWMutex
+	entryThreshold float64
+	exitThreshold  float64
+	leadQuote      *Quote
+	secondaryQuote *Quote
+	spread         *Spread
+	aboveEntry     bool
+	belowExit      bool
+	handlers       map[int]SpreadHandler
+	nextID         int
+}
+
+// NewSpreadMonitor monitors the spread between the lead and secondary
+// legs, identified by their Exchange and Symbol
+func NewSpreadMonitor(lead, secondary *PairLeg, entryThreshold, exitThreshold float64) *SpreadMonitor {
+	return &SpreadMonitor{
+		lead:           *lead,
+		secondary:      *secondary,
+		entryThreshold: entryThreshold,
+		exitThreshold:  exitThreshold,
+		handlers:       make(map[int]SpreadHandler),
+	}
+}
+
+// SetThresholds changes the thresholds. Crossings are evaluated against
+// the new values from the next quote on.
+func (m *SpreadMonitor) SetThresholds(entryThreshold, exitThreshold float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entryThreshold = entryThreshold
+	m.exitThreshold = exitThreshold
+}
+
+// Subscribe registers a handler for threshold crossings and returns a
+// function that removes it
+func (m *SpreadMonitor) Subscribe(handler SpreadHandler) (unsubscribe func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.nextID
+	m.nextID++
+	m.handlers[id] = handler
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.handlers, id)
+	}
+}
+
+// Spread returns the latest spread, and false until both legs are quoted
+func (m *SpreadMonitor) Spread() (Spread, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.spread == nil {
+		return Spread{}, false
+	}
+	return *m.spread, true
+}
+
+// HandleQuote updates the leg the quote belongs to and recomputes the
+// spread. Quotes for other symbols and one-sided quotes are ignored.
+func (m *SpreadMonitor) HandleQuote(q Quote) {
+	if !toDecimal(q.Bid).IsPositive() || !toDecimal(q.Ask).IsPositive() {
+		return
+	}
+	if q.Time.IsZero() {
+		q.Time = time.Now()
+	}
+
+	m.mu.Lock()
+	switch {
+	case q.Exchange == m.lead.Exchange && q.Symbol == m.lead.Symbol:
+		m.leadQuote = &q
+	case q.Exchange == m.secondary.Exchange && q.Symbol == m.secondary.Symbol:
+		m.secondaryQuote = &q
+	default:
+		m.mu.Unlock()
+		return
+	}
+	if m.leadQuote == nil || m.secondaryQuote == nil {
+		m.mu.Unlock()
+		return
+	}
+
+	spread := computeSpread(*m.leadQuote, *m.secondaryQuote)
+	m.spread = &spread
+
+	var events []SpreadEvent
+	if m.entryThreshold != 0 {
+		if above := spread.Entry >= m.entryThreshold; above != m.aboveEntry {
+			m.aboveEntry = above
+			t := SpreadBelowEntry
+			if above {
+				t = SpreadAboveEntry
+			}
+			events = append(events, SpreadEvent{Type: t, Threshold: m.entryThreshold, Spread: spread})
+		}
+	}
+	if m.exitThreshold != 0 {
+		if below := spread.Exit <= m.exitThreshold; below != m.belowExit {
+			m.belowExit = below
+			t := SpreadAboveExit
+			if below {
+				t = SpreadBelowExit
+			}
+			events = append(events, SpreadEvent{Type: t, Threshold: m.exitThreshold, Spread: spread})
+		}
+	}
+	handlers := m.handlersLocked()
+	m.mu.Unlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+// Run feeds quotes from source to HandleQuote until the source is
+// exhausted, returning nil, or fails or ctx is done
+func (m *SpreadMonitor) Run(ctx context.Context, source QuoteSource) error {
+	for {
+		q, err := source.NextQuote(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		m.HandleQuote(q)
+	}
+}
+
+func computeSpread(lead, secondary Quote) Spread {
+	two := decimal.NewFromInt(2)
+	leadBid, leadAsk := toDecimal(lead.Bid), toDecimal(lead.Ask)
+	secBid, secAsk := toDecimal(secondary.Bid), toDecimal(secondary.Ask)
+	leadMid := leadBid.Add(leadAsk).Div(two)
+	secMid := secBid.Add(secAsk).Div(two)
+
+	t := lead.Time
+	if secondary.Time.After(t) {
+		t = secondary.Time
+	}
+	return Spread{
+		Mid:       secMid.Sub(leadMid).Div(leadMid).InexactFloat64(),
+		Entry:     secBid.Sub(leadAsk).Div(leadAsk).InexactFloat64(),
+		Exit:      secAsk.Sub(leadBid).Div(leadBid).InexactFloat64(),
+		Lead:      lead,
+		Secondary: secondary,
+		Time:      t,
+	}
+}
+
+// handlersLocked returns the handlers in subscription order
+func (m *SpreadMonitor) handlersLocked() []SpreadHandler {
+	ids := make([]int, 0, len(m.handlers))
+	for id := range m.handlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	handlers := make([]SpreadHandler, len(ids))
+	for i, id := range ids {
+		handlers[i] = m.handlers[id]
+	}
+	return handlers
+}
@@ -0,0 +1,78 @@
+package versifi
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSpreadMonitor(t *testing.T) {
+	lead := &PairLeg{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT"}
+	secondary := &PairLeg{Exchange: ExchangeBinanceFutures, Symbol: "BTC/USDT"}
+	monitor := NewSpreadMonitor(lead, secondary, 0.01, 0.002)
+
+	var events []SpreadEvent
+	monitor.Subscribe(func(e SpreadEvent) { events = append(events, e) })
+
+	spot := func(bid, ask string) Quote {
+		return Quote{Exchange: ExchangeBinanceSpot, Symbol: "BTC/USDT", Bid: bid, Ask: ask}
+	}
+	perp := func(bid, ask string) Quote {
+		return Quote{Exchange: ExchangeBinanceFutures, Symbol: "BTC/USDT", Bid: bid, Ask: ask}
+	}
+
+	err := monitor.Run(context.Background(), RecordedQuotes(
+		spot("99", "100"),
+		Quote{Exchange: ExchangeOKXSpot, Symbol: "BTC/USDT", Bid: "1", Ask: "2"}, // Ignored
+		perp("100.5", "101"), // Entry 0.5%, exit 2%: no crossings
+		perp("101.5", "102"), // Entry 1.5%: above entry
+		perp("100", "100.1"), // Entry 0%, exit 1.1%: below entry
+		spot("100", "100.1"), // Exit 0.1%: below exit
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []SpreadEventType{SpreadAboveEntry, SpreadBelowEntry, SpreadBelowExit}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %v, got %+v", want, events)
+	}
+	for i, e := range events {
+		if e.Type != want[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, want[i], e.Type)
+		}
+	}
+	if e := events[0]; math.Abs(e.Spread.Entry-0.015) > 1e-9 || e.Threshold != 0.01 {
+		t.Errorf("Unexpected entry event %+v", e)
+	}
+
+	spread, ok := monitor.Spread()
+	if !ok || math.Abs(spread.Exit-0.001) > 1e-9 || math.Abs(spread.Mid-0) > 1e-9 {
+		t.Errorf("Unexpected spread %+v", spread)
+	}
+}
+
+func TestSpreadMonitorInitialState(t *testing.T) {
+	lead := &PairLeg{Exchange: ExchangeOKXSpot, Symbol: "ETH/USDT"}
+	secondary := &PairLeg{Exchange: ExchangeOKXFutures, Symbol: "ETH/USDT"}
+	monitor := NewSpreadMonitor(lead, secondary, 0.01, 0)
+
+	var events []SpreadEvent
+	monitor.Subscribe(func(e SpreadEvent) { events = append(events, e) })
+	if _, ok := monitor.Spread(); ok {
+		t.Error("Expected no spread before both legs are quoted")
+	}
+
+	monitor.HandleQuote(Quote{Exchange: ExchangeOKXSpot, Symbol: "ETH/USDT", Bid: "99", Ask: "100"})
+	monitor.HandleQuote(Quote{Exchange: ExchangeOKXFutures, Symbol: "ETH/USDT", Bid: "105", Ask: "106"})
+	if len(events) != 1 || events[0].Type != SpreadAboveEntry {
+		t.Errorf("Expected the initial spread to report SpreadAboveEntry, got %+v", events)
+	}
+
+	// Raising the threshold moves the spread back below it
+	monitor.SetThresholds(0.1, 0)
+	monitor.HandleQuote(Quote{Exchange: ExchangeOKXFutures, Symbol: "ETH/USDT", Bid: "105", Ask: "106"})
+	if len(events) != 2 || events[1].Type != SpreadBelowEntry {
+		t.Errorf("Expected SpreadBelowEntry, got %+v", events)
+	}
+}
@@ -0,0 +1,71 @@
+package versifi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrUnknownFields is matched by the ErrDecode error of a response holding
+// fields this SDK does not know while Client.StrictDecoding is set
+var ErrUnknownFields = errors.New("unknown fields")
+
+// UnknownFieldsEvent describes a REST response holding fields this SDK does
+// not know, a sign the API has changed
+type UnknownFieldsEvent struct {
+	Method   string
+	Endpoint string
+	// Fields lists JSON paths such as "basic_order.new_field"
+	Fields []string
+}
+
+// UnknownFieldsHandler handles unknown fields events
+type UnknownFieldsHandler func(event UnknownFieldsEvent)
+
+// SetUnknownFieldsHandler sets a handler for REST responses holding fields
+// this SDK does not know. Looking for them costs an extra decode per
+// response, so it only happens while a handler is set or StrictDecoding is.
+// A nil handler disables the reports.
+func (c *Client) SetUnknownFieldsHandler(handler UnknownFieldsHandler) {
+	if handler == nil {
+		c.unknownFieldsHandler.Store(nil)
+		return
+	}
+	c.unknownFieldsHandler.Store(&handler)
+}
+
+// decodeResponse decodes the response data of r into v. Unknown fields are
+// reported to the unknown fields handler and, with StrictDecoding, fail the
+// request.
+func (c *Client) decodeResponse(r *request, data []byte, v interface{}) error {
+	handler := c.unknownFieldsHandler.Load()
+	if !c.StrictDecoding {
+		if err := json.Unmarshal(data, v); err != nil {
+			return r.error(ErrDecode, err)
+		}
+		if handler == nil {
+			return nil
+		}
+		if fields := unknownFields(data, reflect.TypeOf(v)); len(fields) > 0 {
+			(*handler)(UnknownFieldsEvent{Method: r.method, Endpoint: r.endpoint, Fields: fields})
+		}
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		fields := unknownFields(data, reflect.TypeOf(v))
+		if len(fields) == 0 {
+			return r.error(ErrDecode, err)
+		}
+		if handler != nil {
+			(*handler)(UnknownFieldsEvent{Method: r.method, Endpoint: r.endpoint, Fields: fields})
+		}
+		return r.error(ErrDecode, fmt.Errorf("%w: %s", ErrUnknownFields, strings.Join(fields, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"order_id":7,"status":"NEW","fee_tier":"VIP1","basic_order":{"symbol":"BTC/USDT","child_orders":[{"id":1,"venue_ref":"x"}]}}`))
+	}))
+	defer server.Close()
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	// Tolerant by default
+	order, err := client.NewGetOrderService().OrderID(7).Do(ctx)
+	if err != nil || order.OrderID != 7 {
+		t.Fatalf("Unexpected order %+v, %v", order, err)
+	}
+
+	var events []UnknownFieldsEvent
+	client.SetUnknownFieldsHandler(func(event UnknownFieldsEvent) {
+		events = append(events, event)
+	})
+	if _, err := client.NewGetOrderService().OrderID(7).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := UnknownFieldsEvent{Method: http.MethodGet, Endpoint: "/v2/orders/7", Fields: []string{"basic_order.child_orders[0].venue_ref", "fee_tier"}}
+	if len(events) != 1 || !reflect.DeepEqual(events[0], want) {
+		t.Errorf("Expected %+v, got %+v", want, events)
+	}
+
+	client.StrictDecoding = true
+	_, err = client.NewGetOrderService().OrderID(7).Do(ctx)
+	if !errors.Is(err, ErrUnknownFields) || !errors.Is(err, ErrDecode) || len(events) != 2 {
+		t.Errorf("Expected ErrUnknownFields, got %v after %d events", err, len(events))
+	}
+
+	client.SetUnknownFieldsHandler(nil)
+	_, err = client.NewGetOrderService().OrderID(7).Do(ctx)
+	if !errors.Is(err, ErrUnknownFields) || len(events) != 2 {
+		t.Errorf("Expected ErrUnknownFields without a report, got %v after %d events", err, len(events))
+	}
+}
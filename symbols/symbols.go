@@ -0,0 +1,147 @@
+// Package symbols converts between Versifi's BASE/QUOTE symbol format and
+// the native formats of each exchange.
+//
+//	native, _ := symbols.ToNative(versifi.ExchangeOKXFutures, "BTC/USDT") // "BTC-USDT-SWAP"
+//	symbol, _ := symbols.FromNative(versifi.ExchangeBinanceSpot, "ETHBTC") // "ETH/BTC"
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+var (
+	// ErrInvalidSymbol is returned for symbols that cannot be parsed
+	ErrInvalidSymbol = errors.New("invalid symbol")
+	// ErrUnknownExchange is returned for exchanges without conversion rules
+	ErrUnknownExchange = errors.New("unknown exchange")
+)
+
+// rule describes an exchange's native symbol format
+type rule struct {
+	separator string // Between base and quote, empty for concatenated symbols
+	suffix    string // Appended to every symbol, such as "-SWAP"
+}
+
+var rules = map[versifi.ExchangeType]rule{
+	versifi.ExchangeBinanceSpot:    {},
+	versifi.ExchangeBinanceFutures: {},
+	versifi.ExchangeOKXSpot:        {separator: "-"},
+	versifi.ExchangeOKXFutures:     {separator: "-", suffix: "-SWAP"},
+}
+
+// quoteAssets are recognized when splitting concatenated symbols such as
+// BTCUSDT, longest first so that FDUSD wins over USD
+var quoteAssets = []string{
+	"FDUSD", "USDT", "USDC", "BUSD", "TUSD", "USDE",
+	"BTC", "ETH", "BNB", "EUR", "TRY", "BRL", "DAI", "USD",
+}
+
+// Format joins base and quote into a Versifi symbol
+func Format(base, quote string) string {
+	return strings.ToUpper(base) + "/" + strings.ToUpper(quote)
+}
+
+// Parse splits a Versifi symbol into its base and quote assets
+func Parse(symbol string) (base, quote string, err error) {
+	base, quote, ok := strings.Cut(symbol, "/")
+	if !ok || !validAsset(base) || !validAsset(quote) {
+		return "", "", fmt.Errorf("%w: %q is not in BASE/QUOTE form", ErrInvalidSymbol, symbol)
+	}
+	return base, quote, nil
+}
+
+// Validate checks that symbol is a well-formed, upper-case Versifi symbol
+func Validate(symbol string) error {
+	_, _, err := Parse(symbol)
+	return err
+}
+
+// Normalize converts common spellings such as "btc-usdt", "BTC_USDT",
+// "BTC-USDT-SWAP" or "BTCUSDT" to the Versifi form "BTC/USDT"
+func Normalize(symbol string) (string, error) {
+	s := strings.ToUpper(strings.TrimSpace(symbol))
+	s = strings.TrimSuffix(s, "-SWAP")
+
+	for _, sep := range []string{"/", "-", "_", ":"} {
+		if base, quote, ok := strings.Cut(s, sep); ok {
+			if !validAsset(base) || !validAsset(quote) {
+				break
+			}
+			return Format(base, quote), nil
+		}
+	}
+
+	if base, quote, ok := splitConcatenated(s); ok {
+		return Format(base, quote), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidSymbol, symbol)
+}
+
+// ToNative converts a Versifi symbol to the native format of exchange
+func ToNative(exchange versifi.ExchangeType, symbol string) (string, error) {
+	r, ok := rules[exchange]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownExchange, exchange)
+	}
+	base, quote, err := Parse(symbol)
+	if err != nil {
+		return "", err
+	}
+	return base + r.separator + quote + r.suffix, nil
+}
+
+// FromNative converts a native symbol of exchange to the Versifi format
+func FromNative(exchange versifi.ExchangeType, native string) (string, error) {
+	r, ok := rules[exchange]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownExchange, exchange)
+	}
+
+	s := strings.ToUpper(native)
+	if r.suffix != "" {
+		trimmed := strings.TrimSuffix(s, r.suffix)
+		if trimmed == s {
+			return "", fmt.Errorf("%w: %q has no %s suffix", ErrInvalidSymbol, native, r.suffix)
+		}
+		s = trimmed
+	}
+
+	var base, quote string
+	if r.separator != "" {
+		base, quote, ok = strings.Cut(s, r.separator)
+		ok = ok && validAsset(base) && validAsset(quote)
+	} else {
+		base, quote, ok = splitConcatenated(s)
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %q is not a %s symbol", ErrInvalidSymbol, native, exchange)
+	}
+	return Format(base, quote), nil
+}
+
+// splitConcatenated splits symbols such as BTCUSDT on a known quote asset
+func splitConcatenated(s string) (base, quote string, ok bool) {
+	for _, q := range quoteAssets {
+		if strings.HasSuffix(s, q) && validAsset(strings.TrimSuffix(s, q)) {
+			return strings.TrimSuffix(s, q), q, true
+		}
+	}
+	return "", "", false
+}
+
+// validAsset accepts non-empty upper-case alphanumeric asset codes
+func validAsset(asset string) bool {
+	if asset == "" {
+		return false
+	}
+	for _, r := range asset {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package symbols
+
+import (
+	"errors"
+	"testing"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func TestToNativeAndBack(t *testing.T) {
+	tests := []struct {
+		exchange versifi.ExchangeType
+		symbol   string
+		native   string
+	}{
+		{versifi.ExchangeBinanceSpot, "BTC/USDT", "BTCUSDT"},
+		{versifi.ExchangeBinanceSpot, "ETH/BTC", "ETHBTC"},
+		{versifi.ExchangeBinanceFutures, "BTC/FDUSD", "BTCFDUSD"},
+		{versifi.ExchangeOKXSpot, "BTC/USDT", "BTC-USDT"},
+		{versifi.ExchangeOKXFutures, "BTC/USDT", "BTC-USDT-SWAP"},
+	}
+
+	for _, tt := range tests {
+		native, err := ToNative(tt.exchange, tt.symbol)
+		if err != nil || native != tt.native {
+			t.Errorf("ToNative(%s, %s) = %q, %v; want %q", tt.exchange, tt.symbol, native, err, tt.native)
+		}
+		symbol, err := FromNative(tt.exchange, tt.native)
+		if err != nil || symbol != tt.symbol {
+			t.Errorf("FromNative(%s, %s) = %q, %v; want %q", tt.exchange, tt.native, symbol, err, tt.symbol)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for _, in := range []string{"BTC/USDT", "btc-usdt", "BTC_USDT", " BTC-USDT-SWAP ", "btcusdt"} {
+		if got, err := Normalize(in); err != nil || got != "BTC/USDT" {
+			t.Errorf("Normalize(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := Normalize("BTC"); !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("Expected ErrInvalidSymbol, got %v", err)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	if err := Validate("btc/usdt"); !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("Expected lower-case symbol to be rejected, got %v", err)
+	}
+	if _, err := ToNative("KRAKEN", "BTC/USD"); !errors.Is(err, ErrUnknownExchange) {
+		t.Errorf("Expected ErrUnknownExchange, got %v", err)
+	}
+	if _, err := FromNative(versifi.ExchangeOKXFutures, "BTC-USDT"); !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("Expected missing -SWAP suffix to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+// Package tca computes transaction cost analysis for filled orders on the
+// client side, for use until the server's analytics topic is live.
+//
+//	order, err := client.NewGetOrderService().OrderID(id).Do(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	report, err := tca.Analyze(order, tca.Benchmark{
+//		ArrivalPrice: arrival,
+//		Candles:      candles, // bars covering the order's lifetime
+//	})
+//
+// Slippage is reported in basis points with positive values meaning the
+// order did worse than the benchmark: it paid more on a buy or received
+// less on a sell.
+package tca
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// ErrPairOrder is returned by Analyze for pair orders, whose legs trade
+// different symbols and have no single benchmark
+var ErrPairOrder = errors.New("tca: pair orders are not supported")
+
+// Candle is one bar of market data
+type Candle struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Open   string    `json:"open"`
+	High   string    `json:"high"`
+	Low    string    `json:"low"`
+	Close  string    `json:"close"`
+	Volume string    `json:"volume"` // Base asset volume
+}
+
+// CandleSource provides the market bars for a symbol between start and end
+type CandleSource interface {
+	Candles(ctx context.Context, exchange versifi.ExchangeType, symbol string, start, end time.Time) ([]Candle, error)
+}
+
+// CandleSourceFunc adapts a function to a CandleSource
+type CandleSourceFunc func(ctx context.Context, exchange versifi.ExchangeType, symbol string, start, end time.Time) ([]Candle, error)
+
+// Candles calls f(ctx, exchange, symbol, start, end)
+func (f CandleSourceFunc) Candles(ctx context.Context, exchange versifi.ExchangeType, symbol string, start, end time.Time) ([]Candle, error) {
+	return f(ctx, exchange, symbol, start, end)
+}
+
+// Benchmark holds the market prices an execution is measured against.
+// Empty fields leave the matching report fields empty.
+type Benchmark struct {
+	// ArrivalPrice is the market price when the order was submitted,
+	// usually the mid
+	ArrivalPrice string
+	// Candles cover the order's lifetime; they give the interval VWAP and
+	// the market volume used for participation
+	Candles []Candle
+}
+
+// FetchBenchmark loads the candles from the order's creation time to end
+// and pairs them with the arrival price
+func FetchBenchmark(ctx context.Context, source CandleSource, order *versifi.GetOrderResponse, arrivalPrice string, end time.Time) (Benchmark, error) {
+	var (
+		exchange versifi.ExchangeType
+		symbol   string
+	)
+	switch {
+	case order.BasicOrder != nil:
+		exchange, symbol = order.BasicOrder.Exchange, order.BasicOrder.Symbol
+	case order.AlgoOrder != nil:
+		exchange, symbol = order.AlgoOrder.Exchange, order.AlgoOrder.Symbol
+	default:
+		return Benchmark{}, ErrPairOrder
+	}
+
+	candles, err := source.Candles(ctx, exchange, symbol, order.Timestamp.Time, end)
+	if err != nil {
+		return Benchmark{}, err
+	}
+	return Benchmark{ArrivalPrice: arrivalPrice, Candles: candles}, nil
+}
+
+// Report is the cost analysis of one order. Amounts are decimal strings;
+// fields that cannot be computed from the inputs are empty.
+type Report struct {
+	OrderID  int64                `json:"order_id"`
+	Exchange versifi.ExchangeType `json:"exchange"`
+	Symbol   string               `json:"symbol"`
+	Side     versifi.SideType     `json:"side"`
+
+	Quantity       string `json:"quantity,omitempty"`  // Ordered quantity
+	FilledQuantity string `json:"filled_quantity"`     // Sum of trade quantities
+	FillRate       string `json:"fill_rate,omitempty"` // Filled over ordered quantity
+	AveragePrice   string `json:"average_price,omitempty"`
+	Notional       string `json:"notional"`
+	Fees           string `json:"fees"`
+	Trades         int    `json:"trades"`
+
+	// Child order counts and the fraction of child quantity that filled
+	ChildOrders       int    `json:"child_orders"`
+	FilledChildOrders int    `json:"filled_child_orders"`
+	ChildFillRate     string `json:"child_fill_rate,omitempty"`
+
+	ArrivalPrice       string `json:"arrival_price,omitempty"`
+	ArrivalSlippageBps string `json:"arrival_slippage_bps,omitempty"`
+	IntervalVWAP       string `json:"interval_vwap,omitempty"`
+	VWAPSlippageBps    string `json:"vwap_slippage_bps,omitempty"`
+	// MarketVolume is the candle volume over the interval and
+	// Participation the filled quantity as a fraction of it
+	MarketVolume  string `json:"market_volume,omitempty"`
+	Participation string `json:"participation,omitempty"`
+}
+
+// Analyze computes the report for a basic or algo order from its child
+// orders and trades
+func Analyze(order *versifi.GetOrderResponse, benchmark Benchmark) (*Report, error) {
+	var (
+		exchange versifi.ExchangeType
+		symbol   string
+		side     versifi.SideType
+		quantity string
+		children []versifi.ChildOrder
+	)
+	switch {
+	case order.BasicOrder != nil:
+		d := order.BasicOrder
+		exchange, symbol, side, quantity, children = d.Exchange, d.Symbol, d.Side, d.Quantity, d.ChildOrders
+	case order.AlgoOrder != nil:
+		d := order.AlgoOrder
+		exchange, symbol, side, quantity, children = d.Exchange, d.Symbol, d.Side, d.Quantity, d.ChildOrders
+	case order.PairOrder != nil:
+		return nil, ErrPairOrder
+	default:
+		return nil, errors.New("tca: order has no details")
+	}
+
+	var trades []versifi.Trade
+	for _, child := range children {
+		trades = append(trades, child.Trades...)
+	}
+	r := AnalyzeTrades(side, quantity, trades, benchmark)
+	r.OrderID = order.OrderID
+	r.Exchange = exchange
+	r.Symbol = symbol
+
+	var childQuantity, childFilled decimal.Decimal
+	for _, child := range children {
+		r.ChildOrders++
+		if child.OrderStatus == versifi.OrderStatusFilled {
+			r.FilledChildOrders++
+		}
+		childQuantity = childQuantity.Add(child.QuantityDecimal())
+		childFilled = childFilled.Add(child.FilledQuantityDecimal())
+	}
+	if childQuantity.IsPositive() {
+		r.ChildFillRate = ratio(childFilled, childQuantity)
+	}
+	return r, nil
+}
+
+// AnalyzeTrades computes the report for a set of trades on one side. The
+// ordered quantity may be empty when it is not known.
+func AnalyzeTrades(side versifi.SideType, quantity string, trades []versifi.Trade, benchmark Benchmark) *Report {
+	r := &Report{Side: side, Quantity: quantity, Trades: len(trades)}
+
+	var filled, notional, fees decimal.Decimal
+	for _, t := range trades {
+		qty := t.QuantityDecimal()
+		filled = filled.Add(qty)
+		notional = notional.Add(qty.Mul(t.PriceDecimal()))
+		fees = fees.Add(t.FeeDecimal())
+		if r.Exchange == "" {
+			r.Exchange, r.Symbol = t.Exchange, t.Symbol
+		}
+	}
+	r.FilledQuantity = filled.String()
+	r.Notional = notional.String()
+	r.Fees = fees.String()
+
+	if ordered := parse(quantity); ordered.IsPositive() {
+		r.FillRate = ratio(filled, ordered)
+	}
+	if !filled.IsPositive() {
+		return r
+	}
+	avg := notional.DivRound(filled, 12)
+	r.AveragePrice = avg.String()
+
+	if arrival := parse(benchmark.ArrivalPrice); arrival.IsPositive() {
+		r.ArrivalPrice = arrival.String()
+		r.ArrivalSlippageBps = slippageBps(side, avg, arrival)
+	}
+	if vwap, volume := IntervalVWAP(benchmark.Candles); volume.IsPositive() {
+		r.IntervalVWAP = vwap.String()
+		r.VWAPSlippageBps = slippageBps(side, avg, vwap)
+		r.MarketVolume = volume.String()
+		r.Participation = ratio(filled, volume)
+	}
+	return r
+}
+
+// IntervalVWAP returns the volume weighted average of each candle's typical
+// price, (high + low + close) / 3, and the total volume
+func IntervalVWAP(candles []Candle) (vwap, volume decimal.Decimal) {
+	var weighted decimal.Decimal
+	three := decimal.NewFromInt(3)
+	for _, c := range candles {
+		v := parse(c.Volume)
+		if !v.IsPositive() {
+			continue
+		}
+		typical := parse(c.High).Add(parse(c.Low)).Add(parse(c.Close)).Div(three)
+		weighted = weighted.Add(typical.Mul(v))
+		volume = volume.Add(v)
+	}
+	if !volume.IsPositive() {
+		return decimal.Zero, decimal.Zero
+	}
+	return weighted.DivRound(volume, 12), volume
+}
+
+// slippageBps is the cost of executing at price against benchmark, in
+// basis points
+func slippageBps(side versifi.SideType, price, benchmark decimal.Decimal) string {
+	diff := price.Sub(benchmark)
+	if side == versifi.SideTypeSell {
+		diff = diff.Neg()
+	}
+	return diff.Div(benchmark).Mul(decimal.NewFromInt(10000)).Round(2).String()
+}
+
+func ratio(a, b decimal.Decimal) string {
+	return a.DivRound(b, 6).String()
+}
+
+// parse reads a decimal string, returning zero if it is empty or malformed
+func parse(s string) decimal.Decimal {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero
+	}
+	return d
+}
@@ -0,0 +1,106 @@
+package tca
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func TestAnalyzeAlgoOrder(t *testing.T) {
+	order := &versifi.GetOrderResponse{
+		OrderID:   42,
+		Timestamp: versifi.NewTimestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), versifi.PrecisionMilliseconds),
+		AlgoOrder: &versifi.AlgoOrderDetail{
+			Exchange: versifi.ExchangeBinanceSpot,
+			Symbol:   "BTC/USDT",
+			Side:     versifi.SideTypeBuy,
+			Quantity: "4",
+			ChildOrders: []versifi.ChildOrder{
+				{
+					Quantity: "2", FilledQuantity: "2", OrderStatus: versifi.OrderStatusFilled,
+					Trades: []versifi.Trade{
+						{Price: "100", Quantity: "1", Fee: "0.1"},
+						{Price: "102", Quantity: "1", Fee: "0.1"},
+					},
+				},
+				{
+					Quantity: "2", FilledQuantity: "1", OrderStatus: versifi.OrderStatusCanceled,
+					Trades: []versifi.Trade{{Price: "104", Quantity: "1", Fee: "0.1"}},
+				},
+			},
+		},
+	}
+
+	var gotStart time.Time
+	source := CandleSourceFunc(func(ctx context.Context, exchange versifi.ExchangeType, symbol string, start, end time.Time) ([]Candle, error) {
+		gotStart = start
+		return []Candle{
+			{High: "101", Low: "99", Close: "100", Volume: "10"},
+			{High: "103", Low: "101", Close: "102", Volume: "20"},
+		}, nil
+	})
+	benchmark, err := FetchBenchmark(context.Background(), source, order, "100", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotStart.Equal(order.Timestamp.Time) {
+		t.Errorf("Expected candles from the order time, got %v", gotStart)
+	}
+
+	r, err := Analyze(order, benchmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]string{
+		"filled":        r.FilledQuantity,
+		"fill rate":     r.FillRate,
+		"average":       r.AveragePrice,
+		"fees":          r.Fees,
+		"arrival bps":   r.ArrivalSlippageBps,
+		"vwap":          r.IntervalVWAP,
+		"vwap bps":      r.VWAPSlippageBps,
+		"volume":        r.MarketVolume,
+		"participation": r.Participation,
+		"child fill":    r.ChildFillRate,
+	} {
+		want := map[string]string{
+			"filled":        "3",
+			"fill rate":     "0.75",
+			"average":       "102",
+			"fees":          "0.3",
+			"arrival bps":   "200",
+			"vwap":          "101.333333333333",
+			"vwap bps":      "65.79",
+			"volume":        "30",
+			"participation": "0.1",
+			"child fill":    "0.75",
+		}[name]
+		if got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+	}
+	if r.OrderID != 42 || r.Symbol != "BTC/USDT" || r.Trades != 3 || r.ChildOrders != 2 || r.FilledChildOrders != 1 {
+		t.Errorf("Unexpected report %+v", r)
+	}
+}
+
+func TestAnalyzeTradesSell(t *testing.T) {
+	trades := []versifi.Trade{{Price: "99", Quantity: "2"}}
+	r := AnalyzeTrades(versifi.SideTypeSell, "", trades, Benchmark{ArrivalPrice: "100"})
+	// Selling below arrival is a cost
+	if r.ArrivalSlippageBps != "100" || r.FillRate != "" || r.IntervalVWAP != "" {
+		t.Errorf("Unexpected report %+v", r)
+	}
+
+	r = AnalyzeTrades(versifi.SideTypeBuy, "1", nil, Benchmark{ArrivalPrice: "100"})
+	if r.FillRate != "0" || r.AveragePrice != "" || r.ArrivalSlippageBps != "" {
+		t.Errorf("Unexpected report for an unfilled order %+v", r)
+	}
+
+	if _, err := Analyze(&versifi.GetOrderResponse{PairOrder: &versifi.PairOrderDetail{}}, Benchmark{}); !errors.Is(err, ErrPairOrder) {
+		t.Errorf("Expected ErrPairOrder, got %v", err)
+	}
+}
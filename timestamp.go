@@ -0,0 +1,160 @@
+package versifi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimePrecisionType is the unit of an epoch timestamp
+type TimePrecisionType int
+
+// The zero TimePrecisionType is milliseconds, the unit of most API fields
+const (
+	PrecisionMilliseconds TimePrecisionType = iota
+	PrecisionSeconds
+	PrecisionMicroseconds
+	PrecisionNanoseconds
+)
+
+// Timestamp is an epoch time of the API. The API sends seconds,
+// milliseconds or microseconds depending on the field, so a Timestamp
+// decodes any of them, told apart by magnitude, from a JSON number or
+// numeric string, as well as RFC 3339 strings. It remembers the unit it was
+// received in and encodes back in that unit, or in Precision when created
+// with NewTimestamp. Zero is the zero time.Time.
+type Timestamp struct {
+	time.Time
+	// Precision is the unit the timestamp is encoded in
+	Precision TimePrecisionType
+}
+
+// NewTimestamp returns t as a Timestamp encoded in precision
+func NewTimestamp(t time.Time, precision TimePrecisionType) Timestamp {
+	return Timestamp{Time: t, Precision: precision}
+}
+
+// TimestampPtr returns a pointer to a Timestamp
+func TimestampPtr(t time.Time, precision TimePrecisionType) *Timestamp {
+	ts := NewTimestamp(t, precision)
+	return &ts
+}
+
+// Epoch returns the timestamp in its precision, zero for the zero time
+func (t Timestamp) Epoch() int64 {
+	if t.IsZero() {
+		return 0
+	}
+	switch t.Precision {
+	case PrecisionSeconds:
+		return t.Unix()
+	case PrecisionMicroseconds:
+		return t.UnixMicro()
+	case PrecisionNanoseconds:
+		return t.UnixNano()
+	}
+	return t.UnixMilli()
+}
+
+// String formats the timestamp as RFC 3339 with fractional seconds
+func (t Timestamp) String() string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// MarshalJSON encodes the timestamp as an integer in its precision
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, t.Epoch(), 10), nil
+}
+
+// UnmarshalJSON decodes an epoch in any precision or an RFC 3339 string.
+// null leaves the timestamp unchanged.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*t = Timestamp{}
+			return nil
+		}
+		if parsed, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			*t = Timestamp{Time: parsed}
+			return nil
+		}
+	}
+
+	epoch, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		*t = TimestampFromEpoch(epoch)
+		return nil
+	}
+	// Some endpoints send fractional seconds
+	if ts, ok := parseFractionalSeconds(s); ok {
+		*t = ts
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("invalid timestamp %s", data)
+	}
+	*t = Timestamp{Time: time.UnixMicro(int64(math.Round(f * 1e6))), Precision: PrecisionMicroseconds}
+	return nil
+}
+
+// parseFractionalSeconds parses seconds with a decimal fraction, such as
+// 1677721800.123, exactly. The precision is the finest unit the fraction
+// needs, so the timestamp encodes back without losing it.
+func parseFractionalSeconds(s string) (Timestamp, bool) {
+	whole, frac, ok := strings.Cut(s, ".")
+	if !ok || frac == "" || len(frac) > 9 || strings.Trim(frac, "0123456789") != "" {
+		return Timestamp{}, false
+	}
+	sec, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return Timestamp{}, false
+	}
+	nanos, _ := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+	if strings.HasPrefix(whole, "-") {
+		nanos = -nanos
+	}
+
+	precision := PrecisionNanoseconds
+	switch digits := len(strings.TrimRight(frac, "0")); {
+	case digits == 0:
+		precision = PrecisionSeconds
+	case digits <= 3:
+		precision = PrecisionMilliseconds
+	case digits <= 6:
+		precision = PrecisionMicroseconds
+	}
+	return Timestamp{Time: time.Unix(sec, nanos), Precision: precision}, true
+}
+
+// TimestampFromEpoch converts an epoch in the unit its magnitude implies:
+// below 1e11 seconds (until the year 5138), below 1e14 milliseconds, below
+// 1e17 microseconds and nanoseconds above
+func TimestampFromEpoch(epoch int64) Timestamp {
+	abs := epoch
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case epoch == 0:
+		return Timestamp{}
+	case abs < 1e11:
+		return Timestamp{Time: time.Unix(epoch, 0), Precision: PrecisionSeconds}
+	case abs < 1e14:
+		return Timestamp{Time: time.UnixMilli(epoch), Precision: PrecisionMilliseconds}
+	case abs < 1e17:
+		return Timestamp{Time: time.UnixMicro(epoch), Precision: PrecisionMicroseconds}
+	}
+	return Timestamp{Time: time.Unix(0, epoch), Precision: PrecisionNanoseconds}
+}
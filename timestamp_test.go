@@ -0,0 +1,89 @@
+package versifi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestampUnmarshal(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	tests := []struct {
+		input     string
+		precision TimePrecisionType
+	}{
+		{`1709296245`, PrecisionSeconds},
+		{`1709296245000`, PrecisionMilliseconds},
+		{`1709296245000000`, PrecisionMicroseconds},
+		{`1709296245000000000`, PrecisionNanoseconds},
+		{`"1709296245000"`, PrecisionMilliseconds},
+		{`1709296245.0`, PrecisionSeconds},
+		{`"2024-03-01T12:30:45Z"`, PrecisionMilliseconds},
+	}
+	for _, test := range tests {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(test.input), &ts); err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.input, err)
+		}
+		if !ts.Equal(want) || ts.Precision != test.precision {
+			t.Errorf("%s: expected %v in precision %d, got %v in %d", test.input, want, test.precision, ts, ts.Precision)
+		}
+	}
+
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`1709296245.5`), &ts); err != nil || ts.Nanosecond() != 5e8 {
+		t.Errorf("Expected fractional seconds, got %v (%v)", ts, err)
+	}
+	// Fractional seconds keep their fraction when encoded again
+	for input, want := range map[string]string{
+		`1677721800.123`:       `1677721800123`,
+		`1677721800.1234`:      `1677721800123400`,
+		`1677721800.123456789`: `1677721800123456789`,
+		`"1677721800.5"`:       `1677721800500`,
+		`1.6777218001e9`:       `1677721800100000`,
+	} {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(input), &ts); err != nil {
+			t.Fatalf("%s: unexpected error: %v", input, err)
+		}
+		data, _ := json.Marshal(ts)
+		var back Timestamp
+		if err := json.Unmarshal(data, &back); string(data) != want || err != nil || !back.Equal(ts.Time) {
+			t.Errorf("%s: expected %s to round trip, got %s as %v (%v)", input, want, data, back, err)
+		}
+	}
+	ts = NewTimestamp(want, PrecisionMilliseconds)
+	if err := json.Unmarshal([]byte(`null`), &ts); err != nil || !ts.Equal(want) {
+		t.Errorf("Expected null to leave the timestamp unchanged, got %v (%v)", ts, err)
+	}
+	if err := json.Unmarshal([]byte(`""`), &ts); err != nil || !ts.IsZero() {
+		t.Errorf("Expected an empty string to decode as zero, got %v (%v)", ts, err)
+	}
+	if err := json.Unmarshal([]byte(`"yesterday"`), &ts); err == nil {
+		t.Error("Expected an error for an invalid timestamp")
+	}
+}
+
+func TestTimestampMarshal(t *testing.T) {
+	at := time.UnixMicro(1709296245123456)
+	tests := []struct {
+		ts   Timestamp
+		want string
+	}{
+		{NewTimestamp(at, PrecisionSeconds), `1709296245`},
+		{NewTimestamp(at, PrecisionMilliseconds), `1709296245123`},
+		{NewTimestamp(at, PrecisionMicroseconds), `1709296245123456`},
+		{NewTimestamp(at, PrecisionNanoseconds), `1709296245123456000`},
+		{Timestamp{}, `0`},
+	}
+	for _, test := range tests {
+		data, err := json.Marshal(test.ts)
+		if err != nil || string(data) != test.want {
+			t.Errorf("Expected %s, got %s (%v)", test.want, data, err)
+		}
+		var back Timestamp
+		if err := json.Unmarshal(data, &back); err != nil || back.Epoch() != test.ts.Epoch() || back.Precision != test.ts.Precision {
+			t.Errorf("Expected %s to round trip, got %v (%v)", data, back, err)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const defaultTWAPQuantityPrecision = 8
+
+var (
+	// ErrTWAPCanceled is returned by TWAP.Run after Cancel
+	ErrTWAPCanceled = errors.New("twap canceled")
+	// ErrTWAPStarted is returned by a second call to TWAP.Run
+	ErrTWAPStarted = errors.New("twap already started")
+)
+
+// TWAPConfig describes a parent order executed by a client-side TWAP
+type TWAPConfig struct {
+	Exchange ExchangeType
+	Symbol   string
+	Side     SideType
+	Quantity string // Total quantity of the parent order
+	// OrderType of the child orders, MARKET or LIMIT, default MARKET
+	OrderType   BasicOrderType
+	Price       string          // Limit price of LIMIT children
+	TimeInForce TimeInForceType // Optional time in force of LIMIT children
+	// Duration is the time between the first and the last slice
+	Duration time.Duration
+	Slices   int
+	// QuantityPrecision is the number of decimal places of slice
+	// quantities, default 8. The last slice takes the remainder.
+	QuantityPrecision int32
+	// AutoRound rounds each child with Client.Instruments, see
+	// CreateBasicOrderService.AutoRound
+	AutoRound bool
+	// Tracker, when set, submits the children through it so that
+	// TWAPProgress.FilledQuantity can be reported
+	Tracker *OrderTracker
+}
+
+// TWAPProgress reports how far a TWAP has got
+type TWAPProgress struct {
+	Slices         int     `json:"slices"`
+	SlicesSent     int     `json:"slices_sent"`
+	Quantity       string  `json:"quantity"`
+	SentQuantity   string  `json:"sent_quantity"`
+	FilledQuantity string  `json:"filled_quantity,omitempty"` // Only with a Tracker
+	OrderIDs       []int64 `json:"order_ids"`
+	Paused         bool    `json:"paused"`
+	Done           bool    `json:"done"`
+	Err            error   `json:"-"` // Why the TWAP stopped early
+}
+
+// TWAPProgressHandler handles TWAP progress, reported after every slice and
+// when the TWAP stops
+type TWAPProgressHandler func(progress TWAPProgress)
+
+// TWAP slices a parent order into timed child basic orders, for venues or
+// sizes where the server-side TWAP algo is not available.
+//
+//	twap, err := client.NewTWAP(versifi.TWAPConfig{...})
+//	go twap.Run(ctx)
+//	twap.Pause()
+//	twap.Resume()
+//	twap.Cancel()
+//
+// Child orders already placed are not canceled when the TWAP stops.
+type TWAP struct {
+	c      *Client
+	cfg    TWAPConfig
+	total  decimal.Decimal
+	slice  decimal.Decimal
+	cancel chan struct{}
+
+	mu         sync.Mutex
+	started    bool
+	canceled   bool
+	paused     bool
+	resume     chan struct{}
+	sent       decimal.Decimal
+	slicesSent int
+	orderIDs   []int64
+	done       bool
+	err        error
+	handler    TWAPProgressHandler
+}
+
+// NewTWAP validates cfg and creates a TWAP that is started with Run
+func (c *Client) NewTWAP(cfg TWAPConfig) (*TWAP, error) {
+	if cfg.OrderType == "" {
+		cfg.OrderType = BasicOrderTypeMarket
+	}
+	if cfg.QuantityPrecision <= 0 {
+		cfg.QuantityPrecision = defaultTWAPQuantityPrecision
+	}
+
+	switch {
+	case cfg.Slices <= 0:
+		return nil, fmt.Errorf("twap: slices must be positive, got %d", cfg.Slices)
+	case cfg.Duration < 0:
+		return nil, fmt.Errorf("twap: negative duration %s", cfg.Duration)
+	case cfg.OrderType != BasicOrderTypeMarket && cfg.OrderType != BasicOrderTypeLimit:
+		return nil, fmt.Errorf("twap: unsupported order type %s", cfg.OrderType)
+	case cfg.OrderType == BasicOrderTypeLimit && cfg.Price == "":
+		return nil, errors.New("twap: LIMIT slices need a price")
+	}
+
+	total, err := decimal.NewFromString(cfg.Quantity)
+	if err != nil || !total.IsPositive() {
+		return nil, fmt.Errorf("twap: invalid quantity %q", cfg.Quantity)
+	}
+	slice := total.Div(decimal.NewFromInt(int64(cfg.Slices))).Truncate(cfg.QuantityPrecision)
+	if !slice.IsPositive() {
+		return nil, fmt.Errorf("twap: quantity %s is too small for %d slices", cfg.Quantity, cfg.Slices)
+	}
+
+	return &TWAP{
+		c:      c,
+		cfg:    cfg,
+		total:  total,
+		slice:  slice,
+		cancel: make(chan struct{}),
+	}, nil
+}
+
+// SetProgressHandler sets the handler for progress reports
+func (t *TWAP) SetProgressHandler(handler TWAPProgressHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handler = handler
+}
+
+// Run places the slices, the first immediately and the rest evenly over
+// Duration, and returns when every slice is placed, a child order fails,
+// ctx is done or Cancel is called
+func (t *TWAP) Run(ctx context.Context, opts ...RequestOption) error {
+	t.mu.Lock()
+	if t.started {
+		t.mu.Unlock()
+		return ErrTWAPStarted
+	}
+	t.started = true
+	t.mu.Unlock()
+
+	err := t.run(ctx, opts...)
+
+	t.mu.Lock()
+	t.done = true
+	t.err = err
+	t.mu.Unlock()
+	t.report()
+	return err
+}
+
+func (t *TWAP) run(ctx context.Context, opts ...RequestOption) error {
+	var interval time.Duration
+	if t.cfg.Slices > 1 {
+		interval = t.cfg.Duration / time.Duration(t.cfg.Slices-1)
+	}
+
+	for i := 0; i < t.cfg.Slices; i++ {
+		if i > 0 {
+			if err := t.wait(ctx, interval); err != nil {
+				return err
+			}
+		}
+		if err := t.waitResumed(ctx); err != nil {
+			return err
+		}
+
+		quantity := t.slice
+		if i == t.cfg.Slices-1 {
+			t.mu.Lock()
+			quantity = t.total.Sub(t.sent)
+			t.mu.Unlock()
+		}
+		res, err := t.place(ctx, quantity, opts...)
+		if err != nil {
+			return fmt.Errorf("twap slice %d: %w", i+1, err)
+		}
+
+		t.mu.Lock()
+		t.sent = t.sent.Add(quantity)
+		t.slicesSent++
+		t.orderIDs = append(t.orderIDs, res.OrderID)
+		t.mu.Unlock()
+		if i < t.cfg.Slices-1 {
+			t.report()
+		}
+	}
+	return nil
+}
+
+func (t *TWAP) place(ctx context.Context, quantity decimal.Decimal, opts ...RequestOption) (*OrderResponse, error) {
+	order := t.c.NewCreateBasicOrderService().
+		Exchange(t.cfg.Exchange).
+		Symbol(t.cfg.Symbol).
+		Side(t.cfg.Side).
+		OrderType(t.cfg.OrderType).
+		QuantityDecimal(quantity)
+	if t.cfg.OrderType == BasicOrderTypeLimit {
+		order.Price(t.cfg.Price)
+		if t.cfg.TimeInForce != "" {
+			order.TimeInForce(t.cfg.TimeInForce)
+		}
+	}
+	if t.cfg.AutoRound {
+		order.AutoRound()
+	}
+
+	if t.cfg.Tracker != nil {
+		return t.cfg.Tracker.Submit(ctx, order, opts...)
+	}
+	return order.Do(ctx, opts...)
+}
+
+// wait sleeps for d
+func (t *TWAP) wait(ctx context.Context, d time.Duration) error {
+	timer := t.c.clock().NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.cancel:
+		return ErrTWAPCanceled
+	case <-timer.C():
+		return nil
+	}
+}
+
+// waitResumed blocks while the TWAP is paused
+func (t *TWAP) waitResumed(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		paused, resume := t.paused, t.resume
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.cancel:
+			return ErrTWAPCanceled
+		default:
+		}
+		if !paused {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.cancel:
+			return ErrTWAPCanceled
+		case <-resume:
+		}
+	}
+}
+
+// Pause stops placing slices until Resume is called
+func (t *TWAP) Pause() {
+	t.mu.Lock()
+	if t.paused || t.done {
+		t.mu.Unlock()
+		return
+	}
+	t.paused = true
+	t.resume = make(chan struct{})
+	t.mu.Unlock()
+	t.report()
+}
+
+// Resume continues a paused TWAP
+func (t *TWAP) Resume() {
+	t.mu.Lock()
+	if !t.paused {
+		t.mu.Unlock()
+		return
+	}
+	t.paused = false
+	close(t.resume)
+	t.mu.Unlock()
+	t.report()
+}
+
+// Cancel stops the TWAP. Run returns ErrTWAPCanceled.
+func (t *TWAP) Cancel() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.canceled {
+		t.canceled = true
+		close(t.cancel)
+	}
+}
+
+// Progress returns the current progress
+func (t *TWAP) Progress() TWAPProgress {
+	t.mu.Lock()
+	p := TWAPProgress{
+		Slices:       t.cfg.Slices,
+		SlicesSent:   t.slicesSent,
+		Quantity:     t.total.String(),
+		SentQuantity: t.sent.String(),
+		OrderIDs:     append([]int64(nil), t.orderIDs...),
+		Paused:       t.paused,
+		Done:         t.done,
+		Err:          t.err,
+	}
+	t.mu.Unlock()
+
+	if t.cfg.Tracker != nil {
+		var filled decimal.Decimal
+		for _, id := range p.OrderIDs {
+			if o, ok := t.cfg.Tracker.Order(id); ok {
+				filled = filled.Add(toDecimal(o.FilledQuantity))
+			}
+		}
+		p.FilledQuantity = filled.String()
+	}
+	return p
+}
+
+func (t *TWAP) report() {
+	t.mu.Lock()
+	handler := t.handler
+	t.mu.Unlock()
+	if handler != nil {
+		handler(t.Progress())
+	}
+}
@@ -0,0 +1,109 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTWAP(t *testing.T) {
+	var mu sync.Mutex
+	var quantities []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body BasicOrderRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		quantities = append(quantities, body.Quantity)
+		id := int64(len(quantities))
+		mu.Unlock()
+		json.NewEncoder(w).Encode(OrderResponse{OrderID: id, Status: OrderStatusNew})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	if _, err := client.NewTWAP(TWAPConfig{Quantity: "1", Slices: 0}); err == nil {
+		t.Error("Expected error for zero slices")
+	}
+
+	twap, err := client.NewTWAP(TWAPConfig{
+		Exchange: ExchangeBinanceSpot,
+		Symbol:   "BTC/USDT",
+		Side:     SideTypeBuy,
+		Quantity: "1",
+		Duration: 20 * time.Millisecond,
+		Slices:   3,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var reports []TWAPProgress
+	twap.SetProgressHandler(func(p TWAPProgress) { reports = append(reports, p) })
+
+	if err := twap.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(quantities) != 3 || quantities[0] != "0.33333333" || quantities[2] != "0.33333334" {
+		t.Errorf("Unexpected slice quantities %v", quantities)
+	}
+	p := twap.Progress()
+	if !p.Done || p.SlicesSent != 3 || p.SentQuantity != "1" || len(p.OrderIDs) != 3 {
+		t.Errorf("Unexpected progress %+v", p)
+	}
+	if len(reports) != 3 || !reports[2].Done {
+		t.Errorf("Expected 3 progress reports, got %+v", reports)
+	}
+	if err := twap.Run(context.Background()); !errors.Is(err, ErrTWAPStarted) {
+		t.Errorf("Expected ErrTWAPStarted, got %v", err)
+	}
+}
+
+func TestTWAPPauseCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OrderResponse{OrderID: 1, Status: OrderStatusNew})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	twap, err := client.NewTWAP(TWAPConfig{
+		Exchange:  ExchangeBinanceSpot,
+		Symbol:    "BTC/USDT",
+		Side:      SideTypeSell,
+		Quantity:  "2",
+		OrderType: BasicOrderTypeLimit,
+		Price:     "100",
+		Duration:  10 * time.Millisecond,
+		Slices:    2,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	twap.Pause()
+	errc := make(chan error, 1)
+	go func() { errc <- twap.Run(context.Background()) }()
+
+	time.Sleep(30 * time.Millisecond)
+	if p := twap.Progress(); !p.Paused || p.SlicesSent != 0 {
+		t.Errorf("Expected paused TWAP to send nothing, got %+v", p)
+	}
+
+	twap.Cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrTWAPCanceled) {
+			t.Errorf("Expected ErrTWAPCanceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Cancel")
+	}
+}
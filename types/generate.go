@@ -0,0 +1,7 @@
+// Package types holds the request, response and enum types of the Versifi
+// REST API, generated from openapi.yaml. Edit the spec and run go generate
+// to update them; the versifi package checks its hand-written types against
+// these so the two cannot drift apart.
+package types
+
+//go:generate go run ../internal/typegen -spec openapi.yaml -out types.go -package types
@@ -0,0 +1,6 @@
+package types
+
+// Timestamp is an epoch time as the API sends it: milliseconds in response
+// timestamps and microseconds in start_time. The versifi package decodes it
+// in any precision as versifi.Timestamp.
+type Timestamp int64
@@ -0,0 +1,296 @@
+// Code generated by typegen from openapi.yaml. DO NOT EDIT.
+
+package types
+
+import (
+	"encoding/json"
+)
+
+// SideType represents order side
+type SideType string
+
+// SideType values
+const (
+	SideTypeBuy  SideType = "BUY"
+	SideTypeSell SideType = "SELL"
+)
+
+// ExchangeType represents exchange type
+type ExchangeType string
+
+// ExchangeType values
+const (
+	ExchangeBinanceSpot    ExchangeType = "BINANCE_SPOT"
+	ExchangeBinanceFutures ExchangeType = "BINANCE_FUTURES"
+	ExchangeOKXSpot        ExchangeType = "OKX_SPOT"
+	ExchangeOKXFutures     ExchangeType = "OKX_FUTURES"
+)
+
+// AlgoOrderType represents algo order type
+type AlgoOrderType string
+
+// AlgoOrderType values
+const (
+	AlgoOrderTypeTWAP AlgoOrderType = "TWAP"
+	AlgoOrderTypeVWAP AlgoOrderType = "VWAP"
+	AlgoOrderTypeIS   AlgoOrderType = "IS"
+)
+
+// BasicOrderType represents basic order type
+type BasicOrderType string
+
+// BasicOrderType values
+const (
+	BasicOrderTypeMarket          BasicOrderType = "MARKET"
+	BasicOrderTypeLimit           BasicOrderType = "LIMIT"
+	BasicOrderTypeStop            BasicOrderType = "STOP"
+	BasicOrderTypeStopLoss        BasicOrderType = "STOP_LOSS"
+	BasicOrderTypeStopLossLimit   BasicOrderType = "STOP_LOSS_LIMIT"
+	BasicOrderTypeTakeProfit      BasicOrderType = "TAKE_PROFIT"
+	BasicOrderTypeTakeProfitLimit BasicOrderType = "TAKE_PROFIT_LIMIT"
+	BasicOrderTypeLimitMaker      BasicOrderType = "LIMIT_MAKER"
+)
+
+// PairOrderType represents pair order type
+type PairOrderType string
+
+// PairOrderType values
+const (
+	PairOrderTypeBasis PairOrderType = "BASIS"
+)
+
+// TimeInForceType represents time in force
+type TimeInForceType string
+
+// TimeInForceType values
+const (
+	TimeInForceFOK    TimeInForceType = "FOK"
+	TimeInForceGTC    TimeInForceType = "GTC"
+	TimeInForceGTD    TimeInForceType = "GTD"
+	TimeInForceIOC    TimeInForceType = "IOC"
+	TimeInForceGTX    TimeInForceType = "GTX"
+	TimeInForcePostOn TimeInForceType = "POST_ON"
+)
+
+// OrderStatusType represents order status
+type OrderStatusType string
+
+// OrderStatusType values
+const (
+	OrderStatusNew             OrderStatusType = "NEW"
+	OrderStatusPartiallyFilled OrderStatusType = "PARTIALLY_FILLED"
+	OrderStatusFilled          OrderStatusType = "FILLED"
+	OrderStatusCanceled        OrderStatusType = "CANCELED"
+	OrderStatusRejected        OrderStatusType = "REJECTED"
+	OrderStatusExpired         OrderStatusType = "EXPIRED"
+)
+
+// PairStyleType represents pair order style
+type PairStyleType string
+
+// PairStyleType values
+const (
+	PairStyleSync  PairStyleType = "SYNC"
+	PairStyleAsync PairStyleType = "ASYNC"
+	PairStyleTWAP  PairStyleType = "TWAP"
+)
+
+// APIError represents an error from the Versifi API
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// BasicOrderRequest represents the request body for creating a basic order
+type BasicOrderRequest struct {
+	ClientOrderID *int64         `json:"client_order_id,omitempty"`
+	Exchange      ExchangeType   `json:"exchange"`
+	OrderType     BasicOrderType `json:"order_type"`
+	Price         *string        `json:"price,omitempty"`
+	Quantity      string         `json:"quantity"`
+	Side          SideType       `json:"side"`
+	// epoch microseconds
+	StartTime     *Timestamp       `json:"start_time,omitempty"`
+	StopPrice     *string          `json:"stop_price,omitempty"`
+	Symbol        string           `json:"symbol"`
+	TIF           *TimeInForceType `json:"tif,omitempty"`
+	TrailingDelta *string          `json:"trailing_delta,omitempty"`
+}
+
+// AlgoOrderRequest represents the request body for creating an algo order
+type AlgoOrderRequest struct {
+	ClientOrderID *int64        `json:"client_order_id,omitempty"`
+	Exchange      ExchangeType  `json:"exchange"`
+	OrderType     AlgoOrderType `json:"order_type"`
+	// Algorithm parameters such as duration
+	Params   map[string]interface{} `json:"params,omitempty"`
+	Quantity string                 `json:"quantity"`
+	Side     SideType               `json:"side"`
+	Symbol   string                 `json:"symbol"`
+}
+
+// PairLeg represents a leg in a pair order
+type PairLeg struct {
+	Exchange         ExchangeType           `json:"exchange"`
+	Symbol           string                 `json:"symbol"`
+	OrderType        string                 `json:"order_type,omitempty"`
+	LegRatio         *float64               `json:"leg_ratio,omitempty"`
+	MaxPositionLong  *string                `json:"max_position_long,omitempty"`
+	MaxPositionShort *string                `json:"max_position_short,omitempty"`
+	MaxNotionalLong  *string                `json:"max_notional_long,omitempty"`
+	MaxNotionalShort *string                `json:"max_notional_short,omitempty"`
+	Params           map[string]interface{} `json:"params,omitempty"`
+}
+
+// PairOrderLeadFull represents the lead leg with all parameters
+type PairOrderLeadFull struct {
+	OrderType PairOrderType          `json:"order_type"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+	Exchange  ExchangeType           `json:"exchange,omitempty"`
+	Symbol    string                 `json:"symbol,omitempty"`
+	LegRatio  *float64               `json:"leg_ratio,omitempty"`
+}
+
+// PairOrderRequestFull represents the request body for creating a pair order
+type PairOrderRequestFull struct {
+	ClientOrderID *int64             `json:"client_order_id,omitempty"`
+	Lead          *PairOrderLeadFull `json:"lead"`
+	Secondary     *PairLeg           `json:"secondary,omitempty"`
+	Style         *PairStyleType     `json:"style,omitempty"`
+}
+
+// CancelBatchRequest represents the request body for canceling orders
+type CancelBatchRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// OrderResponse represents the common order response structure
+type OrderResponse struct {
+	OrderID       int64           `json:"order_id"`
+	ClientOrderID int64           `json:"client_order_id"`
+	Status        OrderStatusType `json:"status"`
+	Lead          *LegResponse    `json:"lead,omitempty"`
+	Secondary     *LegResponse    `json:"secondary,omitempty"`
+}
+
+// LegResponse represents a leg in the order response
+type LegResponse struct {
+	LegID  int64           `json:"leg_id"`
+	Status OrderStatusType `json:"status"`
+}
+
+// ListOrderItem represents an order in the open order list
+type ListOrderItem struct {
+	OrderID       int64  `json:"order_id"`
+	ClientOrderID int64  `json:"client_order_id"`
+	Status        string `json:"status"`
+	// epoch milliseconds
+	Timestamp        Timestamp `json:"timestamp"`
+	RequestOrderType string    `json:"request_order_type"`
+	RejectReason     string    `json:"reject_reason"`
+}
+
+// GetOrderResponse represents the response structure for getting an order
+type GetOrderResponse struct {
+	OrderID       int64           `json:"order_id"`
+	ClientOrderID int64           `json:"client_order_id"`
+	OrderType     string          `json:"order_type"`
+	Status        OrderStatusType `json:"status"`
+	// epoch milliseconds
+	Timestamp        Timestamp         `json:"timestamp"`
+	RequestOrderType string            `json:"request_order_type"`
+	AlgoOrder        *AlgoOrderDetail  `json:"algo_order,omitempty"`
+	BasicOrder       *BasicOrderDetail `json:"basic_order,omitempty"`
+	PairOrder        *PairOrderDetail  `json:"pair_order,omitempty"`
+}
+
+// AlgoOrderDetail represents algo order details
+type AlgoOrderDetail struct {
+	Exchange           ExchangeType    `json:"exchange"`
+	OrderType          AlgoOrderType   `json:"order_type"`
+	Quantity           string          `json:"quantity"`
+	QuoteOrderQuantity string          `json:"quote_order_quantity,omitempty"`
+	Side               SideType        `json:"side"`
+	Symbol             string          `json:"symbol"`
+	OrderParams        json.RawMessage `json:"order_params,omitempty"`
+	AveragePrice       string          `json:"average_price,omitempty"`
+	FilledQuantity     string          `json:"filled_quantity,omitempty"`
+	RejectReason       string          `json:"reject_reason,omitempty"`
+	TIF                TimeInForceType `json:"tif,omitempty"`
+	ChildOrders        []ChildOrder    `json:"child_orders,omitempty"`
+}
+
+// BasicOrderDetail represents basic order details
+type BasicOrderDetail struct {
+	Exchange           ExchangeType    `json:"exchange"`
+	OrderType          BasicOrderType  `json:"order_type"`
+	Price              string          `json:"price,omitempty"`
+	Quantity           string          `json:"quantity"`
+	QuoteOrderQuantity string          `json:"quote_order_quantity,omitempty"`
+	Side               SideType        `json:"side"`
+	StopPrice          string          `json:"stop_price,omitempty"`
+	Symbol             string          `json:"symbol"`
+	TIF                TimeInForceType `json:"tif,omitempty"`
+	TrailingDelta      string          `json:"trailing_delta,omitempty"`
+	AveragePrice       string          `json:"average_price,omitempty"`
+	FilledQuantity     string          `json:"filled_quantity,omitempty"`
+	RejectReason       string          `json:"reject_reason,omitempty"`
+	ChildOrders        []ChildOrder    `json:"child_orders,omitempty"`
+}
+
+// PairOrderDetail represents pair order details
+type PairOrderDetail struct {
+	LeadLeg      *PairLegDetail  `json:"lead_leg,omitempty"`
+	Secondary    *PairLegDetail  `json:"leg,omitempty"`
+	Params       json.RawMessage `json:"params,omitempty"`
+	RejectReason string          `json:"reject_reason,omitempty"`
+	Style        PairStyleType   `json:"style,omitempty"`
+}
+
+// PairLegDetail represents details of a pair leg
+type PairLegDetail struct {
+	Symbol           string       `json:"symbol"`
+	Exchange         ExchangeType `json:"exchange"`
+	OrderType        string       `json:"order_type"`
+	LegRatio         float64      `json:"leg_ratio"`
+	MaxPositionLong  string       `json:"max_position_long,omitempty"`
+	MaxPositionShort string       `json:"max_position_short,omitempty"`
+	MaxNotionalLong  string       `json:"max_notional_long,omitempty"`
+	MaxNotionalShort string       `json:"max_notional_short,omitempty"`
+	ChildOrders      []ChildOrder `json:"child_order,omitempty"`
+}
+
+// ChildOrder represents a child order and its trades
+type ChildOrder struct {
+	ID              int64           `json:"id,omitempty"`
+	ChildOrderID    int64           `json:"child_order_id,omitempty"`
+	OrderID         int64           `json:"order_id,omitempty"`
+	Exchange        ExchangeType    `json:"exchange,omitempty"`
+	ExchangeOrderID string          `json:"exchange_order_id,omitempty"`
+	Symbol          string          `json:"symbol,omitempty"`
+	OrderType       string          `json:"order_type,omitempty"`
+	Price           string          `json:"price,omitempty"`
+	Quantity        string          `json:"quantity,omitempty"`
+	Side            SideType        `json:"side,omitempty"`
+	OrderStatus     OrderStatusType `json:"order_status,omitempty"`
+	AveragePrice    string          `json:"average_price,omitempty"`
+	FilledQuantity  string          `json:"filled_quantity,omitempty"`
+	RejectReason    string          `json:"reject_reason,omitempty"`
+	LegID           int64           `json:"leg_id,omitempty"`
+	Trades          []Trade         `json:"trades,omitempty"`
+}
+
+// Trade represents a trade execution
+type Trade struct {
+	TradeID         int64        `json:"trade_id"`
+	OrderID         int64        `json:"order_id"`
+	ChildOrderID    int64        `json:"child_order_id"`
+	ExchangeTradeID string       `json:"exchange_trade_id"`
+	Exchange        ExchangeType `json:"exchange"`
+	Symbol          string       `json:"symbol"`
+	Price           string       `json:"price"`
+	Quantity        string       `json:"quantity"`
+	Side            SideType     `json:"side"`
+	Fee             string       `json:"fee"`
+	LegID           int64        `json:"leg_id"`
+}
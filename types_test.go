@@ -0,0 +1,109 @@
+package versifi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/drinkthere/versifi-go/types"
+)
+
+// TestTypesMatchSpec checks the hand-written request and response types
+// against those generated from types/openapi.yaml, so neither drifts from
+// the API as it evolves
+func TestTypesMatchSpec(t *testing.T) {
+	for _, pair := range [][2]interface{}{
+		{APIError{}, types.APIError{}},
+		{BasicOrderRequest{}, types.BasicOrderRequest{}},
+		{AlgoOrderRequest{}, types.AlgoOrderRequest{}},
+		{PairLeg{}, types.PairLeg{}},
+		{PairOrderLeadFull{}, types.PairOrderLeadFull{}},
+		{PairOrderRequestFull{}, types.PairOrderRequestFull{}},
+		{CancelBatchRequest{}, types.CancelBatchRequest{}},
+		{OrderResponse{}, types.OrderResponse{}},
+		{LegResponse{}, types.LegResponse{}},
+		{ListOrderItem{}, types.ListOrderItem{}},
+		{GetOrderResponse{}, types.GetOrderResponse{}},
+		{AlgoOrderDetail{}, types.AlgoOrderDetail{}},
+		{BasicOrderDetail{}, types.BasicOrderDetail{}},
+		{PairOrderDetail{}, types.PairOrderDetail{}},
+		{PairLegDetail{}, types.PairLegDetail{}},
+		{ChildOrder{}, types.ChildOrder{}},
+		{Trade{}, types.Trade{}},
+	} {
+		sdk, gen := taggedFields(reflect.TypeOf(pair[0])), taggedFields(reflect.TypeOf(pair[1]))
+		name := reflect.TypeOf(pair[0]).Name()
+		for tag, typ := range gen {
+			if sdk[tag] != typ {
+				t.Errorf("%s: spec has %s %s, SDK has %q", name, tag, typ, sdk[tag])
+			}
+		}
+		for tag, typ := range sdk {
+			if _, ok := gen[tag]; !ok {
+				t.Errorf("%s: SDK has %s %s, not in the spec", name, tag, typ)
+			}
+		}
+	}
+
+	sdk, gen := enumConsts(t, "common.go"), enumConsts(t, "types/types.go")
+	for name, value := range gen {
+		if sdk[name] != value {
+			t.Errorf("spec has %s = %s, SDK has %q", name, value, sdk[name])
+		}
+	}
+	for name, value := range sdk {
+		if _, ok := gen[name]; !ok {
+			t.Errorf("SDK has %s = %s, not in the spec", name, value)
+		}
+	}
+}
+
+// taggedFields maps the JSON tags of a struct to its field names and types,
+// with package qualifiers removed
+func taggedFields(typ reflect.Type) map[string]string {
+	fields := make(map[string]string)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		ftype := strings.NewReplacer("versifi.", "", "types.", "").Replace(f.Type.String())
+		fields[tag] = f.Name + " " + ftype
+	}
+	return fields
+}
+
+// enumConsts returns the typed string constants declared in a file
+func enumConsts(t *testing.T, path string) map[string]string {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consts := make(map[string]string)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if vs.Type == nil || len(vs.Values) != len(vs.Names) {
+				continue
+			}
+			for i, name := range vs.Names {
+				lit, ok := vs.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				value, _ := strconv.Unquote(lit.Value)
+				consts[name.Name] = vs.Type.(*ast.Ident).Name + "(" + value + ")"
+			}
+		}
+	}
+	return consts
+}
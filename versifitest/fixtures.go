@@ -0,0 +1,364 @@
+package versifitest
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// FixtureTimestamp is the timestamp of fixture orders, in milliseconds,
+// unless set with WithTimestamp. It is fixed so fixtures compare equal
+// across runs.
+const FixtureTimestamp int64 = 1700000000000
+
+// FixtureOption changes an order built by a fixture function
+type FixtureOption func(f *fixture)
+
+type fixture struct {
+	orderID       int64
+	clientOrderID int64
+	exchange      versifi.ExchangeType
+	symbol        string
+	side          versifi.SideType
+	quantity      string
+	price         string
+	filled        string
+	fee           string
+	status        versifi.OrderStatusType
+	timestamp     int64
+	rejectReason  string
+	basicType     versifi.BasicOrderType
+	algoType      versifi.AlgoOrderType
+}
+
+// WithOrderID sets the order ID, 1 by default. Child order and trade IDs
+// are derived from it.
+func WithOrderID(orderID int64) FixtureOption {
+	return func(f *fixture) { f.orderID = orderID }
+}
+
+// WithClientOrderID sets the client order ID
+func WithClientOrderID(clientOrderID int64) FixtureOption {
+	return func(f *fixture) { f.clientOrderID = clientOrderID }
+}
+
+// WithInstrument sets the exchange and symbol, binance_spot BTC/USDT by
+// default
+func WithInstrument(exchange versifi.ExchangeType, symbol string) FixtureOption {
+	return func(f *fixture) { f.exchange, f.symbol = exchange, symbol }
+}
+
+// WithSide sets the side, BUY by default
+func WithSide(side versifi.SideType) FixtureOption {
+	return func(f *fixture) { f.side = side }
+}
+
+// WithQuantity sets the order quantity, 1 by default
+func WithQuantity(quantity string) FixtureOption {
+	return func(f *fixture) { f.quantity = quantity }
+}
+
+// WithPrice sets the limit and fill price, 100 by default
+func WithPrice(price string) FixtureOption {
+	return func(f *fixture) { f.price = price }
+}
+
+// WithFilled sets the filled quantity, executed in a single trade. Unless
+// set with WithStatus, the status follows from it.
+func WithFilled(quantity string) FixtureOption {
+	return func(f *fixture) { f.filled = quantity }
+}
+
+// WithFee sets the fee of the trade, 0 by default
+func WithFee(fee string) FixtureOption {
+	return func(f *fixture) { f.fee = fee }
+}
+
+// WithStatus sets the status
+func WithStatus(status versifi.OrderStatusType) FixtureOption {
+	return func(f *fixture) { f.status = status }
+}
+
+// WithTimestamp sets the timestamp in milliseconds
+func WithTimestamp(timestamp int64) FixtureOption {
+	return func(f *fixture) { f.timestamp = timestamp }
+}
+
+// WithRejectReason rejects the order with reason
+func WithRejectReason(reason string) FixtureOption {
+	return func(f *fixture) {
+		f.rejectReason = reason
+		f.status = versifi.OrderStatusRejected
+	}
+}
+
+// WithBasicOrderType sets the type of a basic order, LIMIT by default
+func WithBasicOrderType(orderType versifi.BasicOrderType) FixtureOption {
+	return func(f *fixture) { f.basicType = orderType }
+}
+
+// WithAlgoOrderType sets the type of an algo order, TWAP by default
+func WithAlgoOrderType(orderType versifi.AlgoOrderType) FixtureOption {
+	return func(f *fixture) { f.algoType = orderType }
+}
+
+// newFixture applies opts to the defaults. With fillAll, the whole
+// quantity is filled unless WithFilled says otherwise.
+func newFixture(fillAll bool, opts []FixtureOption) *fixture {
+	f := &fixture{
+		orderID:   1,
+		exchange:  versifi.ExchangeBinanceSpot,
+		symbol:    "BTC/USDT",
+		side:      versifi.SideTypeBuy,
+		quantity:  "1",
+		price:     "100",
+		fee:       "0",
+		timestamp: FixtureTimestamp,
+		basicType: versifi.BasicOrderTypeLimit,
+		algoType:  versifi.AlgoOrderTypeTWAP,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if fillAll && f.filled == "" {
+		f.filled = f.quantity
+	}
+
+	if f.status == "" {
+		filled := toDecimal(f.filled)
+		switch {
+		case filled.IsZero():
+			f.status = versifi.OrderStatusNew
+		case filled.LessThan(toDecimal(f.quantity)):
+			f.status = versifi.OrderStatusPartiallyFilled
+		default:
+			f.status = versifi.OrderStatusFilled
+		}
+	}
+	return f
+}
+
+// BasicOrder returns a NEW LIMIT basic order buying 1 BTC/USDT at 100 on
+// binance_spot, changed by opts
+func BasicOrder(opts ...FixtureOption) *versifi.GetOrderResponse {
+	f := newFixture(false, opts)
+	return f.basic()
+}
+
+// FilledBasicOrder returns a basic order as BasicOrder, filled in one trade
+func FilledBasicOrder(opts ...FixtureOption) *versifi.GetOrderResponse {
+	f := newFixture(true, opts)
+	return f.basic()
+}
+
+// AlgoOrder returns a NEW TWAP algo order buying 1 BTC/USDT on
+// binance_spot, changed by opts
+func AlgoOrder(opts ...FixtureOption) *versifi.GetOrderResponse {
+	f := newFixture(false, opts)
+	return f.algo()
+}
+
+// FilledAlgoOrder returns an algo order as AlgoOrder, filled at 100 in one
+// trade
+//
+//	server.Handle("GET", "/v2/orders/7").RespondJSON(
+//		versifitest.FilledAlgoOrder(versifitest.WithOrderID(7), versifitest.WithPrice("101.5")))
+func FilledAlgoOrder(opts ...FixtureOption) *versifi.GetOrderResponse {
+	f := newFixture(true, opts)
+	return f.algo()
+}
+
+func (f *fixture) basic() *versifi.GetOrderResponse {
+	res := f.order(versifi.RequestOrderTypeBasic, string(f.basicType))
+	res.BasicOrder = &versifi.BasicOrderDetail{
+		Exchange:       f.exchange,
+		OrderType:      f.basicType,
+		Quantity:       f.quantity,
+		Side:           f.side,
+		Symbol:         f.symbol,
+		AveragePrice:   f.averagePrice(),
+		FilledQuantity: f.filled,
+		RejectReason:   f.rejectReason,
+		ChildOrders:    f.children(),
+	}
+	if f.basicType != versifi.BasicOrderTypeMarket {
+		res.BasicOrder.Price = f.price
+	}
+	return res
+}
+
+func (f *fixture) algo() *versifi.GetOrderResponse {
+	res := f.order(versifi.RequestOrderTypeAlgo, string(f.algoType))
+	res.AlgoOrder = &versifi.AlgoOrderDetail{
+		Exchange:       f.exchange,
+		OrderType:      f.algoType,
+		Quantity:       f.quantity,
+		Side:           f.side,
+		Symbol:         f.symbol,
+		AveragePrice:   f.averagePrice(),
+		FilledQuantity: f.filled,
+		RejectReason:   f.rejectReason,
+		ChildOrders:    f.children(),
+	}
+	return res
+}
+
+func (f *fixture) order(requestOrderType, orderType string) *versifi.GetOrderResponse {
+	return &versifi.GetOrderResponse{
+		OrderID:          f.orderID,
+		ClientOrderID:    f.clientOrderID,
+		OrderType:        orderType,
+		Status:           f.status,
+		Timestamp:        versifi.NewTimestamp(time.UnixMilli(f.timestamp), versifi.PrecisionMilliseconds),
+		RequestOrderType: strings.ToUpper(requestOrderType),
+	}
+}
+
+func (f *fixture) averagePrice() string {
+	if toDecimal(f.filled).IsZero() {
+		return ""
+	}
+	return f.price
+}
+
+// children returns one child order holding a trade for the filled
+// quantity, if any
+func (f *fixture) children() []versifi.ChildOrder {
+	childID := f.orderID * 10
+	child := versifi.ChildOrder{
+		ID:             childID,
+		ChildOrderID:   childID,
+		OrderID:        f.orderID,
+		Exchange:       f.exchange,
+		Symbol:         f.symbol,
+		Price:          f.price,
+		Quantity:       f.quantity,
+		Side:           f.side,
+		OrderStatus:    f.status,
+		AveragePrice:   f.averagePrice(),
+		FilledQuantity: f.filled,
+		RejectReason:   f.rejectReason,
+	}
+	if !toDecimal(f.filled).IsZero() {
+		child.Trades = []versifi.Trade{{
+			TradeID:         childID*10 + 1,
+			OrderID:         f.orderID,
+			ChildOrderID:    childID,
+			ExchangeTradeID: strconv.FormatInt(childID*10+1, 10),
+			Exchange:        f.exchange,
+			Symbol:          f.symbol,
+			Price:           f.price,
+			Quantity:        f.filled,
+			Side:            f.side,
+			Fee:             f.fee,
+		}}
+	}
+	return []versifi.ChildOrder{child}
+}
+
+// ExecutionReport returns the execution_report message a venue would send
+// for order in its current state, with the trades of its first child
+// order, as for an order built by a fixture function:
+//
+//	report := versifitest.ExecutionReport(versifitest.FilledBasicOrder())
+//	server.Ws.SendExecutionReport(report.Message)
+func ExecutionReport(order *versifi.GetOrderResponse) *versifi.WsExecutionReport {
+	detail := versifi.WsExecutionReportDetail{
+		OrderID:          order.OrderID,
+		ClientOrderID:    order.ClientOrderID,
+		OrderType:        order.OrderType,
+		Status:           order.Status,
+		Timestamp:        order.Timestamp,
+		RequestOrderType: order.RequestOrderType,
+	}
+
+	switch {
+	case order.BasicOrder != nil:
+		b := order.BasicOrder
+		detail.Basic = &versifi.WsBasicOrderDetail{
+			QuoteOrderQuantity: b.QuoteOrderQuantity,
+			Symbol:             b.Symbol,
+			ClientOrderID:      order.ClientOrderID,
+			StopPrice:          b.StopPrice,
+			Exchange:           b.Exchange,
+			Price:              b.Price,
+			Quantity:           b.Quantity,
+			Side:               b.Side,
+			OrderType:          b.OrderType,
+			ChildOrder:         wsChildOrder(b.ChildOrders),
+		}
+	case order.AlgoOrder != nil:
+		a := order.AlgoOrder
+		detail.Algo = &versifi.WsAlgoOrderDetail{
+			ID:                 order.OrderID,
+			Exchange:           a.Exchange,
+			OrderType:          a.OrderType,
+			Quantity:           a.Quantity,
+			QuoteOrderQuantity: a.QuoteOrderQuantity,
+			Side:               a.Side,
+			Symbol:             a.Symbol,
+			OrderParams:        a.OrderParams,
+			ChildOrder:         wsChildOrder(a.ChildOrders),
+		}
+	case order.PairOrder != nil:
+		p := order.PairOrder
+		detail.Pair = &versifi.WsPairOrderDetail{Params: p.Params}
+		if p.LeadLeg != nil {
+			detail.Pair.LeadLeg = wsPairLeg(p.LeadLeg)
+		}
+		if p.Secondary != nil {
+			detail.Pair.Leg = wsPairLeg(p.Secondary)
+		}
+	}
+	return &versifi.WsExecutionReport{Op: "execution_report", Success: true, Message: detail}
+}
+
+func wsPairLeg(leg *versifi.PairLegDetail) *versifi.WsPairLeg {
+	return &versifi.WsPairLeg{
+		Symbol:           leg.Symbol,
+		Exchange:         leg.Exchange,
+		OrderType:        leg.OrderType,
+		LegRatio:         leg.LegRatio,
+		MaxPositionLong:  leg.MaxPositionLong,
+		MaxPositionShort: leg.MaxPositionShort,
+		MaxNotionalLong:  leg.MaxNotionalLong,
+		MaxNotionalShort: leg.MaxNotionalShort,
+		ChildOrder:       wsChildOrder(leg.ChildOrders),
+	}
+}
+
+// wsChildOrder converts the first child order, with running fill totals
+// on each trade as the websocket reports them
+func wsChildOrder(children []versifi.ChildOrder) *versifi.WsChildOrder {
+	if len(children) == 0 {
+		return nil
+	}
+	child := children[0]
+	ws := &versifi.WsChildOrder{ID: child.ID, Trades: []versifi.WsTrade{}}
+
+	var filled, notional decimal.Decimal
+	for _, t := range child.Trades {
+		qty, price := toDecimal(t.Quantity), toDecimal(t.Price)
+		filled = filled.Add(qty)
+		notional = notional.Add(qty.Mul(price))
+		trade := versifi.WsTrade{
+			TradeID:                   t.TradeID,
+			CummulativeFilledQuantity: decimalString(filled),
+			OrderID:                   t.OrderID,
+			ExecutedPrice:             t.Price,
+			ExecutedQuantity:          t.Quantity,
+		}
+		if !filled.IsZero() {
+			trade.AveragePrice = notional.Div(filled).String()
+		}
+		if t.LegID != 0 {
+			trade.LegID = versifi.Int64Ptr(t.LegID)
+		}
+		ws.Trades = append(ws.Trades, trade)
+	}
+	return ws
+}
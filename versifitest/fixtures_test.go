@@ -0,0 +1,64 @@
+package versifitest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func TestFixtures(t *testing.T) {
+	order := FilledAlgoOrder(WithOrderID(7), WithQuantity("2"), WithPrice("101.5"), WithSide(versifi.SideTypeSell))
+	if order.Status != versifi.OrderStatusFilled || order.RequestOrderType != "ALGO" || order.OrderType != "TWAP" {
+		t.Fatalf("Unexpected order %+v", order)
+	}
+	algo := order.AlgoOrder
+	if algo.FilledQuantity != "2" || algo.AveragePrice != "101.5" || len(algo.ChildOrders) != 1 ||
+		len(algo.ChildOrders[0].Trades) != 1 || algo.ChildOrders[0].Trades[0].Side != versifi.SideTypeSell {
+		t.Errorf("Unexpected algo order %+v", algo)
+	}
+
+	if s := BasicOrder(WithFilled("0.5")).Status; s != versifi.OrderStatusPartiallyFilled {
+		t.Errorf("Expected PARTIALLY_FILLED, got %s", s)
+	}
+	if s := BasicOrder(WithRejectReason("insufficient balance")); s.Status != versifi.OrderStatusRejected || s.BasicOrder.RejectReason == "" {
+		t.Errorf("Unexpected rejected order %+v", s)
+	}
+	if p := FilledBasicOrder(WithBasicOrderType(versifi.BasicOrderTypeMarket)).BasicOrder.Price; p != "" {
+		t.Errorf("Expected no price on a market order, got %q", p)
+	}
+
+	// The report survives the wire and is what the tracker expects
+	data, err := json.Marshal(ExecutionReport(order))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report versifi.WsExecutionReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	tracker := versifi.NewOrderTracker(versifi.NewClient("test-key", "test-secret"))
+	tracker.ApplyExecutionReport(&report.Message)
+	s, _ := tracker.Order(7)
+	if s.Status != versifi.OrderStatusFilled || s.FilledQuantity != "2" || s.AveragePrice != "101.5" || s.Side != versifi.SideTypeSell {
+		t.Errorf("Unexpected tracked state %+v", s)
+	}
+}
+
+func TestFixturesWithMockServer(t *testing.T) {
+	server := NewMockServer()
+	defer server.Close()
+	server.Handle(http.MethodGet, "/v2/orders/3").RespondJSON(FilledBasicOrder(WithOrderID(3)))
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	res, err := client.NewGetOrderService().OrderID(3).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.OrderID != 3 || res.BasicOrder == nil || res.BasicOrder.FilledQuantity != "1" {
+		t.Errorf("Unexpected order %+v", res)
+	}
+}
@@ -0,0 +1,375 @@
+package versifitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// Response is a canned reply of a MockServer
+type Response struct {
+	// Status defaults to 200
+	Status int
+	// Body is written as is when it is a []byte or string, and encoded as
+	// JSON otherwise. A nil Body writes nothing.
+	Body   interface{}
+	Header http.Header
+	// Delay holds the response back, on top of the route and server
+	// latency
+	Delay time.Duration
+	// Drop closes the connection without responding, so the client sees a
+	// network error
+	Drop bool
+}
+
+// JSON is a response with status encoding body as JSON
+func JSON(status int, body interface{}) Response {
+	return Response{Status: status, Body: body}
+}
+
+// Error is an API error response, as the client decodes into an APIError
+func Error(status int, message string) Response {
+	return Response{Status: status, Body: versifi.APIError{Code: status, Message: message}}
+}
+
+// Drop is a response closing the connection without a reply
+func Drop() Response {
+	return Response{Drop: true}
+}
+
+// Request is a request received by a MockServer
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+	// Route is the pattern of the route that handled the request, or ""
+	// if none matched
+	Route string
+}
+
+// Decode decodes the JSON body into v
+func (r Request) Decode(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Route answers the requests matching a method and path pattern. Its
+// methods return the route so they can be chained.
+type Route struct {
+	method  string
+	pattern string
+
+	mu        *sync.Mutex // the server's
+	responses []Response
+	handler   func(Request) Response
+	delay     time.Duration
+	expect    []func(Request) error
+	calls     int
+}
+
+// Respond scripts the responses: the n-th call gets the n-th response and
+// calls past the end repeat the last one, so a single response is canned.
+//
+//	server.Handle("POST", "/v2/orders/basic").Respond(
+//		versifitest.Error(503, "unavailable"),
+//		versifitest.JSON(200, versifi.OrderResponse{OrderID: 1}),
+//	)
+func (r *Route) Respond(responses ...Response) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append([]Response(nil), responses...)
+	r.handler = nil
+	return r
+}
+
+// RespondJSON cans a 200 response encoding body
+func (r *Route) RespondJSON(body interface{}) *Route {
+	return r.Respond(JSON(http.StatusOK, body))
+}
+
+// RespondFunc computes each response from the request
+func (r *Route) RespondFunc(fn func(Request) Response) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handler = fn
+	r.responses = nil
+	return r
+}
+
+// Delay holds every response of the route back by d
+func (r *Route) Delay(d time.Duration) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delay = d
+	return r
+}
+
+// Expect checks every request of the route with fn. Requests failing the
+// check are answered with a 400 error and reported by AssertExpectations.
+func (r *Route) Expect(fn func(Request) error) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expect = append(r.expect, fn)
+	return r
+}
+
+// MockServer is a programmable fake of the REST API, for tests of code
+// built on the client that need exact control over responses rather than
+// the order simulation of Server. Requests are matched against the routes
+// registered with Handle; unmatched requests get a 404 API error.
+//
+//	server := versifitest.NewMockServer()
+//	defer server.Close()
+//	server.Handle("POST", "/v2/orders/basic").RespondJSON(versifi.OrderResponse{OrderID: 1})
+//	server.Handle("GET", "/v2/orders/*").Respond(versifitest.Drop())
+//
+//	client := versifi.NewClient("key", "secret")
+//	client.BaseURL = server.URL
+//	...
+//	server.AssertCalled(t, "POST", "/v2/orders/basic", 1)
+//	server.AssertExpectations(t)
+//
+// Credentials and signatures are not checked; use Request.Header to assert
+// them.
+type MockServer struct {
+	// URL is the http:// address of the server
+	URL string
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	routes   []*Route
+	requests []Request
+	failures []string
+	latency  time.Duration
+}
+
+// NewMockServer starts a server with no routes
+func NewMockServer() *MockServer {
+	s := &MockServer{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the server
+func (s *MockServer) Close() {
+	s.server.Close()
+}
+
+// Handle returns the route for method and path, creating it if needed. A
+// path segment "*" matches any single segment, as in "/v2/orders/*".
+// Routes are matched in the order they were created.
+func (s *MockServer) Handle(method, path string) *Route {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pattern := strings.TrimSuffix(path, "/")
+	for _, r := range s.routes {
+		if r.method == method && r.pattern == pattern {
+			return r
+		}
+	}
+	r := &Route{method: method, pattern: pattern, mu: &s.mu}
+	s.routes = append(s.routes, r)
+	return r
+}
+
+// SetLatency holds every response back by d, to test timeouts and
+// latency-sensitive code
+func (s *MockServer) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Reset removes every route and forgets the recorded requests
+func (s *MockServer) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = nil
+	s.requests = nil
+	s.failures = nil
+}
+
+// Requests returns every request received, in order
+func (s *MockServer) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Calls returns the requests matching method and the path pattern
+func (s *MockServer) Calls(method, path string) []Request {
+	pattern := strings.TrimSuffix(path, "/")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var calls []Request
+	for _, r := range s.requests {
+		if r.Method == method && matchPath(pattern, r.Path) {
+			calls = append(calls, r)
+		}
+	}
+	return calls
+}
+
+// AssertCalled fails t unless method and the path pattern were requested
+// exactly times times
+func (s *MockServer) AssertCalled(t testing.TB, method, path string, times int) {
+	t.Helper()
+	if n := len(s.Calls(method, path)); n != times {
+		t.Errorf("versifitest: expected %d calls to %s %s, got %d", times, method, path, n)
+	}
+}
+
+// AssertExpectations fails t if a route was never called, a request
+// matched no route, or a request failed a Route.Expect check
+func (s *MockServer) AssertExpectations(t testing.TB) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.routes {
+		if r.calls == 0 {
+			t.Errorf("versifitest: %s %s was never called", r.method, r.pattern)
+		}
+	}
+	for _, req := range s.requests {
+		if req.Route == "" {
+			t.Errorf("versifitest: unexpected request %s %s", req.Method, req.Path)
+		}
+	}
+	for _, failure := range s.failures {
+		t.Error("versifitest: " + failure)
+	}
+}
+
+func (s *MockServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req := Request{
+		Method: r.Method,
+		Path:   strings.TrimSuffix(r.URL.Path, "/"),
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	}
+
+	res, delay := s.respond(&req)
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if res.Drop {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+	writeResponse(w, res)
+}
+
+// respond records req and picks its response and total delay
+func (s *MockServer) respond(req *Request) (Response, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var route *Route
+	for _, r := range s.routes {
+		if r.method == req.Method && matchPath(r.pattern, req.Path) {
+			route = r
+			break
+		}
+	}
+	if route == nil {
+		s.requests = append(s.requests, *req)
+		return Error(http.StatusNotFound, fmt.Sprintf("no route for %s %s", req.Method, req.Path)), s.latency
+	}
+
+	req.Route = route.pattern
+	s.requests = append(s.requests, *req)
+	call := route.calls
+	route.calls++
+
+	for _, check := range route.expect {
+		if err := check(*req); err != nil {
+			s.failures = append(s.failures, fmt.Sprintf("%s %s: %v", req.Method, req.Path, err))
+			return Error(http.StatusBadRequest, err.Error()), s.latency + route.delay
+		}
+	}
+
+	var res Response
+	switch {
+	case route.handler != nil:
+		// Called without the lock, so fn may use the server
+		handler, delay := route.handler, s.latency+route.delay
+		s.mu.Unlock()
+		res = handler(*req)
+		s.mu.Lock()
+		return res, delay + res.Delay
+	case len(route.responses) > 0:
+		res = route.responses[min(call, len(route.responses)-1)]
+	default:
+		res = Response{Status: http.StatusOK}
+	}
+	return res, s.latency + route.delay + res.Delay
+}
+
+func writeResponse(w http.ResponseWriter, res Response) {
+	for k, v := range res.Header {
+		w.Header()[k] = v
+	}
+	status := res.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	switch body := res.Body.(type) {
+	case nil:
+		w.WriteHeader(status)
+	case []byte:
+		w.WriteHeader(status)
+		w.Write(body)
+	case string:
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	default:
+		writeJSON(w, status, body)
+	}
+}
+
+// matchPath reports whether path matches pattern, where a "*" segment
+// matches any one segment
+func matchPath(pattern, path string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == path
+	}
+	want, got := strings.Split(pattern, "/"), strings.Split(path, "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i] != "*" && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package versifitest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func TestMockServerScriptedResponses(t *testing.T) {
+	ctx := context.Background()
+	server := NewMockServer()
+	defer server.Close()
+
+	server.Handle(http.MethodPost, "/v2/orders/basic").
+		Expect(func(r Request) error {
+			var req versifi.BasicOrderRequest
+			if err := r.Decode(&req); err != nil {
+				return err
+			}
+			if req.Symbol != "BTC/USDT" {
+				return fmt.Errorf("unexpected symbol %q", req.Symbol)
+			}
+			return nil
+		}).
+		Respond(
+			Error(http.StatusServiceUnavailable, "unavailable"),
+			JSON(http.StatusOK, versifi.OrderResponse{OrderID: 42, Status: versifi.OrderStatusNew}),
+		)
+	server.Handle(http.MethodGet, "/v2/orders/*").RespondFunc(func(r Request) Response {
+		return JSON(http.StatusOK, versifi.GetOrderResponse{OrderID: 42, Status: versifi.OrderStatusFilled})
+	})
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	order := client.NewCreateBasicOrderService().
+		Exchange(versifi.ExchangeBinanceSpot).
+		Symbol("BTC/USDT").
+		Side(versifi.SideTypeBuy).
+		OrderType(versifi.BasicOrderTypeMarket).
+		Quantity("1")
+
+	var apiErr *versifi.APIError
+	if _, err := order.Do(ctx); !errors.As(err, &apiErr) || apiErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected a 503 APIError, got %v", err)
+	}
+	res, err := order.Do(ctx)
+	if err != nil || res.OrderID != 42 {
+		t.Fatalf("Expected order 42, got %+v, %v", res, err)
+	}
+	if _, err := order.Do(ctx); err != nil {
+		t.Errorf("Expected the last response to repeat, got %v", err)
+	}
+
+	got, err := client.NewGetOrderService().OrderID(42).Do(ctx)
+	if err != nil || got.Status != versifi.OrderStatusFilled {
+		t.Fatalf("Unexpected order %+v, %v", got, err)
+	}
+
+	server.AssertCalled(t, http.MethodPost, "/v2/orders/basic", 3)
+	server.AssertCalled(t, http.MethodGet, "/v2/orders/*", 1)
+	if calls := server.Calls(http.MethodGet, "/v2/orders/42"); len(calls) != 1 || calls[0].Header.Get("X-VERSIFI-API-KEY") != "test-key" {
+		t.Errorf("Unexpected calls %+v", calls)
+	}
+	server.AssertExpectations(t)
+}
+
+func TestMockServerFaults(t *testing.T) {
+	ctx := context.Background()
+	server := NewMockServer()
+	defer server.Close()
+
+	server.Handle(http.MethodGet, "/v2/orders/1").Respond(Drop())
+	server.Handle(http.MethodGet, "/v2/orders/2").Delay(200 * time.Millisecond).RespondJSON(versifi.GetOrderResponse{OrderID: 2})
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	_, err := client.NewGetOrderService().OrderID(1).Do(ctx)
+	var apiErr *versifi.APIError
+	if err == nil || errors.As(err, &apiErr) {
+		t.Errorf("Expected a network error, got %v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.NewGetOrderService().OrderID(2).Do(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+
+	if _, err := client.NewGetOrderService().OrderID(3).Do(ctx); !errors.As(err, &apiErr) || apiErr.Code != http.StatusNotFound {
+		t.Errorf("Expected a 404 for an unmatched request, got %v", err)
+	}
+	if n := len(server.Requests()); n != 3 {
+		t.Errorf("Expected 3 recorded requests, got %d", n)
+	}
+}
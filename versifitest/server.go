@@ -0,0 +1,698 @@
+package versifitest
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// Step is one scripted change to an order. A step with a Quantity adds a
+// trade of that quantity at Price; Status defaults to PARTIALLY_FILLED or
+// FILLED according to the filled quantity.
+type Step struct {
+	Status       versifi.OrderStatusType
+	Quantity     string
+	Price        string
+	RejectReason string
+}
+
+// Lifecycle is the sequence of steps a new order goes through, one step
+// per call to Server.Advance
+type Lifecycle []Step
+
+// Fill is a step filling quantity at price
+func Fill(quantity, price string) Step {
+	return Step{Quantity: quantity, Price: price}
+}
+
+// Cancel is a step canceling the order
+func Cancel() Step {
+	return Step{Status: versifi.OrderStatusCanceled}
+}
+
+// Reject is a step rejecting the order with reason
+func Reject(reason string) Step {
+	return Step{Status: versifi.OrderStatusRejected, RejectReason: reason}
+}
+
+// Server is a fake Versifi API serving the order REST endpoints and the
+// WebSocket protocol. Orders are acknowledged as NEW and then move through
+// their Lifecycle only when the test calls Advance, so order flows are
+// deterministic; every change is published as an execution_report.
+//
+//	server := versifitest.NewServer("key", "secret")
+//	defer server.Close()
+//	server.SetLifecycle(versifitest.Lifecycle{versifitest.Fill("1", "100")})
+//
+//	client := versifi.NewClient("key", "secret")
+//	client.BaseURL = server.URL
+//	ws := versifi.NewWsClient("key", "secret")
+//	ws.BaseURL = server.Ws.URL
+type Server struct {
+	// URL is the http:// address of the REST API
+	URL string
+	// Ws serves the WebSocket protocol and publishes execution reports
+	Ws *WsServer
+
+	apiKey    string
+	apiSecret string
+	server    *httptest.Server
+
+	mu          sync.Mutex
+	lifecycle   Lifecycle
+	orders      map[int64]*serverOrder
+	nextOrderID int64
+	nextTradeID int64
+	// changed is closed and replaced whenever an order changes, waking
+	// long-polling get order requests
+	changed chan struct{}
+}
+
+// errInvalidOrder is the error message for orders missing required fields
+const errInvalidOrder = "exchange, symbol, side and a positive quantity are required"
+
+type serverOrder struct {
+	res       versifi.GetOrderResponse
+	exchange  versifi.ExchangeType
+	symbol    string
+	side      versifi.SideType
+	quantity  decimal.Decimal
+	filled    decimal.Decimal
+	notional  decimal.Decimal
+	children  []versifi.ChildOrder
+	lifecycle Lifecycle
+}
+
+// NewServer starts a server accepting the given credentials on both REST and
+// WebSocket. Empty credentials accept any key and signature.
+func NewServer(apiKey, apiSecret string) *Server {
+	s := &Server{
+		Ws:        NewWsServer(apiKey, apiSecret),
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		orders:    make(map[int64]*serverOrder),
+		changed:   make(chan struct{}),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the REST and WebSocket servers
+func (s *Server) Close() {
+	s.server.Close()
+	s.Ws.Close()
+}
+
+// SetLifecycle sets the lifecycle of orders created from now on
+func (s *Server) SetLifecycle(lifecycle Lifecycle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lifecycle = append(Lifecycle(nil), lifecycle...)
+}
+
+// SetOrderLifecycle replaces the remaining lifecycle of one order
+func (s *Server) SetOrderLifecycle(orderID int64, lifecycle Lifecycle) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[orderID]
+	if !ok {
+		return fmt.Errorf("order %d not found", orderID)
+	}
+	o.lifecycle = append(Lifecycle(nil), lifecycle...)
+	return nil
+}
+
+// Advance applies the next lifecycle step of an order and publishes the
+// execution report. It returns false when the order has no steps left or
+// is already final.
+func (s *Server) Advance(orderID int64) (bool, error) {
+	s.mu.Lock()
+	o, ok := s.orders[orderID]
+	if !ok {
+		s.mu.Unlock()
+		return false, fmt.Errorf("order %d not found", orderID)
+	}
+	if len(o.lifecycle) == 0 || o.res.Status.IsFinal() {
+		s.mu.Unlock()
+		return false, nil
+	}
+	step := o.lifecycle[0]
+	o.lifecycle = o.lifecycle[1:]
+	report := s.applyLocked(o, step)
+	s.mu.Unlock()
+
+	_, err := s.Ws.SendExecutionReport(report)
+	return true, err
+}
+
+// AdvanceAll applies every remaining step of an order, in order
+func (s *Server) AdvanceAll(orderID int64) error {
+	for {
+		ok, err := s.Advance(orderID)
+		if err != nil || !ok {
+			return err
+		}
+	}
+}
+
+// Order returns the current state of an order as the get order endpoint does
+func (s *Server) Order(orderID int64) (*versifi.GetOrderResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[orderID]
+	if !ok {
+		return nil, false
+	}
+	return o.export(), true
+}
+
+// applyLocked applies step to o and returns the execution report for it
+func (s *Server) applyLocked(o *serverOrder, step Step) versifi.WsExecutionReportDetail {
+	var trades []versifi.WsTrade
+	if qty := toDecimal(step.Quantity); qty.Sign() > 0 {
+		price := toDecimal(step.Price)
+		o.filled = o.filled.Add(qty)
+		o.notional = o.notional.Add(qty.Mul(price))
+
+		s.nextTradeID++
+		child := &o.children[0]
+		child.Trades = append(child.Trades, versifi.Trade{
+			TradeID:      s.nextTradeID,
+			OrderID:      o.res.OrderID,
+			ChildOrderID: child.ChildOrderID,
+			Exchange:     o.exchange,
+			Symbol:       o.symbol,
+			Price:        price.String(),
+			Quantity:     qty.String(),
+			Side:         o.side,
+			Fee:          "0",
+			LegID:        child.LegID,
+		})
+		trade := versifi.WsTrade{
+			TradeID:                   s.nextTradeID,
+			OrderID:                   o.res.OrderID,
+			ExecutedPrice:             price.String(),
+			ExecutedQuantity:          qty.String(),
+			AveragePrice:              o.averagePrice(),
+			CummulativeFilledQuantity: o.filled.String(),
+		}
+		if o.res.PairOrder != nil {
+			legID := child.LegID
+			trade.LegID = &legID
+		}
+		trades = append(trades, trade)
+
+		o.res.Status = versifi.OrderStatusPartiallyFilled
+		if o.quantity.Sign() > 0 && o.filled.GreaterThanOrEqual(o.quantity) {
+			o.res.Status = versifi.OrderStatusFilled
+		}
+	}
+	if step.Status != "" {
+		o.res.Status = step.Status
+	}
+	if step.RejectReason != "" {
+		o.setRejectReason(step.RejectReason)
+	}
+	if now := time.Now(); now.After(o.res.Timestamp.Time) {
+		o.res.Timestamp = versifi.NewTimestamp(now, versifi.PrecisionMilliseconds)
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return o.report(trades)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !s.authorized(r, body) {
+		writeError(w, http.StatusUnauthorized, "invalid api key or signature")
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case r.Method == http.MethodPost && path == "/v2/orders/basic":
+		var req versifi.BasicOrderRequest
+		if decode(w, body, &req) {
+			s.createBasic(w, &req)
+		}
+	case r.Method == http.MethodPost && path == "/v2/orders/algo":
+		var req versifi.AlgoOrderRequest
+		if decode(w, body, &req) {
+			s.createAlgo(w, &req)
+		}
+	case r.Method == http.MethodPost && path == "/v2/orders/pair":
+		var req versifi.PairOrderRequestFull
+		if decode(w, body, &req) {
+			s.createPair(w, &req)
+		}
+	case r.Method == http.MethodGet && path == "/v2/orders":
+		s.list(w, r)
+	case r.Method == http.MethodDelete && path == "/v2/orders/batch":
+		var req versifi.CancelBatchRequest
+		if decode(w, body, &req) {
+			s.cancel(w, req.IDs, false)
+		}
+	case strings.HasPrefix(path, "/v2/orders/"):
+		id, err := strconv.ParseInt(strings.TrimPrefix(path, "/v2/orders/"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			o, ok := s.waitOrder(r, id)
+			if !ok {
+				writeError(w, http.StatusNotFound, fmt.Sprintf("order %d not found", id))
+				return
+			}
+			writeJSON(w, http.StatusOK, o)
+		case http.MethodDelete:
+			s.cancel(w, []int64{id}, true)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// waitOrder returns an order, first holding the request while its status
+// is the status query parameter, or the status on arrival, for up to the
+// wait_ms query parameter
+func (s *Server) waitOrder(r *http.Request, orderID int64) (*versifi.GetOrderResponse, bool) {
+	wait, _ := strconv.Atoi(r.URL.Query().Get("wait_ms"))
+	timeout := time.NewTimer(time.Duration(wait) * time.Millisecond)
+	defer timeout.Stop()
+
+	known := versifi.OrderStatusType(r.URL.Query().Get("status"))
+	for {
+		s.mu.Lock()
+		o, ok := s.orders[orderID]
+		if !ok {
+			s.mu.Unlock()
+			return nil, false
+		}
+		if known == "" {
+			known = o.res.Status
+		}
+		res, changed := o.export(), s.changed
+		s.mu.Unlock()
+		if wait <= 0 || res.Status != known {
+			return res, true
+		}
+
+		select {
+		case <-changed:
+		case <-timeout.C:
+			return res, true
+		case <-r.Context().Done():
+			return res, true
+		}
+	}
+}
+
+// authorized checks the API key and the signature of the query string for
+// GET and DELETE requests, or of the body otherwise
+func (s *Server) authorized(r *http.Request, body []byte) bool {
+	if s.apiKey != "" && r.Header.Get("X-VERSIFI-API-KEY") != s.apiKey {
+		return false
+	}
+	if s.apiSecret == "" {
+		return true
+	}
+	payload := string(body)
+	if r.Method == http.MethodGet || r.Method == http.MethodDelete {
+		payload = r.URL.Query().Encode()
+	}
+	return r.Header.Get("X-VERSIFI-API-SIGN") == sign(s.apiSecret, payload)
+}
+
+func (s *Server) createBasic(w http.ResponseWriter, req *versifi.BasicOrderRequest) {
+	o := s.newOrder(req.ClientOrderID, req.Exchange, req.Symbol, req.Side, req.Quantity)
+	o.res.RequestOrderType = strings.ToUpper(versifi.RequestOrderTypeBasic)
+	o.res.OrderType = string(req.OrderType)
+	o.res.BasicOrder = &versifi.BasicOrderDetail{
+		Exchange:  req.Exchange,
+		OrderType: req.OrderType,
+		Price:     stringValue(req.Price),
+		Quantity:  req.Quantity,
+		Side:      req.Side,
+		StopPrice: stringValue(req.StopPrice),
+		Symbol:    req.Symbol,
+	}
+	if req.TIF != nil {
+		o.res.BasicOrder.TIF = *req.TIF
+	}
+	if !o.valid() {
+		writeError(w, http.StatusBadRequest, errInvalidOrder)
+		return
+	}
+	s.create(w, o, nil)
+}
+
+func (s *Server) createAlgo(w http.ResponseWriter, req *versifi.AlgoOrderRequest) {
+	o := s.newOrder(req.ClientOrderID, req.Exchange, req.Symbol, req.Side, req.Quantity)
+	params, _ := json.Marshal(req.Params)
+	o.res.RequestOrderType = strings.ToUpper(versifi.RequestOrderTypeAlgo)
+	o.res.OrderType = string(req.OrderType)
+	o.res.AlgoOrder = &versifi.AlgoOrderDetail{
+		Exchange:    req.Exchange,
+		OrderType:   req.OrderType,
+		Quantity:    req.Quantity,
+		Side:        req.Side,
+		Symbol:      req.Symbol,
+		OrderParams: params,
+	}
+	if !o.valid() {
+		writeError(w, http.StatusBadRequest, errInvalidOrder)
+		return
+	}
+	s.create(w, o, nil)
+}
+
+// createPair creates a pair order whose scripted fills go to the lead leg.
+// Pair orders have no quantity, so they only become FILLED by a Step status.
+func (s *Server) createPair(w http.ResponseWriter, req *versifi.PairOrderRequestFull) {
+	if req.Lead == nil || req.Secondary == nil || req.Lead.Exchange == "" || req.Lead.Symbol == "" {
+		writeError(w, http.StatusBadRequest, "lead and secondary legs are required")
+		return
+	}
+	o := s.newOrder(req.ClientOrderID, req.Lead.Exchange, req.Lead.Symbol, "", "")
+	params, _ := json.Marshal(req.Lead.Params)
+	o.res.RequestOrderType = strings.ToUpper(versifi.RequestOrderTypePair)
+	o.res.OrderType = string(req.Lead.OrderType)
+	o.res.PairOrder = &versifi.PairOrderDetail{
+		LeadLeg:   &versifi.PairLegDetail{Exchange: req.Lead.Exchange, Symbol: req.Lead.Symbol, OrderType: string(req.Lead.OrderType)},
+		Secondary: &versifi.PairLegDetail{Exchange: req.Secondary.Exchange, Symbol: req.Secondary.Symbol, OrderType: req.Secondary.OrderType},
+		Params:    params,
+	}
+	if req.Style != nil {
+		o.res.PairOrder.Style = *req.Style
+	}
+	o.children[0].LegID = 1
+	s.create(w, o, &versifi.LegResponse{LegID: 1, Status: versifi.OrderStatusNew})
+}
+
+func (s *Server) newOrder(clientOrderID *int64, exchange versifi.ExchangeType, symbol string, side versifi.SideType, quantity string) *serverOrder {
+	o := &serverOrder{
+		exchange: exchange,
+		symbol:   symbol,
+		side:     side,
+		quantity: toDecimal(quantity),
+	}
+	if clientOrderID != nil {
+		o.res.ClientOrderID = *clientOrderID
+	}
+	return o
+}
+
+// valid reports whether o has what basic and algo orders require
+func (o *serverOrder) valid() bool {
+	return o.exchange != "" && o.symbol != "" && o.quantity.Sign() > 0 &&
+		(o.side == versifi.SideTypeBuy || o.side == versifi.SideTypeSell)
+}
+
+func (s *Server) create(w http.ResponseWriter, o *serverOrder, lead *versifi.LegResponse) {
+	s.mu.Lock()
+	s.nextOrderID++
+	o.res.OrderID = s.nextOrderID
+	o.res.Status = versifi.OrderStatusNew
+	o.res.Timestamp = versifi.NewTimestamp(time.Now(), versifi.PrecisionMilliseconds)
+	o.children = []versifi.ChildOrder{{
+		ID:           o.res.OrderID,
+		ChildOrderID: o.res.OrderID,
+		OrderID:      o.res.OrderID,
+		Exchange:     o.exchange,
+		Symbol:       o.symbol,
+		Side:         o.side,
+		Quantity:     decimalString(o.quantity),
+		LegID:        o.legID(),
+	}}
+	o.lifecycle = append(Lifecycle(nil), s.lifecycle...)
+	s.orders[o.res.OrderID] = o
+	report := o.report(nil)
+	res := versifi.OrderResponse{OrderID: o.res.OrderID, ClientOrderID: o.res.ClientOrderID, Status: o.res.Status, Lead: lead}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, res)
+	s.Ws.SendExecutionReport(report)
+}
+
+// cancel cancels the open orders among ids. With strict set, an unknown
+// or final order is an error, as for the single cancel endpoint.
+func (s *Server) cancel(w http.ResponseWriter, ids []int64, strict bool) {
+	s.mu.Lock()
+	var reports []versifi.WsExecutionReportDetail
+	for _, id := range ids {
+		o, ok := s.orders[id]
+		switch {
+		case !ok && strict:
+			s.mu.Unlock()
+			writeError(w, http.StatusNotFound, fmt.Sprintf("order %d not found", id))
+			return
+		case ok && o.res.Status.IsFinal() && strict:
+			s.mu.Unlock()
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("order %d is already %s", id, o.res.Status))
+			return
+		case ok && !o.res.Status.IsFinal():
+			o.lifecycle = nil
+			reports = append(reports, s.applyLocked(o, Cancel()))
+		}
+	}
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+	for _, report := range reports {
+		s.Ws.SendExecutionReport(report)
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	status := versifi.OrderStatusType(query.Get("status"))
+
+	s.mu.Lock()
+	ids := make([]int64, 0, len(s.orders))
+	for id := range s.orders {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	items := []versifi.ListOrderItem{}
+	for _, id := range ids {
+		o := s.orders[id]
+		if status != "" && o.res.Status != status {
+			continue
+		}
+		items = append(items, versifi.ListOrderItem{
+			OrderID:          o.res.OrderID,
+			ClientOrderID:    o.res.ClientOrderID,
+			Status:           string(o.res.Status),
+			Timestamp:        o.res.Timestamp,
+			RequestOrderType: o.res.RequestOrderType,
+			RejectReason:     o.rejectReason(),
+		})
+	}
+	s.mu.Unlock()
+
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (o *serverOrder) legID() int64 {
+	if o.res.PairOrder != nil {
+		return 1
+	}
+	return 0
+}
+
+func (o *serverOrder) averagePrice() string {
+	if o.filled.IsZero() {
+		return ""
+	}
+	return o.notional.Div(o.filled).String()
+}
+
+func (o *serverOrder) rejectReason() string {
+	switch {
+	case o.res.BasicOrder != nil:
+		return o.res.BasicOrder.RejectReason
+	case o.res.AlgoOrder != nil:
+		return o.res.AlgoOrder.RejectReason
+	case o.res.PairOrder != nil:
+		return o.res.PairOrder.RejectReason
+	}
+	return ""
+}
+
+func (o *serverOrder) setRejectReason(reason string) {
+	o.children[0].RejectReason = reason
+	switch {
+	case o.res.BasicOrder != nil:
+		o.res.BasicOrder.RejectReason = reason
+	case o.res.AlgoOrder != nil:
+		o.res.AlgoOrder.RejectReason = reason
+	case o.res.PairOrder != nil:
+		o.res.PairOrder.RejectReason = reason
+	}
+}
+
+// export returns a copy of the order with its fill state filled in
+func (o *serverOrder) export() *versifi.GetOrderResponse {
+	res := o.res
+	children := make([]versifi.ChildOrder, len(o.children))
+	for i, child := range o.children {
+		child.OrderStatus = o.res.Status
+		child.AveragePrice = o.averagePrice()
+		child.FilledQuantity = o.filled.String()
+		child.Trades = append([]versifi.Trade(nil), child.Trades...)
+		children[i] = child
+	}
+
+	switch {
+	case res.BasicOrder != nil:
+		basic := *res.BasicOrder
+		basic.AveragePrice, basic.FilledQuantity, basic.ChildOrders = o.averagePrice(), o.filled.String(), children
+		res.BasicOrder = &basic
+	case res.AlgoOrder != nil:
+		algo := *res.AlgoOrder
+		algo.AveragePrice, algo.FilledQuantity, algo.ChildOrders = o.averagePrice(), o.filled.String(), children
+		res.AlgoOrder = &algo
+	case res.PairOrder != nil:
+		pair := *res.PairOrder
+		lead := *pair.LeadLeg
+		lead.ChildOrders = children
+		pair.LeadLeg = &lead
+		res.PairOrder = &pair
+	}
+	return &res
+}
+
+// report returns the execution report for the current state and trades
+func (o *serverOrder) report(trades []versifi.WsTrade) versifi.WsExecutionReportDetail {
+	child := &versifi.WsChildOrder{ID: o.children[0].ID, Trades: trades}
+	if child.Trades == nil {
+		child.Trades = []versifi.WsTrade{}
+	}
+	detail := versifi.WsExecutionReportDetail{
+		OrderID:          o.res.OrderID,
+		ClientOrderID:    o.res.ClientOrderID,
+		OrderType:        o.res.OrderType,
+		Status:           o.res.Status,
+		Timestamp:        o.res.Timestamp,
+		RequestOrderType: o.res.RequestOrderType,
+	}
+	switch {
+	case o.res.BasicOrder != nil:
+		b := o.res.BasicOrder
+		detail.Basic = &versifi.WsBasicOrderDetail{
+			Symbol:        b.Symbol,
+			ClientOrderID: o.res.ClientOrderID,
+			StopPrice:     b.StopPrice,
+			Exchange:      b.Exchange,
+			Price:         b.Price,
+			Quantity:      b.Quantity,
+			Side:          b.Side,
+			OrderType:     b.OrderType,
+			ChildOrder:    child,
+		}
+	case o.res.AlgoOrder != nil:
+		a := o.res.AlgoOrder
+		detail.Algo = &versifi.WsAlgoOrderDetail{
+			ID:          o.res.OrderID,
+			Exchange:    a.Exchange,
+			OrderType:   a.OrderType,
+			Quantity:    a.Quantity,
+			Side:        a.Side,
+			Symbol:      a.Symbol,
+			OrderParams: a.OrderParams,
+			ChildOrder:  child,
+		}
+	case o.res.PairOrder != nil:
+		p := o.res.PairOrder
+		detail.Pair = &versifi.WsPairOrderDetail{
+			Params:  p.Params,
+			LeadLeg: &versifi.WsPairLeg{Symbol: p.LeadLeg.Symbol, Exchange: p.LeadLeg.Exchange, OrderType: p.LeadLeg.OrderType, ChildOrder: child},
+			Leg:     &versifi.WsPairLeg{Symbol: p.Secondary.Symbol, Exchange: p.Secondary.Exchange, OrderType: p.Secondary.OrderType},
+		}
+	}
+	return detail
+}
+
+func decode(w http.ResponseWriter, body []byte, v interface{}) bool {
+	if err := json.Unmarshal(body, v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// readBody reads the request body, decompressing it when the client sent it
+// gzipped
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Header.Get("Content-Encoding") != "gzip" {
+		return io.ReadAll(r.Body)
+	}
+	zr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, versifi.APIError{Code: status, Message: message})
+}
+
+func decimalString(d decimal.Decimal) string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.String()
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func toDecimal(s string) decimal.Decimal {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero
+	}
+	return d
+}
@@ -0,0 +1,198 @@
+package versifitest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func TestServerOrderLifecycle(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer("test-key", "test-secret")
+	defer server.Close()
+	server.SetLifecycle(Lifecycle{Fill("1", "100"), Fill("1", "102")})
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	ws := newWsClient(t, server.Ws, "test-key", "test-secret")
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Disconnect()
+
+	tracker := versifi.NewOrderTracker(client)
+	updates := make(chan versifi.OrderUpdate, 16)
+	tracker.Subscribe(func(update versifi.OrderUpdate) { updates <- update })
+	if err := tracker.Attach(ws); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Ws.WaitForSubscription("execution_report", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := tracker.Submit(ctx, client.NewCreateBasicOrderService().
+		Exchange(versifi.ExchangeBinanceSpot).
+		Symbol("BTC/USDT").
+		Side(versifi.SideTypeBuy).
+		OrderType(versifi.BasicOrderTypeLimit).
+		Price("105").
+		Quantity("2").
+		ClientOrderID(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != versifi.OrderStatusNew || res.ClientOrderID != 7 {
+		t.Fatalf("Unexpected ack %+v", res)
+	}
+
+	if err := server.AdvanceAll(res.OrderID); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case update := <-updates:
+			done = update.Order.Status == versifi.OrderStatusFilled
+		case <-deadline:
+			t.Fatal("Timed out waiting for the fill")
+		}
+	}
+
+	order, err := client.NewGetOrderService().OrderID(res.OrderID).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	basic := order.BasicOrder
+	if order.Status != versifi.OrderStatusFilled || basic.AveragePrice != "101" || basic.FilledQuantity != "2" || len(basic.ChildOrders[0].Trades) != 2 {
+		t.Errorf("Unexpected order %+v", basic)
+	}
+
+	// Final orders cannot be canceled
+	var apiErr *versifi.APIError
+	if err := client.NewCancelOrderService().OrderID(res.OrderID).Do(ctx); !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusBadRequest {
+		t.Errorf("Expected a 400, got %v", err)
+	}
+}
+
+func TestServerCancelAndList(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer("test-key", "test-secret")
+	defer server.Close()
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		res, err := client.NewCreateAlgoOrderService().
+			Exchange(versifi.ExchangeBinanceSpot).
+			Symbol("BTC/USDT").
+			Side(versifi.SideTypeSell).
+			OrderType(versifi.AlgoOrderTypeTWAP).
+			Quantity("1").
+			Params(map[string]interface{}{"duration": 60}).
+			Do(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, res.OrderID)
+	}
+
+	if err := server.SetOrderLifecycle(ids[0], Lifecycle{Reject("insufficient balance")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Advance(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.NewCancelBatchOrderService().OrderIDs(ids).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := client.NewListOpenOrdersService().Status(versifi.OrderStatusCanceled).Limit(1).Offset(1).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].OrderID != ids[2] {
+		t.Errorf("Unexpected canceled orders %+v", items)
+	}
+	if order, _ := server.Order(ids[0]); order.Status != versifi.OrderStatusRejected || order.AlgoOrder.RejectReason != "insufficient balance" {
+		t.Errorf("Unexpected rejected order %+v", order)
+	}
+
+	var apiErr *versifi.APIError
+	if _, err := client.NewGetOrderService().OrderID(999).Do(ctx); !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusNotFound {
+		t.Errorf("Expected a 404, got %v", err)
+	}
+
+	bad := versifi.NewClient("test-key", "wrong-secret")
+	bad.BaseURL = server.URL
+	if _, err := bad.NewGetOrderService().OrderID(ids[0]).Do(ctx); !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusUnauthorized {
+		t.Errorf("Expected a 401, got %v", err)
+	}
+}
+
+func TestServerCompressedBodies(t *testing.T) {
+	server := NewServer("test-key", "test-secret")
+	defer server.Close()
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	client.CompressThreshold = 1
+	res, err := client.NewCreateAlgoOrderService().
+		Exchange(versifi.ExchangeBinanceSpot).
+		Symbol("BTC/USDT").
+		Side(versifi.SideTypeBuy).
+		OrderType(versifi.AlgoOrderTypeTWAP).
+		Quantity("1").
+		Params(map[string]interface{}{"duration": 60}).
+		Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.NewCancelBatchOrderService().OrderIDs([]int64{res.OrderID}).Do(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerWaitForChange(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer("test-key", "test-secret")
+	defer server.Close()
+	server.SetLifecycle(Lifecycle{Fill("1", "100")})
+
+	client := versifi.NewClient("test-key", "test-secret")
+	client.BaseURL = server.URL
+	res, err := client.NewCreateBasicOrderService().Exchange(versifi.ExchangeBinanceSpot).Symbol("BTC/USDT").
+		Side(versifi.SideTypeBuy).OrderType(versifi.BasicOrderTypeMarket).Quantity("2").Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing changes: the order is returned after the timeout
+	svc := client.NewGetOrderService().OrderID(res.OrderID).WaitForChange(50 * time.Millisecond)
+	start := time.Now()
+	order, err := svc.Do(ctx)
+	if err != nil || order.Status != versifi.OrderStatusNew || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("Expected the unchanged order after the timeout, got %+v after %v (%v)", order, time.Since(start), err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.Advance(res.OrderID)
+	}()
+	order, err = svc.WaitForChange(5 * time.Second).Do(ctx)
+	if err != nil || order.Status != versifi.OrderStatusPartiallyFilled || time.Since(start) > 4*time.Second {
+		t.Errorf("Expected the order as soon as it changed, got %+v (%v)", order, err)
+	}
+
+	// A change before the request is returned at once
+	order, err = svc.KnownStatus(versifi.OrderStatusNew).Do(ctx)
+	if err != nil || order.Status != versifi.OrderStatusPartiallyFilled || time.Since(start) > 4*time.Second {
+		t.Errorf("Expected the changed order at once, got %+v (%v)", order, err)
+	}
+}
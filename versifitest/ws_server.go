@@ -0,0 +1,286 @@
+// Package versifitest provides in-process fakes of the Versifi API for tests.
+package versifitest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+// WsServer is a fake Versifi websocket endpoint speaking the
+// auth/subscribe/ping/execution_report protocol over httptest.
+//
+//	server := versifitest.NewWsServer("key", "secret")
+//	defer server.Close()
+//	client := versifi.NewWsClient("key", "secret")
+//	client.BaseURL = server.URL
+type WsServer struct {
+	// URL is the ws:// address of the server
+	URL string
+
+	apiKey    string
+	apiSecret string
+	server    *httptest.Server
+	upgrader  websocket.Upgrader
+
+	mu         sync.Mutex
+	cond       *sync.Cond
+	conns      map[*wsConn]struct{}
+	received   []json.RawMessage
+	rejectAuth bool
+}
+
+type wsConn struct {
+	conn          *websocket.Conn
+	writeMu       sync.Mutex
+	authenticated bool
+	topics        map[string]bool
+}
+
+// NewWsServer starts a server accepting the given credentials.
+// Empty credentials accept any key and signature.
+func NewWsServer(apiKey, apiSecret string) *WsServer {
+	s := &WsServer{
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		conns:     make(map[*wsConn]struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveWs))
+	s.URL = "ws" + strings.TrimPrefix(s.server.URL, "http")
+	return s
+}
+
+// Close disconnects all clients and shuts the server down
+func (s *WsServer) Close() {
+	s.DropConnections()
+	s.server.Close()
+}
+
+// RejectAuth makes subsequent auth requests fail when reject is true
+func (s *WsServer) RejectAuth(reject bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectAuth = reject
+}
+
+// DropConnections closes every open client connection without a close frame,
+// simulating a network failure
+func (s *WsServer) DropConnections() {
+	s.mu.Lock()
+	conns := make([]*wsConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		c.conn.Close()
+	}
+}
+
+// Received returns every message received from clients, in order
+func (s *WsServer) Received() []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]json.RawMessage(nil), s.received...)
+}
+
+// WaitForSubscription blocks until at least one authenticated client is
+// subscribed to topic, or the timeout elapses
+func (s *WsServer) WaitForSubscription(topic string, timeout time.Duration) error {
+	timer := time.AfterFunc(timeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	deadline := time.Now().Add(timeout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for !s.subscribedLocked(topic) {
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("no subscription to %s after %v", topic, timeout)
+		}
+		s.cond.Wait()
+	}
+	return nil
+}
+
+// Publish sends {"op": topic, "success": true, "message": message} to every
+// client subscribed to topic and returns the number of recipients
+func (s *WsServer) Publish(topic string, message interface{}) (int, error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"op":      topic,
+		"success": true,
+		"message": message,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	var targets []*wsConn
+	for c := range s.conns {
+		if c.authenticated && c.topics[topic] {
+			targets = append(targets, c)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, c := range targets {
+		if err := c.write(data); err != nil {
+			return 0, err
+		}
+	}
+	return len(targets), nil
+}
+
+// SendExecutionReport publishes an execution_report to subscribed clients
+func (s *WsServer) SendExecutionReport(detail versifi.WsExecutionReportDetail) (int, error) {
+	return s.Publish("execution_report", detail)
+}
+
+func (s *WsServer) subscribedLocked(topic string) bool {
+	for c := range s.conns {
+		if c.authenticated && c.topics[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *WsServer) serveWs(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &wsConn{conn: conn, topics: make(map[string]bool)}
+
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg struct {
+			Op   string            `json:"op"`
+			Args []json.RawMessage `json:"args"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		s.received = append(s.received, json.RawMessage(data))
+		s.mu.Unlock()
+
+		var resp interface{}
+		switch msg.Op {
+		case "auth":
+			resp = s.handleAuth(c, msg.Args)
+		case "subscribe":
+			resp = s.handleSubscribe(c, msg.Args)
+		case "ping":
+			resp = map[string]interface{}{"op": "ping", "success": true, "message": map[string]interface{}{}}
+		default:
+			continue
+		}
+
+		out, err := json.Marshal(resp)
+		if err != nil {
+			return
+		}
+		if err := c.write(out); err != nil {
+			return
+		}
+	}
+}
+
+func (s *WsServer) handleAuth(c *wsConn, args []json.RawMessage) interface{} {
+	fail := func(reason string) interface{} {
+		return map[string]interface{}{"op": "auth", "success": false, "message": reason}
+	}
+
+	var key, expires, signature string
+	if len(args) != 3 ||
+		json.Unmarshal(args[0], &key) != nil ||
+		json.Unmarshal(args[1], &expires) != nil ||
+		json.Unmarshal(args[2], &signature) != nil {
+		return fail("invalid auth args")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rejectAuth {
+		return fail("authentication rejected")
+	}
+	if s.apiKey != "" && key != s.apiKey {
+		return fail("invalid api key")
+	}
+	if s.apiSecret != "" && signature != sign(s.apiSecret, "GET/realtime"+expires) {
+		return fail("invalid signature")
+	}
+
+	c.authenticated = true
+	s.cond.Broadcast()
+	return map[string]interface{}{"op": "auth", "success": true}
+}
+
+func (s *WsServer) handleSubscribe(c *wsConn, args []json.RawMessage) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !c.authenticated {
+		return map[string]interface{}{"op": "subscribe", "success": false, "message": "not authenticated"}
+	}
+
+	var topics []string
+	for _, arg := range args {
+		var topic string
+		if json.Unmarshal(arg, &topic) == nil {
+			c.topics[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	s.cond.Broadcast()
+	return map[string]interface{}{"op": "subscribe", "success": true, "message": topics}
+}
+
+func (c *wsConn) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func sign(secret, payload string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(payload))
+	return hex.EncodeToString(h.Sum(nil))
+}
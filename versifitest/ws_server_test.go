@@ -0,0 +1,79 @@
+package versifitest
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func newWsClient(t *testing.T, server *WsServer, apiKey, apiSecret string) *versifi.WsClient {
+	client := versifi.NewWsClient(apiKey, apiSecret)
+	client.BaseURL = server.URL
+	client.Logger = log.New(io.Discard, "", 0)
+	return client
+}
+
+func TestWsServerExecutionReport(t *testing.T) {
+	server := NewWsServer("test-key", "test-secret")
+	defer server.Close()
+
+	client := newWsClient(t, server, "test-key", "test-secret")
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Disconnect()
+
+	reports := make(chan versifi.WsExecutionReport, 1)
+	err := client.SubscribeExecutionReport(func(message []byte) {
+		var report versifi.WsExecutionReport
+		if err := json.Unmarshal(message, &report); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		reports <- report
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := server.WaitForSubscription("execution_report", 5*time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	n, err := server.SendExecutionReport(versifi.WsExecutionReportDetail{
+		OrderID: 12345,
+		Status:  versifi.OrderStatusFilled,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Expected 1 recipient, got %d", n)
+	}
+
+	select {
+	case report := <-reports:
+		if report.Message.OrderID != 12345 {
+			t.Errorf("Expected OrderID 12345, got %d", report.Message.OrderID)
+		}
+		if report.Message.Status != versifi.OrderStatusFilled {
+			t.Errorf("Expected status FILLED, got %s", report.Message.Status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for execution report")
+	}
+}
+
+func TestWsServerRejectsBadSignature(t *testing.T) {
+	server := NewWsServer("test-key", "test-secret")
+	defer server.Close()
+
+	client := newWsClient(t, server, "test-key", "wrong-secret")
+	if err := client.Connect(); err == nil {
+		client.Disconnect()
+		t.Fatal("Expected authentication error, got nil")
+	}
+}
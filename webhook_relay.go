@@ -0,0 +1,339 @@
+package versifi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWebhookAttempts   = 5
+	defaultWebhookRetryDelay = time.Second
+	defaultWebhookTimeout    = 10 * time.Second
+	defaultWebhookQueueSize  = 1024
+
+	// WebhookSignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+	// of "<t>.<body>" keyed with the relay secret
+	WebhookSignatureHeader = "X-Versifi-Signature"
+	// WebhookEventIDHeader carries the event ID, for deduplicating retries
+	WebhookEventIDHeader = "X-Versifi-Event-Id"
+)
+
+var (
+	// ErrWebhookQueueFull is reported when an endpoint falls so far behind
+	// that its queue is full and an event is dropped
+	ErrWebhookQueueFull = errors.New("webhook queue full")
+	// ErrWebhookSignature is returned by VerifyWebhookSignature for
+	// missing, malformed, wrong or expired signatures
+	ErrWebhookSignature = errors.New("invalid webhook signature")
+)
+
+// WebhookEvent is the JSON body POSTed by a WebhookRelay
+type WebhookEvent struct {
+	// ID identifies the event; a report received twice has the same ID
+	ID   string          `json:"id"`
+	Type string          `json:"type"` // Always "execution_report"
+	Time int64           `json:"time"` // When the relay received the report, in milliseconds
+	Data json.RawMessage `json:"data"` // The execution report detail as received
+}
+
+// WebhookError reports an event that could not be delivered to an endpoint
+type WebhookError struct {
+	URL     string
+	EventID string
+	Err     error
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("webhook %s event %s: %v", e.URL, e.EventID, e.Err)
+}
+
+func (e *WebhookError) Unwrap() error {
+	return e.Err
+}
+
+// WebhookRelay POSTs signed execution report events to webhook URLs, so
+// web backends can receive fills without holding a WebSocket connection.
+//
+// Each URL has its own queue and goroutine, so a slow endpoint does not
+// delay the others and events reach each endpoint in order. Failed
+// deliveries are retried with exponential backoff; 4xx responses other than
+// 408 and 429 are not retried. Set the fields before the first event.
+type WebhookRelay struct {
+	// MaxAttempts is the number of deliveries tried per event, default 5
+	MaxAttempts int
+	// RetryDelay is the pause before the first retry, doubled for each
+	// following one, default 1s
+	RetryDelay time.Duration
+	// Timeout bounds each delivery, default 10s
+	Timeout    time.Duration
+	HTTPClient *http.Client
+
+	secret    string
+	endpoints []*webhookEndpoint
+	wg        sync.WaitGroup
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	mu         sync.RWMutex
+	closed     bool
+	errHandler ErrHandler
+}
+
+type webhookEndpoint struct {
+	url   string
+	queue chan webhookDelivery
+}
+
+type webhookDelivery struct {
+	id   string
+	body []byte
+}
+
+// NewWebhookRelay creates a relay signing events with secret and starts
+// delivering to urls
+func NewWebhookRelay(secret string, urls ...string) *WebhookRelay {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &WebhookRelay{
+		MaxAttempts: defaultWebhookAttempts,
+		RetryDelay:  defaultWebhookRetryDelay,
+		Timeout:     defaultWebhookTimeout,
+		HTTPClient:  http.DefaultClient,
+		secret:      secret,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for _, url := range urls {
+		e := &webhookEndpoint{url: url, queue: make(chan webhookDelivery, defaultWebhookQueueSize)}
+		r.endpoints = append(r.endpoints, e)
+		r.wg.Add(1)
+		go r.deliver(e)
+	}
+	return r
+}
+
+// SetErrorHandler sets the handler for undecodable reports and WebhookErrors
+func (r *WebhookRelay) SetErrorHandler(handler ErrHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errHandler = handler
+}
+
+// Attach relays the execution reports of ws. It registers with
+// OnExecutionReport, so the client's execution_report handler keeps
+// receiving its messages.
+func (r *WebhookRelay) Attach(ws *WsClient) error {
+	if err := ws.EnsureSubscribed("execution_report"); err != nil {
+		return err
+	}
+	ws.OnExecutionReport(r.ApplyExecutionReport)
+	return nil
+}
+
+// HandleExecutionReport queues an execution_report message for every URL.
+// It never blocks; events for an endpoint whose queue is full are dropped
+// and reported.
+func (r *WebhookRelay) HandleExecutionReport(message []byte) {
+	var report struct {
+		Message json.RawMessage `json:"message"`
+	}
+	if err := json.Unmarshal(message, &report); err != nil {
+		r.error(err)
+		return
+	}
+	r.relay(report.Message)
+}
+
+// ApplyExecutionReport queues a decoded execution report for every URL, like
+// HandleExecutionReport. It is an ExecutionReportHandler.
+func (r *WebhookRelay) ApplyExecutionReport(detail *WsExecutionReportDetail) {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		r.error(err)
+		return
+	}
+	r.relay(data)
+}
+
+// relay queues an event carrying data for every URL
+func (r *WebhookRelay) relay(data json.RawMessage) {
+	sum := sha256.Sum256(data)
+	event := WebhookEvent{
+		ID:   hex.EncodeToString(sum[:16]),
+		Type: "execution_report",
+		Time: time.Now().UnixMilli(),
+		Data: data,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		r.error(err)
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	for _, e := range r.endpoints {
+		select {
+		case e.queue <- webhookDelivery{event.ID, body}:
+		default:
+			go r.error(&WebhookError{URL: e.url, EventID: event.ID, Err: ErrWebhookQueueFull})
+		}
+	}
+}
+
+// Close stops accepting events, waits for the queued ones to be delivered
+// or to fail, and returns. Use ctx to stop waiting and abandon retries.
+func (r *WebhookRelay) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		for _, e := range r.endpoints {
+			close(e.queue)
+		}
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		r.cancel()
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (r *WebhookRelay) deliver(e *webhookEndpoint) {
+	defer r.wg.Done()
+	for d := range e.queue {
+		if err := r.post(e.url, d.id, d.body); err != nil {
+			r.error(&WebhookError{URL: e.url, EventID: d.id, Err: err})
+		}
+	}
+}
+
+// post delivers one event, retrying failures
+func (r *WebhookRelay) post(url, id string, body []byte) error {
+	attempts := r.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	delay := r.RetryDelay
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-r.ctx.Done():
+				return err
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		var retry bool
+		if retry, err = r.postOnce(url, id, body); err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (r *WebhookRelay) postOnce(url, id string, body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(r.ctx, r.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventIDHeader, id)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(r.secret, time.Now(), body))
+
+	res, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	res.Body.Close()
+
+	switch {
+	case res.StatusCode < http.StatusMultipleChoices:
+		return false, nil
+	case res.StatusCode >= http.StatusInternalServerError,
+		res.StatusCode == http.StatusRequestTimeout,
+		res.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("unexpected status %d", res.StatusCode)
+	default:
+		return false, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+}
+
+func (r *WebhookRelay) error(err error) {
+	r.mu.RLock()
+	handler := r.errHandler
+	r.mu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// SignWebhook returns the WebhookSignatureHeader value for body sent at t
+func SignWebhook(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + webhookMAC(secret, ts, body)
+}
+
+// VerifyWebhookSignature checks the WebhookSignatureHeader of a received
+// webhook. Signatures older than tolerance are rejected to stop replays;
+// zero disables the check.
+func VerifyWebhookSignature(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, mac string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			mac = v
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || mac == "" {
+		return fmt.Errorf("%w: malformed header", ErrWebhookSignature)
+	}
+	if !hmac.Equal([]byte(mac), []byte(webhookMAC(secret, ts, body))) {
+		return fmt.Errorf("%w: signature mismatch", ErrWebhookSignature)
+	}
+	if age := time.Since(time.Unix(sec, 0)); tolerance > 0 && (age > tolerance || age < -tolerance) {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrWebhookSignature)
+	}
+	return nil
+}
+
+func webhookMAC(secret, ts string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
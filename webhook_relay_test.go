@@ -0,0 +1,134 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookRelay(t *testing.T) {
+	var mu sync.Mutex
+	var events []WebhookEvent
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := VerifyWebhookSignature("secret", r.Header.Get(WebhookSignatureHeader), body, time.Minute); err != nil {
+			t.Errorf("Unexpected signature error: %v", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var event WebhookEvent
+		json.Unmarshal(body, &event)
+		if event.ID != r.Header.Get(WebhookEventIDHeader) {
+			t.Errorf("Event ID header %s does not match body %s", r.Header.Get(WebhookEventIDHeader), event.ID)
+		}
+		events = append(events, event)
+	}))
+	defer server.Close()
+
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer rejecting.Close()
+
+	relay := NewWebhookRelay("secret", server.URL, rejecting.URL)
+	relay.RetryDelay = time.Millisecond
+
+	var errMu sync.Mutex
+	var errs []error
+	relay.SetErrorHandler(func(err error) {
+		errMu.Lock()
+		errs = append(errs, err)
+		errMu.Unlock()
+	})
+
+	relay.HandleExecutionReport([]byte(`{"op":"execution_report","success":true,"message":{"order_id":1,"status":"NEW"}}`))
+	relay.HandleExecutionReport([]byte(`{"op":"execution_report","success":true,"message":{"order_id":1,"status":"FILLED"}}`))
+	if err := relay.Close(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(events) != 2 || attempts != 3 {
+		t.Fatalf("Expected 2 events in 3 attempts, got %d in %d", len(events), attempts)
+	}
+	if string(events[1].Data) != `{"order_id":1,"status":"FILLED"}` || events[0].ID == events[1].ID {
+		t.Errorf("Unexpected events %+v", events)
+	}
+
+	// The 410 endpoint fails each event once, without retries
+	var webhookErr *WebhookError
+	if len(errs) != 2 || !errors.As(errs[0], &webhookErr) || webhookErr.URL != rejecting.URL {
+		t.Errorf("Expected 2 errors from the rejecting endpoint, got %v", errs)
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	header := SignWebhook("secret", time.Now(), body)
+
+	if err := VerifyWebhookSignature("secret", header, body, time.Minute); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := VerifyWebhookSignature("other", header, body, time.Minute); !errors.Is(err, ErrWebhookSignature) {
+		t.Errorf("Expected ErrWebhookSignature for wrong secret, got %v", err)
+	}
+	old := SignWebhook("secret", time.Now().Add(-time.Hour), body)
+	if err := VerifyWebhookSignature("secret", old, body, time.Minute); !errors.Is(err, ErrWebhookSignature) {
+		t.Errorf("Expected ErrWebhookSignature for old signature, got %v", err)
+	}
+	if err := VerifyWebhookSignature("secret", "garbage", body, 0); !errors.Is(err, ErrWebhookSignature) {
+		t.Errorf("Expected ErrWebhookSignature for malformed header, got %v", err)
+	}
+}
+
+func TestWebhookRelayAttach(t *testing.T) {
+	events := make(chan WebhookEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event WebhookEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+	}))
+	defer server.Close()
+
+	relay := NewWebhookRelay("secret", server.URL)
+	defer relay.Close(context.Background())
+
+	wsServer := newTestWsServer(t)
+	ws := newTestWsClient(t, wsServer)
+	// A handler set before Attach keeps its messages
+	reports := make(chan struct{}, 1)
+	if err := ws.SubscribeExecutionReport(func([]byte) { reports <- struct{}{} }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := relay.Attach(ws); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wsServer.push(basicExecutionReport(5, OrderStatusNew, 1000))
+
+	select {
+	case event := <-events:
+		var detail WsExecutionReportDetail
+		if err := json.Unmarshal(event.Data, &detail); err != nil || detail.OrderID != 5 || detail.Status != OrderStatusNew {
+			t.Errorf("Unexpected event %+v (%v)", event, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the webhook")
+	}
+	select {
+	case <-reports:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the client's handler to keep receiving reports")
+	}
+}
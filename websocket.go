@@ -1,10 +1,10 @@
 package versifi
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -14,11 +14,13 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-// WebSocket configuration
-var (
-	BaseWSMainURL      = "wss://example.com/v1/ws" // Update with actual production URL
-	WebsocketTimeout   = time.Second * 60
-	WebsocketKeepalive = true
+// WebSocket defaults. Each client carries its own settings in BaseURL,
+// KeepaliveInterval, KeepaliveTimeout and DisableKeepalive, so clients with
+// different settings can run side by side.
+const (
+	BaseWSMainURL    = "wss://example.com/v1/ws"         // Update with actual production URL
+	BaseWSTestnetURL = "wss://testnet.example.com/v1/ws" // Update with actual testnet URL
+	WebsocketTimeout = time.Second * 60
 )
 
 // WsHandler handles websocket messages
@@ -27,22 +29,109 @@ type WsHandler func(message []byte)
 // ErrHandler handles websocket errors
 type ErrHandler func(err error)
 
+// Errors returned by WsClient. Failures to dial or write to the connection
+// also match ErrTransport.
+var (
+	ErrAlreadyConnected = errors.New("already connected")
+	ErrNotConnected     = errors.New("not connected")
+	ErrNotAuthenticated = errors.New("not authenticated")
+	// ErrAuthFailed is matched when the server rejects the credentials
+	ErrAuthFailed = errors.New("authentication failed")
+	// ErrAuthTimeout is returned when the server does not answer the auth
+	// request in time
+	ErrAuthTimeout = errors.New("authentication timeout")
+)
+
 // WsClient represents a websocket client
 type WsClient struct {
 	APIKey         string
 	APISecret      string
 	BaseURL        string
-	LocalAddr      string // Local IP address to bind to (optional)
+	LocalAddr      string        // Local IP address to bind to (optional)
+	AuthExpiry     time.Duration // Lifetime of each auth signature
+	ReauthInterval time.Duration // Re-authenticate this often, zero disables renewal
+	// Ping interval and maximum silence before the connection is considered
+	// dead, zero uses WebsocketTimeout/2 and WebsocketTimeout
+	KeepaliveInterval time.Duration
+	KeepaliveTimeout  time.Duration
+	// DisableKeepalive stops the client sending pings and timing out silent
+	// connections
+	DisableKeepalive bool
+	// Codec controls the wire encoding, nil uses JSONCodec
+	Codec WsCodec
+	// ReplayOnReconnect sends {"op": "replay", "since": ts} after reconnecting
+	// so the server resends missed execution reports, if it supports replay
+	ReplayOnReconnect bool
+	// FallbackURLs are tried in order when BaseURL cannot be reached
+	FallbackURLs []string
+	// ProtocolVersion is sent with the auth request when set, so the server
+	// can select a protocol version; see SupportedProtocolVersion
+	ProtocolVersion string
+	// MaxSendQueue caps how many messages may wait for the send limiter,
+	// zero means no cap; see SetSendLimiter
+	MaxSendQueue int
+	// Heartbeat alert thresholds, zero disables the check
+	MaxPingRTT time.Duration
+	MaxSilence time.Duration
+	// Clock times auth expiry, keepalive, session renewal and reconnect
+	// delays, nil uses SystemClock
+	Clock Clock
 	conn           *websocket.Conn
 	mu             sync.RWMutex
+	writeMu        sync.Mutex
 	isConnected    bool
 	isAuthenticated bool
 	handlers       map[string]WsHandler
+	subscriptions  map[string]bool
 	errHandler     ErrHandler
 	done           chan struct{}
+	readDone       chan struct{}
+	lastPong       time.Time
+	lastMessage    time.Time
+	pingSentAt     time.Time
+	lastPingRTT    time.Duration
+	heartbeatHandler HeartbeatHandler
+	inflight       sync.WaitGroup
+	draining       bool
 	reconnect      bool
 	reconnectDelay time.Duration
+	reconnects     int
+	connectedAt    time.Time
+	sessionHandler SessionHandler
+	resumeHandler  ResumeHandler
+	lastReportTime int64
+	activeEndpoint int
+	activeURL      string
+	endpointHandler EndpointHandler
+	reconnectHandler ReconnectHandler
+	schemaHandler  SchemaHandler
+	serverVersion  string
+	ackPolicy      AckPolicy
+	ackedIDs       map[string]struct{}
+	ackOrder       []string
+	reauth         chan struct{}
+	sendLimiter    RateLimiter
+	sendQueue      int
+	metrics        WsMetrics
+	tap            MessageTap
+	journal        Journal
 	Logger         *log.Logger
+	LogLevel       LogLevel // Minimum level written to Logger
+	LogMessages    bool     // Log every received message body at debug level
+	leveledLogger  LeveledLogger
+	creds          *Credentials
+	// ReuseBuffers reads frames into, and encodes messages sent by SendJSON
+	// in, pooled buffers instead of allocating one per message. Messages
+	// passed to handlers, taps and codecs are then only valid until they
+	// return; copy any you keep.
+	ReuseBuffers        bool
+	reportHandlers      map[int]ExecutionReportHandler
+	reportHandlerList   []ExecutionReportHandler
+	nextReportHandlerID int
+	resumeHandlers      map[int]ResumeHandler
+	nextResumeHandlerID int
+	reconnectHandlers   map[int]ReconnectHandler
+	nextReconnectID     int
 }
 
 // NewWsClient creates a new websocket client
@@ -50,56 +139,66 @@ func NewWsClient(apiKey, apiSecret string) *WsClient {
 	return &WsClient{
 		APIKey:         apiKey,
 		APISecret:      apiSecret,
-		BaseURL:        getWSEndpoint(),
+		BaseURL:        BaseWSMainURL,
+		AuthExpiry:     DefaultAuthExpiry,
+		ReauthInterval: DefaultReauthInterval,
 		handlers:       make(map[string]WsHandler),
+		subscriptions:  make(map[string]bool),
 		done:           make(chan struct{}),
+		reauth:         make(chan struct{}, 1),
 		reconnect:      true,
 		reconnectDelay: 5 * time.Second,
 		Logger:         log.Default(),
+		LogLevel:       LogLevelInfo,
 	}
 }
 
+// NewTestnetWsClient creates a websocket client for the sandbox environment
+func NewTestnetWsClient(apiKey, apiSecret string) *WsClient {
+	c := NewWsClient(apiKey, apiSecret)
+	c.BaseURL = BaseWSTestnetURL
+	return c
+}
+
 // NewWsClientWithLocalAddr creates a new websocket client that binds to a specific local IP address
 // This is useful when the server has multiple IP addresses but only one is whitelisted
 func NewWsClientWithLocalAddr(apiKey, apiSecret, localAddr string) *WsClient {
 	return &WsClient{
 		APIKey:         apiKey,
 		APISecret:      apiSecret,
-		BaseURL:        getWSEndpoint(),
+		BaseURL:        BaseWSMainURL,
 		LocalAddr:      localAddr,
+		AuthExpiry:     DefaultAuthExpiry,
+		ReauthInterval: DefaultReauthInterval,
 		handlers:       make(map[string]WsHandler),
+		subscriptions:  make(map[string]bool),
 		done:           make(chan struct{}),
+		reauth:         make(chan struct{}, 1),
 		reconnect:      true,
 		reconnectDelay: 5 * time.Second,
 		Logger:         log.Default(),
+		LogLevel:       LogLevelInfo,
 	}
 }
 
-func getWSEndpoint() string {
-	if UseTestnet {
-		return BaseWSMainURL // Update if testnet has different URL
-	}
-	return BaseWSMainURL
-}
-
 // Connect establishes websocket connection and authenticates
 func (c *WsClient) Connect() error {
 	c.mu.Lock()
 	if c.isConnected {
 		c.mu.Unlock()
-		return fmt.Errorf("already connected")
+		return ErrAlreadyConnected
 	}
 	c.mu.Unlock()
 
-	// Create websocket dialer
-	dialer := websocket.DefaultDialer
+	// Create websocket dialer, copied so DefaultDialer is not modified
+	dialer := *websocket.DefaultDialer
 	dialer.HandshakeTimeout = 45 * time.Second
 
 	// If local address is specified, configure the dialer to bind to it
 	if c.LocalAddr != "" {
 		localTCPAddr, err := net.ResolveTCPAddr("tcp", c.LocalAddr+":0")
 		if err != nil {
-			c.Logger.Printf("Warning: failed to resolve local address %s: %v", c.LocalAddr, err)
+			c.log().Warnf("Warning: failed to resolve local address %s: %v", c.LocalAddr, err)
 		} else {
 			// Create custom net dialer with local address binding
 			netDialer := &net.Dialer{
@@ -108,19 +207,24 @@ func (c *WsClient) Connect() error {
 				KeepAlive: 30 * time.Second,
 			}
 			dialer.NetDial = netDialer.Dial
-			c.Logger.Printf("WebSocket binding to local address: %s", c.LocalAddr)
+			c.log().Infof("WebSocket binding to local address: %s", c.LocalAddr)
 		}
 	}
 
-	// Dial websocket (no headers needed for initial connection)
-	conn, _, err := dialer.Dial(c.BaseURL, nil)
+	conn, err := c.dialEndpoints(&dialer)
 	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+		return fmt.Errorf("failed to connect: %w: %w", ErrTransport, err)
 	}
 
+	c.handleControlFrames(conn)
+
 	c.mu.Lock()
 	c.conn = conn
 	c.isConnected = true
+	c.readDone = make(chan struct{})
+	c.lastPong = c.clock().Now()
+	c.connectedAt = c.lastPong
+	c.lastMessage = c.lastPong
 	c.mu.Unlock()
 
 	// Start reading messages
@@ -128,40 +232,57 @@ func (c *WsClient) Connect() error {
 
 	// Authenticate after connection
 	if err := c.authenticate(); err != nil {
+		c.wsMetrics().AuthFailure()
 		c.Disconnect()
-		return fmt.Errorf("authentication failed: %w", err)
+		return fmt.Errorf("authenticate: %w", err)
 	}
 
+	c.mu.RLock()
+	readDone := c.readDone
+	c.mu.RUnlock()
+
 	// Start keepalive if enabled
-	if WebsocketKeepalive {
-		go c.keepAlive()
+	if !c.DisableKeepalive {
+		go c.keepAlive(conn, readDone)
 	}
 
+	go c.renewSession(readDone)
+
+	c.notifyEndpoint()
+
 	return nil
 }
 
 // authenticate sends authentication message to the server
 func (c *WsClient) authenticate() error {
-	// Calculate expiration timestamp (e.g., 5 minutes from now)
-	expires := time.Now().Add(5 * time.Minute).Unix()
+	// Calculate expiration timestamp
+	expiry := c.AuthExpiry
+	if expiry <= 0 {
+		expiry = DefaultAuthExpiry
+	}
+	expires := c.clock().Now().Add(expiry).Unix()
 
 	// Create payload for signature: "GET/realtime{expires}"
 	payload := fmt.Sprintf("GET/realtime%d", expires)
 
 	// Generate signature
-	signature := c.sign(payload)
+	creds := c.credentials()
+	signature := sign(creds.APISecret, payload)
 
 	// Send authentication message
 	authMsg := map[string]interface{}{
 		"op": "auth",
 		"args": []interface{}{
-			c.APIKey,
+			creds.APIKey,
 			fmt.Sprintf("%d", expires),
 			signature,
 		},
 	}
+	if c.ProtocolVersion != "" {
+		authMsg["version"] = c.ProtocolVersion
+	}
 
-	c.Logger.Printf("Sending authentication message...")
+	c.log().Debugf("Sending authentication message...")
 
 	if err := c.SendJSON(authMsg); err != nil {
 		return err
@@ -172,7 +293,7 @@ func (c *WsClient) authenticate() error {
 	tempHandler := func(message []byte) {
 		var resp WsResponse
 		if err := json.Unmarshal(message, &resp); err != nil {
-			authResponse <- fmt.Errorf("failed to parse auth response: %w", err)
+			authResponse <- fmt.Errorf("%w: failed to parse auth response: %w", ErrAuthFailed, err)
 			return
 		}
 
@@ -181,10 +302,11 @@ func (c *WsClient) authenticate() error {
 				c.mu.Lock()
 				c.isAuthenticated = true
 				c.mu.Unlock()
-				c.Logger.Printf("Authentication successful")
+				c.log().Infof("Authentication successful")
+				c.checkServerVersion(resp.Version)
 				authResponse <- nil
 			} else {
-				authResponse <- fmt.Errorf("authentication failed: %v", resp.Message)
+				authResponse <- fmt.Errorf("%w: %v", ErrAuthFailed, resp.Message)
 			}
 		}
 	}
@@ -195,45 +317,111 @@ func (c *WsClient) authenticate() error {
 	c.mu.Unlock()
 
 	// Wait for auth response or timeout
+	timeout := c.clock().NewTimer(10 * time.Second)
+	defer timeout.Stop()
 	select {
 	case err := <-authResponse:
 		c.mu.Lock()
 		delete(c.handlers, "__auth__")
 		c.mu.Unlock()
 		return err
-	case <-time.After(10 * time.Second):
+	case <-timeout.C():
 		c.mu.Lock()
 		delete(c.handlers, "__auth__")
 		c.mu.Unlock()
-		return fmt.Errorf("authentication timeout")
+		return ErrAuthTimeout
 	}
 }
 
-// Disconnect closes the websocket connection
+// Disconnect closes the websocket connection immediately.
+// Use Shutdown to let running handlers finish first.
 func (c *WsClient) Disconnect() error {
+	return c.disconnect(true)
+}
+
+// Shutdown gracefully closes the connection. It stops dispatching new messages,
+// waits for running handlers to return, sends a close frame and waits for the
+// server to acknowledge it before tearing the connection down. If ctx expires
+// first the connection is closed immediately and ctx.Err() is returned.
+// Shutdown must not be called from a handler.
+func (c *WsClient) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	c.draining = true
+	c.reconnect = false
+	conn := c.conn
+	readDone := c.readDone
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		c.disconnect(true)
+		return ctx.Err()
+	}
+
+	c.writeMu.Lock()
+	err := conn.WriteMessage(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+	)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.log().Warnf("error sending close message: %v", err)
+		return c.disconnect(false)
+	}
+
+	// The server echoes the close frame, which ends the read loop
+	select {
+	case <-readDone:
+	case <-ctx.Done():
+		c.disconnect(false)
+		return ctx.Err()
+	}
+
+	return c.disconnect(false)
+}
+
+// disconnect tears down the connection, optionally sending a close frame first
+func (c *WsClient) disconnect(sendClose bool) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if !c.isConnected {
+	if c.conn == nil {
 		return nil
 	}
 
 	c.reconnect = false
-	close(c.done)
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
 
-	if c.conn != nil {
+	if sendClose {
+		c.writeMu.Lock()
 		err := c.conn.WriteMessage(
 			websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
 		)
+		c.writeMu.Unlock()
 		if err != nil {
-			c.Logger.Printf("error sending close message: %v", err)
+			c.logLocked().Warnf("error sending close message: %v", err)
 		}
+	}
 
-		err = c.conn.Close()
-		if err != nil {
-			return fmt.Errorf("failed to close connection: %w", err)
-		}
+	err := c.conn.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close connection: %w", err)
 	}
 
 	c.isConnected = false
@@ -248,12 +436,14 @@ func (c *WsClient) Subscribe(topic string, handler WsHandler) error {
 	c.mu.RLock()
 	if !c.isAuthenticated {
 		c.mu.RUnlock()
-		return fmt.Errorf("not authenticated")
+		return ErrNotAuthenticated
 	}
 	c.mu.RUnlock()
 
+	c.Handle(topic, handler)
+
 	c.mu.Lock()
-	c.handlers[topic] = handler
+	c.subscriptions[topic] = true
 	c.mu.Unlock()
 
 	// Send subscription message
@@ -265,10 +455,33 @@ func (c *WsClient) Subscribe(topic string, handler WsHandler) error {
 	return c.SendJSON(subscribeMsg)
 }
 
+// EnsureSubscribed subscribes to topic unless already subscribed, leaving
+// its handler as it is. Helpers registered with OnExecutionReport use it in
+// place of SubscribeExecutionReport, which would replace the handler.
+func (c *WsClient) EnsureSubscribed(topic string) error {
+	c.mu.Lock()
+	if !c.isAuthenticated {
+		c.mu.Unlock()
+		return ErrNotAuthenticated
+	}
+	if c.subscriptions[topic] {
+		c.mu.Unlock()
+		return nil
+	}
+	c.subscriptions[topic] = true
+	c.mu.Unlock()
+
+	return c.SendJSON(map[string]interface{}{
+		"op":   "subscribe",
+		"args": []string{topic},
+	})
+}
+
 // Unsubscribe unsubscribes from a specific topic
 func (c *WsClient) Unsubscribe(topic string) error {
 	c.mu.Lock()
 	delete(c.handlers, topic)
+	delete(c.subscriptions, topic)
 	c.mu.Unlock()
 
 	// Send unsubscription message (if needed)
@@ -293,14 +506,60 @@ func (c *WsClient) SetErrorHandler(handler ErrHandler) {
 
 // SendJSON sends a JSON message
 func (c *WsClient) SendJSON(v interface{}) error {
+	return c.sendJSON(v, nil)
+}
+
+// sendJSON sends v, calling beforeWrite, if set, once v waited for the
+// send limiter and is about to be written
+func (c *WsClient) sendJSON(v interface{}, beforeWrite func()) error {
+	if err := c.waitToSend(); err != nil {
+		return err
+	}
+
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	conn := c.conn
+	isConnected := c.isConnected
+	c.mu.RUnlock()
+
+	if !isConnected || conn == nil {
+		return ErrNotConnected
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if c.ReuseBuffers {
+		var buf *bytes.Buffer
+		if buf, err = encodeJSON(v); err != nil {
+			return err
+		}
+		defer putBuffer(buf)
+		data = buf.Bytes()
+	} else if data, err = json.Marshal(v); err != nil {
+		return err
+	}
+
+	frameType, frame, err := c.codec().Encode(data)
+	if err != nil {
+		return err
+	}
 
-	if !c.isConnected || c.conn == nil {
-		return fmt.Errorf("not connected")
+	if beforeWrite != nil {
+		beforeWrite()
+	}
+	// gorilla connections support only one concurrent writer
+	c.writeMu.Lock()
+	err = conn.WriteMessage(frameType, frame)
+	c.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTransport, err)
 	}
 
-	return c.conn.WriteJSON(v)
+	c.tapMessage(MessageOutbound, data)
+	c.journalMessage(MessageOutbound, data)
+	c.wsMetrics().MessageSent(len(frame))
+	return nil
 }
 
 // SendPing sends a ping message
@@ -308,27 +567,48 @@ func (c *WsClient) SendPing() error {
 	pingMsg := map[string]string{
 		"op": "ping",
 	}
-	return c.SendJSON(pingMsg)
+	// The round trip is timed from the write, not from the limiter wait
+	return c.sendJSON(pingMsg, c.markPingSent)
 }
 
 // readMessages reads messages from websocket
 func (c *WsClient) readMessages() {
+	c.mu.RLock()
+	readDone := c.readDone
+	c.mu.RUnlock()
+
 	defer func() {
 		c.mu.Lock()
 		c.isConnected = false
 		c.isAuthenticated = false
+		reconnect := c.reconnect
 		c.mu.Unlock()
+		close(readDone)
 
 		// Attempt reconnection if enabled
-		if c.reconnect {
-			c.Logger.Printf("connection lost, attempting to reconnect in %v", c.reconnectDelay)
-			time.Sleep(c.reconnectDelay)
-			if err := c.Connect(); err != nil {
-				c.Logger.Printf("reconnection failed: %v", err)
+		if reconnect {
+			c.log().Warnf("connection lost, attempting to reconnect in %v", c.reconnectDelay)
+			sleep(c.clock(), c.reconnectDelay, nil)
+			err := c.Connect()
+			c.wsMetrics().Reconnect(err)
+			c.notifyReconnect(err)
+			if err != nil {
+				c.log().Errorf("reconnection failed: %v", err)
+				if c.errHandler != nil {
+					c.errHandler(err)
+				}
+				return
+			}
+			c.mu.Lock()
+			c.reconnects++
+			c.mu.Unlock()
+			if err := c.resubscribe(); err != nil {
+				c.log().Errorf("resubscription failed: %v", err)
 				if c.errHandler != nil {
 					c.errHandler(err)
 				}
 			}
+			c.resume()
 		}
 	}()
 
@@ -337,125 +617,215 @@ func (c *WsClient) readMessages() {
 		case <-c.done:
 			return
 		default:
-			_, message, err := c.conn.ReadMessage()
+			frameType, frame, buf, err := c.readFrame(c.conn)
 			if err != nil {
-				c.Logger.Printf("error reading message: %v", err)
+				c.mu.RLock()
+				draining := c.draining
+				c.mu.RUnlock()
+
+				// The close handshake started by Shutdown ends here
+				if draining {
+					return
+				}
+
+				c.log().Errorf("error reading message: %v", err)
 				if c.errHandler != nil {
 					c.errHandler(err)
 				}
 				return
 			}
 
-			c.Logger.Printf("Received message: %s", string(message))
-
-			// Parse message to determine operation type
-			var wsResp WsResponse
-			if err := json.Unmarshal(message, &wsResp); err != nil {
-				c.Logger.Printf("error unmarshaling message: %v", err)
-				continue
-			}
-
-			// Handle special operations
-			if wsResp.Op == "auth" {
-				// Check if there's an auth handler
-				c.mu.RLock()
-				handler, exists := c.handlers["__auth__"]
-				c.mu.RUnlock()
-
-				if exists && handler != nil {
-					handler(message)
-				}
-				continue
-			}
+			c.handleFrame(frameType, frame)
+			releaseFrame(buf)
+		}
+	}
+}
 
-			if wsResp.Op == "ping" {
-				c.Logger.Printf("Received pong response")
-				continue
-			}
+// handleFrame decodes a received frame and dispatches the message. The
+// message is validated once and its op peeked without decoding it; only
+// the rare auth and subscribe responses are decoded here, the rest is left
+// to handlers.
+func (c *WsClient) handleFrame(frameType int, frame []byte) {
+	c.markMessage()
 
-			if wsResp.Op == "subscribe" {
-				c.Logger.Printf("Subscription confirmed: %v", wsResp.Message)
-				continue
-			}
+	message, err := c.codec().Decode(frameType, frame)
+	if err != nil {
+		c.log().Warnf("error decoding frame: %v", err)
+		return
+	}
 
-			// Handle execution_report messages
-			if wsResp.Op == "execution_report" {
-				c.mu.RLock()
-				handler, exists := c.handlers["execution_report"]
-				c.mu.RUnlock()
+	if c.LogMessages {
+		c.log().Debugf("Received message: %s", string(message))
+	}
+	c.tapMessage(MessageInbound, message)
+	c.journalMessage(MessageInbound, message)
+
+	var (
+		op     string
+		ok     bool
+		wsResp *WsResponse
+	)
+	if json.Valid(message) {
+		op, ok = peekOp(message)
+	}
+	if !ok || op == "auth" || op == "subscribe" {
+		wsResp = new(WsResponse)
+		if err := json.Unmarshal(message, wsResp); err != nil {
+			c.log().Warnf("error unmarshaling message: %v", err)
+			return
+		}
+		op = wsResp.Op
+	}
 
-				if exists && handler != nil {
-					handler(message)
-				}
+	c.wsMetrics().MessageReceived(c.metricOp(op), len(frame))
+	c.checkSchema(op, message)
 
-				// Also call wildcard handler if exists
-				c.mu.RLock()
-				wildcardHandler, exists := c.handlers["*"]
-				c.mu.RUnlock()
+	// Handle special operations
+	switch op {
+	case "auth":
+		// Check if there's an auth handler
+		c.mu.RLock()
+		handler, exists := c.handlers["__auth__"]
+		c.mu.RUnlock()
 
-				if exists && wildcardHandler != nil {
-					wildcardHandler(message)
-				}
-				continue
-			}
+		if exists && handler != nil {
+			handler(message)
+		} else if !wsResp.Success {
+			c.revokeSession(wsResp.Message)
+		}
+		return
+	case "ping":
+		c.markPong()
+		c.markPingAnswered()
+		c.log().Debugf("Received pong response")
+		return
+	case "subscribe":
+		c.log().Infof("Subscription confirmed: %v", wsResp.Message)
+		return
+	}
 
-			// Handle other topics
-			c.mu.RLock()
-			handler, exists := c.handlers[wsResp.Op]
-			c.mu.RUnlock()
+	ackID := c.ackID(message)
+	if ackID != "" && c.acked(ackID) {
+		// Redelivery of a message that was already handled
+		c.sendAck(ackID)
+		return
+	}
 
-			if exists && handler != nil {
-				handler(message)
-			} else {
-				// Call wildcard handler
-				c.mu.RLock()
-				wildcardHandler, exists := c.handlers["*"]
-				c.mu.RUnlock()
+	var handlerErr error
+	if op == "execution_report" {
+		timestamp, decoded, err := c.applyExecutionReport(message)
+		if decoded {
+			c.recordReportTime(timestamp)
+		} else {
+			c.recordExecutionReport(message)
+		}
+		handlerErr = err
+	}
 
-				if exists && wildcardHandler != nil {
-					wildcardHandler(message)
-				}
-			}
+	if pattern, handler := c.route(op); handler != nil {
+		if err := c.dispatch(pattern, handler, message); handlerErr == nil {
+			handlerErr = err
 		}
 	}
+
+	c.settle(ackID, op, handlerErr)
 }
 
-// keepAlive sends periodic ping messages
-func (c *WsClient) keepAlive() {
-	ticker := time.NewTicker(WebsocketTimeout / 2)
+// keepAlive sends periodic application and protocol pings on conn and
+// closes it when no pong has been seen within the keepalive timeout
+func (c *WsClient) keepAlive(conn *websocket.Conn, readDone <-chan struct{}) {
+	ticker := c.clock().NewTicker(c.keepaliveInterval())
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-c.done:
 			return
-		case <-ticker.C:
+		case <-readDone:
+			return
+		case <-ticker.C():
 			c.mu.RLock()
 			isConnected := c.isConnected
+			lastPong := c.lastPong
 			c.mu.RUnlock()
 
 			if !isConnected {
 				return
 			}
 
-			// Send application-level ping (not WebSocket ping)
+			c.checkSilence()
+
+			if silence := c.clock().Now().Sub(lastPong); silence > c.keepaliveTimeout() {
+				err := fmt.Errorf("keepalive timeout: no pong for %v", silence)
+				c.log().Warnf("%v", err)
+				if c.errHandler != nil {
+					c.errHandler(err)
+				}
+				// Closing the connection ends the read loop, which reconnects
+				conn.Close()
+				return
+			}
+
+			// Send application-level ping
 			if err := c.SendPing(); err != nil {
-				c.Logger.Printf("error sending ping: %v", err)
+				c.log().Errorf("error sending ping: %v", err)
 				if c.errHandler != nil {
 					c.errHandler(err)
 				}
 				return
 			}
+
+			// Send protocol-level ping, answered by the peer's websocket stack
+			deadline := time.Now().Add(c.keepaliveInterval())
+			if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				c.log().Warnf("error sending ping frame: %v", err)
+			}
 		}
 	}
 }
 
-// sign creates HMAC SHA256 signature
-func (c *WsClient) sign(payload string) string {
-	key := []byte(c.APISecret)
-	h := hmac.New(sha256.New, key)
-	h.Write([]byte(payload))
-	return hex.EncodeToString(h.Sum(nil))
+// clock returns the Clock timing the client
+func (c *WsClient) clock() Clock {
+	return clockOrSystem(c.Clock)
+}
+
+// keepaliveInterval returns how often pings are sent
+func (c *WsClient) keepaliveInterval() time.Duration {
+	if c.KeepaliveInterval > 0 {
+		return c.KeepaliveInterval
+	}
+	return WebsocketTimeout / 2
+}
+
+// keepaliveTimeout returns how long the connection may go without a pong
+func (c *WsClient) keepaliveTimeout() time.Duration {
+	if c.KeepaliveTimeout > 0 {
+		return c.KeepaliveTimeout
+	}
+	return WebsocketTimeout
+}
+
+// handleControlFrames answers server pings and records pongs as liveness
+func (c *WsClient) handleControlFrames(conn *websocket.Conn) {
+	conn.SetPingHandler(func(appData string) error {
+		c.markPong()
+		err := conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(time.Second))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+	conn.SetPongHandler(func(string) error {
+		c.markPong()
+		return nil
+	})
+}
+
+// markPong records that the server has shown it is alive
+func (c *WsClient) markPong() {
+	c.mu.Lock()
+	c.lastPong = c.clock().Now()
+	c.mu.Unlock()
 }
 
 // IsConnected returns the connection status
@@ -479,6 +849,7 @@ type WsResponse struct {
 	Op      string      `json:"op"`
 	Success bool        `json:"success"`
 	Message interface{} `json:"message,omitempty"`
+	Version string      `json:"version,omitempty"` // Protocol version, sent with auth responses
 }
 
 // WsExecutionReport represents the execution_report message
@@ -494,9 +865,14 @@ type WsExecutionReportDetail struct {
 	ClientOrderID    int64           `json:"client_order_id"`
 	OrderType        string          `json:"order_type"`
 	Status           OrderStatusType `json:"status"`
-	Timestamp        int64           `json:"timestamp"`
+	Timestamp        Timestamp       `json:"timestamp"`
 	RequestOrderType string          `json:"request_order_type"`
-	Order            interface{}     `json:"order"` // Can be BasicOrder, AlgoOrder, or PairOrder
+	Order            json.RawMessage `json:"order"` // Raw order, decoded into one of the fields below
+
+	// Set according to RequestOrderType when the report is decoded
+	Basic *WsBasicOrderDetail `json:"-"`
+	Algo  *WsAlgoOrderDetail  `json:"-"`
+	Pair  *WsPairOrderDetail  `json:"-"`
 }
 
 // WsBasicOrderDetail represents a basic order in execution report
@@ -0,0 +1,1264 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testWsServer is a minimal server speaking the auth/subscribe/ping protocol
+type testWsServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	received chan map[string]interface{}
+	// ignorePings stops the server answering both kinds of ping
+	ignorePings bool
+	// binaryFrames counts frames received with the binary frame type
+	binaryFrames int
+	// authVersion is returned as the protocol version in auth responses
+	authVersion string
+	// rejectAuth fails auth requests
+	rejectAuth bool
+}
+
+func newTestWsServer(t *testing.T) *testWsServer {
+	s := &testWsServer{received: make(chan map[string]interface{}, 100)}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		s.mu.Lock()
+		s.conn = conn
+		ignorePings := s.ignorePings
+		authVersion := s.authVersion
+		s.mu.Unlock()
+
+		if ignorePings {
+			conn.SetPingHandler(func(string) error { return nil })
+		}
+
+		for {
+			frameType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if frameType == websocket.BinaryMessage {
+				s.mu.Lock()
+				s.binaryFrames++
+				s.mu.Unlock()
+			}
+
+			var msg map[string]interface{}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				continue
+			}
+			s.received <- msg
+
+			if msg["op"] == "ping" && ignorePings {
+				continue
+			}
+
+			s.mu.Lock()
+			rejectAuth := s.rejectAuth
+			s.mu.Unlock()
+
+			switch msg["op"] {
+			case "auth", "subscribe", "ping":
+				resp := map[string]interface{}{"op": msg["op"], "success": !(msg["op"] == "auth" && rejectAuth)}
+				if msg["op"] == "auth" && authVersion != "" {
+					resp["version"] = authVersion
+				}
+				s.push(resp)
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testWsServer) url() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func (s *testWsServer) push(v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conn.WriteJSON(v)
+}
+
+func newTestWsClient(t *testing.T, s *testWsServer, setup ...func(*WsClient)) *WsClient {
+	c := NewWsClient("test-key", "test-secret")
+	c.BaseURL = s.url()
+	c.Logger = log.New(io.Discard, "", 0)
+	for _, f := range setup {
+		f(c)
+	}
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { c.Disconnect() })
+	return c
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type recordingWsMetrics struct {
+	mu           sync.Mutex
+	received     map[string]int
+	sent         int
+	handled      map[string]int
+	authFailures int
+}
+
+func (m *recordingWsMetrics) MessageReceived(topic string, bytes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.received[topic]++
+}
+
+func (m *recordingWsMetrics) MessageSent(bytes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent++
+}
+
+func (m *recordingWsMetrics) HandlerLatency(topic string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handled[topic]++
+}
+
+func (m *recordingWsMetrics) Reconnect(err error) {}
+
+func (m *recordingWsMetrics) AuthFailure() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.authFailures++
+}
+
+func TestWsMetrics(t *testing.T) {
+	server := newTestWsServer(t)
+	client := newTestWsClient(t, server)
+
+	metrics := &recordingWsMetrics{received: map[string]int{}, handled: map[string]int{}}
+	client.SetMetrics(metrics)
+
+	done := make(chan struct{})
+	err := client.SubscribeExecutionReport(func(message []byte) {
+		close(done)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Ops the client does not know are counted together
+	server.push(map[string]interface{}{"op": "promo_1", "success": true})
+	server.push(map[string]interface{}{"op": "promo_2", "success": true})
+	server.push(map[string]interface{}{
+		"op":      "execution_report",
+		"success": true,
+		"message": map[string]interface{}{"order_id": 1, "status": "NEW"},
+	})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for execution report")
+	}
+
+	waitFor(t, func() bool {
+		metrics.mu.Lock()
+		defer metrics.mu.Unlock()
+		return metrics.handled["execution_report"] == 1
+	})
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	if metrics.sent != 1 {
+		t.Errorf("Expected 1 sent message, got %d", metrics.sent)
+	}
+
+	if metrics.received["execution_report"] != 1 {
+		t.Errorf("Expected 1 execution_report received, got %d", metrics.received["execution_report"])
+	}
+	if metrics.received[otherOp] != 2 || metrics.received["promo_1"] != 0 {
+		t.Errorf("Expected unknown ops to be counted as %s, got %v", otherOp, metrics.received)
+	}
+}
+
+func TestWsMessageTap(t *testing.T) {
+	server := newTestWsServer(t)
+
+	var mu sync.Mutex
+	var frames []string
+	tap := func(direction MessageDirection, message []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		frames = append(frames, string(direction)+" "+string(message))
+	}
+
+	newTestWsClient(t, server, func(c *WsClient) {
+		c.SetMessageTap(tap)
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, got %d: %v", len(frames), frames)
+	}
+
+	if !strings.HasPrefix(frames[0], "outbound ") || !strings.Contains(frames[0], `"op":"auth"`) {
+		t.Errorf("Expected outbound auth frame, got %s", frames[0])
+	}
+
+	if strings.Contains(frames[0], "test-key") || !strings.Contains(frames[0], redacted) {
+		t.Errorf("Expected credentials to be redacted, got %s", frames[0])
+	}
+
+	if !strings.HasPrefix(frames[1], "inbound ") {
+		t.Errorf("Expected inbound auth response, got %s", frames[1])
+	}
+}
+
+func TestWsJournalReplay(t *testing.T) {
+	server := newTestWsServer(t)
+	journal := &MemoryJournal{}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.Clock = NewSimulatedClock(start)
+		c.SetJournal(journal)
+	})
+
+	received := make(chan struct{}, 1)
+	if err := client.SubscribeExecutionReport(func(message []byte) { received <- struct{}{} }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.push(map[string]interface{}{"op": "execution_report", "success": true, "message": map[string]interface{}{"order_id": 7}})
+	<-received
+
+	entries := journal.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("Expected auth, auth response, subscribe and report, got %+v", entries)
+	}
+	if entries[0].Direction != MessageOutbound || strings.Contains(entries[0].Message, "test-key") {
+		t.Errorf("Expected a redacted outbound auth entry, got %+v", entries[0])
+	}
+	// Entries are stamped by the client's clock
+	for _, entry := range entries {
+		if !entry.Time.Equal(start) {
+			t.Errorf("Expected entries at %v, got %+v", start, entry)
+		}
+	}
+
+	// Replay on a fresh client that is never connected
+	replay := NewWsClient("", "")
+	var replayed []string
+	replay.Handle("*", func(message []byte) { replayed = append(replayed, string(message)) })
+	var typed []int64
+	replay.OnExecutionReport(func(detail *WsExecutionReportDetail) { typed = append(typed, detail.OrderID) })
+	if err := replay.Replay(context.Background(), journal, JournalFilter{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(replayed) != 1 || !strings.Contains(replayed[0], `"order_id":7`) {
+		t.Errorf("Expected only the execution report to be replayed, got %v", replayed)
+	}
+	if len(typed) != 1 || typed[0] != 7 {
+		t.Errorf("Expected the typed handler to receive the replayed report, got %v", typed)
+	}
+	if replay.LastExecutionReportTime() != 0 {
+		t.Error("Expected replay to leave the last report time alone")
+	}
+
+	if err := replay.Replay(context.Background(), journal, JournalFilter{Since: time.Now().Add(time.Hour)}); err != nil || len(replayed) != 1 {
+		t.Errorf("Expected the filter to exclude every entry, got %v %v", err, replayed)
+	}
+}
+
+func TestWsShutdownDrainsHandlers(t *testing.T) {
+	server := newTestWsServer(t)
+	client := newTestWsClient(t, server)
+
+	var errs []error
+	client.SetErrorHandler(func(err error) {
+		errs = append(errs, err)
+	})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	err := client.SubscribeExecutionReport(func(message []byte) {
+		close(started)
+		<-release
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	server.push(map[string]interface{}{"op": "execution_report", "success": true})
+	<-started
+
+	result := make(chan error, 1)
+	go func() {
+		result <- client.Shutdown(context.Background())
+	}()
+
+	select {
+	case err := <-result:
+		t.Fatalf("Shutdown returned before handler finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for shutdown")
+	}
+
+	if client.IsConnected() {
+		t.Error("Expected client to be disconnected")
+	}
+
+	if len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+}
+
+func TestWsShutdownDeadline(t *testing.T) {
+	server := newTestWsServer(t)
+	client := newTestWsClient(t, server)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	err := client.SubscribeExecutionReport(func(message []byte) {
+		close(started)
+		<-release
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	server.push(map[string]interface{}{"op": "execution_report", "success": true})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = client.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+
+	if client.IsConnected() {
+		t.Error("Expected client to be disconnected")
+	}
+}
+
+func TestWsSessionRenewal(t *testing.T) {
+	server := newTestWsServer(t)
+
+	renewed := make(chan struct{}, 10)
+	newTestWsClient(t, server, func(c *WsClient) {
+		c.ReauthInterval = 20 * time.Millisecond
+		c.SetSessionHandler(func(event SessionEvent) {
+			if event.Type == SessionRenewed {
+				renewed <- struct{}{}
+			}
+		})
+	})
+
+	select {
+	case <-renewed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for session renewal")
+	}
+}
+
+func TestWsSessionRenewalAuthFailure(t *testing.T) {
+	server := newTestWsServer(t)
+
+	metrics := &recordingWsMetrics{received: map[string]int{}, handled: map[string]int{}}
+	failed := make(chan struct{}, 10)
+	newTestWsClient(t, server, func(c *WsClient) {
+		c.ReauthInterval = 20 * time.Millisecond
+		c.SetMetrics(metrics)
+		c.SetSessionHandler(func(event SessionEvent) {
+			if event.Type == SessionRenewFailed {
+				failed <- struct{}{}
+			}
+		})
+	})
+	server.mu.Lock()
+	server.rejectAuth = true
+	server.mu.Unlock()
+
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a failed renewal")
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.authFailures == 0 {
+		t.Error("Expected the failed renewal to be counted as an auth failure")
+	}
+}
+
+func TestWsSessionRevoked(t *testing.T) {
+	server := newTestWsServer(t)
+
+	events := make(chan SessionEvent, 10)
+	newTestWsClient(t, server, func(c *WsClient) {
+		c.SetSessionHandler(func(event SessionEvent) {
+			events <- event
+		})
+	})
+
+	server.push(map[string]interface{}{"op": "auth", "success": false, "message": "session expired"})
+
+	select {
+	case event := <-events:
+		if event.Type != SessionRevoked {
+			t.Errorf("Expected %s event, got %s", SessionRevoked, event.Type)
+		}
+		if event.Err == nil {
+			t.Error("Expected revocation error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for revocation")
+	}
+
+	// The client re-authenticates immediately after a revocation
+	select {
+	case event := <-events:
+		if event.Type != SessionRenewed {
+			t.Errorf("Expected %s event, got %s", SessionRenewed, event.Type)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for re-authentication")
+	}
+}
+
+func TestWsAnswersServerPing(t *testing.T) {
+	server := newTestWsServer(t)
+	newTestWsClient(t, server)
+
+	pong := make(chan string, 1)
+	server.mu.Lock()
+	server.conn.SetPongHandler(func(appData string) error {
+		pong <- appData
+		return nil
+	})
+	err := server.conn.WriteControl(websocket.PingMessage, []byte("hello"), time.Now().Add(time.Second))
+	server.mu.Unlock()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case appData := <-pong:
+		if appData != "hello" {
+			t.Errorf("Expected pong data hello, got %s", appData)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for pong")
+	}
+}
+
+func TestWsKeepaliveTimeout(t *testing.T) {
+	server := newTestWsServer(t)
+	server.ignorePings = true
+
+	errs := make(chan error, 10)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.KeepaliveInterval = 20 * time.Millisecond
+		c.KeepaliveTimeout = 100 * time.Millisecond
+		c.reconnect = false
+		c.SetErrorHandler(func(err error) {
+			errs <- err
+		})
+	})
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "keepalive timeout") {
+			t.Errorf("Expected keepalive timeout error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for keepalive timeout")
+	}
+
+	waitFor(t, func() bool {
+		return !client.IsConnected()
+	})
+}
+
+// syncBuffer is a goroutine-safe log sink
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWsMessageBodyLogging(t *testing.T) {
+	for _, logMessages := range []bool{false, true} {
+		server := newTestWsServer(t)
+
+		var out syncBuffer
+		client := newTestWsClient(t, server, func(c *WsClient) {
+			c.Logger = log.New(&out, "", 0)
+			c.LogLevel = LogLevelDebug
+			c.LogMessages = logMessages
+		})
+
+		done := make(chan struct{})
+		client.SubscribeExecutionReport(func(message []byte) {
+			close(done)
+		})
+		server.push(map[string]interface{}{"op": "execution_report", "success": true, "message": "secret-order"})
+		<-done
+
+		logged := strings.Contains(out.String(), "secret-order")
+		if logged != logMessages {
+			t.Errorf("LogMessages=%v: expected body logged=%v, got %v", logMessages, logMessages, logged)
+		}
+	}
+}
+
+type recordingLogger struct {
+	syncBuffer
+}
+
+func (l *recordingLogger) Debugf(format string, v ...interface{}) {}
+
+func (l *recordingLogger) Infof(format string, v ...interface{}) {
+	fmt.Fprintf(l, format+"\n", v...)
+}
+
+func (l *recordingLogger) Warnf(format string, v ...interface{}) {}
+
+func (l *recordingLogger) Errorf(format string, v ...interface{}) {}
+
+func TestWsSetLogger(t *testing.T) {
+	server := newTestWsServer(t)
+
+	logger := &recordingLogger{}
+	newTestWsClient(t, server, func(c *WsClient) {
+		c.SetLogger(logger)
+	})
+
+	if !strings.Contains(logger.String(), "Authentication successful") {
+		t.Errorf("Expected info log from custom logger, got %q", logger.String())
+	}
+}
+
+// binaryCodec sends JSON in binary frames and accepts both frame types
+type binaryCodec struct{}
+
+func (binaryCodec) Encode(message []byte) (int, []byte, error) {
+	return websocket.BinaryMessage, message, nil
+}
+
+func (binaryCodec) Decode(frameType int, frame []byte) ([]byte, error) {
+	return frame, nil
+}
+
+func TestWsCodec(t *testing.T) {
+	server := newTestWsServer(t)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.Codec = binaryCodec{}
+	})
+
+	if err := client.SendPing(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	waitFor(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		// auth and ping
+		return server.binaryFrames == 2
+	})
+}
+
+func TestJSONCodecRejectsBinaryFrames(t *testing.T) {
+	if _, err := (JSONCodec{}).Decode(websocket.BinaryMessage, []byte("{}")); err == nil {
+		t.Error("Expected error for binary frame")
+	}
+}
+
+func TestWsReplayOnReconnect(t *testing.T) {
+	server := newTestWsServer(t)
+
+	resumed := make(chan int64, 1)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.ReplayOnReconnect = true
+		c.reconnectDelay = 10 * time.Millisecond
+		c.SetResumeHandler(func(since int64) {
+			resumed <- since
+		})
+	})
+
+	done := make(chan struct{})
+	client.SubscribeExecutionReport(func(message []byte) {
+		close(done)
+	})
+	server.push(map[string]interface{}{
+		"op":      "execution_report",
+		"success": true,
+		"message": map[string]interface{}{"order_id": 1, "timestamp": 1677721800},
+	})
+	<-done
+
+	if ts := client.LastExecutionReportTime(); ts != 1677721800 {
+		t.Fatalf("Expected last report time 1677721800, got %d", ts)
+	}
+
+	server.mu.Lock()
+	server.conn.Close()
+	server.mu.Unlock()
+
+	select {
+	case since := <-resumed:
+		if since != 1677721800 {
+			t.Errorf("Expected resume since 1677721800, got %d", since)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for resume")
+	}
+
+	for {
+		select {
+		case msg := <-server.received:
+			if msg["op"] != "replay" {
+				continue
+			}
+			if msg["since"] != float64(1677721800) {
+				t.Errorf("Expected replay since 1677721800, got %v", msg["since"])
+			}
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for replay request")
+		}
+	}
+}
+
+func TestWsAckAfterSuccess(t *testing.T) {
+	server := newTestWsServer(t)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.SetAckPolicy(AckAfterSuccess)
+	})
+
+	var mu sync.Mutex
+	calls := 0
+	client.SubscribeExecutionReport(func(message []byte) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n == 1 {
+			panic("handler failed")
+		}
+	})
+
+	report := map[string]interface{}{"op": "execution_report", "success": true, "msg_id": 7}
+	nextAck := func() interface{} {
+		for {
+			select {
+			case msg := <-server.received:
+				if msg["op"] == "ack" {
+					return msg["args"].([]interface{})[0]
+				}
+			case <-time.After(200 * time.Millisecond):
+				return nil
+			}
+		}
+	}
+
+	// First delivery panics and is not acknowledged
+	server.push(report)
+	if id := nextAck(); id != nil {
+		t.Fatalf("Expected no ack after panic, got %v", id)
+	}
+
+	// Redelivery succeeds and is acknowledged
+	server.push(report)
+	if id := nextAck(); id != float64(7) {
+		t.Fatalf("Expected ack for 7, got %v", id)
+	}
+
+	// A duplicate is acknowledged again without calling the handler
+	server.push(report)
+	if id := nextAck(); id != float64(7) {
+		t.Fatalf("Expected ack for 7, got %v", id)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 {
+		t.Errorf("Expected 2 handler calls, got %d", calls)
+	}
+}
+
+func TestWsRoutePrecedence(t *testing.T) {
+	client := NewWsClient("test-key", "test-secret")
+
+	noop := func([]byte) {}
+	client.Handle("*", noop)
+	client.Handle("execution_*", noop)
+	client.Handle("execution_report_*", noop)
+	client.Handle("execution_report", noop)
+
+	tests := map[string]string{
+		"execution_report":    "execution_report",
+		"execution_report_v2": "execution_report_*",
+		"execution_summary":   "execution_*",
+		"analytics":           "*",
+		"execution":           "*",
+	}
+
+	for op, expected := range tests {
+		pattern, handler := client.route(op)
+		if handler == nil || pattern != expected {
+			t.Errorf("route(%q): expected %q, got %q", op, expected, pattern)
+		}
+	}
+
+	client.Unsubscribe("*")
+	if _, handler := client.route("analytics"); handler != nil {
+		t.Error("Expected no handler after removing wildcard")
+	}
+}
+
+func TestWsHandlerPanicRecovered(t *testing.T) {
+	server := newTestWsServer(t)
+
+	errs := make(chan error, 10)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.SetErrorHandler(func(err error) {
+			errs <- err
+		})
+	})
+
+	delivered := make(chan struct{}, 10)
+	client.SubscribeExecutionReport(func(message []byte) {
+		delivered <- struct{}{}
+		if strings.Contains(string(message), "boom") {
+			panic("boom")
+		}
+	})
+
+	server.push(map[string]interface{}{"op": "execution_report", "success": true, "message": "boom"})
+	server.push(map[string]interface{}{"op": "execution_report", "success": true, "message": "ok"})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-delivered:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for message %d", i+1)
+		}
+	}
+
+	select {
+	case err := <-errs:
+		var panicErr *HandlerPanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("Expected HandlerPanicError, got %v", err)
+		}
+		if panicErr.Topic != "execution_report" || panicErr.Value != "boom" {
+			t.Errorf("Unexpected panic error %+v", panicErr)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for panic error")
+	}
+
+	if !client.IsConnected() {
+		t.Error("Expected client to stay connected")
+	}
+}
+
+func TestWsFailover(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := "ws" + strings.TrimPrefix(down.URL, "http")
+	down.Close()
+
+	server := newTestWsServer(t)
+
+	endpoints := make(chan string, 1)
+	client := NewWsClient("test-key", "test-secret")
+	client.BaseURL = downURL
+	client.FallbackURLs = []string{server.url()}
+	client.Logger = log.New(io.Discard, "", 0)
+	client.SetEndpointHandler(func(url string) {
+		endpoints <- url
+	})
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Disconnect()
+
+	if client.ActiveEndpoint() != server.url() {
+		t.Errorf("Expected active endpoint %s, got %s", server.url(), client.ActiveEndpoint())
+	}
+
+	select {
+	case url := <-endpoints:
+		if url != server.url() {
+			t.Errorf("Expected endpoint event for %s, got %s", server.url(), url)
+		}
+	default:
+		t.Error("Expected endpoint event")
+	}
+}
+
+func TestWsResubscribeOnReconnect(t *testing.T) {
+	server := newTestWsServer(t)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.reconnectDelay = 10 * time.Millisecond
+	})
+
+	if err := client.SubscribeExecutionReport(func([]byte) {}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	waitForSubscribe := func() {
+		for {
+			select {
+			case msg := <-server.received:
+				if msg["op"] != "subscribe" {
+					continue
+				}
+				args := msg["args"].([]interface{})
+				if len(args) != 1 || args[0] != "execution_report" {
+					t.Errorf("Unexpected subscribe args %v", args)
+				}
+				return
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for subscribe")
+			}
+		}
+	}
+
+	waitForSubscribe()
+
+	server.mu.Lock()
+	server.conn.Close()
+	server.mu.Unlock()
+
+	// The subscription is restored on the new connection
+	waitForSubscribe()
+}
+
+func TestWsPingRTT(t *testing.T) {
+	server := newTestWsServer(t)
+
+	alerts := make(chan HeartbeatAlert, 10)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.MaxPingRTT = time.Nanosecond
+		c.SetHeartbeatHandler(func(alert HeartbeatAlert) {
+			alerts <- alert
+		})
+	})
+
+	if err := client.SendPing(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case alert := <-alerts:
+		if alert.Type != HeartbeatSlowRTT || alert.RTT <= 0 {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for RTT alert")
+	}
+
+	if client.LastPingRTT() <= 0 {
+		t.Error("Expected LastPingRTT to be recorded")
+	}
+}
+
+// slowLimiter holds every message for delay
+type slowLimiter struct{ delay time.Duration }
+
+func (l slowLimiter) Wait(ctx context.Context) error {
+	time.Sleep(l.delay)
+	return nil
+}
+
+func TestWsPingRTTExcludesSendQueue(t *testing.T) {
+	server := newTestWsServer(t)
+	client := newTestWsClient(t, server)
+	client.SetSendLimiter(slowLimiter{delay: 300 * time.Millisecond})
+
+	if err := client.SendPing(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	waitFor(t, func() bool { return client.LastPingRTT() > 0 })
+	if rtt := client.LastPingRTT(); rtt >= 300*time.Millisecond {
+		t.Errorf("Expected the RTT to exclude the limiter wait, got %v", rtt)
+	}
+}
+
+func TestWsSilenceAlert(t *testing.T) {
+	server := newTestWsServer(t)
+	server.ignorePings = true
+
+	alerts := make(chan HeartbeatAlert, 10)
+	newTestWsClient(t, server, func(c *WsClient) {
+		c.KeepaliveInterval = 20 * time.Millisecond
+		c.KeepaliveTimeout = time.Minute
+		c.MaxSilence = 10 * time.Millisecond
+		c.SetHeartbeatHandler(func(alert HeartbeatAlert) {
+			alerts <- alert
+		})
+	})
+
+	select {
+	case alert := <-alerts:
+		if alert.Type != HeartbeatSilence || alert.Silence <= 10*time.Millisecond {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for silence alert")
+	}
+}
+
+func TestWsExecutionReportOrderDecoding(t *testing.T) {
+	data := []byte(`{
+		"op": "execution_report",
+		"success": true,
+		"message": {
+			"order_id": 12345,
+			"request_order_type": "algo",
+			"order": {"id": 1, "order_type": "TWAP", "symbol": "BTC/USDT", "side": "BUY", "quantity": "1.0"}
+		}
+	}`)
+
+	var report WsExecutionReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	algo, ok := report.Message.AlgoOrder()
+	if !ok {
+		t.Fatal("Expected algo order")
+	}
+	if algo.OrderType != AlgoOrderTypeTWAP || algo.Symbol != "BTC/USDT" {
+		t.Errorf("Unexpected algo order %+v", algo)
+	}
+	if _, ok := report.Message.BasicOrder(); ok {
+		t.Error("Expected no basic order")
+	}
+
+	// Typed orders survive a round trip
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded WsExecutionReport
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decoded.Message.Algo == nil || decoded.Message.Algo.Symbol != "BTC/USDT" {
+		t.Errorf("Expected algo order after round trip, got %+v", decoded.Message)
+	}
+}
+
+func TestWsSchemaEvents(t *testing.T) {
+	server := newTestWsServer(t)
+	server.authVersion = "2.0"
+
+	var mu sync.Mutex
+	var events []SchemaEvent
+	handled := make(chan string, 10)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.ProtocolVersion = SupportedProtocolVersion
+		c.SetSchemaHandler(func(event SchemaEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		})
+		c.Handle("*", func(message []byte) {
+			var resp WsResponse
+			json.Unmarshal(message, &resp)
+			handled <- resp.Op
+		})
+		c.Handle("execution_report", func(message []byte) { handled <- "execution_report" })
+	})
+
+	auth := <-server.received
+	if auth["version"] != SupportedProtocolVersion {
+		t.Errorf("Expected version %s in auth request, got %v", SupportedProtocolVersion, auth["version"])
+	}
+	if v := client.ServerProtocolVersion(); v != "2.0" {
+		t.Errorf("Expected server version 2.0, got %q", v)
+	}
+
+	server.push(map[string]interface{}{"op": "position_update", "success": true})
+	server.push(map[string]interface{}{
+		"op":      "execution_report",
+		"success": true,
+		"message": map[string]interface{}{
+			"order_id":           "order-1",
+			"request_order_type": "BASIC",
+			"venue":              "binance",
+			"order":              map[string]interface{}{"symbol": "BTCUSDT", "iceberg": true},
+		},
+	})
+
+	// Unknown messages are still delivered
+	for _, want := range []string{"position_update", "execution_report"} {
+		select {
+		case op := <-handled:
+			if op != want {
+				t.Errorf("Expected %s to be handled, got %s", want, op)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s", want)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 schema events, got %+v", events)
+	}
+	if events[0].Type != SchemaVersionMismatch || events[0].ServerVersion != "2.0" {
+		t.Errorf("Expected version mismatch, got %+v", events[0])
+	}
+	if events[1].Type != SchemaUnknownOp || events[1].Op != "position_update" {
+		t.Errorf("Expected unknown op, got %+v", events[1])
+	}
+	want := []string{"message.order.iceberg", "message.venue"}
+	if events[2].Type != SchemaUnknownFields || fmt.Sprint(events[2].UnknownFields) != fmt.Sprint(want) {
+		t.Errorf("Expected unknown fields %v, got %+v", want, events[2])
+	}
+}
+
+func TestWsSendLimiterQueue(t *testing.T) {
+	server := newTestWsServer(t)
+	client := newTestWsClient(t, server)
+	<-server.received // auth
+
+	client.SetSendLimiter(NewRateLimiter(0.001, 1))
+	client.MaxSendQueue = 1
+
+	// The first message takes the only token
+	if err := client.SendJSON(map[string]string{"op": "first"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The second waits for a token, filling the queue
+	queued := make(chan error, 1)
+	go func() { queued <- client.SendJSON(map[string]string{"op": "second"}) }()
+	waitFor(t, func() bool { return client.SendQueueDepth() == 1 })
+
+	if err := client.SendJSON(map[string]string{"op": "third"}); err != ErrSendQueueFull {
+		t.Errorf("Expected ErrSendQueueFull, got %v", err)
+	}
+
+	// Disconnecting releases the waiting sender
+	client.Disconnect()
+	select {
+	case err := <-queued:
+		if err == nil {
+			t.Error("Expected queued send to fail after disconnect")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Queued send was not released by Disconnect")
+	}
+	if depth := client.SendQueueDepth(); depth != 0 {
+		t.Errorf("Expected empty queue, got %d", depth)
+	}
+}
+
+func TestWsHealth(t *testing.T) {
+	server := newTestWsServer(t)
+	client := NewWsClient("test-key", "test-secret")
+	client.BaseURL = server.url()
+	client.Logger = log.New(io.Discard, "", 0)
+	client.reconnectDelay = 10 * time.Millisecond
+
+	if h := client.Health(); h.Ready() || h.Uptime != 0 {
+		t.Errorf("Expected unready health before Connect, got %+v", h)
+	}
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Disconnect()
+
+	h := client.Health()
+	if !h.Ready() || h.Endpoint != server.url() || h.LastMessage.IsZero() {
+		t.Errorf("Expected ready health, got %+v", h)
+	}
+
+	server.mu.Lock()
+	server.conn.Close()
+	server.mu.Unlock()
+
+	waitFor(t, func() bool {
+		h := client.Health()
+		return h.Reconnects == 1 && h.Ready()
+	})
+
+	client.Disconnect()
+	if h := client.Health(); h.Connected || h.Reconnects != 1 {
+		t.Errorf("Expected disconnected health, got %+v", h)
+	}
+}
+
+func TestWsSimulatedClock(t *testing.T) {
+	server := newTestWsServer(t)
+	server.ignorePings = true
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewSimulatedClock(start)
+	errs := make(chan error, 10)
+	client := newTestWsClient(t, server, func(c *WsClient) {
+		c.Clock = clock
+		c.AuthExpiry = time.Minute
+		c.ReauthInterval = 0
+		c.KeepaliveInterval = time.Second
+		c.KeepaliveTimeout = 3 * time.Second
+		c.reconnect = false
+		c.SetErrorHandler(func(err error) { errs <- err })
+	})
+
+	auth := <-server.received
+	args, _ := auth["args"].([]interface{})
+	if len(args) != 3 || args[1] != fmt.Sprint(start.Add(time.Minute).Unix()) {
+		t.Errorf("Expected the auth expiry from the clock, got %v", auth)
+	}
+
+	// No real time passes: the keepalive only times out as the clock moves
+	time.Sleep(50 * time.Millisecond)
+	if !client.IsConnected() {
+		t.Fatal("Expected the connection to stay up while the clock is still")
+	}
+	waitFor(t, func() bool {
+		clock.Advance(time.Second)
+		return len(errs) > 0
+	})
+	if err := <-errs; !strings.Contains(err.Error(), "keepalive timeout") {
+		t.Errorf("Expected keepalive timeout error, got %v", err)
+	}
+	waitFor(t, func() bool { return !client.IsConnected() })
+}
+
+// TestWsClientsConcurrent connects clients with different endpoints and
+// keepalive settings at once; run with -race to check they share no
+// mutable state
+func TestWsClientsConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		server := newTestWsServer(t)
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			c := NewWsClient("test-key", "test-secret")
+			c.BaseURL = server.url()
+			c.Logger = log.New(io.Discard, "", 0)
+			c.DisableKeepalive = g%2 == 0
+			c.KeepaliveInterval = time.Duration(g+1) * 10 * time.Millisecond
+			if err := c.Connect(); err != nil {
+				t.Error(err)
+				return
+			}
+			defer c.Disconnect()
+
+			received := make(chan struct{}, 1)
+			if err := c.SubscribeExecutionReport(func([]byte) { received <- struct{}{} }); err != nil {
+				t.Error(err)
+				return
+			}
+			server.push(basicExecutionReport(int64(g), OrderStatusNew, 1000))
+			select {
+			case <-received:
+			case <-time.After(5 * time.Second):
+				t.Errorf("Client %d received no report", g)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestWsErrors(t *testing.T) {
+	ws := NewWsClient("test-key", "test-secret")
+	ws.Logger = log.New(io.Discard, "", 0)
+	if err := ws.Subscribe("execution_report", func([]byte) {}); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("Expected ErrNotAuthenticated, got %v", err)
+	}
+	if err := ws.SendJSON(map[string]string{"op": "ping"}); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Expected ErrNotConnected, got %v", err)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	ws.BaseURL = "ws" + strings.TrimPrefix(closed.URL, "http")
+	if err := ws.Connect(); !errors.Is(err, ErrTransport) {
+		t.Errorf("Expected a transport error, got %v", err)
+	}
+
+	upgrader := websocket.Upgrader{}
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.ReadMessage()
+		conn.WriteJSON(map[string]interface{}{"op": "auth", "success": false, "message": "invalid signature"})
+		conn.ReadMessage()
+	}))
+	defer rejecting.Close()
+	ws.BaseURL = "ws" + strings.TrimPrefix(rejecting.URL, "http")
+	err := ws.Connect()
+	if !errors.Is(err, ErrAuthFailed) || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package versifi
+
+import "encoding/json"
+
+// AckPolicy decides whether a delivered message is acknowledged.
+//
+// Acknowledgements are only sent for messages carrying a top-level "msg_id"
+// field and take the form {"op": "ack", "args": [msg_id]}. Unacknowledged
+// messages are expected to be redelivered by the server; redeliveries of a
+// message that was already acknowledged are acked again without invoking
+// handlers.
+type AckPolicy interface {
+	// ShouldAck is called once all handlers for a message have returned.
+	// handlerErr is a *HandlerPanicError if a handler panicked.
+	ShouldAck(topic string, handlerErr error) bool
+}
+
+// AckPolicyFunc adapts a function to AckPolicy
+type AckPolicyFunc func(topic string, handlerErr error) bool
+
+// ShouldAck implements AckPolicy
+func (f AckPolicyFunc) ShouldAck(topic string, handlerErr error) bool {
+	return f(topic, handlerErr)
+}
+
+// Built-in ack policies
+var (
+	// AckAfterSuccess acknowledges messages whose handlers returned normally
+	AckAfterSuccess AckPolicy = AckPolicyFunc(func(_ string, handlerErr error) bool {
+		return handlerErr == nil
+	})
+	// AckAlways acknowledges every message, even if a handler panicked
+	AckAlways AckPolicy = AckPolicyFunc(func(string, error) bool {
+		return true
+	})
+)
+
+// ackHistorySize bounds the number of acknowledged IDs kept for dedup
+const ackHistorySize = 4096
+
+// SetAckPolicy enables acknowledgements with the given policy, nil disables them.
+// Handler panics are reported to the policy as a *HandlerPanicError.
+func (c *WsClient) SetAckPolicy(policy AckPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ackPolicy = policy
+	if c.ackedIDs == nil {
+		c.ackedIDs = make(map[string]struct{})
+	}
+}
+
+// ackID returns the message ID to acknowledge, or "" if acks are disabled
+// or the message carries no ID
+func (c *WsClient) ackID(message []byte) string {
+	c.mu.RLock()
+	policy := c.ackPolicy
+	c.mu.RUnlock()
+
+	if policy == nil {
+		return ""
+	}
+
+	return string(jsonField(message, "msg_id"))
+}
+
+// acked reports whether id has already been acknowledged
+func (c *WsClient) acked(id string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.ackedIDs[id]
+	return ok
+}
+
+// settle applies the ack policy to a handled message
+func (c *WsClient) settle(id, topic string, handlerErr error) {
+	if id == "" {
+		return
+	}
+
+	c.mu.RLock()
+	policy := c.ackPolicy
+	c.mu.RUnlock()
+
+	if policy == nil || !policy.ShouldAck(topic, handlerErr) {
+		c.log().Warnf("not acknowledging %s message %s: %v", topic, id, handlerErr)
+		return
+	}
+
+	c.mu.Lock()
+	c.ackedIDs[id] = struct{}{}
+	c.ackOrder = append(c.ackOrder, id)
+	if len(c.ackOrder) > ackHistorySize {
+		delete(c.ackedIDs, c.ackOrder[0])
+		c.ackOrder = c.ackOrder[1:]
+	}
+	c.mu.Unlock()
+
+	c.sendAck(id)
+}
+
+// sendAck acknowledges message id; id is the raw JSON value of msg_id
+func (c *WsClient) sendAck(id string) {
+	ackMsg := map[string]interface{}{
+		"op":   "ack",
+		"args": []json.RawMessage{json.RawMessage(id)},
+	}
+	if err := c.SendJSON(ackMsg); err != nil {
+		c.log().Warnf("error sending ack: %v", err)
+	}
+}
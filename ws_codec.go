@@ -0,0 +1,43 @@
+package versifi
+
+import (
+	"fmt"
+
+	"github.com/gorilla/websocket"
+)
+
+// WsCodec translates between the JSON messages seen by handlers, taps and
+// SendJSON callers and the frames on the wire. Setting WsClient.Codec enables
+// an alternative encoding (protobuf, msgpack, ...) for a connection without
+// changing handler signatures.
+type WsCodec interface {
+	// Encode converts a JSON message into a frame type and payload. With
+	// WsClient.ReuseBuffers the message is only valid until Encode returns.
+	Encode(message []byte) (frameType int, frame []byte, err error)
+	// Decode converts a received frame into a JSON message
+	Decode(frameType int, frame []byte) (message []byte, err error)
+}
+
+// JSONCodec sends and receives JSON text frames, it is the default codec
+type JSONCodec struct{}
+
+// Encode implements WsCodec
+func (JSONCodec) Encode(message []byte) (int, []byte, error) {
+	return websocket.TextMessage, message, nil
+}
+
+// Decode implements WsCodec
+func (JSONCodec) Decode(frameType int, frame []byte) ([]byte, error) {
+	if frameType != websocket.TextMessage {
+		return nil, fmt.Errorf("unexpected frame type %d", frameType)
+	}
+	return frame, nil
+}
+
+// codec returns the codec for the connection
+func (c *WsClient) codec() WsCodec {
+	if c.Codec == nil {
+		return JSONCodec{}
+	}
+	return c.Codec
+}
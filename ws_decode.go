@@ -0,0 +1,246 @@
+package versifi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// ExecutionReportHandler receives execution reports decoded by the read
+// loop. The detail is reused once the handler returns; copy what you keep.
+type ExecutionReportHandler func(detail *WsExecutionReportDetail)
+
+// OnExecutionReport registers handler for every execution report and
+// returns a function that removes it. Reports are decoded once, into a
+// reused struct, however many handlers are registered, so trackers avoid a
+// decode each:
+//
+//	ws.SubscribeExecutionReport(nil)
+//	ws.OnExecutionReport(tracker.ApplyExecutionReport)
+//	ws.OnExecutionReport(positions.ApplyExecutionReport)
+//
+// Handlers run on the read loop, before the execution_report WsHandler.
+// Reports that fail to decode are passed to the error handler.
+func (c *WsClient) OnExecutionReport(handler ExecutionReportHandler) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reportHandlers == nil {
+		c.reportHandlers = make(map[int]ExecutionReportHandler)
+	}
+	id := c.nextReportHandlerID
+	c.nextReportHandlerID++
+	c.reportHandlers[id] = handler
+	c.reportHandlerList = sortedReportHandlers(c.reportHandlers)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.reportHandlers, id)
+		c.reportHandlerList = sortedReportHandlers(c.reportHandlers)
+	}
+}
+
+// sortedReportHandlers returns the handlers in registration order. The
+// read loop uses this copy, rebuilt on every change, so dispatching a
+// report does not allocate.
+func sortedReportHandlers(handlers map[int]ExecutionReportHandler) []ExecutionReportHandler {
+	ids := make([]int, 0, len(handlers))
+	for id := range handlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	list := make([]ExecutionReportHandler, len(ids))
+	for i, id := range ids {
+		list[i] = handlers[id]
+	}
+	return list
+}
+
+var reportPool = sync.Pool{New: func() interface{} { return new(WsExecutionReport) }}
+
+// applyExecutionReport decodes message for the typed handlers. It reports
+// whether there were handlers and the report decoded, and returns a decode
+// error or a handler panic as err.
+func (c *WsClient) applyExecutionReport(message []byte) (timestamp int64, decoded bool, err error) {
+	c.mu.RLock()
+	handlers := c.reportHandlerList
+	errHandler := c.errHandler
+	c.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		return 0, false, nil
+	}
+
+	report := reportPool.Get().(*WsExecutionReport)
+	defer reportPool.Put(report)
+	// Nested orders are allocated afresh, so handlers may keep them
+	*report = WsExecutionReport{}
+	if err := json.Unmarshal(message, report); err != nil {
+		c.log().Warnf("error decoding execution report: %v", err)
+		if errHandler != nil {
+			errHandler(err)
+		}
+		return 0, false, err
+	}
+
+	timestamp, decoded = report.Message.Timestamp.Epoch(), true
+	defer c.recoverHandler("execution_report", &err)
+	for _, handler := range handlers {
+		handler(&report.Message)
+	}
+	return timestamp, decoded, nil
+}
+
+// readFrame reads the next frame from conn. With ReuseBuffers set the frame
+// is read into a pooled buffer, which must be given to releaseFrame once
+// the message has been handled; otherwise the buffer is nil.
+func (c *WsClient) readFrame(conn *websocket.Conn) (frameType int, frame []byte, buf *bytes.Buffer, err error) {
+	if !c.ReuseBuffers {
+		frameType, frame, err = conn.ReadMessage()
+		return frameType, frame, nil, err
+	}
+
+	frameType, r, err := conn.NextReader()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if buf, err = readAll(r); err != nil {
+		return 0, nil, nil, err
+	}
+	return frameType, buf.Bytes(), buf, nil
+}
+
+// releaseFrame returns a buffer from readFrame to the pool
+func releaseFrame(buf *bytes.Buffer) {
+	putBuffer(buf)
+}
+
+// opNames interns the known ops, so peeking them does not allocate
+var opNames = func() map[string]string {
+	names := make(map[string]string, len(knownOps))
+	for op := range knownOps {
+		names[op] = op
+	}
+	return names
+}()
+
+// peekOp returns the op of a valid JSON message without decoding it. It
+// reports false when the op cannot be read that way, such as when it is
+// absent or escaped, and the message should be decoded instead.
+func peekOp(message []byte) (string, bool) {
+	raw := jsonField(message, "op")
+	if len(raw) < 2 || raw[0] != '"' || bytes.IndexByte(raw, '\\') >= 0 {
+		return "", false
+	}
+	raw = raw[1 : len(raw)-1]
+	if op, ok := opNames[string(raw)]; ok {
+		return op, true
+	}
+	return string(raw), true
+}
+
+// peekInt returns the integer value of a raw JSON number
+func peekInt(raw []byte) (int64, bool) {
+	neg := len(raw) > 0 && raw[0] == '-'
+	if neg {
+		raw = raw[1:]
+	}
+	if len(raw) == 0 || len(raw) > 18 {
+		return 0, false
+	}
+	var n int64
+	for _, b := range raw {
+		if b < '0' || b > '9' {
+			return 0, false
+		}
+		n = n*10 + int64(b-'0')
+	}
+	if neg {
+		n = -n
+	}
+	return n, true
+}
+
+// jsonField returns the raw value of key in the valid JSON object data, or
+// nil if data is not an object or has no such key. Like encoding/json the
+// last of duplicate keys wins; unlike it, names match exactly.
+func jsonField(data []byte, key string) []byte {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return nil
+	}
+	var value []byte
+	for i++; ; i++ {
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != '"' {
+			return value
+		}
+		end := skipValue(data, i)
+		name := data[i+1 : end-1]
+		i = skipSpace(data, end) + 1 // the colon
+		i = skipSpace(data, i)
+		end = skipValue(data, i)
+		if string(name) == key {
+			value = data[i:end]
+		}
+		i = skipSpace(data, end)
+		if i >= len(data) || data[i] != ',' {
+			return value
+		}
+	}
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// skipValue returns the end of the valid JSON value starting at i
+func skipValue(data []byte, i int) int {
+	if i >= len(data) {
+		return i
+	}
+	switch data[i] {
+	case '"':
+		for i++; i < len(data); i++ {
+			switch data[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return i
+	case '{', '[':
+		depth := 0
+		for i < len(data) {
+			switch data[i] {
+			case '"':
+				i = skipValue(data, i)
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return i
+	default:
+		for i < len(data) {
+			switch data[i] {
+			case ',', '}', ']', ' ', '\t', '\n', '\r':
+				return i
+			}
+			i++
+		}
+		return i
+	}
+}
@@ -0,0 +1,177 @@
+package versifi
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestPeekOp(t *testing.T) {
+	for _, tc := range []struct {
+		message string
+		op      string
+		ok      bool
+	}{
+		{`{"op":"execution_report","success":true}`, "execution_report", true},
+		{` { "success" : true , "message" : {"op":"x","a":["}",{"b":"\"op\""}]} , "op" : "ping" } `, "ping", true},
+		{`{"message":"{\"op\":\"auth\"}","op":"custom"}`, "custom", true},
+		{`{"op":"auth","op":"subscribe"}`, "subscribe", true},
+		{`{"op":"a\"b"}`, "", false},
+		{`{"op":1}`, "", false},
+		{`{"success":true}`, "", false},
+		{`{}`, "", false},
+		{`null`, "", false},
+		{`["op"]`, "", false},
+	} {
+		op, ok := peekOp([]byte(tc.message))
+		if op != tc.op || ok != tc.ok {
+			t.Errorf("%s: expected %q, %v, got %q, %v", tc.message, tc.op, tc.ok, op, ok)
+		}
+	}
+
+	message := []byte(`{"op":"execution_report","message":{"status":"NEW","timestamp":1700000000123,"x":[1,2]}}`)
+	if ts, ok := peekInt(jsonField(jsonField(message, "message"), "timestamp")); !ok || ts != 1700000000123 {
+		t.Errorf("Unexpected timestamp %d, %v", ts, ok)
+	}
+	for _, raw := range []string{"", "1.5", "1e3", `"1"`, "-", "12345678901234567890"} {
+		if _, ok := peekInt([]byte(raw)); ok {
+			t.Errorf("Expected %q not to parse", raw)
+		}
+	}
+}
+
+func TestWsOnExecutionReport(t *testing.T) {
+	server := newTestWsServer(t)
+	ws := newTestWsClient(t, server, func(c *WsClient) { c.ReuseBuffers = true })
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	ws.OnExecutionReport(func(d *WsExecutionReportDetail) { record("first " + string(d.Status)) })
+	unsubscribe := ws.OnExecutionReport(func(d *WsExecutionReportDetail) {
+		if d.Basic == nil || d.Basic.Symbol != "BTC/USDT" {
+			t.Errorf("Unexpected report %+v", d)
+		}
+		record("second " + string(d.Status))
+	})
+	if err := ws.SubscribeExecutionReport(func(message []byte) { record("raw") }); err != nil {
+		t.Fatal(err)
+	}
+
+	server.push(basicExecutionReport(1, OrderStatusNew, 1000))
+	waitFor(t, func() bool { return ws.LastExecutionReportTime() == 1000 })
+	unsubscribe()
+	server.push(basicExecutionReport(1, OrderStatusCanceled, 2000))
+	waitFor(t, func() bool { return ws.LastExecutionReportTime() == 2000 })
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"first NEW", "second NEW", "raw", "first CANCELED", "raw"}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, events)
+			break
+		}
+	}
+}
+
+func TestWsExecutionReportHandlerPanic(t *testing.T) {
+	ws := NewWsClient("key", "secret")
+	ws.Logger = log.New(io.Discard, "", 0)
+	var got error
+	ws.SetErrorHandler(func(err error) { got = err })
+	ws.OnExecutionReport(func(*WsExecutionReportDetail) { panic("boom") })
+
+	message, _ := json.Marshal(basicExecutionReport(1, OrderStatusNew, 1000))
+	ws.handleFrame(websocket.TextMessage, message)
+	if _, ok := got.(*HandlerPanicError); !ok {
+		t.Errorf("Expected a HandlerPanicError, got %v", got)
+	}
+	if ws.LastExecutionReportTime() != 1000 {
+		t.Errorf("Expected the report time to be recorded, got %d", ws.LastExecutionReportTime())
+	}
+}
+
+// benchmarkFillReport is an execution report as sent for each fill
+func benchmarkFillReport() []byte {
+	message, _ := json.Marshal(basicExecutionReport(42, OrderStatusPartiallyFilled, 1700000000000,
+		WsTrade{TradeID: 7, ExecutedPrice: "43210.5", ExecutedQuantity: "0.015", CummulativeFilledQuantity: "0.045"}))
+	return message
+}
+
+// BenchmarkWsExecutionReport compares the allocations per fill of the read
+// loop as it was (decode the envelope, the timestamp and then the report in
+// the handler) with the current one, delivering raw messages to a handler
+// that decodes them and typed reports to OnExecutionReport handlers
+func BenchmarkWsExecutionReport(b *testing.B) {
+	message := benchmarkFillReport()
+	newClient := func() *WsClient {
+		ws := NewWsClient("key", "secret")
+		ws.Logger = log.New(io.Discard, "", 0)
+		return ws
+	}
+
+	b.Run("previous", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var envelope WsResponse
+			if err := json.Unmarshal(message, &envelope); err != nil {
+				b.Fatal(err)
+			}
+			var ts struct {
+				Message struct {
+					Timestamp int64 `json:"timestamp"`
+				} `json:"message"`
+			}
+			json.Unmarshal(message, &ts)
+			var report WsExecutionReport
+			json.Unmarshal(message, &report)
+		}
+	})
+
+	b.Run("raw handler", func(b *testing.B) {
+		ws := newClient()
+		ws.Handle("execution_report", func(message []byte) {
+			var report WsExecutionReport
+			json.Unmarshal(message, &report)
+		})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ws.handleFrame(websocket.TextMessage, message)
+		}
+	})
+
+	b.Run("typed handlers", func(b *testing.B) {
+		ws := newClient()
+		var filled int
+		for j := 0; j < 3; j++ {
+			ws.OnExecutionReport(func(d *WsExecutionReportDetail) { filled += len(d.Basic.ChildOrder.Trades) })
+		}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ws.handleFrame(websocket.TextMessage, message)
+		}
+	})
+
+	b.Run("routing only", func(b *testing.B) {
+		ws := newClient()
+		ws.Handle("execution_report", func([]byte) {})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ws.handleFrame(websocket.TextMessage, message)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package versifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Values of WsExecutionReportDetail.RequestOrderType
+const (
+	RequestOrderTypeBasic = "basic"
+	RequestOrderTypeAlgo  = "algo"
+	RequestOrderTypePair  = "pair"
+)
+
+// BasicOrder returns the order as a basic order, if it is one
+func (d *WsExecutionReportDetail) BasicOrder() (*WsBasicOrderDetail, bool) {
+	return d.Basic, d.Basic != nil
+}
+
+// AlgoOrder returns the order as an algo order, if it is one
+func (d *WsExecutionReportDetail) AlgoOrder() (*WsAlgoOrderDetail, bool) {
+	return d.Algo, d.Algo != nil
+}
+
+// PairOrder returns the order as a pair order, if it is one
+func (d *WsExecutionReportDetail) PairOrder() (*WsPairOrderDetail, bool) {
+	return d.Pair, d.Pair != nil
+}
+
+// UnmarshalJSON decodes the order into Basic, Algo or Pair according to
+// RequestOrderType. Order keeps the raw JSON, including for unknown types.
+func (d *WsExecutionReportDetail) UnmarshalJSON(data []byte) error {
+	type alias WsExecutionReportDetail
+	var raw alias
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*d = WsExecutionReportDetail(raw)
+
+	if len(d.Order) == 0 || string(d.Order) == "null" {
+		return nil
+	}
+
+	var err error
+	switch strings.ToLower(d.RequestOrderType) {
+	case RequestOrderTypeBasic:
+		d.Basic = new(WsBasicOrderDetail)
+		err = json.Unmarshal(d.Order, d.Basic)
+	case RequestOrderTypeAlgo:
+		d.Algo = new(WsAlgoOrderDetail)
+		err = json.Unmarshal(d.Order, d.Algo)
+	case RequestOrderTypePair:
+		d.Pair = new(WsPairOrderDetail)
+		err = json.Unmarshal(d.Order, d.Pair)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode %s order: %w", d.RequestOrderType, err)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the typed order, if set, in place of Order
+func (d WsExecutionReportDetail) MarshalJSON() ([]byte, error) {
+	type alias WsExecutionReportDetail
+	raw := alias(d)
+
+	var order interface{}
+	switch {
+	case d.Basic != nil:
+		order = d.Basic
+	case d.Algo != nil:
+		order = d.Algo
+	case d.Pair != nil:
+		order = d.Pair
+	}
+
+	if order != nil {
+		data, err := json.Marshal(order)
+		if err != nil {
+			return nil, err
+		}
+		raw.Order = data
+	}
+	return json.Marshal(raw)
+}
@@ -0,0 +1,158 @@
+package versifi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gorilla/websocket"
+)
+
+// EndpointHandler is called with the URL of the endpoint a connection was established to
+type EndpointHandler func(url string)
+
+// SetEndpointHandler sets the handler notified after every successful
+// connect, including reconnects and failovers to a fallback URL
+func (c *WsClient) SetEndpointHandler(handler EndpointHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpointHandler = handler
+}
+
+// ReconnectHandler is called after each automatic reconnection attempt with
+// its result, nil on success. The client stops reconnecting after a failed
+// attempt.
+type ReconnectHandler func(err error)
+
+// SetReconnectHandler sets the handler notified after each automatic
+// reconnection attempt
+func (c *WsClient) SetReconnectHandler(handler ReconnectHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reconnectHandler = handler
+}
+
+// OnReconnect registers handler to be called after each automatic
+// reconnection attempt, after the handler set with SetReconnectHandler, and
+// returns a function that removes it. Helpers such as Alerter use it so they
+// do not replace the client's reconnect handler.
+func (c *WsClient) OnReconnect(handler ReconnectHandler) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reconnectHandlers == nil {
+		c.reconnectHandlers = make(map[int]ReconnectHandler)
+	}
+	id := c.nextReconnectID
+	c.nextReconnectID++
+	c.reconnectHandlers[id] = handler
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.reconnectHandlers, id)
+	}
+}
+
+// notifyReconnect calls the reconnect handlers with the result of an
+// automatic reconnection attempt, in registration order
+func (c *WsClient) notifyReconnect(err error) {
+	c.mu.RLock()
+	handler := c.reconnectHandler
+	ids := make([]int, 0, len(c.reconnectHandlers))
+	for id := range c.reconnectHandlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	handlers := make([]ReconnectHandler, len(ids))
+	for i, id := range ids {
+		handlers[i] = c.reconnectHandlers[id]
+	}
+	c.mu.RUnlock()
+
+	if handler != nil {
+		handler(err)
+	}
+	for _, h := range handlers {
+		h(err)
+	}
+}
+
+// ActiveEndpoint returns the URL of the current or most recent connection
+func (c *WsClient) ActiveEndpoint() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.activeURL
+}
+
+// endpoints returns BaseURL followed by the fallback URLs
+func (c *WsClient) endpoints() []string {
+	return append([]string{c.BaseURL}, c.FallbackURLs...)
+}
+
+// dialEndpoints tries each endpoint in turn, starting with the one that
+// last succeeded, and returns the first connection established
+func (c *WsClient) dialEndpoints(dialer *websocket.Dialer) (*websocket.Conn, error) {
+	endpoints := c.endpoints()
+
+	c.mu.RLock()
+	start := c.activeEndpoint
+	c.mu.RUnlock()
+
+	var errs []error
+	for i := range endpoints {
+		idx := (start + i) % len(endpoints)
+		url := endpoints[idx]
+
+		// Dial websocket (no headers needed for initial connection)
+		conn, _, err := dialer.Dial(url, nil)
+		if err != nil {
+			c.log().Warnf("failed to connect to %s: %v", url, err)
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			continue
+		}
+
+		if idx != start {
+			c.log().Warnf("failed over to websocket endpoint %s", url)
+		}
+
+		c.mu.Lock()
+		c.activeEndpoint = idx
+		c.activeURL = url
+		c.mu.Unlock()
+		return conn, nil
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// notifyEndpoint reports the active endpoint to the endpoint handler
+func (c *WsClient) notifyEndpoint() {
+	c.mu.RLock()
+	handler := c.endpointHandler
+	url := c.activeURL
+	c.mu.RUnlock()
+
+	if handler != nil {
+		handler(url)
+	}
+}
+
+// resubscribe restores the server-side subscriptions after a reconnect
+func (c *WsClient) resubscribe() error {
+	c.mu.RLock()
+	topics := make([]string, 0, len(c.subscriptions))
+	for topic := range c.subscriptions {
+		topics = append(topics, topic)
+	}
+	c.mu.RUnlock()
+
+	if len(topics) == 0 {
+		return nil
+	}
+	sort.Strings(topics)
+
+	subscribeMsg := map[string]interface{}{
+		"op":   "subscribe",
+		"args": topics,
+	}
+	return c.SendJSON(subscribeMsg)
+}
@@ -0,0 +1,41 @@
+package versifi
+
+import "time"
+
+// WsHealth is a point-in-time snapshot of a WsClient's connection state,
+// suitable for readiness probes
+type WsHealth struct {
+	Connected     bool          `json:"connected"`
+	Authenticated bool          `json:"authenticated"`
+	Endpoint      string        `json:"endpoint,omitempty"`
+	Uptime        time.Duration `json:"uptime"`       // Time since the current connection was established
+	LastMessage   time.Time     `json:"last_message"` // Last inbound frame, or the connection time if none yet
+	LastPingRTT   time.Duration `json:"last_ping_rtt"`
+	Reconnects    int           `json:"reconnects"`  // Successful automatic reconnections
+	QueueDepth    int           `json:"queue_depth"` // Messages waiting for the send limiter
+}
+
+// Ready reports whether the client is connected and authenticated
+func (h WsHealth) Ready() bool {
+	return h.Connected && h.Authenticated
+}
+
+// Health returns a snapshot of the connection state
+func (c *WsClient) Health() WsHealth {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	h := WsHealth{
+		Connected:     c.isConnected,
+		Authenticated: c.isAuthenticated,
+		LastMessage:   c.lastMessage,
+		LastPingRTT:   c.lastPingRTT,
+		Reconnects:    c.reconnects,
+		QueueDepth:    c.sendQueue,
+	}
+	if c.isConnected {
+		h.Endpoint = c.activeURL
+		h.Uptime = c.clock().Now().Sub(c.connectedAt)
+	}
+	return h
+}
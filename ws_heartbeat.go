@@ -0,0 +1,93 @@
+package versifi
+
+import "time"
+
+// HeartbeatAlertType identifies which heartbeat threshold was exceeded
+type HeartbeatAlertType string
+
+const (
+	HeartbeatSlowRTT HeartbeatAlertType = "SLOW_RTT"
+	HeartbeatSilence HeartbeatAlertType = "SILENCE"
+)
+
+// HeartbeatAlert reports a ping round trip above MaxPingRTT or a period
+// without inbound messages longer than MaxSilence
+type HeartbeatAlert struct {
+	Type    HeartbeatAlertType
+	RTT     time.Duration
+	Silence time.Duration
+}
+
+// HeartbeatHandler handles heartbeat alerts
+type HeartbeatHandler func(alert HeartbeatAlert)
+
+// SetHeartbeatHandler sets the handler called when MaxPingRTT or MaxSilence is exceeded
+func (c *WsClient) SetHeartbeatHandler(handler HeartbeatHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.heartbeatHandler = handler
+}
+
+// LastPingRTT returns the round-trip time of the most recently answered application ping
+func (c *WsClient) LastPingRTT() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastPingRTT
+}
+
+// markPingSent records when an application ping was sent
+func (c *WsClient) markPingSent() {
+	c.mu.Lock()
+	c.pingSentAt = c.clock().Now()
+	c.mu.Unlock()
+}
+
+// markPingAnswered records the round trip of the outstanding ping
+func (c *WsClient) markPingAnswered() {
+	c.mu.Lock()
+	if c.pingSentAt.IsZero() {
+		c.mu.Unlock()
+		return
+	}
+	rtt := c.clock().Now().Sub(c.pingSentAt)
+	c.pingSentAt = time.Time{}
+	c.lastPingRTT = rtt
+	c.mu.Unlock()
+
+	if c.MaxPingRTT > 0 && rtt > c.MaxPingRTT {
+		c.heartbeatAlert(HeartbeatAlert{Type: HeartbeatSlowRTT, RTT: rtt})
+	}
+}
+
+// markMessage records that a message was received
+func (c *WsClient) markMessage() {
+	c.mu.Lock()
+	c.lastMessage = c.clock().Now()
+	c.mu.Unlock()
+}
+
+// checkSilence alerts if no message has been received for longer than MaxSilence
+func (c *WsClient) checkSilence() {
+	if c.MaxSilence <= 0 {
+		return
+	}
+
+	c.mu.RLock()
+	silence := c.clock().Now().Sub(c.lastMessage)
+	c.mu.RUnlock()
+
+	if silence > c.MaxSilence {
+		c.heartbeatAlert(HeartbeatAlert{Type: HeartbeatSilence, Silence: silence})
+	}
+}
+
+func (c *WsClient) heartbeatAlert(alert HeartbeatAlert) {
+	c.mu.RLock()
+	handler := c.heartbeatHandler
+	c.mu.RUnlock()
+
+	c.log().Warnf("heartbeat alert %s: rtt=%v silence=%v", alert.Type, alert.RTT, alert.Silence)
+	if handler != nil {
+		handler(alert)
+	}
+}
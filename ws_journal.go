@@ -0,0 +1,168 @@
+package versifi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JournalEntry is one websocket message recorded by a Journal
+type JournalEntry struct {
+	Time      time.Time        `json:"time"`
+	Direction MessageDirection `json:"direction"`
+	Message   string           `json:"message"`
+}
+
+// JournalFilter selects journal entries. Zero fields match everything.
+type JournalFilter struct {
+	// Since and Until bound the entry time, inclusive
+	Since time.Time
+	Until time.Time
+	// Direction selects inbound or outbound messages only
+	Direction MessageDirection
+}
+
+// Matches reports whether e is selected by f
+func (f JournalFilter) Matches(e JournalEntry) bool {
+	return (f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || !e.Time.After(f.Until)) &&
+		(f.Direction == "" || e.Direction == f.Direction)
+}
+
+// Journal is an append-only store of websocket messages. Append is called
+// from the read loop and should return quickly.
+type Journal interface {
+	// Append records one message
+	Append(entry JournalEntry) error
+	// Read calls fn with the entries matching filter in the order they
+	// were appended, stopping at the first error
+	Read(ctx context.Context, filter JournalFilter, fn func(JournalEntry) error) error
+}
+
+// SetJournal records every inbound and outbound message in journal, with
+// credentials in auth messages redacted as for SetMessageTap. Append
+// errors are passed to the error handler. A nil journal stops recording.
+func (c *WsClient) SetJournal(journal Journal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.journal = journal
+}
+
+// journalMessage appends message to the journal, if one is set
+func (c *WsClient) journalMessage(direction MessageDirection, message []byte) {
+	c.mu.RLock()
+	journal, errHandler := c.journal, c.errHandler
+	c.mu.RUnlock()
+
+	if journal == nil {
+		return
+	}
+
+	if direction == MessageOutbound {
+		message = redactAuth(message)
+	}
+	err := journal.Append(JournalEntry{Time: c.clock().Now(), Direction: direction, Message: string(message)})
+	if err != nil {
+		c.log().Warnf("error journaling message: %v", err)
+		if errHandler != nil {
+			errHandler(fmt.Errorf("journal: %w", err))
+		}
+	}
+}
+
+// Replay feeds the inbound messages in journal matching filter through the
+// registered handlers, as if they had just been received, to reproduce
+// strategy behavior after an incident. Control messages (auth, ping,
+// subscribe) are skipped, and replayed messages are not acknowledged,
+// tapped or journaled again. The client does not need to be connected.
+func (c *WsClient) Replay(ctx context.Context, journal Journal, filter JournalFilter) error {
+	filter.Direction = MessageInbound
+	return journal.Read(ctx, filter, func(e JournalEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.ReplayMessage([]byte(e.Message))
+		return nil
+	})
+}
+
+// ReplayMessage feeds one inbound message through the registered handlers,
+// see Replay. Execution reports reach the OnExecutionReport handlers first,
+// as live ones do, but do not move LastExecutionReportTime. Undecodable
+// messages are skipped. It returns the error of a panicking handler, if any.
+func (c *WsClient) ReplayMessage(message []byte) error {
+	var wsResp WsResponse
+	if err := json.Unmarshal(message, &wsResp); err != nil {
+		c.log().Warnf("error unmarshaling replayed message: %v", err)
+		return nil
+	}
+
+	switch wsResp.Op {
+	case "auth", "ping", "subscribe":
+		return nil
+	}
+
+	var handlerErr error
+	if wsResp.Op == "execution_report" {
+		// Decode errors go to the error handler, like the live ones
+		if _, decoded, err := c.applyExecutionReport(message); decoded {
+			handlerErr = err
+		}
+	}
+	if pattern, handler := c.route(wsResp.Op); handler != nil {
+		if err := c.dispatch(pattern, handler, message); handlerErr == nil {
+			handlerErr = err
+		}
+	}
+	return handlerErr
+}
+
+// MemoryJournal is a Journal keeping entries in memory, for tests and
+// short sessions. It grows without bound unless Limit is set.
+type MemoryJournal struct {
+	// Limit, when positive, drops the oldest entries beyond Limit
+	Limit int
+
+	mu      sync.RWMutex
+	entries []JournalEntry
+}
+
+// Append records entry
+func (j *MemoryJournal) Append(entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, entry)
+	if j.Limit > 0 && len(j.entries) > j.Limit {
+		j.entries = append(j.entries[:0:0], j.entries[len(j.entries)-j.Limit:]...)
+	}
+	return nil
+}
+
+// Read calls fn with the entries matching filter
+func (j *MemoryJournal) Read(ctx context.Context, filter JournalFilter, fn func(JournalEntry) error) error {
+	j.mu.RLock()
+	entries := append([]JournalEntry(nil), j.entries...)
+	j.mu.RUnlock()
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !filter.Matches(e) {
+			continue
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Entries returns a copy of the recorded entries
+func (j *MemoryJournal) Entries() []JournalEntry {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return append([]JournalEntry(nil), j.entries...)
+}
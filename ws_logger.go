@@ -0,0 +1,73 @@
+package versifi
+
+import "log"
+
+// LogLevel controls which WsClient log lines are emitted
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	LogLevelOff
+)
+
+// LeveledLogger is implemented by structured loggers (zap, logrus, slog adapters, ...)
+type LeveledLogger interface {
+	Debugf(format string, v ...interface{})
+	Infof(format string, v ...interface{})
+	Warnf(format string, v ...interface{})
+	Errorf(format string, v ...interface{})
+}
+
+// SetLogger routes all WsClient logging to logger, bypassing Logger and LogLevel
+func (c *WsClient) SetLogger(logger LeveledLogger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leveledLogger = logger
+}
+
+// log returns the logger to write to
+func (c *WsClient) log() LeveledLogger {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.logLocked()
+}
+
+// logLocked is like log but expects c.mu to be held
+func (c *WsClient) logLocked() LeveledLogger {
+	if c.leveledLogger != nil {
+		return c.leveledLogger
+	}
+	return stdLogger{logger: c.Logger, level: c.LogLevel}
+}
+
+// stdLogger filters a standard library logger by level
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+func (l stdLogger) logf(level LogLevel, prefix, format string, v ...interface{}) {
+	if l.logger == nil || level < l.level {
+		return
+	}
+	l.logger.Printf(prefix+format, v...)
+}
+
+func (l stdLogger) Debugf(format string, v ...interface{}) {
+	l.logf(LogLevelDebug, "[DEBUG] ", format, v...)
+}
+
+func (l stdLogger) Infof(format string, v ...interface{}) {
+	l.logf(LogLevelInfo, "[INFO] ", format, v...)
+}
+
+func (l stdLogger) Warnf(format string, v ...interface{}) {
+	l.logf(LogLevelWarn, "[WARN] ", format, v...)
+}
+
+func (l stdLogger) Errorf(format string, v ...interface{}) {
+	l.logf(LogLevelError, "[ERROR] ", format, v...)
+}
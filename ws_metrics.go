@@ -0,0 +1,85 @@
+package versifi
+
+import "time"
+
+// WsMetrics receives measurements from a WsClient.
+// Implementations must be safe for concurrent use and should return quickly,
+// since they are called inline on the read and write paths.
+type WsMetrics interface {
+	// MessageReceived is called for every inbound frame with its op and size
+	// in bytes. Ops neither known to the SDK nor subscribed to by name are
+	// reported as "other", so a server cannot grow the set of topics.
+	MessageReceived(topic string, bytes int)
+	// MessageSent is called for every outbound frame with its size in bytes
+	MessageSent(bytes int)
+	// HandlerLatency reports how long a subscription handler took for one message
+	HandlerLatency(topic string, d time.Duration)
+	// Reconnect is called after each reconnection attempt, err is nil on success
+	Reconnect(err error)
+	// AuthFailure is called when authentication is rejected or times out
+	AuthFailure()
+}
+
+// otherOp is the metrics topic of unexpected ops
+const otherOp = "other"
+
+type noopWsMetrics struct{}
+
+func (noopWsMetrics) MessageReceived(string, int)          {}
+func (noopWsMetrics) MessageSent(int)                      {}
+func (noopWsMetrics) HandlerLatency(string, time.Duration) {}
+func (noopWsMetrics) Reconnect(error)                      {}
+func (noopWsMetrics) AuthFailure()                         {}
+
+// SetMetrics sets the metrics hook, nil disables reporting
+func (c *WsClient) SetMetrics(m WsMetrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = m
+}
+
+func (c *WsClient) wsMetrics() WsMetrics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.metrics == nil {
+		return noopWsMetrics{}
+	}
+	return c.metrics
+}
+
+// metricOp returns the metrics topic of op: op itself if it is known or
+// has a handler of its own, otherOp otherwise
+func (c *WsClient) metricOp(op string) string {
+	if knownOps[op] {
+		return op
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.handlers[op]; ok {
+		return op
+	}
+	return otherOp
+}
+
+// dispatch invokes handler for message and records its latency.
+// Messages are dropped once Shutdown has started. A panic in the handler is
+// recovered, reported to the error handler and returned.
+func (c *WsClient) dispatch(topic string, handler WsHandler, message []byte) (err error) {
+	c.mu.RLock()
+	if c.draining {
+		c.mu.RUnlock()
+		return nil
+	}
+	c.inflight.Add(1)
+	c.mu.RUnlock()
+	defer c.inflight.Done()
+
+	start := time.Now()
+	defer func() {
+		c.wsMetrics().HandlerLatency(topic, time.Since(start))
+	}()
+	defer c.recoverHandler(topic, &err)
+
+	handler(message)
+	return nil
+}
@@ -0,0 +1,219 @@
+package versifi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrMalformedMessage is matched by every ParseError
+	ErrMalformedMessage = errors.New("malformed message")
+	// ErrMissingField is matched by ParseErrors for a required field that
+	// is absent or empty
+	ErrMissingField = errors.New("missing field")
+)
+
+// ParseError reports a websocket message that failed validation
+type ParseError struct {
+	// Op is the message's op, if it could be read
+	Op string
+	// Field is the JSON path of the offending field, such as
+	// "message.order.child_order.trades[0].trade_id", or "" for the whole
+	// message
+	Field string
+	// Missing is set when Field is absent or empty
+	Missing bool
+	// Reason describes an invalid field
+	Reason string
+	// Err is the underlying decoding error, if any
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	msg := "malformed message"
+	if e.Op != "" {
+		msg = "malformed " + e.Op + " message"
+	}
+	switch {
+	case e.Missing:
+		msg += ": missing " + e.Field
+	case e.Field != "":
+		msg += ": " + e.Field
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrMalformedMessage, and ErrMissingField for missing fields
+func (e *ParseError) Is(target error) bool {
+	return target == ErrMalformedMessage || (target == ErrMissingField && e.Missing)
+}
+
+// ParseMessage decodes the envelope of a websocket message, which must be a
+// JSON object with an op
+func ParseMessage(data []byte) (*WsResponse, error) {
+	if !isJSONObject(data) {
+		return nil, &ParseError{Reason: "not a JSON object"}
+	}
+	var msg WsResponse
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, &ParseError{Err: err}
+	}
+	if msg.Op == "" {
+		return nil, &ParseError{Field: "op", Missing: true}
+	}
+	return &msg, nil
+}
+
+// ParseExecutionReport decodes and validates an execution_report message.
+// Unlike a plain json.Unmarshal it rejects other ops, reports missing
+// identifiers and statuses, requires the order of basic, algo and pair
+// reports, and checks trade IDs and quantities. Reports of order types this
+// SDK does not know are accepted with the order left raw. Every error is a
+// *ParseError.
+//
+//	ws.SubscribeExecutionReport(func(message []byte) {
+//		report, err := versifi.ParseExecutionReport(message)
+//		if err != nil {
+//			log.Printf("bad report: %v", err)
+//			return
+//		}
+//		...
+//	})
+func ParseExecutionReport(data []byte) (*WsExecutionReport, error) {
+	const op = "execution_report"
+
+	if !isJSONObject(data) {
+		return nil, &ParseError{Reason: "not a JSON object"}
+	}
+	var envelope struct {
+		Op      string          `json:"op"`
+		Message json.RawMessage `json:"message"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, &ParseError{Err: err}
+	}
+	switch {
+	case envelope.Op == "":
+		return nil, &ParseError{Field: "op", Missing: true}
+	case envelope.Op != op:
+		return nil, &ParseError{Op: envelope.Op, Field: "op", Reason: fmt.Sprintf("expected %s", op)}
+	case !isJSONObject(envelope.Message):
+		return nil, &ParseError{Op: op, Field: "message", Missing: true}
+	}
+
+	var report WsExecutionReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, &ParseError{Op: op, Field: "message", Err: err}
+	}
+	if err := validateExecutionReport(&report.Message); err != nil {
+		err.Op = op
+		return nil, err
+	}
+	return &report, nil
+}
+
+func validateExecutionReport(d *WsExecutionReportDetail) *ParseError {
+	switch {
+	case d.OrderID <= 0:
+		return &ParseError{Field: "message.order_id", Missing: true}
+	case d.Status == "":
+		return &ParseError{Field: "message.status", Missing: true}
+	case d.RequestOrderType == "":
+		return &ParseError{Field: "message.request_order_type", Missing: true}
+	}
+
+	switch {
+	case d.Basic != nil:
+		if d.Basic.Exchange == "" {
+			return &ParseError{Field: "message.order.exchange", Missing: true}
+		}
+		if d.Basic.Symbol == "" {
+			return &ParseError{Field: "message.order.symbol", Missing: true}
+		}
+		return validateWsChildOrder("message.order.child_order", d.Basic.ChildOrder)
+	case d.Algo != nil:
+		if d.Algo.Exchange == "" {
+			return &ParseError{Field: "message.order.exchange", Missing: true}
+		}
+		if d.Algo.Symbol == "" {
+			return &ParseError{Field: "message.order.symbol", Missing: true}
+		}
+		return validateWsChildOrder("message.order.child_order", d.Algo.ChildOrder)
+	case d.Pair != nil:
+		if d.Pair.LeadLeg == nil {
+			return &ParseError{Field: "message.order.lead_leg", Missing: true}
+		}
+		if err := validateWsChildOrder("message.order.lead_leg.child_order", d.Pair.LeadLeg.ChildOrder); err != nil {
+			return err
+		}
+		if d.Pair.Leg != nil {
+			return validateWsChildOrder("message.order.leg.child_order", d.Pair.Leg.ChildOrder)
+		}
+		return nil
+	}
+
+	// The order was absent, or of a type this SDK does not decode
+	switch strings.ToLower(d.RequestOrderType) {
+	case RequestOrderTypeBasic, RequestOrderTypeAlgo, RequestOrderTypePair:
+		return &ParseError{Field: "message.order", Missing: true}
+	}
+	return nil
+}
+
+func validateWsChildOrder(path string, child *WsChildOrder) *ParseError {
+	if child == nil {
+		return nil
+	}
+	for i, t := range child.Trades {
+		field := fmt.Sprintf("%s.trades[%d]", path, i)
+		if t.TradeID <= 0 {
+			return &ParseError{Field: field + ".trade_id", Missing: true}
+		}
+		if err := validateDecimal(field+".executed_quantity", t.ExecutedQuantity, true); err != nil {
+			return err
+		}
+		if err := validateDecimal(field+".executed_price", t.ExecutedPrice, false); err != nil {
+			return err
+		}
+		if err := validateDecimal(field+".cummulative_filled_quantity", t.CummulativeFilledQuantity, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDecimal checks that value, if set, is a decimal number
+func validateDecimal(field, value string, required bool) *ParseError {
+	if value == "" {
+		if required {
+			return &ParseError{Field: field, Missing: true}
+		}
+		return nil
+	}
+	if _, err := decimal.NewFromString(value); err != nil {
+		return &ParseError{Field: field, Reason: fmt.Sprintf("invalid decimal %q", value)}
+	}
+	return nil
+}
+
+// isJSONObject reports whether data looks like a JSON object, which a
+// struct decode does not check: "null" decodes into any struct
+func isJSONObject(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
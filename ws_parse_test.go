@@ -0,0 +1,107 @@
+package versifi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseExecutionReport(t *testing.T) {
+	valid, _ := json.Marshal(basicExecutionReport(5, OrderStatusPartiallyFilled, 1000,
+		WsTrade{TradeID: 1, ExecutedPrice: "100", ExecutedQuantity: "0.5"}))
+
+	report, err := ParseExecutionReport(valid)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Message.OrderID != 5 || report.Message.Basic == nil || len(report.Message.Basic.ChildOrder.Trades) != 1 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	for _, tc := range []struct {
+		message string
+		field   string
+		missing bool
+	}{
+		{`null`, "", false},
+		{`[1]`, "", false},
+		{`{"op":`, "", false},
+		{`{"success":true}`, "op", true},
+		{`{"op":"analytics","message":{}}`, "op", false},
+		{`{"op":"execution_report","message":null}`, "message", true},
+		{`{"op":"execution_report","message":{"order_id":"x"}}`, "message", false},
+		{`{"op":"execution_report","message":{"status":"NEW"}}`, "message.order_id", true},
+		{`{"op":"execution_report","message":{"order_id":1,"request_order_type":"BASIC"}}`, "message.status", true},
+		{`{"op":"execution_report","message":{"order_id":1,"status":"NEW","request_order_type":"BASIC"}}`, "message.order", true},
+		{`{"op":"execution_report","message":{"order_id":1,"status":"NEW","request_order_type":"ALGO","order":{"exchange":"binance_spot"}}}`, "message.order.symbol", true},
+		{`{"op":"execution_report","message":{"order_id":1,"status":"NEW","request_order_type":"PAIR","order":{}}}`, "message.order.lead_leg", true},
+		{`{"op":"execution_report","message":{"order_id":1,"status":"NEW","request_order_type":"BASIC","order":{"exchange":"binance_spot","symbol":"BTC/USDT","child_order":{"id":1,"trades":[{"trade_id":1,"executed_quantity":"1e"}]}}}}`,
+			"message.order.child_order.trades[0].executed_quantity", false},
+		{`{"op":"execution_report","message":{"order_id":1,"status":"NEW","request_order_type":"BASIC","order":{"exchange":"binance_spot","symbol":"BTC/USDT","child_order":{"id":1,"trades":[{"executed_quantity":"1"}]}}}}`,
+			"message.order.child_order.trades[0].trade_id", true},
+	} {
+		_, err := ParseExecutionReport([]byte(tc.message))
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("%s: expected a ParseError, got %v", tc.message, err)
+			continue
+		}
+		if parseErr.Field != tc.field || errors.Is(err, ErrMissingField) != tc.missing {
+			t.Errorf("%s: expected field %q (missing %v), got %v", tc.message, tc.field, tc.missing, err)
+		}
+	}
+
+	// Order types this SDK does not know are kept raw
+	report, err = ParseExecutionReport([]byte(`{"op":"execution_report","message":{"order_id":1,"status":"NEW","request_order_type":"SPREAD","order":{"x":1}}}`))
+	if err != nil || string(report.Message.Order) != `{"x":1}` {
+		t.Errorf("Unexpected result %+v, %v", report, err)
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	msg, err := ParseMessage([]byte(`{"op":"auth","success":true,"version":"1.2"}`))
+	if err != nil || msg.Op != "auth" || !msg.Success || msg.Version != "1.2" {
+		t.Errorf("Unexpected result %+v, %v", msg, err)
+	}
+	if _, err := ParseMessage([]byte(`{"success":true}`)); !errors.Is(err, ErrMissingField) {
+		t.Errorf("Expected ErrMissingField, got %v", err)
+	}
+	if _, err := ParseMessage([]byte(`"auth"`)); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("Expected ErrMalformedMessage, got %v", err)
+	}
+}
+
+func FuzzParseExecutionReport(f *testing.F) {
+	basic, _ := json.Marshal(basicExecutionReport(5, OrderStatusFilled, 1000,
+		WsTrade{TradeID: 1, ExecutedPrice: "100", ExecutedQuantity: "2", CummulativeFilledQuantity: "2"}))
+	lead := int64(1)
+	pair, _ := json.Marshal(WsExecutionReport{Op: "execution_report", Success: true, Message: WsExecutionReportDetail{
+		OrderID: 9, Status: OrderStatusNew, RequestOrderType: "PAIR",
+		Pair: &WsPairOrderDetail{LeadLeg: &WsPairLeg{Symbol: "BTC/USDT", Exchange: ExchangeBinanceSpot,
+			ChildOrder: &WsChildOrder{ID: 1, Trades: []WsTrade{{TradeID: 3, LegID: &lead, ExecutedQuantity: "1"}}}}},
+	}})
+	for _, seed := range [][]byte{
+		basic,
+		pair,
+		[]byte(`{"op":"execution_report","message":{"order_id":1,"status":"NEW","request_order_type":"ALGO","order":null}}`),
+		[]byte(`{"op":"execution_report","message":{"order_id":1,"status":"NEW","request_order_type":"BASIC","order":[]}}`),
+		[]byte(`null`),
+		[]byte(``),
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		report, err := ParseExecutionReport(data)
+		if err != nil {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected a ParseError, got %T: %v", err, err)
+			}
+			return
+		}
+		if report.Message.OrderID <= 0 || report.Message.Status == "" {
+			t.Fatalf("Accepted an invalid report: %s", data)
+		}
+	})
+}
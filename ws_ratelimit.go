@@ -0,0 +1,63 @@
+package versifi
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSendQueueFull is returned by SendJSON when MaxSendQueue messages are
+// already waiting for the send limiter
+var ErrSendQueueFull = errors.New("send queue full")
+
+// SetSendLimiter throttles every outbound message, including auth, subscribe
+// and ping, through limiter so the client stays within the server's
+// per-connection message limit. Callers of SendJSON block while they wait
+// their turn. A nil limiter disables throttling.
+func (c *WsClient) SetSendLimiter(limiter RateLimiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendLimiter = limiter
+}
+
+// SendQueueDepth returns the number of messages waiting for the send limiter
+func (c *WsClient) SendQueueDepth() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sendQueue
+}
+
+// waitToSend blocks until the send limiter admits one more message.
+// Waiting is abandoned when the client is disconnected.
+func (c *WsClient) waitToSend() error {
+	c.mu.Lock()
+	limiter := c.sendLimiter
+	if limiter == nil {
+		c.mu.Unlock()
+		return nil
+	}
+	if c.MaxSendQueue > 0 && c.sendQueue >= c.MaxSendQueue {
+		c.mu.Unlock()
+		return ErrSendQueueFull
+	}
+	c.sendQueue++
+	done := c.done
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.sendQueue--
+		c.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return limiter.Wait(ctx)
+}
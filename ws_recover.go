@@ -0,0 +1,34 @@
+package versifi
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// HandlerPanicError reports a panic recovered from a subscription handler
+type HandlerPanicError struct {
+	Topic string
+	Value interface{}
+	Stack []byte
+}
+
+func (e *HandlerPanicError) Error() string {
+	return fmt.Sprintf("handler for %s panicked: %v", e.Topic, e.Value)
+}
+
+// recoverHandler stops a handler panic from killing the read loop. The panic
+// is stored in err and passed to the error handler.
+func (c *WsClient) recoverHandler(topic string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	panicErr := &HandlerPanicError{Topic: topic, Value: r, Stack: debug.Stack()}
+	*err = panicErr
+
+	c.log().Errorf("%v\n%s", panicErr, panicErr.Stack)
+	if c.errHandler != nil {
+		c.errHandler(panicErr)
+	}
+}
@@ -0,0 +1,93 @@
+package versifi
+
+import "sort"
+
+// ResumeHandler is called after a successful reconnect with the timestamp of
+// the last execution report received before the outage (zero if none), so
+// missed fills can be backfilled with BackfillOrdersService
+type ResumeHandler func(since int64)
+
+// SetResumeHandler sets the handler called after each reconnect
+func (c *WsClient) SetResumeHandler(handler ResumeHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resumeHandler = handler
+}
+
+// OnResume registers handler to be called after each reconnect, after the
+// handler set with SetResumeHandler, and returns a function that removes it.
+// Helpers such as OrderTracker use it so they do not replace the client's
+// resume handler.
+func (c *WsClient) OnResume(handler ResumeHandler) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumeHandlers == nil {
+		c.resumeHandlers = make(map[int]ResumeHandler)
+	}
+	id := c.nextResumeHandlerID
+	c.nextResumeHandlerID++
+	c.resumeHandlers[id] = handler
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.resumeHandlers, id)
+	}
+}
+
+// LastExecutionReportTime returns the timestamp of the latest execution report received
+func (c *WsClient) LastExecutionReportTime() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastReportTime
+}
+
+// recordExecutionReport remembers the newest execution report timestamp,
+// read without decoding the report
+func (c *WsClient) recordExecutionReport(message []byte) {
+	if ts, ok := peekInt(jsonField(jsonField(message, "message"), "timestamp")); ok {
+		c.recordReportTime(ts)
+	}
+}
+
+func (c *WsClient) recordReportTime(ts int64) {
+	c.mu.Lock()
+	if ts > c.lastReportTime {
+		c.lastReportTime = ts
+	}
+	c.mu.Unlock()
+}
+
+// resume asks the server to replay missed execution reports, when enabled,
+// and notifies the resume handler
+func (c *WsClient) resume() {
+	c.mu.RLock()
+	since := c.lastReportTime
+	handler := c.resumeHandler
+	ids := make([]int, 0, len(c.resumeHandlers))
+	for id := range c.resumeHandlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	handlers := make([]ResumeHandler, len(ids))
+	for i, id := range ids {
+		handlers[i] = c.resumeHandlers[id]
+	}
+	c.mu.RUnlock()
+
+	if c.ReplayOnReconnect && since > 0 {
+		replayMsg := map[string]interface{}{
+			"op":    "replay",
+			"since": since,
+		}
+		if err := c.SendJSON(replayMsg); err != nil {
+			c.log().Warnf("error requesting replay: %v", err)
+		}
+	}
+
+	if handler != nil {
+		handler(since)
+	}
+	for _, handler := range handlers {
+		handler(since)
+	}
+}
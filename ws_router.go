@@ -0,0 +1,49 @@
+package versifi
+
+import "strings"
+
+// Handle registers handler for messages whose op matches pattern without
+// sending a subscribe request. Patterns are matched with this precedence:
+//
+//   - an exact op, e.g. "execution_report"
+//   - the longest prefix pattern ending in "*", e.g. "execution_*"
+//   - the catch-all "*"
+//
+// Only the most specific handler is invoked for each message.
+func (c *WsClient) Handle(pattern string, handler WsHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[pattern] = handler
+}
+
+// route returns the most specific handler for op and the pattern it was registered with
+func (c *WsClient) route(op string) (string, WsHandler) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if handler, ok := c.handlers[op]; ok && handler != nil {
+		return op, handler
+	}
+
+	var (
+		bestPattern string
+		bestHandler WsHandler
+	)
+	for pattern, handler := range c.handlers {
+		if handler == nil || len(pattern) < 2 || !strings.HasSuffix(pattern, "*") {
+			continue
+		}
+		prefix := strings.TrimSuffix(pattern, "*")
+		if strings.HasPrefix(op, prefix) && len(pattern) > len(bestPattern) {
+			bestPattern, bestHandler = pattern, handler
+		}
+	}
+	if bestHandler != nil {
+		return bestPattern, bestHandler
+	}
+
+	if handler, ok := c.handlers["*"]; ok && handler != nil {
+		return "*", handler
+	}
+	return "", nil
+}
@@ -0,0 +1,143 @@
+package versifi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SupportedProtocolVersion is the websocket protocol version this SDK implements
+const SupportedProtocolVersion = "1"
+
+// SchemaEventType identifies a mismatch between the server and this SDK
+type SchemaEventType string
+
+const (
+	SchemaUnknownOp       SchemaEventType = "UNKNOWN_OP"
+	SchemaUnknownFields   SchemaEventType = "UNKNOWN_FIELDS"
+	SchemaVersionMismatch SchemaEventType = "VERSION_MISMATCH"
+)
+
+// SchemaEvent describes server output the SDK does not fully understand.
+// Messages are still delivered to handlers as usual.
+type SchemaEvent struct {
+	Type SchemaEventType
+	Op   string
+	// UnknownFields lists JSON paths such as "message.order.new_field"
+	UnknownFields []string
+	// ServerVersion is the protocol version reported by the server
+	ServerVersion string
+	Message       []byte
+}
+
+// SchemaHandler handles schema events
+type SchemaHandler func(event SchemaEvent)
+
+// knownOps are the ops this SDK understands
+var knownOps = map[string]bool{
+	"auth":             true,
+	"ping":             true,
+	"subscribe":        true,
+	"execution_report": true,
+	"analytics":        true,
+}
+
+// SetSchemaHandler sets a handler for unknown ops, unknown fields in known
+// messages and protocol version mismatches. Checking for unknown fields
+// costs an extra decode per message, so it only happens while a handler is set.
+func (c *WsClient) SetSchemaHandler(handler SchemaHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schemaHandler = handler
+}
+
+// ServerProtocolVersion returns the protocol version reported in the last auth response
+func (c *WsClient) ServerProtocolVersion() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.serverVersion
+}
+
+func (c *WsClient) schemaEvent(event SchemaEvent) {
+	c.mu.RLock()
+	handler := c.schemaHandler
+	c.mu.RUnlock()
+
+	if handler != nil {
+		handler(event)
+	}
+}
+
+// checkServerVersion records the server's protocol version and reports a
+// major version different from the one this SDK implements
+func (c *WsClient) checkServerVersion(version string) {
+	if version == "" {
+		return
+	}
+
+	c.mu.Lock()
+	c.serverVersion = version
+	c.mu.Unlock()
+
+	if majorVersion(version) != majorVersion(SupportedProtocolVersion) {
+		c.log().Warnf("server protocol version %s differs from supported version %s", version, SupportedProtocolVersion)
+		c.schemaEvent(SchemaEvent{Type: SchemaVersionMismatch, Op: "auth", ServerVersion: version})
+	}
+}
+
+func majorVersion(version string) string {
+	version = strings.TrimPrefix(version, "v")
+	return strings.SplitN(version, ".", 2)[0]
+}
+
+// checkSchema reports unknown ops and fields in message to the schema handler
+func (c *WsClient) checkSchema(op string, message []byte) {
+	c.mu.RLock()
+	handler := c.schemaHandler
+	c.mu.RUnlock()
+
+	if handler == nil {
+		return
+	}
+
+	if !knownOps[op] {
+		handler(SchemaEvent{Type: SchemaUnknownOp, Op: op, Message: message})
+		return
+	}
+
+	if op != "execution_report" {
+		return
+	}
+
+	fields := unknownFields(message, reflect.TypeOf(WsExecutionReport{}))
+
+	// The order is kept raw in the struct, so check it against its typed form
+	var report struct {
+		Message struct {
+			RequestOrderType string          `json:"request_order_type"`
+			Order            json.RawMessage `json:"order"`
+		} `json:"message"`
+	}
+	if json.Unmarshal(message, &report) == nil && len(report.Message.Order) > 0 {
+		var orderType reflect.Type
+		switch strings.ToLower(report.Message.RequestOrderType) {
+		case RequestOrderTypeBasic:
+			orderType = reflect.TypeOf(WsBasicOrderDetail{})
+		case RequestOrderTypeAlgo:
+			orderType = reflect.TypeOf(WsAlgoOrderDetail{})
+		case RequestOrderTypePair:
+			orderType = reflect.TypeOf(WsPairOrderDetail{})
+		}
+		if orderType != nil {
+			for _, field := range unknownFields(report.Message.Order, orderType) {
+				fields = append(fields, "message.order."+field)
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		sort.Strings(fields)
+		handler(SchemaEvent{Type: SchemaUnknownFields, Op: op, UnknownFields: fields, Message: message})
+	}
+}
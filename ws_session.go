@@ -0,0 +1,97 @@
+package versifi
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default session lifetimes used by NewWsClient
+const (
+	DefaultAuthExpiry     = 5 * time.Minute
+	DefaultReauthInterval = 4 * time.Minute
+)
+
+// SessionEventType represents a change in the websocket session
+type SessionEventType string
+
+const (
+	SessionRenewed     SessionEventType = "RENEWED"
+	SessionRenewFailed SessionEventType = "RENEW_FAILED"
+	SessionRevoked     SessionEventType = "REVOKED"
+)
+
+// SessionEvent describes a session renewal or revocation
+type SessionEvent struct {
+	Type SessionEventType
+	Err  error
+}
+
+// SessionHandler handles session events
+type SessionHandler func(event SessionEvent)
+
+// SetSessionHandler sets the handler notified when the session is renewed,
+// fails to renew, or is revoked by the server
+func (c *WsClient) SetSessionHandler(handler SessionHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessionHandler = handler
+}
+
+func (c *WsClient) sessionEvent(event SessionEvent) {
+	c.mu.RLock()
+	handler := c.sessionHandler
+	c.mu.RUnlock()
+
+	if handler != nil {
+		handler(event)
+	}
+}
+
+// revokeSession handles an auth failure the client did not ask for
+func (c *WsClient) revokeSession(message interface{}) {
+	c.mu.Lock()
+	c.isAuthenticated = false
+	c.mu.Unlock()
+
+	c.log().Warnf("Session revoked by server: %v", message)
+	c.sessionEvent(SessionEvent{
+		Type: SessionRevoked,
+		Err:  fmt.Errorf("%w: session revoked: %v", ErrAuthFailed, message),
+	})
+
+	// Ask renewSession to re-authenticate right away
+	select {
+	case c.reauth <- struct{}{}:
+	default:
+	}
+}
+
+// renewSession re-authenticates every ReauthInterval, before the signed
+// expiry passes, until the connection identified by readDone ends
+func (c *WsClient) renewSession(readDone <-chan struct{}) {
+	if c.ReauthInterval <= 0 {
+		return
+	}
+
+	ticker := c.clock().NewTicker(c.ReauthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-readDone:
+			return
+		case <-ticker.C():
+		case <-c.reauth:
+		}
+
+		if err := c.authenticate(); err != nil {
+			c.log().Errorf("session renewal failed: %v", err)
+			c.wsMetrics().AuthFailure()
+			c.sessionEvent(SessionEvent{Type: SessionRenewFailed, Err: err})
+			continue
+		}
+		c.sessionEvent(SessionEvent{Type: SessionRenewed})
+	}
+}
@@ -0,0 +1,65 @@
+package versifi
+
+import "encoding/json"
+
+// MessageDirection identifies whether a frame was sent or received
+type MessageDirection string
+
+const (
+	MessageInbound  MessageDirection = "inbound"
+	MessageOutbound MessageDirection = "outbound"
+)
+
+// MessageTap receives every raw websocket frame.
+// The message must not be modified or retained after the call returns.
+type MessageTap func(direction MessageDirection, message []byte)
+
+const redacted = "REDACTED"
+
+// SetMessageTap sets a tap that observes all inbound and outbound frames.
+// The API key and signature in auth messages are redacted before the tap sees them.
+func (c *WsClient) SetMessageTap(tap MessageTap) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tap = tap
+}
+
+// tapMessage passes message to the tap, if one is set
+func (c *WsClient) tapMessage(direction MessageDirection, message []byte) {
+	c.mu.RLock()
+	tap := c.tap
+	c.mu.RUnlock()
+
+	if tap == nil {
+		return
+	}
+
+	if direction == MessageOutbound {
+		message = redactAuth(message)
+	}
+	tap(direction, message)
+}
+
+// redactAuth masks credentials in an auth message, other messages are returned as is
+func redactAuth(message []byte) []byte {
+	var msg struct {
+		Op   string        `json:"op"`
+		Args []interface{} `json:"args"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil || msg.Op != "auth" {
+		return message
+	}
+
+	// args are [api key, expires, signature]
+	for i := range msg.Args {
+		if i != 1 {
+			msg.Args[i] = redacted
+		}
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return []byte(`{"op":"auth"}`)
+	}
+	return data
+}
@@ -0,0 +1,107 @@
+// Package wsjournal provides persistent backends for the websocket message
+// journal of versifi.WsClient.
+//
+//	j, err := wsjournal.NewJSONL("ws.jsonl")
+//	if err != nil {
+//		return err
+//	}
+//	defer j.Close()
+//	wsClient.SetJournal(j)
+//
+// After an incident, register the same handlers on a fresh client and
+// replay the session:
+//
+//	err = replayClient.Replay(ctx, j, versifi.JournalFilter{Since: start, Until: end})
+package wsjournal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+var _ versifi.Journal = (*JSONL)(nil)
+
+// JSONL is a Journal appending one JSON entry per line to a file
+type JSONL struct {
+	// Sync flushes the file to stable storage after every append
+	Sync bool
+
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewJSONL opens, or creates, the file at path for appending
+func NewJSONL(path string) (*JSONL, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONL{path: path, file: file}, nil
+}
+
+// Append writes entry as a new line
+func (j *JSONL) Append(entry versifi.JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(line); err != nil {
+		return err
+	}
+	if j.Sync {
+		return j.file.Sync()
+	}
+	return nil
+}
+
+// Read scans the file for entries matching filter. It reads through its
+// own handle, so appends may continue while fn runs.
+func (j *JSONL) Read(ctx context.Context, filter versifi.JournalFilter, fn func(versifi.JournalEntry) error) error {
+	file, err := os.Open(j.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e versifi.JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if !filter.Matches(e) {
+			continue
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Close closes the file
+func (j *JSONL) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
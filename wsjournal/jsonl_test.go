@@ -0,0 +1,52 @@
+package wsjournal
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	versifi "github.com/drinkthere/versifi-go"
+)
+
+func TestJSONL(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "ws.jsonl")
+
+	j, err := NewJSONL(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, e := range []versifi.JournalEntry{
+		{Direction: versifi.MessageOutbound, Message: `{"op":"subscribe","args":["execution_report"]}`},
+		{Direction: versifi.MessageInbound, Message: `{"op":"execution_report","message":{"order_id":1}}`},
+		{Direction: versifi.MessageInbound, Message: `{"op":"execution_report","message":{"order_id":2}}`},
+	} {
+		e.Time = start.Add(time.Duration(i) * time.Second)
+		if err := j.Append(e); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	j.Close()
+
+	// Entries survive reopening
+	j, err = NewJSONL(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer j.Close()
+
+	var messages []string
+	err = j.Read(ctx, versifi.JournalFilter{Since: start.Add(time.Second), Direction: versifi.MessageInbound}, func(e versifi.JournalEntry) error {
+		messages = append(messages, e.Message)
+		// Appending while reading must not block
+		return j.Append(versifi.JournalEntry{Time: time.Now(), Direction: versifi.MessageOutbound, Message: `{"op":"ping"}`})
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(messages) != 2 || messages[1] != `{"op":"execution_report","message":{"order_id":2}}` {
+		t.Errorf("Unexpected messages %v", messages)
+	}
+}